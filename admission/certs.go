@@ -0,0 +1,188 @@
+package admission
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+)
+
+const (
+	certFileName = "tls.crt"
+	keyFileName  = "tls.key"
+
+	// certReloadInterval is how often the certificate is reloaded regardless of file events,
+	// in case events are missed, e.g. when the certificate directory is replaced
+	certReloadInterval = time.Minute
+
+	// certExpiryWarningPeriod is how long before its expiry a certificate that was not rotated is warned about
+	certExpiryWarningPeriod = 7 * 24 * time.Hour
+
+	certExpiring = "expiring"
+	certExpired  = "expired"
+)
+
+// certWatcher serves the webhook server certificate from the certificate directory,
+// reloading it when the files change so rotated certificates are served without a restart.
+// Secret volumes are updated by replacing a symlink in the directory, so the directory is watched.
+type certWatcher struct {
+	certDir      string
+	timeProvider func() time.Time
+	log          logr.Logger
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	leaf *x509.Certificate
+	// expiryWarning is the last expiry state warned about for the loaded certificate
+	expiryWarning string
+}
+
+func newCertWatcher(certDir string, timeProvider func() time.Time, log logr.Logger) *certWatcher {
+	return &certWatcher{
+		certDir:      certDir,
+		timeProvider: timeProvider,
+		log:          log,
+	}
+}
+
+// load reads the certificate and key, keeping the current certificate if they are invalid
+func (w *certWatcher) load() error {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(w.certDir, certFileName), filepath.Join(w.certDir, keyFileName))
+	if err != nil {
+		return fmt.Errorf("could not load certificate, %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("could not parse certificate, %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.leaf != nil && w.leaf.Equal(leaf) {
+		return nil
+	}
+	w.cert = &cert
+	w.leaf = leaf
+	w.expiryWarning = ""
+	webhookCertificateExpiry.Set(float64(leaf.NotAfter.Unix()))
+	w.log.Info("loaded webhook certificate", "directory", w.certDir, "serial", leaf.SerialNumber.String(), "notAfter", leaf.NotAfter)
+	return nil
+}
+
+// GetCertificate returns the current certificate, implementing tls.Config's GetCertificate
+func (w *certWatcher) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.cert == nil {
+		return nil, fmt.Errorf("no webhook certificate loaded")
+	}
+	return w.cert, nil
+}
+
+// checkLoaded returns an error if no valid certificate is loaded
+func (w *certWatcher) checkLoaded() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.leaf == nil {
+		return fmt.Errorf("no webhook certificate loaded from %s", w.certDir)
+	}
+	return w.checkValidity()
+}
+
+func (w *certWatcher) checkValidity() error {
+	now := w.timeProvider()
+	if now.After(w.leaf.NotAfter) {
+		return fmt.Errorf("webhook certificate expired at %s", w.leaf.NotAfter)
+	}
+	if now.Before(w.leaf.NotBefore) {
+		return fmt.Errorf("webhook certificate not valid before %s", w.leaf.NotBefore)
+	}
+	return nil
+}
+
+// warnExpiry logs a warning once the loaded certificate is about to expire and once it expired,
+// an expired certificate only makes the webhook unready, so rotation failures are surfaced here
+func (w *certWatcher) warnExpiry() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.leaf == nil {
+		return
+	}
+
+	now := w.timeProvider()
+	var state string
+	switch {
+	case now.After(w.leaf.NotAfter):
+		state = certExpired
+	case now.Add(certExpiryWarningPeriod).After(w.leaf.NotAfter):
+		state = certExpiring
+	default:
+		return
+	}
+	if state == w.expiryWarning {
+		return
+	}
+	w.expiryWarning = state
+
+	if state == certExpired {
+		w.log.Error(fmt.Errorf("webhook certificate expired at %s", w.leaf.NotAfter), "webhook certificate was not rotated",
+			"directory", w.certDir, "serial", w.leaf.SerialNumber.String())
+		return
+	}
+	w.log.Info("webhook certificate expires soon and was not rotated yet",
+		"directory", w.certDir, "serial", w.leaf.SerialNumber.String(), "notAfter", w.leaf.NotAfter)
+}
+
+// Watch reloads the certificate when the files in the certificate directory change, until the context is done
+func (w *certWatcher) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not create certificate watcher, %w", err)
+	}
+	defer watcher.Close()
+
+	events, errs := watcher.Events, watcher.Errors
+	if err := watcher.Add(w.certDir); err != nil {
+		// the directory may not exist yet, the certificate is still reloaded periodically
+		w.log.Error(err, "could not watch certificate directory", "directory", w.certDir)
+		events, errs = nil, nil
+	}
+
+	ticker := time.NewTicker(certReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			w.reload("event", event.String())
+		case err, ok := <-errs:
+			if !ok {
+				return nil
+			}
+			w.log.Error(err, "certificate watcher error")
+		case <-ticker.C:
+			w.reload("trigger", "interval")
+		}
+	}
+}
+
+func (w *certWatcher) reload(keysAndValues ...interface{}) {
+	if err := w.load(); err != nil {
+		w.log.Error(err, "could not reload webhook certificate, serving the current certificate", keysAndValues...)
+	}
+	w.warnExpiry()
+}
@@ -0,0 +1,143 @@
+package admission
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self-signed certificate and key for localhost to the directory
+func writeTestCertificate(t *testing.T, dir string, serial int64, notBefore time.Time, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "wave-admission-control"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, keyFileName), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, certFileName), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+}
+
+func getTestCertDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "webhook-certs")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func getServedSerial(t *testing.T, w *certWatcher) int64 {
+	cert, err := w.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.SerialNumber.Int64()
+}
+
+func TestCertWatcher(t *testing.T) {
+	now := time.Now()
+
+	t.Run("whenNoCertificate", func(tt *testing.T) {
+		w := newCertWatcher(getTestCertDir(tt), time.Now, log)
+		assert.Error(tt, w.load())
+		_, err := w.GetCertificate(nil)
+		assert.Error(tt, err)
+		assert.Error(tt, w.checkLoaded())
+		w.warnExpiry()
+		assert.Equal(tt, "", w.expiryWarning)
+	})
+
+	t.Run("whenValidCertificate", func(tt *testing.T) {
+		dir := getTestCertDir(tt)
+		writeTestCertificate(tt, dir, 1, now.Add(-time.Hour), now.Add(time.Hour))
+		w := newCertWatcher(dir, time.Now, log)
+		require.NoError(tt, w.load())
+		assert.Equal(tt, int64(1), getServedSerial(tt, w))
+		assert.NoError(tt, w.checkLoaded())
+		assert.Equal(tt, float64(now.Add(time.Hour).Unix()), testutil.ToFloat64(webhookCertificateExpiry))
+	})
+
+	t.Run("whenCertificateExpires", func(tt *testing.T) {
+		dir := getTestCertDir(tt)
+		writeTestCertificate(tt, dir, 1, now.Add(-time.Hour), now.Add(time.Hour))
+		later := now.Add(2 * time.Hour)
+		w := newCertWatcher(dir, func() time.Time { return later }, log)
+		require.NoError(tt, w.load())
+		assert.Error(tt, w.checkLoaded())
+		w.warnExpiry()
+		assert.Equal(tt, certExpired, w.expiryWarning)
+	})
+
+	t.Run("whenCertificateExpiresSoon", func(tt *testing.T) {
+		dir := getTestCertDir(tt)
+		writeTestCertificate(tt, dir, 1, now.Add(-time.Hour), now.Add(24*time.Hour))
+		w := newCertWatcher(dir, time.Now, log)
+		require.NoError(tt, w.load())
+		assert.NoError(tt, w.checkLoaded())
+		w.warnExpiry()
+		assert.Equal(tt, certExpiring, w.expiryWarning)
+
+		// a rotated certificate resets the warning
+		writeTestCertificate(tt, dir, 2, now.Add(-time.Hour), now.Add(30*24*time.Hour))
+		require.NoError(tt, w.load())
+		w.warnExpiry()
+		assert.Equal(tt, "", w.expiryWarning)
+	})
+
+	t.Run("whenInvalidCertificateWritten", func(tt *testing.T) {
+		dir := getTestCertDir(tt)
+		writeTestCertificate(tt, dir, 1, now.Add(-time.Hour), now.Add(time.Hour))
+		w := newCertWatcher(dir, time.Now, log)
+		require.NoError(tt, w.load())
+
+		require.NoError(tt, ioutil.WriteFile(filepath.Join(dir, certFileName), []byte("garbage"), 0600))
+		assert.Error(tt, w.load())
+		assert.Equal(tt, int64(1), getServedSerial(tt, w))
+		assert.NoError(tt, w.checkLoaded())
+	})
+
+	t.Run("whenCertificateRotated", func(tt *testing.T) {
+		dir := getTestCertDir(tt)
+		writeTestCertificate(tt, dir, 1, now.Add(-time.Hour), now.Add(time.Hour))
+		w := newCertWatcher(dir, time.Now, log)
+		require.NoError(tt, w.load())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan error)
+		go func() { done <- w.Watch(ctx) }()
+
+		// give the watcher time to start watching the directory
+		time.Sleep(100 * time.Millisecond)
+		writeTestCertificate(tt, dir, 2, now.Add(-time.Hour), now.Add(time.Hour))
+		assert.Eventually(tt, func() bool {
+			return getServedSerial(tt, w) == 2
+		}, 5*time.Second, 50*time.Millisecond)
+
+		cancel()
+		assert.NoError(tt, <-done)
+	})
+}
@@ -3,29 +3,44 @@ package admission
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/magiconair/properties"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/spotinst/wave-operator/cloudstorage"
 	"github.com/spotinst/wave-operator/internal/config"
 )
 
+const (
+	sparkDriverAnnotationPrefix = "spark.kubernetes.driver.annotation."
+	sparkDriverLabelPrefix      = "spark.kubernetes.driver.label."
+)
+
+// sparkPropertiesKeys are the config map keys holding Spark properties, in order of precedence.
+// Spark on Kubernetes mounts spark.properties, spark-defaults.conf is read from SPARK_CONF_DIR by spark-submit.
+var sparkPropertiesKeys = []string{"spark.properties", "spark-defaults.conf"}
+
 type ConfigMapMutator struct {
-	log      logr.Logger
-	client   kubernetes.Interface
-	provider cloudstorage.CloudStorageProvider
+	log            logr.Logger
+	client         kubernetes.Interface
+	provider       cloudstorage.CloudStorageProvider
+	policyProvider PolicyProvider
+	sparkConf      config.SparkConf
 }
 
-func NewConfigMapMutator(log logr.Logger, client kubernetes.Interface, provider cloudstorage.CloudStorageProvider) ConfigMapMutator {
+func NewConfigMapMutator(log logr.Logger, client kubernetes.Interface, provider cloudstorage.CloudStorageProvider, policyProvider PolicyProvider, sparkConf config.SparkConf) ConfigMapMutator {
 	return ConfigMapMutator{
-		log:      log,
-		client:   client,
-		provider: provider,
+		log:            log,
+		client:         client,
+		provider:       provider,
+		policyProvider: policyProvider,
+		sparkConf:      sparkConf,
 	}
 }
 
@@ -48,61 +63,79 @@ func (m ConfigMapMutator) Mutate(req *admissionv1.AdmissionRequest) (*admissionv
 		Allowed: true,
 	}
 
-	if len(sourceObj.ObjectMeta.OwnerReferences) == 0 {
+	if len(sourceObj.OwnerReferences) == 0 && sourceObj.Labels[SparkAppLabel] == "" {
 		return resp, nil
 	}
 
-	if sourceObj.Data["spark.properties"] == "" {
+	propertiesKey := getSparkPropertiesKey(sourceObj)
+	if propertiesKey == "" {
 		return resp, nil
 	}
 
-	ownerPod, err := m.client.CoreV1().Pods(sourceObj.Namespace).Get(ctx, sourceObj.OwnerReferences[0].Name, metav1.GetOptions{})
+	namespace := sourceObj.Namespace
+	if namespace == "" {
+		namespace = req.Namespace
+	}
+
+	modObj := sourceObj.DeepCopy()
+	props, err := properties.LoadString(modObj.Data[propertiesKey])
 	if err != nil {
-		log.Error(err, "could not get owner pod")
+		log.Error(err, "un-parsable spark property data in configmap")
 		return resp, nil
 	}
 
-	log.Info("Got config map owner pod",
-		"name", ownerPod.Name, "namespace", ownerPod.Namespace, "labels", ownerPod.Labels, "annotations", ownerPod.Annotations)
+	if props == nil {
+		props = properties.NewProperties()
+	}
+	original := props.Map()
 
-	if ownerPod.Labels[SparkRoleLabel] != SparkRoleDriverValue {
+	ownerPod := m.getDriverPod(ctx, sourceObj, namespace, props, log)
+	if ownerPod == nil {
 		log.Info("Not a driver config map, will not mutate config map")
 		return resp, nil
 	}
 
+	log.Info("Got config map driver pod",
+		"name", ownerPod.Name, "namespace", ownerPod.Namespace, "labels", ownerPod.Labels, "annotations", ownerPod.Annotations)
+
+	policy := getPolicy(ctx, m.policyProvider, namespace, log)
+
 	propOverride := map[string]string{
 		"spark.metrics.appStatusSource.enabled": "true",
 	}
 
-	if config.IsEventLogSyncEnabled(ownerPod.Annotations) {
+	if resolveEventLogSync(ownerPod.Annotations, policy) {
 		log.Info("Event log sync enabled, attempting to configure event log")
 		storageInfo, err := m.provider.GetStorageInfo()
 		if err != nil || storageInfo == nil {
 			log.Error(err, "Not configuring event log sync, error getting storage info")
 		} else {
 			log.Info("Storage info present will enable event log")
-			propOverride["spark.eventLog.dir"] = "file:///var/log/spark"
+			propOverride["spark.eventLog.dir"] = config.SyncedEventLogDir
 			propOverride["spark.eventLog.enabled"] = "true"
 		}
 	}
 
-	modObj := sourceObj.DeepCopy()
-	log.Info("constructing patch", "owner", sourceObj.OwnerReferences[0].Name)
-	propertyString := modObj.Data["spark.properties"]
+	log.Info("constructing patch", "driver", ownerPod.Name, "key", propertiesKey)
 
-	props, err := properties.LoadString(propertyString)
-	if err != nil {
-		log.Error(err, "un-parsable spark property data in configmap")
-		return resp, nil
-	}
+	// The properties required by the operator take precedence over the configured ones, event log sync depends on them
+	applySparkConf(props, m.sparkConf, policy, ownerPod.Annotations)
+	props.Merge(properties.LoadMap(propOverride))
 
-	if props == nil {
-		props = properties.NewProperties()
-	}
+	// Decommissioning properties are defaults, the application's and configured properties take precedence
+	mergeSparkConfDefaults(props, m.getDecommissionConf(ownerPod, props, policy, log))
+	mergeSparkConfDefaults(props, getZoneAffinityConf(ownerPod))
 
-	props.Merge(properties.LoadMap(propOverride))
+	modObj.Data[propertiesKey] = props.String()
 
-	modObj.Data["spark.properties"] = props.String()
+	changed := getChangedSparkConf(original, props)
+	if len(changed) > 0 {
+		log.Info("Changed spark properties", "keys", changed)
+		if modObj.Annotations == nil {
+			modObj.Annotations = make(map[string]string)
+		}
+		modObj.Annotations[config.WaveConfigAnnotationSparkConfChanged] = strings.Join(changed, ",")
+	}
 
 	patch, err := GetJsonPatch(sourceObj, modObj)
 	if err != nil {
@@ -116,3 +149,77 @@ func (m ConfigMapMutator) Mutate(req *admissionv1.AdmissionRequest) (*admissionv
 	resp.PatchType = &jsonPatchType
 	return resp, nil
 }
+
+// getSparkPropertiesKey returns the config map key holding the Spark properties, empty if there is none
+func getSparkPropertiesKey(cm *corev1.ConfigMap) string {
+	for _, key := range sparkPropertiesKeys {
+		if cm.Data[key] != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// getDriverPod returns the driver pod of the config map, nil if it is not a driver config map.
+// The driver is found among the config map's owners, or by the config map's application label.
+// Config maps created before their driver pod are matched to a pending driver described by the Spark properties.
+func (m ConfigMapMutator) getDriverPod(ctx context.Context, cm *corev1.ConfigMap, namespace string, props *properties.Properties, log logr.Logger) *corev1.Pod {
+	for _, owner := range cm.OwnerReferences {
+		if owner.Kind != "Pod" {
+			continue
+		}
+		pod, err := m.client.CoreV1().Pods(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			log.Error(err, "could not get owner pod", "owner", owner.Name)
+			continue
+		}
+		if pod.Labels[SparkRoleLabel] == SparkRoleDriverValue {
+			return pod
+		}
+	}
+
+	appID := cm.Labels[SparkAppLabel]
+	if appID == "" {
+		return nil
+	}
+
+	selector := labels.SelectorFromSet(map[string]string{
+		SparkAppLabel:  appID,
+		SparkRoleLabel: SparkRoleDriverValue,
+	})
+	pods, err := m.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		log.Error(err, "could not list driver pods", "application", appID)
+		return nil
+	}
+	if len(pods.Items) > 0 {
+		return &pods.Items[0]
+	}
+
+	log.Info("Driver pod does not exist yet, using the driver configuration of the spark properties", "application", appID)
+	return getPendingDriverPod(namespace, appID, props)
+}
+
+// getPendingDriverPod returns the driver pod described by the application's Spark properties
+func getPendingDriverPod(namespace string, appID string, props *properties.Properties) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        props.GetString("spark.kubernetes.driver.pod.name", ""),
+			Namespace:   namespace,
+			Labels:      getPrefixedProperties(props, sparkDriverLabelPrefix),
+			Annotations: getPrefixedProperties(props, sparkDriverAnnotationPrefix),
+		},
+	}
+	pod.Labels[SparkAppLabel] = appID
+	pod.Labels[SparkRoleLabel] = SparkRoleDriverValue
+	return pod
+}
+
+// getPrefixedProperties returns the properties with the prefix, keyed without the prefix
+func getPrefixedProperties(props *properties.Properties, prefix string) map[string]string {
+	filtered := make(map[string]string)
+	for _, key := range props.FilterStripPrefix(prefix).Keys() {
+		filtered[key] = props.GetString(prefix+key, "")
+	}
+	return filtered
+}
@@ -4,9 +4,12 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/magiconair/properties"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 
@@ -105,7 +108,7 @@ func getDriverPod(name string, namespace string, eventLogSyncEnabled bool, event
 func TestMutateEmptyCM(t *testing.T) {
 	clientSet := k8sfake.NewSimpleClientset()
 	req := getAdmissionRequest(t, emptyConfigMap)
-	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}).Mutate(req)
+	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}, newTestPolicyProvider(), config.SparkConf{}).Mutate(req)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	assert.Equal(t, emptyConfigMap.UID, r.UID)
@@ -117,7 +120,7 @@ func TestMutateEmptyCM(t *testing.T) {
 func TestMutateNonSparkCM(t *testing.T) {
 	clientSet := k8sfake.NewSimpleClientset()
 	req := getAdmissionRequest(t, nonSparkConfigMap)
-	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}).Mutate(req)
+	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}, newTestPolicyProvider(), config.SparkConf{}).Mutate(req)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	assert.Equal(t, nonSparkConfigMap.UID, r.UID)
@@ -133,7 +136,7 @@ func TestMutateNonSparkDriverCM(t *testing.T) {
 	testFunc := func(ownerPod *corev1.Pod) {
 		clientSet := k8sfake.NewSimpleClientset(ownerPod)
 		req := getAdmissionRequest(t, cm)
-		r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}).Mutate(req)
+		r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}, newTestPolicyProvider(), config.SparkConf{}).Mutate(req)
 		assert.NoError(t, err)
 		assert.NotNil(t, r)
 		assert.Equal(t, cm.UID, r.UID)
@@ -168,7 +171,7 @@ func TestMutateBadSparkCM(t *testing.T) {
 	driver := getDriverPod(cm.OwnerReferences[0].Name, cm.Namespace, true, "")
 	clientSet := k8sfake.NewSimpleClientset(driver)
 	req := getAdmissionRequest(t, cm)
-	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}).Mutate(req)
+	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}, newTestPolicyProvider(), config.SparkConf{}).Mutate(req)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	assert.Equal(t, cm.UID, r.UID)
@@ -182,7 +185,7 @@ func TestMutateSparkCM(t *testing.T) {
 	driver := getDriverPod(cm.OwnerReferences[0].Name, cm.Namespace, true, "")
 	clientSet := k8sfake.NewSimpleClientset(driver)
 	req := getAdmissionRequest(t, cm)
-	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}).Mutate(req)
+	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}, newTestPolicyProvider(), config.SparkConf{}).Mutate(req)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	assert.Equal(t, cm.UID, r.UID)
@@ -201,7 +204,7 @@ func TestMutateConfiguresMetrics(t *testing.T) {
 	driver := getDriverPod(cm.OwnerReferences[0].Name, cm.Namespace, false, "")
 	clientSet := k8sfake.NewSimpleClientset(driver)
 	req := getAdmissionRequest(t, cm)
-	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}).Mutate(req)
+	r, err := NewConfigMapMutator(log, clientSet, &util.FakeStorageProvider{}, newTestPolicyProvider(), config.SparkConf{}).Mutate(req)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	assert.Equal(t, cm.UID, r.UID)
@@ -238,7 +241,7 @@ func TestEventLogSyncConfiguration(t *testing.T) {
 			tc.storageProvider = &util.FakeStorageProvider{}
 		}
 
-		r, err := NewConfigMapMutator(log, clientSet, tc.storageProvider).Mutate(req)
+		r, err := NewConfigMapMutator(log, clientSet, tc.storageProvider, newTestPolicyProvider(), config.SparkConf{}).Mutate(req)
 		assert.NoError(tt, err)
 		assert.NotNil(tt, r)
 		assert.Equal(tt, cm.UID, r.UID)
@@ -328,3 +331,84 @@ func TestEventLogSyncConfiguration(t *testing.T) {
 		testFunc(tt, tc)
 	})
 }
+
+func TestMutateConfigMap_driverDetection(t *testing.T) {
+
+	const appID = "spark-d645eaf14e484364a48daf153b95f824"
+
+	mutate := func(t *testing.T, cm *corev1.ConfigMap, objects ...runtime.Object) *properties.Properties {
+		r, err := NewConfigMapMutator(log, k8sfake.NewSimpleClientset(objects...), &util.FakeStorageProvider{}, newTestPolicyProvider(), config.SparkConf{}).Mutate(getAdmissionRequest(t, cm))
+		require.NoError(t, err)
+		if r.Patch == nil {
+			return nil
+		}
+		obj, err := ApplyJsonPatch(r.Patch, cm)
+		require.NoError(t, err)
+		mutated, ok := obj.(*corev1.ConfigMap)
+		require.True(t, ok)
+		data := mutated.Data["spark.properties"]
+		if data == "" {
+			data = mutated.Data["spark-defaults.conf"]
+		}
+		props, err := properties.LoadString(data)
+		require.NoError(t, err)
+		return props
+	}
+
+	getLabeledConfigMap := func() *corev1.ConfigMap {
+		cm := sparkConfigMap.DeepCopy()
+		cm.OwnerReferences = nil
+		cm.Labels = map[string]string{SparkAppLabel: appID}
+		return cm
+	}
+
+	t.Run("whenDriverNotFirstOwner", func(tt *testing.T) {
+		cm := sparkConfigMap.DeepCopy()
+		cm.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "sparkoperator.k8s.io/v1beta2", Kind: "SparkApplication", Name: "spark-pi-512"},
+			{APIVersion: "v1", Kind: "Pod", Name: "missing-pod"},
+			cm.OwnerReferences[0],
+		}
+		driver := getDriverPod(sparkConfigMap.OwnerReferences[0].Name, cm.Namespace, true, "")
+		props := mutate(tt, cm, driver)
+		require.NotNil(tt, props)
+		assert.Equal(tt, config.SyncedEventLogDir, props.MustGet("spark.eventLog.dir"))
+	})
+
+	t.Run("whenMatchedByLabel", func(tt *testing.T) {
+		driver := getDriverPod("spark-pi-512-driver", "spark-jobs", true, "")
+		driver.Labels[SparkAppLabel] = appID
+		props := mutate(tt, getLabeledConfigMap(), driver)
+		require.NotNil(tt, props)
+		assert.Equal(tt, config.SyncedEventLogDir, props.MustGet("spark.eventLog.dir"))
+	})
+
+	t.Run("whenDriverNotCreated", func(tt *testing.T) {
+		cm := getLabeledConfigMap()
+		cm.Data["spark.properties"] += "spark.kubernetes.driver.annotation.wave.spot.io/sync-event-logs=true\n"
+		props := mutate(tt, cm)
+		require.NotNil(tt, props)
+		assert.Equal(tt, "true", props.MustGet("spark.metrics.appStatusSource.enabled"))
+		assert.Equal(tt, config.SyncedEventLogDir, props.MustGet("spark.eventLog.dir"))
+
+		props = mutate(tt, getLabeledConfigMap())
+		require.NotNil(tt, props)
+		_, ok := props.Get("spark.eventLog.dir")
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenOwnerNotDriverAndNoLabel", func(tt *testing.T) {
+		executor := getDriverPod(sparkConfigMap.OwnerReferences[0].Name, sparkConfigMap.Namespace, true, "")
+		executor.Labels[SparkRoleLabel] = SparkRoleExecutorValue
+		assert.Nil(tt, mutate(tt, sparkConfigMap, executor))
+	})
+
+	t.Run("whenSparkDefaultsConf", func(tt *testing.T) {
+		cm := getLabeledConfigMap()
+		cm.Data = map[string]string{"spark-defaults.conf": "spark.executor.memory   512m\nspark.executor.cores 1\n"}
+		props := mutate(tt, cm)
+		require.NotNil(tt, props)
+		assert.Equal(tt, "512m", props.MustGet("spark.executor.memory"))
+		assert.Equal(tt, "true", props.MustGet("spark.metrics.appStatusSource.enabled"))
+	})
+}
@@ -0,0 +1,172 @@
+package admission
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/magiconair/properties"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+const (
+	// sparkVersionLabel is set by Spark on driver and executor pods
+	sparkVersionLabel = "spark-version"
+	// sparkOperatorVersionLabel is set by the spark-operator to the application's Spark version
+	sparkOperatorVersionLabel = "version"
+
+	sparkExecutorAnnotationPrefix = "spark.kubernetes.executor.annotation."
+
+	executorContainerName = "spark-kubernetes-executor"
+	// decommissionScript is shipped in Spark images, it signals the executor to decommission and waits for it to exit
+	decommissionScript = "/opt/decom.sh"
+	// executorDecommissionGracePeriod lets executors migrate their blocks, matching the spot interruption notice
+	executorDecommissionGracePeriod = 120 * time.Second
+
+	fallbackStorageDir = "spark-decommission/"
+)
+
+// supportsDecommission returns whether the Spark version supports graceful executor decommissioning, Spark 3.1 or later
+func supportsDecommission(sparkVersion string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(sparkVersion), "v"), ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return major > 3 || (major == 3 && minor >= 1)
+}
+
+// getSparkVersion returns the pod's Spark version from its labels, or from the wave.spot.io/spark-version annotation
+func getSparkVersion(pod *corev1.Pod) string {
+	if v := pod.Labels[sparkVersionLabel]; v != "" {
+		return v
+	}
+	if v := pod.Labels[sparkOperatorVersionLabel]; v != "" {
+		return v
+	}
+	return strings.TrimSpace(pod.Annotations[config.WaveConfigAnnotationSparkVersion])
+}
+
+// getExecutorAnnotations returns the executor pod annotations configured by the Spark properties
+func getExecutorAnnotations(props *properties.Properties) map[string]string {
+	return getPrefixedProperties(props, sparkExecutorAnnotationPrefix)
+}
+
+// getDecommissionConf returns the Spark properties enabling graceful decommissioning of the application's executors,
+// nil if decommissioning is not enabled. Decommissioning is enabled by default for Spark versions that support it
+// when executors run on spot instances.
+func (m ConfigMapMutator) getDecommissionConf(driver *corev1.Pod, props *properties.Properties, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) map[string]string {
+	executorAnnotations := getExecutorAnnotations(props)
+
+	enabled, configured := config.GetExecutorDecommission(executorAnnotations)
+	if !configured {
+		enabled, configured = config.GetExecutorDecommission(driver.Annotations)
+	}
+	if configured && !enabled {
+		log.Info("Executor decommissioning disabled")
+		return nil
+	}
+
+	if sparkEnabled, err := strconv.ParseBool(props.GetString("spark.decommission.enabled", "")); err == nil && !sparkEnabled {
+		log.Info("Executor decommissioning disabled by the application's Spark properties")
+		return nil
+	}
+
+	sparkVersion := getSparkVersion(driver)
+	if sparkVersion == "" {
+		log.Info(fmt.Sprintf("Spark version unknown, executor decommissioning disabled, the driver has no %q or %q label or %s annotation",
+			sparkVersionLabel, sparkOperatorVersionLabel, config.WaveConfigAnnotationSparkVersion))
+		return nil
+	}
+	if !supportsDecommission(sparkVersion) {
+		log.Info("Spark version does not support executor decommissioning", "sparkVersion", sparkVersion)
+		return nil
+	}
+
+	if !configured && !areExecutorsOnSpot(executorAnnotations, getPodPolicy(policy, SparkRoleExecutorValue), log) {
+		return nil
+	}
+
+	log.Info("Enabling executor decommissioning", "sparkVersion", sparkVersion)
+	conf := map[string]string{
+		"spark.decommission.enabled":                       "true",
+		"spark.storage.decommission.enabled":               "true",
+		"spark.storage.decommission.shuffleBlocks.enabled": "true",
+		"spark.storage.decommission.rddBlocks.enabled":     "true",
+		// Marks the executor pods for the decommission pre-stop hook
+		sparkExecutorAnnotationPrefix + config.WaveConfigAnnotationExecutorDecommission: "true",
+	}
+
+	if config.IsDecommissionFallbackStorageEnabled(executorAnnotations) || config.IsDecommissionFallbackStorageEnabled(driver.Annotations) {
+		storageInfo, err := m.provider.GetStorageInfo()
+		if err != nil || storageInfo == nil {
+			log.Error(err, "Not configuring decommission fallback storage, error getting storage info")
+		} else {
+			conf["spark.storage.decommission.fallbackStorage.path"] = strings.TrimSuffix(storageInfo.Path, "/") + "/" + fallbackStorageDir
+			conf["spark.storage.decommission.fallbackStorage.cleanUp"] = "true"
+		}
+	}
+
+	return conf
+}
+
+// areExecutorsOnSpot returns whether any of the application's executors are placed on spot instances
+func areExecutorsOnSpot(executorAnnotations map[string]string, podPolicy *v1alpha1.WaveSparkPodPolicy, log logr.Logger) bool {
+	if percentage, configured, err := config.GetExecutorOnDemandPercentage(executorAnnotations); err == nil && configured {
+		return percentage < 100 && constrainInstanceLifecycle(config.InstanceLifecycleSpot, podPolicy, log) == config.InstanceLifecycleSpot
+	}
+	return resolveInstanceLifecycle(executorAnnotations, podPolicy, config.InstanceLifecycleSpot, log) == config.InstanceLifecycleSpot
+}
+
+// buildDecommissionHook adds a pre-stop hook decommissioning executors of applications with decommissioning enabled,
+// unless the executor container already has a pre-stop hook. Spark adds the same hook itself when
+// spark.decommission.enabled is set, the hook is only added for executors created without it, e.g. from pod templates.
+func (m PodMutator) buildDecommissionHook(pod *corev1.Pod, log logr.Logger) {
+	if enabled, _ := config.GetExecutorDecommission(pod.Annotations); !enabled {
+		return
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return
+	}
+
+	container := &pod.Spec.Containers[0]
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == executorContainerName {
+			container = &pod.Spec.Containers[i]
+		}
+	}
+
+	if container.Lifecycle != nil && container.Lifecycle.PreStop != nil {
+		if preStop := container.Lifecycle.PreStop; preStop.Exec == nil || len(preStop.Exec.Command) != 1 || preStop.Exec.Command[0] != decommissionScript {
+			log.Info(fmt.Sprintf("Container %q already has a pre-stop hook, will not be mutated", container.Name))
+		}
+	} else {
+		log.Info(fmt.Sprintf("Adding decommission pre-stop hook to container %q", container.Name))
+		if container.Lifecycle == nil {
+			container.Lifecycle = &corev1.Lifecycle{}
+		}
+		container.Lifecycle.PreStop = &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{decommissionScript},
+			},
+		}
+	}
+
+	// keeping a longer grace period if one is already set
+	gracePeriodSeconds := int64(executorDecommissionGracePeriod.Seconds())
+	if pod.Spec.TerminationGracePeriodSeconds == nil || *pod.Spec.TerminationGracePeriodSeconds < gracePeriodSeconds {
+		pod.Spec.TerminationGracePeriodSeconds = &gracePeriodSeconds
+	}
+}
@@ -0,0 +1,222 @@
+package admission
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/util"
+)
+
+func TestSupportsDecommission(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"3.1.1":   true,
+		"3.2.0":   true,
+		"v3.3":    true,
+		"4.0.0":   true,
+		"3.0.0":   false,
+		"2.4.7":   false,
+		"3":       false,
+		"":        false,
+		"latest":  false,
+		"3.x.any": false,
+	} {
+		assert.Equal(t, expected, supportsDecommission(version), version)
+	}
+}
+
+func TestDecommissionConfiguration(t *testing.T) {
+
+	mutate := func(t *testing.T, driverLabels map[string]string, driverAnnotations map[string]string, sparkConf string, policies ...*v1alpha1.WaveSparkPolicy) *properties.Properties {
+		cm := sparkConfigMap.DeepCopy()
+		cm.Data["spark.properties"] += sparkConf
+		driver := getDriverPod(cm.OwnerReferences[0].Name, cm.Namespace, false, "")
+		for k, v := range driverLabels {
+			driver.Labels[k] = v
+		}
+		for k, v := range driverAnnotations {
+			driver.Annotations[k] = v
+		}
+		var policy *v1alpha1.WaveSparkPolicy
+		if len(policies) > 0 {
+			policy = policies[0]
+			policy.Namespace = cm.Namespace
+		}
+		policyProvider := newTestPolicyProvider()
+		if policy != nil {
+			policyProvider = newTestPolicyProvider(policy)
+		}
+		r, err := NewConfigMapMutator(log, k8sfake.NewSimpleClientset(driver), &util.FakeStorageProvider{}, policyProvider, config.SparkConf{}).Mutate(getAdmissionRequest(t, cm))
+		require.NoError(t, err)
+		obj, err := ApplyJsonPatch(r.Patch, cm)
+		require.NoError(t, err)
+		mutated, ok := obj.(*corev1.ConfigMap)
+		require.True(t, ok)
+		props, err := properties.LoadString(mutated.Data["spark.properties"])
+		require.NoError(t, err)
+		return props
+	}
+
+	spark31 := map[string]string{sparkVersionLabel: "3.1.2"}
+	executorMarker := sparkExecutorAnnotationPrefix + config.WaveConfigAnnotationExecutorDecommission
+
+	t.Run("whenExecutorsOnSpot", func(tt *testing.T) {
+		props := mutate(tt, spark31, nil, "")
+		assert.Equal(tt, "true", props.GetString("spark.decommission.enabled", ""))
+		assert.Equal(tt, "true", props.GetString("spark.storage.decommission.enabled", ""))
+		assert.Equal(tt, "true", props.GetString("spark.storage.decommission.shuffleBlocks.enabled", ""))
+		assert.Equal(tt, "true", props.GetString("spark.storage.decommission.rddBlocks.enabled", ""))
+		assert.Equal(tt, "true", props.GetString(executorMarker, ""))
+		_, ok := props.Get("spark.storage.decommission.fallbackStorage.path")
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenSparkOperatorVersionLabel", func(tt *testing.T) {
+		props := mutate(tt, map[string]string{sparkOperatorVersionLabel: "3.1.1"}, nil, "")
+		assert.Equal(tt, "true", props.GetString("spark.decommission.enabled", ""))
+	})
+
+	t.Run("whenSparkVersionNotSupported", func(tt *testing.T) {
+		props := mutate(tt, map[string]string{sparkVersionLabel: "3.0.0"}, nil, "")
+		_, ok := props.Get("spark.decommission.enabled")
+		assert.False(tt, ok)
+
+		props = mutate(tt, nil, map[string]string{config.WaveConfigAnnotationExecutorDecommission: "true"}, "")
+		_, ok = props.Get("spark.decommission.enabled")
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenSparkVersionAnnotation", func(tt *testing.T) {
+		props := mutate(tt, nil, map[string]string{config.WaveConfigAnnotationSparkVersion: "3.2.0"}, "")
+		assert.Equal(tt, "true", props.GetString("spark.decommission.enabled", ""))
+
+		// the labels set by Spark take precedence
+		props = mutate(tt, map[string]string{sparkVersionLabel: "3.0.1"}, map[string]string{config.WaveConfigAnnotationSparkVersion: "3.2.0"}, "")
+		_, ok := props.Get("spark.decommission.enabled")
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenExecutorsOnDemand", func(tt *testing.T) {
+		props := mutate(tt, spark31, nil, "spark.kubernetes.executor.annotation.wave.spot.io/instance-lifecycle=od\n")
+		_, ok := props.Get("spark.decommission.enabled")
+		assert.False(tt, ok)
+
+		// explicitly enabled
+		props = mutate(tt, spark31, map[string]string{config.WaveConfigAnnotationExecutorDecommission: "true"},
+			"spark.kubernetes.executor.annotation.wave.spot.io/instance-lifecycle=od\n")
+		assert.Equal(tt, "true", props.GetString("spark.decommission.enabled", ""))
+	})
+
+	t.Run("whenOnDemandPercentage", func(tt *testing.T) {
+		props := mutate(tt, spark31, nil, "spark.kubernetes.executor.annotation.wave.spot.io/executor-on-demand-percentage=50\n")
+		assert.Equal(tt, "true", props.GetString("spark.decommission.enabled", ""))
+
+		props = mutate(tt, spark31, nil, "spark.kubernetes.executor.annotation.wave.spot.io/executor-on-demand-percentage=100\n")
+		_, ok := props.Get("spark.decommission.enabled")
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenPolicyOnDemand", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "")
+		policy.Spec.Executor.InstanceLifecycle = "od"
+		props := mutate(tt, spark31, nil, "", policy)
+		_, ok := props.Get("spark.decommission.enabled")
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenDisabled", func(tt *testing.T) {
+		props := mutate(tt, spark31, map[string]string{config.WaveConfigAnnotationExecutorDecommission: "false"}, "")
+		_, ok := props.Get("spark.decommission.enabled")
+		assert.False(tt, ok)
+
+		props = mutate(tt, spark31, nil, "spark.kubernetes.executor.annotation.wave.spot.io/executor-decommission=false\n")
+		_, ok = props.Get("spark.decommission.enabled")
+		assert.False(tt, ok)
+		assert.Equal(tt, "false", props.GetString(executorMarker, ""))
+	})
+
+	t.Run("whenDisabledBySparkConf", func(tt *testing.T) {
+		props := mutate(tt, spark31, nil, "spark.decommission.enabled=false\n")
+		assert.Equal(tt, "false", props.GetString("spark.decommission.enabled", ""))
+		_, ok := props.Get(executorMarker)
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenApplicationSetsProperties", func(tt *testing.T) {
+		props := mutate(tt, spark31, nil, "spark.storage.decommission.rddBlocks.enabled=false\n")
+		assert.Equal(tt, "true", props.GetString("spark.decommission.enabled", ""))
+		assert.Equal(tt, "false", props.GetString("spark.storage.decommission.rddBlocks.enabled", ""))
+	})
+
+	t.Run("whenFallbackStorage", func(tt *testing.T) {
+		props := mutate(tt, spark31, map[string]string{config.WaveConfigAnnotationDecommissionFallbackStorage: "true"}, "")
+		assert.Equal(tt, "s3://fake/spark-decommission/", props.GetString("spark.storage.decommission.fallbackStorage.path", ""))
+		assert.Equal(tt, "true", props.GetString("spark.storage.decommission.fallbackStorage.cleanUp", ""))
+	})
+}
+
+func TestMutateExecutorPod_decommissionHook(t *testing.T) {
+
+	mutate := func(pod *corev1.Pod) *corev1.Pod {
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil)
+		return m.mutateExecutorPod(pod, nil, log)
+	}
+
+	getPod := func(decommission string) *corev1.Pod {
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Spec.Containers = []corev1.Container{
+			{Name: "sidecar"},
+			{Name: executorContainerName},
+		}
+		if decommission != "" {
+			pod.Annotations[config.WaveConfigAnnotationExecutorDecommission] = decommission
+		}
+		return pod
+	}
+
+	t.Run("whenEnabled", func(tt *testing.T) {
+		mutated := mutate(getPod("true"))
+		assert.Nil(tt, mutated.Spec.Containers[0].Lifecycle)
+		require.NotNil(tt, mutated.Spec.Containers[1].Lifecycle)
+		require.NotNil(tt, mutated.Spec.Containers[1].Lifecycle.PreStop)
+		assert.Equal(tt, []string{decommissionScript}, mutated.Spec.Containers[1].Lifecycle.PreStop.Exec.Command)
+		require.NotNil(tt, mutated.Spec.TerminationGracePeriodSeconds)
+		assert.Equal(tt, int64(120), *mutated.Spec.TerminationGracePeriodSeconds)
+	})
+
+	t.Run("whenNotEnabled", func(tt *testing.T) {
+		for _, value := range []string{"", "false"} {
+			mutated := mutate(getPod(value))
+			assert.Nil(tt, mutated.Spec.Containers[1].Lifecycle)
+			assert.Nil(tt, mutated.Spec.TerminationGracePeriodSeconds)
+		}
+	})
+
+	t.Run("whenSparkAddedPreStop", func(tt *testing.T) {
+		pod := getPod("true")
+		preStop := &corev1.Handler{Exec: &corev1.ExecAction{Command: []string{decommissionScript}}}
+		pod.Spec.Containers[1].Lifecycle = &corev1.Lifecycle{PreStop: preStop}
+		mutated := mutate(pod)
+		assert.Equal(tt, preStop, mutated.Spec.Containers[1].Lifecycle.PreStop)
+		assert.Nil(tt, mutated.Spec.Containers[0].Lifecycle)
+		require.NotNil(tt, mutated.Spec.TerminationGracePeriodSeconds)
+		assert.Equal(tt, int64(120), *mutated.Spec.TerminationGracePeriodSeconds)
+	})
+
+	t.Run("whenPreStopSet", func(tt *testing.T) {
+		pod := getPod("true")
+		preStop := &corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"/opt/custom.sh"}}}
+		pod.Spec.Containers[1].Lifecycle = &corev1.Lifecycle{PreStop: preStop}
+		gracePeriod := int64(600)
+		pod.Spec.TerminationGracePeriodSeconds = &gracePeriod
+		mutated := mutate(pod)
+		assert.Equal(tt, preStop, mutated.Spec.Containers[1].Lifecycle.PreStop)
+		assert.Equal(tt, int64(600), *mutated.Spec.TerminationGracePeriodSeconds)
+	})
+}
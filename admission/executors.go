@@ -0,0 +1,130 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+const (
+	SparkAppLabel = "spark-app-selector"
+
+	// recentPlacementTTL is how long placements are remembered after admission, covering executors
+	// not yet in the cache of the pod reader
+	recentPlacementTTL = 2 * time.Minute
+)
+
+// ExecutorPlacement steers the executors of an application to on-demand or spot instances,
+// holding the application's on-demand percentage
+type ExecutorPlacement interface {
+	// Place returns the instance lifecycle of the executor pod
+	Place(ctx context.Context, pod *corev1.Pod, onDemandPercentage int) (config.InstanceLifecycle, error)
+}
+
+type placement struct {
+	lifecycle config.InstanceLifecycle
+	admitted  time.Time
+}
+
+type executorPlacement struct {
+	client client.Reader
+	log    logr.Logger
+	now    func() time.Time
+
+	mutex sync.Mutex
+	// recent placements by application id and pod name
+	recent map[string]map[string]placement
+}
+
+func NewExecutorPlacement(client client.Reader, log logr.Logger) ExecutorPlacement {
+	return &executorPlacement{
+		client: client,
+		log:    log,
+		now:    time.Now,
+		recent: make(map[string]map[string]placement),
+	}
+}
+
+// Place counts the application's running executors per instance lifecycle, the executor is placed on on-demand
+// instances while the on-demand executors are below the percentage of all executors, including the new one
+func (p *executorPlacement) Place(ctx context.Context, pod *corev1.Pod, onDemandPercentage int) (config.InstanceLifecycle, error) {
+	appID := pod.Labels[SparkAppLabel]
+	if appID == "" {
+		return "", fmt.Errorf("executor pod has no %s label", SparkAppLabel)
+	}
+
+	list := &corev1.PodList{}
+	err := p.client.List(ctx, list, client.InNamespace(pod.Namespace), client.MatchingLabels{
+		SparkAppLabel:  appID,
+		SparkRoleLabel: SparkRoleExecutorValue,
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not list executor pods, %w", err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	p.pruneRecent(now)
+
+	executors := make(map[string]config.InstanceLifecycle)
+	for _, e := range list.Items {
+		if e.DeletionTimestamp != nil || e.Status.Phase == corev1.PodSucceeded || e.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		executors[e.Name] = config.ParseInstanceLifecycle(e.Annotations[config.WaveConfigAnnotationAssignedInstanceLifecycle])
+	}
+	for name, r := range p.recent[appID] {
+		if _, ok := executors[name]; !ok {
+			executors[name] = r.lifecycle
+		}
+	}
+	delete(executors, pod.Name)
+
+	onDemand := 0
+	for _, lifecycle := range executors {
+		if lifecycle == config.InstanceLifecycleOnDemand {
+			onDemand++
+		}
+	}
+
+	lifecycle := config.InstanceLifecycleSpot
+	// on-demand executors required for the percentage, rounded up
+	required := (onDemandPercentage*(len(executors)+1) + 99) / 100
+	if onDemand < required {
+		lifecycle = config.InstanceLifecycleOnDemand
+	}
+
+	p.log.Info("Placing executor", "app", appID, "pod", pod.Name, "executors", len(executors),
+		"onDemand", onDemand, "percentage", onDemandPercentage, "lifecycle", lifecycle)
+
+	if pod.Name != "" {
+		if p.recent[appID] == nil {
+			p.recent[appID] = make(map[string]placement)
+		}
+		p.recent[appID][pod.Name] = placement{lifecycle: lifecycle, admitted: now}
+	}
+
+	return lifecycle, nil
+}
+
+func (p *executorPlacement) pruneRecent(now time.Time) {
+	for appID, placements := range p.recent {
+		for name, r := range placements {
+			if now.Sub(r.admitted) > recentPlacementTTL {
+				delete(placements, name)
+			}
+		}
+		if len(placements) == 0 {
+			delete(p.recent, appID)
+		}
+	}
+}
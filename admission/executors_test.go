@@ -0,0 +1,194 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlrt_fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/util"
+)
+
+func newTestExecutorPlacement(pods ...runtime.Object) *executorPlacement {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	return NewExecutorPlacement(ctrlrt_fake.NewFakeClientWithScheme(testScheme, pods...), log).(*executorPlacement)
+}
+
+func getExecutorPod(appID string, name string, assigned config.InstanceLifecycle) *corev1.Pod {
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				SparkAppLabel:  appID,
+				SparkRoleLabel: SparkRoleExecutorValue,
+			},
+			Annotations: map[string]string{},
+		},
+	}
+	if assigned != "" {
+		pod.Annotations[config.WaveConfigAnnotationAssignedInstanceLifecycle] = string(assigned)
+	}
+	return pod
+}
+
+func TestExecutorPlacement_Place(t *testing.T) {
+
+	ctx := context.TODO()
+
+	place := func(t *testing.T, p *executorPlacement, count int, percentage int) []config.InstanceLifecycle {
+		placed := make([]config.InstanceLifecycle, 0, count)
+		for i := 0; i < count; i++ {
+			lifecycle, err := p.Place(ctx, getExecutorPod("app-1", fmt.Sprintf("exec-%d", i), ""), percentage)
+			require.NoError(t, err)
+			placed = append(placed, lifecycle)
+		}
+		return placed
+	}
+
+	countOnDemand := func(placed []config.InstanceLifecycle) int {
+		onDemand := 0
+		for _, l := range placed {
+			if l == config.InstanceLifecycleOnDemand {
+				onDemand++
+			}
+		}
+		return onDemand
+	}
+
+	t.Run("whenHoldingPercentage", func(tt *testing.T) {
+		placed := place(tt, newTestExecutorPlacement(), 10, 30)
+		assert.Equal(tt, config.InstanceLifecycleOnDemand, placed[0])
+		assert.Equal(tt, 3, countOnDemand(placed))
+	})
+
+	t.Run("whenAllOrNothing", func(tt *testing.T) {
+		assert.Equal(tt, 5, countOnDemand(place(tt, newTestExecutorPlacement(), 5, 100)))
+		assert.Equal(tt, 0, countOnDemand(place(tt, newTestExecutorPlacement(), 5, 0)))
+	})
+
+	t.Run("whenExecutorsRunning", func(tt *testing.T) {
+		p := newTestExecutorPlacement(
+			getExecutorPod("app-1", "running-1", config.InstanceLifecycleSpot),
+			getExecutorPod("app-1", "running-2", config.InstanceLifecycleSpot),
+			getExecutorPod("app-1", "running-3", config.InstanceLifecycleOnDemand),
+			getExecutorPod("app-2", "other-1", config.InstanceLifecycleSpot),
+		)
+		// 1 of 4 executors is below 50%
+		lifecycle, err := p.Place(ctx, getExecutorPod("app-1", "new-1", ""), 50)
+		require.NoError(tt, err)
+		assert.Equal(tt, config.InstanceLifecycleOnDemand, lifecycle)
+		// 2 of 5 executors is below 50%
+		lifecycle, err = p.Place(ctx, getExecutorPod("app-1", "new-2", ""), 50)
+		require.NoError(tt, err)
+		assert.Equal(tt, config.InstanceLifecycleOnDemand, lifecycle)
+		// 3 of 6 executors is 50%
+		lifecycle, err = p.Place(ctx, getExecutorPod("app-1", "new-3", ""), 50)
+		require.NoError(tt, err)
+		assert.Equal(tt, config.InstanceLifecycleSpot, lifecycle)
+	})
+
+	t.Run("whenExecutorsTerminated", func(tt *testing.T) {
+		failed := getExecutorPod("app-1", "failed-1", config.InstanceLifecycleOnDemand)
+		failed.Status.Phase = corev1.PodFailed
+		p := newTestExecutorPlacement(failed)
+		lifecycle, err := p.Place(ctx, getExecutorPod("app-1", "new-1", ""), 50)
+		require.NoError(tt, err)
+		assert.Equal(tt, config.InstanceLifecycleOnDemand, lifecycle)
+	})
+
+	t.Run("whenReadmitted", func(tt *testing.T) {
+		// a pod admitted again does not count itself
+		p := newTestExecutorPlacement()
+		for i := 0; i < 3; i++ {
+			lifecycle, err := p.Place(ctx, getExecutorPod("app-1", "exec-1", ""), 50)
+			require.NoError(tt, err)
+			assert.Equal(tt, config.InstanceLifecycleOnDemand, lifecycle)
+		}
+	})
+
+	t.Run("whenRecentPlacementsExpire", func(tt *testing.T) {
+		p := newTestExecutorPlacement()
+		now := time.Now()
+		p.now = func() time.Time { return now }
+		place(tt, p, 4, 50)
+		assert.Len(tt, p.recent["app-1"], 4)
+		now = now.Add(recentPlacementTTL + time.Second)
+		lifecycle, err := p.Place(ctx, getExecutorPod("app-1", "exec-late", ""), 50)
+		require.NoError(tt, err)
+		assert.Equal(tt, config.InstanceLifecycleOnDemand, lifecycle)
+		assert.Len(tt, p.recent["app-1"], 1)
+	})
+
+	t.Run("whenNoAppLabel", func(tt *testing.T) {
+		pod := getExecutorPod("", "exec-1", "")
+		_, err := newTestExecutorPlacement().Place(ctx, pod, 50)
+		assert.Error(tt, err)
+	})
+}
+
+func TestMutateExecutorPod_onDemandPercentage(t *testing.T) {
+
+	mutate := func(t *testing.T, m PodMutator, pod *corev1.Pod) *corev1.Pod {
+		r, err := m.Mutate(getAdmissionRequest(t, pod))
+		require.NoError(t, err)
+		obj, err := ApplyJsonPatch(r.Patch, pod)
+		require.NoError(t, err)
+		mutated, ok := obj.(*corev1.Pod)
+		require.True(t, ok)
+		return mutated
+	}
+
+	getPod := func(name string, percentage string) *corev1.Pod {
+		pod := getExecutorPod("app-1", name, "")
+		pod.Annotations[config.WaveConfigAnnotationExecutorOnDemandPercentage] = percentage
+		return pod
+	}
+
+	t.Run("whenSteered", func(tt *testing.T) {
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{},
+			newTestPolicyProvider(), newTestExecutorPlacement())
+
+		first := mutate(tt, m, getPod("exec-1", "50"))
+		assert.Equal(tt, "od", first.Annotations[config.WaveConfigAnnotationAssignedInstanceLifecycle])
+		assert.Equal(tt, getOnDemandAffinity(), first.Spec.Affinity)
+
+		second := mutate(tt, m, getPod("exec-2", "50"))
+		assert.Equal(tt, "spot", second.Annotations[config.WaveConfigAnnotationAssignedInstanceLifecycle])
+		assert.Equal(tt, getOnDemandAntiAffinity(), second.Spec.Affinity)
+	})
+
+	t.Run("whenPolicyConstrains", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "default")
+		policy.Spec.Executor.AllowedInstanceLifecycles = []string{"spot"}
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{},
+			newTestPolicyProvider(policy), newTestExecutorPlacement())
+
+		mutated := mutate(tt, m, getPod("exec-1", "100"))
+		assert.Equal(tt, "spot", mutated.Annotations[config.WaveConfigAnnotationAssignedInstanceLifecycle])
+		assert.Equal(tt, getOnDemandAntiAffinity(), mutated.Spec.Affinity)
+	})
+
+	t.Run("whenInvalidPercentage", func(tt *testing.T) {
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{},
+			newTestPolicyProvider(), newTestExecutorPlacement())
+
+		mutated := mutate(tt, m, getPod("exec-1", "150"))
+		assert.NotContains(tt, mutated.Annotations, config.WaveConfigAnnotationAssignedInstanceLifecycle)
+		assert.Equal(tt, getOnDemandAntiAffinity(), mutated.Spec.Affinity)
+	})
+}
@@ -0,0 +1,63 @@
+package admission
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	mutatorPod       = "pod"
+	mutatorConfigMap = "configmap"
+	validatorPod     = "pod-validation"
+)
+
+var admissionRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_admission_requests_total",
+		Help: "Total number of admission requests received",
+	},
+	[]string{"mutator"},
+)
+
+var admissionPatches = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_admission_patches_total",
+		Help: "Total number of admission responses that patched the object",
+	},
+	[]string{"mutator"},
+)
+
+var admissionErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_admission_errors_total",
+		Help: "Total number of admission requests that could not be mutated",
+	},
+	[]string{"mutator"},
+)
+
+var admissionDenials = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_admission_denials_total",
+		Help: "Total number of admission requests that were denied",
+	},
+	[]string{"mutator"},
+)
+
+var admissionWarnings = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_admission_warnings_total",
+		Help: "Total number of admission responses with warnings",
+	},
+	[]string{"mutator"},
+)
+
+var webhookCertificateExpiry = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "wave_admission_webhook_certificate_expiry_timestamp_seconds",
+		Help: "Expiry time of the served webhook certificate in seconds since the epoch",
+	},
+)
+
+func init() {
+	metrics.Registry.MustRegister(admissionRequests, admissionPatches, admissionErrors, admissionDenials, admissionWarnings, webhookCertificateExpiry)
+}
@@ -1,13 +1,17 @@
 package admission
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/spotinst/wave-operator/api/v1alpha1"
 	"github.com/spotinst/wave-operator/cloudstorage"
 	"github.com/spotinst/wave-operator/internal/config"
 	"github.com/spotinst/wave-operator/internal/config/instances"
@@ -23,6 +27,9 @@ const (
 	nodeLifeCycleValueOnDemand = "od"
 
 	nodeInstanceTypeKey = "node.kubernetes.io/instance-type"
+
+	// syncIntervalEnvVar sets the storage sync container's sync interval in seconds
+	syncIntervalEnvVar = "SYNC_INTERVAL_SECONDS"
 )
 
 var (
@@ -41,13 +48,21 @@ var (
 type PodMutator struct {
 	storageProvider     cloudstorage.CloudStorageProvider
 	instanceTypeManager instances.InstanceTypeManager
+	storageSync         config.StorageSync
+	sparkConf           config.SparkConf
+	policyProvider      PolicyProvider
+	executorPlacement   ExecutorPlacement
 	baseLogger          logr.Logger
 }
 
-func NewPodMutator(log logr.Logger, storageProvider cloudstorage.CloudStorageProvider, instanceTypeManager instances.InstanceTypeManager) PodMutator {
+func NewPodMutator(log logr.Logger, storageProvider cloudstorage.CloudStorageProvider, instanceTypeManager instances.InstanceTypeManager, storageSync config.StorageSync, sparkConf config.SparkConf, policyProvider PolicyProvider, executorPlacement ExecutorPlacement) PodMutator {
 	return PodMutator{
 		storageProvider:     storageProvider,
 		instanceTypeManager: instanceTypeManager,
+		storageSync:         storageSync,
+		sparkConf:           sparkConf,
+		policyProvider:      policyProvider,
+		executorPlacement:   executorPlacement,
 		baseLogger:          log,
 	}
 }
@@ -79,13 +94,19 @@ func (m PodMutator) Mutate(req *admissionv1.AdmissionRequest) (*admissionv1.Admi
 		return resp, nil
 	}
 
+	namespace := sourceObj.Namespace
+	if namespace == "" {
+		namespace = req.Namespace
+	}
+	policy := getPolicy(context.TODO(), m.policyProvider, namespace, log)
+
 	var modObj *corev1.Pod
 	if sparkRole == SparkRoleDriverValue {
 		log.Info("Mutating driver pod")
-		modObj = m.mutateDriverPod(sourceObj, log)
+		modObj = m.mutateDriverPod(sourceObj, policy, log)
 	} else {
 		log.Info("Mutating executor pod")
-		modObj = m.mutateExecutorPod(sourceObj, log)
+		modObj = m.mutateExecutorPod(sourceObj, policy, log)
 	}
 
 	patchBytes, err := GetJsonPatch(sourceObj, modObj)
@@ -101,14 +122,24 @@ func (m PodMutator) Mutate(req *admissionv1.AdmissionRequest) (*admissionv1.Admi
 	return resp, nil
 }
 
-func (m PodMutator) mutateDriverPod(sourceObj *corev1.Pod, log logr.Logger) *corev1.Pod {
+func (m PodMutator) mutateDriverPod(sourceObj *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) *corev1.Pod {
 
 	modObj := sourceObj.DeepCopy()
 
 	// node affinity
-	m.buildAffinityDriver(modObj, log)
+	podPolicy := getPodPolicy(policy, SparkRoleDriverValue)
+	m.buildAffinityDriver(modObj, podPolicy, log)
+	m.buildZoneAffinityDriver(modObj, policy, log)
+	applyPolicy(modObj, policy, podPolicy)
+
+	eventLogSyncEnabled := resolveEventLogSync(sourceObj.Annotations, policy)
+	if policy != nil {
+		setEventLogSyncAnnotation(modObj, eventLogSyncEnabled)
+	}
+
+	m.buildSparkConfEnv(modObj, policy, log)
 
-	if !config.IsEventLogSyncEnabled(sourceObj.Annotations) {
+	if !eventLogSyncEnabled {
 		log.Info("Event log sync not enabled, will not add storage sync container")
 		return modObj
 	}
@@ -126,14 +157,26 @@ func (m PodMutator) mutateDriverPod(sourceObj *corev1.Pod, log logr.Logger) *cor
 
 	log.Info("driver pod admission control", "mountPath", volumeMount.MountPath)
 
+	syncConf := config.GetStorageSyncConfig(sourceObj.Annotations, m.storageSync, log)
+
+	env := []corev1.EnvVar{{Name: "S3_REGION", Value: storageInfo.Region}}
+	if syncConf.SyncInterval > 0 {
+		seconds := int64(syncConf.SyncInterval.Round(time.Second).Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		env = append(env, corev1.EnvVar{Name: syncIntervalEnvVar, Value: strconv.FormatInt(seconds, 10)})
+	}
+
 	webServerPort := strconv.Itoa(int(storagesync.Port))
 	storageContainer := corev1.Container{
 		Name:            storagesync.SyncContainerName,
-		Image:           "public.ecr.aws/l8m2k1n1/netapp/cloud-storage-sync:v0.4.0",
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		Image:           syncConf.Image,
+		ImagePullPolicy: syncConf.ImagePullPolicy,
 		Command:         []string{"/tini"},
 		Args:            []string{"--", "./run.sh", volumeMount.MountPath, "spark:" + storageInfo.Name, "forever", webServerPort},
-		Env:             []corev1.EnvVar{{Name: "S3_REGION", Value: storageInfo.Region}},
+		Env:             env,
+		Resources:       syncConf.Resources.ResourceRequirements,
 		Lifecycle: &corev1.Lifecycle{
 			PreStop: &corev1.Handler{
 				Exec: &corev1.ExecAction{
@@ -152,6 +195,10 @@ func (m PodMutator) mutateDriverPod(sourceObj *corev1.Pod, log logr.Logger) *cor
 			},
 		},
 	}
+	if syncConf.SecurityContext != nil && !reflect.DeepEqual(syncConf.SecurityContext.SecurityContext, corev1.SecurityContext{}) {
+		securityContext := syncConf.SecurityContext.SecurityContext
+		storageContainer.SecurityContext = &securityContext
+	}
 
 	// add storage sync container
 	exists := false
@@ -197,17 +244,39 @@ func (m PodMutator) mutateDriverPod(sourceObj *corev1.Pod, log logr.Logger) *cor
 	if !exists {
 		modObj.Spec.Volumes = append(modObj.Spec.Volumes, volume)
 	}
-	if config.IsEventLogSyncEnabled(modObj.Annotations) {
-		var eventLogTerminationSeconds int64 = 300
-		modObj.Spec.TerminationGracePeriodSeconds = &eventLogTerminationSeconds
+	// add image pull secrets
+	for _, secret := range syncConf.ImagePullSecrets {
+		exists = false
+		for _, s := range modObj.Spec.ImagePullSecrets {
+			if s.Name == secret {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			modObj.Spec.ImagePullSecrets = append(modObj.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+		}
+	}
+
+	// give the storage sync container time to sync the final event log,
+	// keeping a longer grace period if one is already set
+	gracePeriodSeconds := int64(syncConf.TerminationGracePeriod.Seconds())
+	if modObj.Spec.TerminationGracePeriodSeconds == nil || *modObj.Spec.TerminationGracePeriodSeconds < gracePeriodSeconds {
+		modObj.Spec.TerminationGracePeriodSeconds = &gracePeriodSeconds
 	}
 	return modObj
 }
 
-func (m PodMutator) mutateExecutorPod(sourceObj *corev1.Pod, log logr.Logger) *corev1.Pod {
+func (m PodMutator) mutateExecutorPod(sourceObj *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) *corev1.Pod {
 	modObj := sourceObj.DeepCopy()
 	// node affinity
-	m.buildAffinityExecutor(modObj, log)
+	podPolicy := getPodPolicy(policy, SparkRoleExecutorValue)
+	lifecycle := m.placeExecutor(modObj, podPolicy, log)
+	m.buildAffinityExecutor(modObj, podPolicy, lifecycle, log)
+	m.buildTopologySpreadExecutor(modObj, policy, log)
+	m.buildZoneAffinityExecutor(modObj, policy, log)
+	m.buildDecommissionHook(modObj, log)
+	applyPolicy(modObj, policy, podPolicy)
 	return modObj
 }
 
@@ -218,14 +287,9 @@ type nodeAffinityConfig struct {
 	instanceTypes []string
 }
 
-func (m PodMutator) getNodeAffinityConfig(annotations map[string]string, defaultLifecycle config.InstanceLifecycle, log logr.Logger) nodeAffinityConfig {
-	lifecycle := config.GetInstanceLifecycle(annotations, log)
-	if lifecycle == "" {
-		// Use default
-		lifecycle = defaultLifecycle
-	}
-
-	instanceTypes := config.GetConfiguredInstanceTypes(annotations, m.instanceTypeManager, log)
+func (m PodMutator) getNodeAffinityConfig(annotations map[string]string, podPolicy *v1alpha1.WaveSparkPodPolicy, defaultLifecycle config.InstanceLifecycle, log logr.Logger) nodeAffinityConfig {
+	lifecycle := resolveInstanceLifecycle(annotations, podPolicy, defaultLifecycle, log)
+	instanceTypes := resolveInstanceTypes(annotations, podPolicy, m.instanceTypeManager, log)
 
 	return nodeAffinityConfig{
 		instanceLifecycle: lifecycle,
@@ -233,16 +297,45 @@ func (m PodMutator) getNodeAffinityConfig(annotations map[string]string, default
 	}
 }
 
-func (m PodMutator) buildAffinityDriver(pod *corev1.Pod, log logr.Logger) {
-	conf := m.getNodeAffinityConfig(pod.Annotations, config.InstanceLifecycleOnDemand, log)
+func (m PodMutator) buildAffinityDriver(pod *corev1.Pod, podPolicy *v1alpha1.WaveSparkPodPolicy, log logr.Logger) {
+	conf := m.getNodeAffinityConfig(pod.Annotations, podPolicy, config.InstanceLifecycleOnDemand, log)
 	m.buildAffinity(pod, conf, log)
 }
 
-func (m PodMutator) buildAffinityExecutor(pod *corev1.Pod, log logr.Logger) {
-	conf := m.getNodeAffinityConfig(pod.Annotations, config.InstanceLifecycleSpot, log)
+// buildAffinityExecutor builds the executor's node affinity, the placed lifecycle, if any, takes precedence over
+// the configured lifecycle
+func (m PodMutator) buildAffinityExecutor(pod *corev1.Pod, podPolicy *v1alpha1.WaveSparkPodPolicy, placed config.InstanceLifecycle, log logr.Logger) {
+	conf := m.getNodeAffinityConfig(pod.Annotations, podPolicy, config.InstanceLifecycleSpot, log)
+	if placed != "" {
+		conf.instanceLifecycle = placed
+	}
 	m.buildAffinity(pod, conf, log)
 }
 
+// placeExecutor returns the lifecycle the executor is steered to by the application's on-demand percentage,
+// empty if the percentage is not configured or the executor cannot be placed
+func (m PodMutator) placeExecutor(pod *corev1.Pod, podPolicy *v1alpha1.WaveSparkPodPolicy, log logr.Logger) config.InstanceLifecycle {
+	percentage, configured, err := config.GetExecutorOnDemandPercentage(pod.Annotations)
+	if err != nil {
+		log.Info(fmt.Sprintf("Ignoring annotation, %s", err.Error()))
+		return ""
+	}
+	if !configured || m.executorPlacement == nil {
+		return ""
+	}
+	placed, err := m.executorPlacement.Place(context.TODO(), pod, percentage)
+	if err != nil {
+		log.Error(err, "could not place executor, using configured instance lifecycle")
+		return ""
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	placed = constrainInstanceLifecycle(placed, podPolicy, log)
+	pod.Annotations[config.WaveConfigAnnotationAssignedInstanceLifecycle] = string(placed)
+	return placed
+}
+
 func (m PodMutator) buildAffinity(pod *corev1.Pod, conf nodeAffinityConfig, log logr.Logger) {
 	if pod.Spec.Affinity == nil {
 		pod.Spec.Affinity = &corev1.Affinity{}
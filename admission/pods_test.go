@@ -2,10 +2,12 @@ package admission
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/spotinst/wave-operator/cloudstorage"
@@ -154,7 +156,7 @@ func TestMutateDriverPod(t *testing.T) {
 		}
 
 		req := getAdmissionRequest(t, driverPod)
-		r, err := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}).Mutate(req)
+		r, err := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil).Mutate(req)
 		assert.NoError(t, err)
 		assert.NotNil(t, r)
 		assert.Equal(t, driverPod.UID, r.UID)
@@ -171,6 +173,9 @@ func TestMutateDriverPod(t *testing.T) {
 		if tc.shouldAddEventLogSync {
 			assert.Equal(t, len(driverPod.Spec.Containers)+1, len(newPod.Spec.Containers))
 			assert.Equal(t, "storage-sync", newPod.Spec.Containers[0].Name)
+			assert.Equal(t, config.DefaultStorageSyncImage, newPod.Spec.Containers[0].Image)
+			assert.Equal(t, corev1.PullIfNotPresent, newPod.Spec.Containers[0].ImagePullPolicy)
+			assert.Equal(t, int64(300), *newPod.Spec.TerminationGracePeriodSeconds)
 			assert.Equal(t, len(driverPod.Spec.Volumes)+1, len(newPod.Spec.Volumes))
 			assert.Equal(t, "spark-logs", newPod.Spec.Volumes[0].Name)
 		} else {
@@ -181,6 +186,114 @@ func TestMutateDriverPod(t *testing.T) {
 	}
 }
 
+func TestMutateDriverPod_storageSyncConfiguration(t *testing.T) {
+
+	gracePeriod := int64(30)
+	runAsNonRoot := true
+
+	storageSync := config.StorageSync{
+		Image:            "registry.example.com/cloud-storage-sync:v1",
+		ImagePullPolicy:  corev1.PullAlways,
+		ImagePullSecrets: []string{"registry"},
+		Resources: config.ResourceRequirements{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		}},
+		SecurityContext:        &config.SecurityContext{SecurityContext: corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot}},
+		SyncInterval:           10 * time.Second,
+		TerminationGracePeriod: 10 * time.Minute,
+	}
+
+	mutate := func(t *testing.T, driverPod *corev1.Pod) *corev1.Pod {
+		req := getAdmissionRequest(t, driverPod)
+		r, err := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, storageSync, config.SparkConf{}, newTestPolicyProvider(), nil).Mutate(req)
+		require.NoError(t, err)
+		obj, err := ApplyJsonPatch(r.Patch, driverPod)
+		require.NoError(t, err)
+		newPod, ok := obj.(*corev1.Pod)
+		require.True(t, ok)
+		require.Equal(t, "storage-sync", newPod.Spec.Containers[0].Name)
+		return newPod
+	}
+
+	getDriverPod := func() *corev1.Pod {
+		driverPod := getSimplePod()
+		driverPod.Labels = map[string]string{
+			SparkRoleLabel: SparkRoleDriverValue,
+		}
+		driverPod.Annotations[config.WaveConfigAnnotationSyncEventLogs] = "true"
+		driverPod.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+		driverPod.Spec.TerminationGracePeriodSeconds = &gracePeriod
+		return driverPod
+	}
+
+	t.Run("whenOperatorConfiguration", func(tt *testing.T) {
+		newPod := mutate(tt, getDriverPod())
+		c := newPod.Spec.Containers[0]
+		assert.Equal(tt, "registry.example.com/cloud-storage-sync:v1", c.Image)
+		assert.Equal(tt, corev1.PullAlways, c.ImagePullPolicy)
+		assert.Equal(tt, storageSync.Resources.ResourceRequirements, c.Resources)
+		require.NotNil(tt, c.SecurityContext)
+		assert.Equal(tt, &runAsNonRoot, c.SecurityContext.RunAsNonRoot)
+		assert.Contains(tt, c.Env, corev1.EnvVar{Name: "SYNC_INTERVAL_SECONDS", Value: "10"})
+		assert.Equal(tt, []corev1.LocalObjectReference{{Name: "registry"}}, newPod.Spec.ImagePullSecrets)
+		assert.Equal(tt, int64(600), *newPod.Spec.TerminationGracePeriodSeconds)
+	})
+
+	t.Run("whenAnnotationOverrides", func(tt *testing.T) {
+		driverPod := getDriverPod()
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncImage] = "registry.example.com/cloud-storage-sync:v2"
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncImagePullPolicy] = "Never"
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncImagePullSecrets] = "other, registry"
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncResources] = `{"limits":{"memory":"256Mi"}}`
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncSecurityContext] = `{"runAsUser":1000}`
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncInterval] = "1m"
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncTerminationGracePeriod] = "15m"
+
+		newPod := mutate(tt, driverPod)
+		c := newPod.Spec.Containers[0]
+		assert.Equal(tt, "registry.example.com/cloud-storage-sync:v2", c.Image)
+		assert.Equal(tt, corev1.PullNever, c.ImagePullPolicy)
+		assert.Equal(tt, corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		}, c.Resources)
+		require.NotNil(tt, c.SecurityContext)
+		assert.Nil(tt, c.SecurityContext.RunAsNonRoot)
+		assert.Equal(tt, int64(1000), *c.SecurityContext.RunAsUser)
+		assert.Contains(tt, c.Env, corev1.EnvVar{Name: "SYNC_INTERVAL_SECONDS", Value: "60"})
+		assert.Equal(tt, []corev1.LocalObjectReference{{Name: "registry"}, {Name: "other"}}, newPod.Spec.ImagePullSecrets)
+		assert.Equal(tt, int64(900), *newPod.Spec.TerminationGracePeriodSeconds)
+	})
+
+	t.Run("whenInvalidAnnotations", func(tt *testing.T) {
+		driverPod := getDriverPod()
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncImagePullPolicy] = "Sometimes"
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncResources] = `{"requests":"lots"}`
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncSecurityContext] = `{"runAsRoot":true}`
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncInterval] = "often"
+		driverPod.Annotations[config.WaveConfigAnnotationStorageSyncTerminationGracePeriod] = "-1m"
+
+		newPod := mutate(tt, driverPod)
+		c := newPod.Spec.Containers[0]
+		assert.Equal(tt, corev1.PullAlways, c.ImagePullPolicy)
+		assert.Equal(tt, storageSync.Resources.ResourceRequirements, c.Resources)
+		assert.Equal(tt, &runAsNonRoot, c.SecurityContext.RunAsNonRoot)
+		assert.Contains(tt, c.Env, corev1.EnvVar{Name: "SYNC_INTERVAL_SECONDS", Value: "10"})
+		assert.Equal(tt, int64(600), *newPod.Spec.TerminationGracePeriodSeconds)
+	})
+
+	t.Run("whenLongerGracePeriodSet", func(tt *testing.T) {
+		driverPod := getDriverPod()
+		longGracePeriod := int64(3600)
+		driverPod.Spec.TerminationGracePeriodSeconds = &longGracePeriod
+
+		newPod := mutate(tt, driverPod)
+		assert.Equal(tt, int64(3600), *newPod.Spec.TerminationGracePeriodSeconds)
+	})
+}
+
 func TestMutateSparkPod_instanceConfiguration(t *testing.T) {
 
 	type testCase struct {
@@ -207,7 +320,7 @@ func TestMutateSparkPod_instanceConfiguration(t *testing.T) {
 	testFunc := func(tt *testing.T, tc testCase) {
 
 		req := getAdmissionRequest(tt, tc.pod)
-		res, err := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}).Mutate(req)
+		res, err := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil).Mutate(req)
 		assert.NoError(tt, err)
 		assert.NotNil(tt, res)
 		assert.Equal(tt, tc.pod.UID, res.UID)
@@ -786,7 +899,7 @@ func TestMutateExecutorPod(t *testing.T) {
 		SparkRoleLabel: SparkRoleExecutorValue,
 	}
 	req := getAdmissionRequest(t, execPod)
-	r, err := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}).Mutate(req)
+	r, err := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil).Mutate(req)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	assert.Equal(t, execPod.UID, r.UID)
@@ -810,7 +923,7 @@ func TestIdempotency(t *testing.T) {
 	}
 	driverPod.Annotations[config.WaveConfigAnnotationSyncEventLogs] = "true"
 	req := getAdmissionRequest(t, driverPod)
-	m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{})
+	m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil)
 	r, err := m.Mutate(req)
 	require.NoError(t, err)
 
@@ -838,7 +951,7 @@ func TestIdempotency(t *testing.T) {
 func TestSkipNonSparkPod(t *testing.T) {
 	nonSparkPod := getSimplePod()
 	req := getAdmissionRequest(t, nonSparkPod)
-	r, err := NewPodMutator(log, &util.FailedStorageProvider{}, &util.FakeInstanceTypeManager{}).Mutate(req)
+	r, err := NewPodMutator(log, &util.FailedStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil).Mutate(req)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 	assert.Equal(t, nonSparkPod.UID, r.UID)
@@ -857,7 +970,7 @@ func TestMutatePodBadStorage(t *testing.T) {
 		driverPod.Annotations[config.WaveConfigAnnotationSyncEventLogs] = "true"
 
 		req := getAdmissionRequest(t, driverPod)
-		r, err := NewPodMutator(log, provider, &util.FakeInstanceTypeManager{}).Mutate(req)
+		r, err := NewPodMutator(log, provider, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil).Mutate(req)
 		require.NoError(t, err)
 		assert.NotNil(t, r)
 		assert.Equal(t, driverPod.UID, r.UID)
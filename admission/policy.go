@@ -0,0 +1,218 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/config/instances"
+)
+
+// +kubebuilder:rbac:groups=wave.spot.io,resources=wavesparkpolicies,verbs=get;list;watch
+
+// PolicyProvider provides the WaveSparkPolicy applied to the Spark applications of a namespace
+type PolicyProvider interface {
+	// GetPolicy returns the policy of the namespace, nil if the namespace has no policy
+	GetPolicy(ctx context.Context, namespace string) (*v1alpha1.WaveSparkPolicy, error)
+}
+
+type policyProvider struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+func NewPolicyProvider(client client.Reader, log logr.Logger) PolicyProvider {
+	return &policyProvider{
+		client: client,
+		log:    log,
+	}
+}
+
+// GetPolicy returns the policy of the namespace, the first policy by name if the namespace has more than one
+func (p *policyProvider) GetPolicy(ctx context.Context, namespace string) (*v1alpha1.WaveSparkPolicy, error) {
+	list := &v1alpha1.WaveSparkPolicyList{}
+	err := p.client.List(ctx, list, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("could not list spark policies, %w", err)
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	if len(list.Items) > 1 {
+		p.log.Info("Multiple spark policies in namespace, applying the first", "namespace", namespace, "policy", list.Items[0].Name)
+	}
+	return &list.Items[0], nil
+}
+
+// getPolicy returns the policy of the namespace, nil if there is none or it cannot be retrieved
+func getPolicy(ctx context.Context, provider PolicyProvider, namespace string, log logr.Logger) *v1alpha1.WaveSparkPolicy {
+	policy, err := provider.GetPolicy(ctx, namespace)
+	if err != nil {
+		log.Error(err, "could not get spark policy, continuing without policy")
+		return nil
+	}
+	return policy
+}
+
+// getPodPolicy returns the driver or executor part of the policy, nil if there is no policy
+func getPodPolicy(policy *v1alpha1.WaveSparkPolicy, sparkRole string) *v1alpha1.WaveSparkPodPolicy {
+	if policy == nil {
+		return nil
+	}
+	if sparkRole == SparkRoleDriverValue {
+		return &policy.Spec.Driver
+	}
+	return &policy.Spec.Executor
+}
+
+// resolveEventLogSync returns whether event log sync is enabled for the driver pod,
+// the policy's enforced setting takes precedence over the pod's annotation, which takes precedence over the policy's default
+func resolveEventLogSync(annotations map[string]string, policy *v1alpha1.WaveSparkPolicy) bool {
+	enabled, configured := config.GetEventLogSync(annotations)
+	if policy == nil || policy.Spec.EventLogSync.Enabled == nil {
+		return enabled
+	}
+	if policy.Spec.EventLogSync.Enforced || !configured {
+		return *policy.Spec.EventLogSync.Enabled
+	}
+	return enabled
+}
+
+// resolveInstanceLifecycle returns the instance lifecycle of the pod, the pod's annotation takes precedence over
+// the policy's default, which takes precedence over the operator's default. Lifecycles the policy does not allow
+// are replaced by the policy's default, or its first allowed lifecycle.
+func resolveInstanceLifecycle(annotations map[string]string, podPolicy *v1alpha1.WaveSparkPodPolicy, defaultLifecycle config.InstanceLifecycle, log logr.Logger) config.InstanceLifecycle {
+	lifecycle := config.GetInstanceLifecycle(annotations, log)
+	if podPolicy == nil {
+		if lifecycle == "" {
+			return defaultLifecycle
+		}
+		return lifecycle
+	}
+
+	if lifecycle == "" {
+		lifecycle = config.ParseInstanceLifecycle(podPolicy.InstanceLifecycle)
+	}
+	if lifecycle == "" {
+		lifecycle = defaultLifecycle
+	}
+	return constrainInstanceLifecycle(lifecycle, podPolicy, log)
+}
+
+// constrainInstanceLifecycle returns the lifecycle if the pod policy allows it, otherwise the policy default
+// if allowed, or the first allowed lifecycle
+func constrainInstanceLifecycle(lifecycle config.InstanceLifecycle, podPolicy *v1alpha1.WaveSparkPodPolicy, log logr.Logger) config.InstanceLifecycle {
+	if podPolicy == nil || len(podPolicy.AllowedInstanceLifecycles) == 0 {
+		return lifecycle
+	}
+	allowed := make([]config.InstanceLifecycle, 0, len(podPolicy.AllowedInstanceLifecycles))
+	for _, a := range podPolicy.AllowedInstanceLifecycles {
+		if l := config.ParseInstanceLifecycle(a); l != "" {
+			allowed = append(allowed, l)
+		}
+	}
+	if len(allowed) == 0 {
+		log.Info(fmt.Sprintf("Ignoring spark policy allowed instance lifecycles, no valid lifecycle in %v", podPolicy.AllowedInstanceLifecycles))
+		return lifecycle
+	}
+	for _, l := range allowed {
+		if l == lifecycle {
+			return lifecycle
+		}
+	}
+	policyDefault := config.ParseInstanceLifecycle(podPolicy.InstanceLifecycle)
+	constrained := allowed[0]
+	for _, l := range allowed {
+		if l == policyDefault {
+			constrained = l
+		}
+	}
+	log.Info(fmt.Sprintf("Instance lifecycle %q not allowed by spark policy, using %q", lifecycle, constrained))
+	return constrained
+}
+
+// resolveInstanceTypes returns the instance types of the pod, the pod's annotation takes precedence over the
+// policy's default. Instance types the policy does not allow are removed, if none remain the allowed types are used.
+func resolveInstanceTypes(annotations map[string]string, podPolicy *v1alpha1.WaveSparkPodPolicy, instanceTypeManager instances.InstanceTypeManager, log logr.Logger) []string {
+	instanceTypes := config.GetConfiguredInstanceTypes(annotations, instanceTypeManager, log)
+	if podPolicy == nil {
+		return instanceTypes
+	}
+	if len(instanceTypes) == 0 && len(podPolicy.InstanceTypes) > 0 {
+		instanceTypes = config.GetInstanceTypes(podPolicy.InstanceTypes, instanceTypeManager, log)
+	}
+
+	if len(podPolicy.AllowedInstanceTypes) == 0 {
+		return instanceTypes
+	}
+	allowed := config.GetInstanceTypes(podPolicy.AllowedInstanceTypes, instanceTypeManager, log)
+	if len(allowed) == 0 {
+		log.Info(fmt.Sprintf("Ignoring spark policy allowed instance types, no valid instance type in %v", podPolicy.AllowedInstanceTypes))
+		return instanceTypes
+	}
+	isAllowed := make(map[string]bool, len(allowed))
+	for _, it := range allowed {
+		isAllowed[it] = true
+	}
+	constrained := make([]string, 0, len(instanceTypes))
+	for _, it := range instanceTypes {
+		if isAllowed[it] {
+			constrained = append(constrained, it)
+		} else {
+			log.Info(fmt.Sprintf("Instance type %q not allowed by spark policy", it))
+		}
+	}
+	if len(constrained) == 0 {
+		return allowed
+	}
+	return constrained
+}
+
+// applyPolicy adds the policy's tolerations to the pod and records the policy in the pod's annotations
+func applyPolicy(pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, podPolicy *v1alpha1.WaveSparkPodPolicy) {
+	if policy == nil {
+		return
+	}
+
+	for i := range podPolicy.Tolerations {
+		toleration := podPolicy.Tolerations[i]
+		exists := false
+		for _, t := range pod.Spec.Tolerations {
+			if t.MatchToleration(&toleration) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			pod.Spec.Tolerations = append(pod.Spec.Tolerations, toleration)
+		}
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[config.WaveConfigAnnotationSparkPolicy] = policy.Name
+}
+
+// setEventLogSyncAnnotation records the resolved event log sync setting in the driver pod's annotations,
+// so everything reading the annotation sees the setting of the policy
+func setEventLogSyncAnnotation(pod *corev1.Pod, enabled bool) {
+	if current, configured := config.GetEventLogSync(pod.Annotations); configured && current == enabled {
+		return
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[config.WaveConfigAnnotationSyncEventLogs] = strconv.FormatBool(enabled)
+	delete(pod.Annotations, config.WaveConfigAnnotationSyncEventLogsOld)
+}
@@ -0,0 +1,328 @@
+package admission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlrt_fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/util"
+)
+
+var F = false
+
+func newTestPolicyProvider(policies ...runtime.Object) PolicyProvider {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = v1alpha1.AddToScheme(testScheme)
+	return NewPolicyProvider(ctrlrt_fake.NewFakeClientWithScheme(testScheme, policies...), log)
+}
+
+func newTestPolicy(name string, namespace string) *v1alpha1.WaveSparkPolicy {
+	return &v1alpha1.WaveSparkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+}
+
+func TestPolicyProvider(t *testing.T) {
+
+	t.Run("whenNoPolicy", func(tt *testing.T) {
+		p := newTestPolicyProvider(newTestPolicy("other", "other-namespace"))
+		policy, err := p.GetPolicy(context.TODO(), "spark-jobs")
+		require.NoError(tt, err)
+		assert.Nil(tt, policy)
+	})
+
+	t.Run("whenMultiplePolicies", func(tt *testing.T) {
+		p := newTestPolicyProvider(
+			newTestPolicy("team-b", "spark-jobs"),
+			newTestPolicy("team-a", "spark-jobs"),
+			newTestPolicy("aaa", "other-namespace"))
+		policy, err := p.GetPolicy(context.TODO(), "spark-jobs")
+		require.NoError(tt, err)
+		require.NotNil(tt, policy)
+		assert.Equal(tt, "team-a", policy.Name)
+	})
+}
+
+func TestResolveEventLogSync(t *testing.T) {
+
+	type testCase struct {
+		annotation string
+		enabled    *bool
+		enforced   bool
+		expected   bool
+	}
+
+	testCases := map[string]testCase{
+		"noPolicy":                   {annotation: "true", expected: true},
+		"policyDefault":              {enabled: &T, expected: true},
+		"annotationOverridesDefault": {annotation: "false", enabled: &T, expected: false},
+		"invalidAnnotation":          {annotation: "nonsense", enabled: &T, expected: true},
+		"policyEnforcedOn":           {annotation: "false", enabled: &T, enforced: true, expected: true},
+		"policyEnforcedOff":          {annotation: "true", enabled: &F, enforced: true, expected: false},
+		"enforcedWithoutEnabled":     {annotation: "true", enforced: true, expected: true},
+	}
+
+	for name, tc := range testCases {
+		annotations := map[string]string{}
+		if tc.annotation != "" {
+			annotations[config.WaveConfigAnnotationSyncEventLogs] = tc.annotation
+		}
+		var policy *v1alpha1.WaveSparkPolicy
+		if name != "noPolicy" {
+			policy = newTestPolicy("policy", "spark-jobs")
+			policy.Spec.EventLogSync = v1alpha1.EventLogSyncPolicy{Enabled: tc.enabled, Enforced: tc.enforced}
+		}
+		assert.Equal(t, tc.expected, resolveEventLogSync(annotations, policy), name)
+	}
+}
+
+func TestResolveInstanceLifecycle(t *testing.T) {
+
+	type testCase struct {
+		annotation string
+		podPolicy  *v1alpha1.WaveSparkPodPolicy
+		expected   config.InstanceLifecycle
+	}
+
+	testCases := map[string]testCase{
+		"operatorDefault": {
+			expected: config.InstanceLifecycleOnDemand,
+		},
+		"annotation": {
+			annotation: "spot",
+			podPolicy:  &v1alpha1.WaveSparkPodPolicy{InstanceLifecycle: "od"},
+			expected:   config.InstanceLifecycleSpot,
+		},
+		"policyDefault": {
+			podPolicy: &v1alpha1.WaveSparkPodPolicy{InstanceLifecycle: "spot"},
+			expected:  config.InstanceLifecycleSpot,
+		},
+		"annotationNotAllowed": {
+			annotation: "od",
+			podPolicy:  &v1alpha1.WaveSparkPodPolicy{AllowedInstanceLifecycles: []string{"spot"}},
+			expected:   config.InstanceLifecycleSpot,
+		},
+		"operatorDefaultNotAllowed": {
+			podPolicy: &v1alpha1.WaveSparkPodPolicy{AllowedInstanceLifecycles: []string{"spot"}},
+			expected:  config.InstanceLifecycleSpot,
+		},
+		"annotationAllowed": {
+			annotation: "od",
+			podPolicy:  &v1alpha1.WaveSparkPodPolicy{InstanceLifecycle: "spot", AllowedInstanceLifecycles: []string{"spot", "od"}},
+			expected:   config.InstanceLifecycleOnDemand,
+		},
+		"invalidAllowed": {
+			annotation: "od",
+			podPolicy:  &v1alpha1.WaveSparkPodPolicy{AllowedInstanceLifecycles: []string{"nonsense"}},
+			expected:   config.InstanceLifecycleOnDemand,
+		},
+	}
+
+	for name, tc := range testCases {
+		annotations := map[string]string{}
+		if tc.annotation != "" {
+			annotations[config.WaveConfigAnnotationInstanceLifecycle] = tc.annotation
+		}
+		res := resolveInstanceLifecycle(annotations, tc.podPolicy, config.InstanceLifecycleOnDemand, log)
+		assert.Equal(t, tc.expected, res, name)
+	}
+}
+
+func TestResolveInstanceTypes(t *testing.T) {
+
+	type testCase struct {
+		annotation string
+		podPolicy  *v1alpha1.WaveSparkPodPolicy
+		expected   []string
+	}
+
+	testCases := map[string]testCase{
+		"noPolicy": {
+			annotation: "m5.xlarge",
+			expected:   []string{"m5.xlarge"},
+		},
+		"noConfiguration": {
+			podPolicy: &v1alpha1.WaveSparkPodPolicy{},
+			expected:  []string{},
+		},
+		"policyDefault": {
+			podPolicy: &v1alpha1.WaveSparkPodPolicy{InstanceTypes: []string{"h1"}},
+			expected:  []string{"h1.large", "h1.medium", "h1.small"},
+		},
+		"annotationOverridesDefault": {
+			annotation: "t2.micro",
+			podPolicy:  &v1alpha1.WaveSparkPodPolicy{InstanceTypes: []string{"h1"}},
+			expected:   []string{"t2.micro"},
+		},
+		"annotationPartlyAllowed": {
+			annotation: "t2.micro,m5.xlarge",
+			podPolicy:  &v1alpha1.WaveSparkPodPolicy{AllowedInstanceTypes: []string{"m5.xlarge", "m5.2xlarge"}},
+			expected:   []string{"m5.xlarge"},
+		},
+		"annotationNotAllowed": {
+			annotation: "t2.micro",
+			podPolicy:  &v1alpha1.WaveSparkPodPolicy{AllowedInstanceTypes: []string{"m5.xlarge", "m5.2xlarge"}},
+			expected:   []string{"m5.2xlarge", "m5.xlarge"},
+		},
+		"noTypesWithAllowed": {
+			podPolicy: &v1alpha1.WaveSparkPodPolicy{AllowedInstanceTypes: []string{"m5.xlarge"}},
+			expected:  []string{"m5.xlarge"},
+		},
+		"invalidAllowed": {
+			annotation: "t2.micro",
+			podPolicy:  &v1alpha1.WaveSparkPodPolicy{AllowedInstanceTypes: []string{"nonsense"}},
+			expected:   []string{"t2.micro"},
+		},
+	}
+
+	for name, tc := range testCases {
+		annotations := map[string]string{}
+		if tc.annotation != "" {
+			annotations[config.WaveConfigAnnotationInstanceType] = tc.annotation
+		}
+		res := resolveInstanceTypes(annotations, tc.podPolicy, &util.FakeInstanceTypeManager{}, log)
+		assert.Equal(t, tc.expected, res, name)
+	}
+}
+
+func TestMutateSparkPod_policy(t *testing.T) {
+
+	toleration := corev1.Toleration{
+		Key:      "dedicated",
+		Operator: corev1.TolerationOpEqual,
+		Value:    "spark",
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+
+	policy := newTestPolicy("team-a", "spark-jobs")
+	policy.Spec = v1alpha1.WaveSparkPolicySpec{
+		Driver: v1alpha1.WaveSparkPodPolicy{
+			AllowedInstanceLifecycles: []string{"od"},
+			InstanceTypes:             []string{"m5.xlarge"},
+			Tolerations:               []corev1.Toleration{toleration},
+		},
+		Executor: v1alpha1.WaveSparkPodPolicy{
+			InstanceLifecycle:    "od",
+			AllowedInstanceTypes: []string{"m5.xlarge", "m5.2xlarge"},
+		},
+		EventLogSync: v1alpha1.EventLogSyncPolicy{
+			Enabled: &T,
+		},
+	}
+
+	mutate := func(t *testing.T, pod *corev1.Pod) *corev1.Pod {
+		req := getAdmissionRequest(t, pod)
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(policy), nil)
+		r, err := m.Mutate(req)
+		require.NoError(t, err)
+		obj, err := ApplyJsonPatch(r.Patch, pod)
+		require.NoError(t, err)
+		newPod, ok := obj.(*corev1.Pod)
+		require.True(t, ok)
+		return newPod
+	}
+
+	getRequirements := func(pod *corev1.Pod) []corev1.NodeSelectorRequirement {
+		require.NotNil(t, pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+		return pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions
+	}
+
+	t.Run("whenDriver", func(tt *testing.T) {
+		pod := getSimplePod()
+		pod.Namespace = "spark-jobs"
+		pod.Labels = map[string]string{SparkRoleLabel: SparkRoleDriverValue}
+		pod.Annotations[config.WaveConfigAnnotationInstanceLifecycle] = "spot"
+
+		newPod := mutate(tt, pod)
+		assert.Equal(tt, "team-a", newPod.Annotations[config.WaveConfigAnnotationSparkPolicy])
+		assert.Equal(tt, "true", newPod.Annotations[config.WaveConfigAnnotationSyncEventLogs])
+		assert.Equal(tt, "storage-sync", newPod.Spec.Containers[0].Name)
+		assert.Equal(tt, []corev1.Toleration{toleration}, newPod.Spec.Tolerations)
+		assert.Equal(tt, []corev1.NodeSelectorRequirement{
+			{Key: nodeLifeCycleKey, Operator: corev1.NodeSelectorOpIn, Values: []string{nodeLifeCycleValueOnDemand}},
+			{Key: nodeInstanceTypeKey, Operator: corev1.NodeSelectorOpIn, Values: []string{"m5.xlarge"}},
+		}, getRequirements(newPod))
+
+		// Mutating the mutated pod does not change it
+		assert.Equal(tt, newPod, mutate(tt, newPod))
+	})
+
+	t.Run("whenExecutor", func(tt *testing.T) {
+		pod := getSimplePod()
+		pod.Namespace = "spark-jobs"
+		pod.Labels = map[string]string{SparkRoleLabel: SparkRoleExecutorValue}
+		pod.Annotations[config.WaveConfigAnnotationInstanceType] = "m5.2xlarge,t2.micro"
+
+		newPod := mutate(tt, pod)
+		assert.Equal(tt, "team-a", newPod.Annotations[config.WaveConfigAnnotationSparkPolicy])
+		assert.Empty(tt, newPod.Annotations[config.WaveConfigAnnotationSyncEventLogs])
+		assert.Equal(tt, 1, len(newPod.Spec.Containers))
+		assert.Empty(tt, newPod.Spec.Tolerations)
+		assert.Equal(tt, []corev1.NodeSelectorRequirement{
+			{Key: nodeLifeCycleKey, Operator: corev1.NodeSelectorOpIn, Values: []string{nodeLifeCycleValueOnDemand}},
+			{Key: nodeInstanceTypeKey, Operator: corev1.NodeSelectorOpIn, Values: []string{"m5.2xlarge"}},
+		}, getRequirements(newPod))
+	})
+
+	t.Run("whenOtherNamespace", func(tt *testing.T) {
+		pod := getSimplePod()
+		pod.Labels = map[string]string{SparkRoleLabel: SparkRoleDriverValue}
+
+		newPod := mutate(tt, pod)
+		assert.Empty(tt, newPod.Annotations[config.WaveConfigAnnotationSparkPolicy])
+		assert.Empty(tt, newPod.Annotations[config.WaveConfigAnnotationSyncEventLogs])
+		assert.Equal(tt, 1, len(newPod.Spec.Containers))
+	})
+}
+
+func TestMutateConfigMap_policy(t *testing.T) {
+	cm := sparkConfigMap
+	driver := getDriverPod(cm.OwnerReferences[0].Name, cm.Namespace, true, "")
+
+	policy := newTestPolicy("team-a", cm.Namespace)
+	policy.Spec.SparkConf = v1alpha1.SparkConfPolicy{
+		Defaults: map[string]string{
+			"spark.executor.memory":   "4g",
+			"spark.sql.shuffle.parts": "400",
+		},
+		Overrides: map[string]string{
+			"spark.executor.instances": "10",
+			"spark.eventLog.dir":       "s3a://elsewhere",
+		},
+	}
+
+	req := getAdmissionRequest(t, cm)
+	r, err := NewConfigMapMutator(log, k8sfake.NewSimpleClientset(driver), &util.FakeStorageProvider{}, newTestPolicyProvider(policy), config.SparkConf{}).Mutate(req)
+	require.NoError(t, err)
+	obj, err := ApplyJsonPatch(r.Patch, cm)
+	require.NoError(t, err)
+	newCm, ok := obj.(*corev1.ConfigMap)
+	require.True(t, ok)
+
+	props, err := properties.LoadString(newCm.Data["spark.properties"])
+	require.NoError(t, err)
+
+	// Defaults do not replace the application's properties
+	assert.Equal(t, "512m", props.MustGet("spark.executor.memory"))
+	assert.Equal(t, "400", props.MustGet("spark.sql.shuffle.parts"))
+	// Overrides do
+	assert.Equal(t, "10", props.MustGet("spark.executor.instances"))
+	// Except the operator's properties
+	assert.Equal(t, config.SyncedEventLogDir, props.MustGet("spark.eventLog.dir"))
+	assert.Equal(t, "true", props.MustGet("spark.metrics.appStatusSource.enabled"))
+}
@@ -7,8 +7,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -16,6 +14,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/spotinst/wave-operator/cloudstorage"
+	"github.com/spotinst/wave-operator/internal/config"
 	"github.com/spotinst/wave-operator/internal/config/instances"
 )
 
@@ -23,6 +22,12 @@ type AdmissionController struct {
 	client              kubernetes.Interface
 	provider            cloudstorage.CloudStorageProvider
 	instanceTypeManager instances.InstanceTypeManager
+	storageSync         config.StorageSync
+	sparkConf           config.SparkConf
+	webhook             config.AdmissionWebhook
+	certWatcher         *certWatcher
+	policyProvider      PolicyProvider
+	executorPlacement   ExecutorPlacement
 	log                 logr.Logger
 }
 
@@ -31,20 +36,30 @@ type Mutator interface {
 }
 
 func handleRoot(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprintf(w, "Wave Mutating Admission Webhook")
+	fmt.Fprintf(w, "Wave Admission Webhook")
 }
 
-func NewAdmissionController(client kubernetes.Interface, provider cloudstorage.CloudStorageProvider, instanceTypeManager instances.InstanceTypeManager, log logr.Logger) *AdmissionController {
+func NewAdmissionController(client kubernetes.Interface, provider cloudstorage.CloudStorageProvider, instanceTypeManager instances.InstanceTypeManager, storageSync config.StorageSync, sparkConf config.SparkConf, webhook config.AdmissionWebhook, policyProvider PolicyProvider, executorPlacement ExecutorPlacement, log logr.Logger) *AdmissionController {
+	webhook = webhook.WithDefaults()
 	return &AdmissionController{
 		client:              client,
 		provider:            provider,
 		instanceTypeManager: instanceTypeManager,
+		storageSync:         storageSync,
+		sparkConf:           sparkConf,
+		webhook:             webhook,
+		certWatcher:         newCertWatcher(webhook.CertDir, time.Now, log.WithName("certWatcher")),
+		policyProvider:      policyProvider,
+		executorPlacement:   executorPlacement,
 		log:                 log,
 	}
 }
 
-func (ac *AdmissionController) GetHandlerFunc(m Mutator) func(http.ResponseWriter, *http.Request) {
+// GetHandlerFunc returns the webhook handler of the mutator, the mutator name labels its metrics
+func (ac *AdmissionController) GetHandlerFunc(mutator string, m Mutator) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
+		admissionRequests.WithLabelValues(mutator).Inc()
+
 		body, err := ioutil.ReadAll(r.Body)
 		defer r.Body.Close()
 		if err != nil {
@@ -64,10 +79,20 @@ func (ac *AdmissionController) GetHandlerFunc(m Mutator) func(http.ResponseWrite
 		response, err := m.Mutate(review.Request)
 		if err != nil {
 			ac.log.Error(err, "mutating webhook request", "name", review.Request.Name, "kind", review.Request.Kind)
+			admissionErrors.WithLabelValues(mutator).Inc()
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 		review.Response = response
+		if response != nil && len(response.Patch) > 0 {
+			admissionPatches.WithLabelValues(mutator).Inc()
+		}
+		if response != nil && !response.Allowed {
+			admissionDenials.WithLabelValues(mutator).Inc()
+		}
+		if response != nil && len(response.Warnings) > 0 {
+			admissionWarnings.WithLabelValues(mutator).Inc()
+		}
 
 		w.WriteHeader(http.StatusOK)
 		encoder := json.NewEncoder(w)
@@ -79,48 +104,82 @@ func (ac *AdmissionController) GetHandlerFunc(m Mutator) func(http.ResponseWrite
 	}
 }
 
-func (ac *AdmissionController) Start(ctx context.Context) error {
-	var err error
+// CheckCertificate returns an error if no valid webhook certificate is loaded, or it expired, the webhook is not ready.
+// It is a readiness check only, restarting the operator does not rotate the certificate.
+func (ac *AdmissionController) CheckCertificate(_ *http.Request) error {
+	return ac.certWatcher.checkLoaded()
+}
 
-	var conf *tls.Config = nil
-	// TODO: Remove telepresence variable check, looks out of place
-	certDir := fmt.Sprintf("%s/etc/webhook/certs", os.Getenv("TELEPRESENCE_ROOT"))
-	fileinfo, err := os.Stat(certDir)
+// NeedLeaderElection returns false, the webhook is served on every replica, and replicas waiting for
+// the leader election lease must still load the certificate to become ready
+func (ac *AdmissionController) NeedLeaderElection() bool {
+	return false
+}
+
+func (ac *AdmissionController) getTLSConfig() (*tls.Config, error) {
+	minVersion, err := ac.webhook.GetMinTLSVersion()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	ac.log.Info("loading webhook certs", "directory", fileinfo.Name())
-	cert, err := tls.LoadX509KeyPair(filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+	cipherSuites, err := ac.webhook.GetCipherSuites()
 	if err != nil {
-		conf = &tls.Config{
-			Certificates: []tls.Certificate{cert},
-		}
+		return nil, err
 	}
+	return &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		GetCertificate: ac.certWatcher.GetCertificate,
+	}, nil
+}
 
-	pm := NewPodMutator(ac.log, ac.provider, ac.instanceTypeManager)
-	cm := NewConfigMapMutator(ac.log, ac.client, ac.provider)
+func (ac *AdmissionController) Start(ctx context.Context) error {
+	conf, err := ac.getTLSConfig()
+	if err != nil {
+		return fmt.Errorf("invalid webhook tls configuration, %w", err)
+	}
+
+	ac.log.Info("loading webhook certs", "directory", ac.webhook.CertDir)
+	if err := ac.certWatcher.load(); err != nil {
+		// the certificate may not be issued yet, it is loaded once it is and the webhook is not ready until then
+		ac.log.Error(err, "could not load webhook certificate")
+	}
+	ac.certWatcher.warnExpiry()
+	go func() {
+		if err := ac.certWatcher.Watch(ctx); err != nil {
+			ac.log.Error(err, "webhook certificate will not be reloaded")
+		}
+	}()
+
+	pm := NewPodMutator(ac.log, ac.provider, ac.instanceTypeManager, ac.storageSync, ac.sparkConf, ac.policyProvider, ac.executorPlacement)
+	cm := NewConfigMapMutator(ac.log, ac.client, ac.provider, ac.policyProvider, ac.sparkConf)
+	pv := NewPodValidator(ac.log, ac.client, ac.instanceTypeManager, ac.policyProvider)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", handleRoot)
-	mux.HandleFunc("/mutate/pod", ac.GetHandlerFunc(pm))
-	mux.HandleFunc("/mutate/configmap", ac.GetHandlerFunc(cm))
+	mux.HandleFunc("/mutate/pod", ac.GetHandlerFunc(mutatorPod, pm))
+	mux.HandleFunc("/mutate/configmap", ac.GetHandlerFunc(mutatorConfigMap, cm))
+	mux.HandleFunc("/validate/pod", ac.GetHandlerFunc(validatorPod, validatingMutator{pv}))
 
 	srv := &http.Server{
-		Addr:      "0.0.0.0:9443", // TODO configure port
+		Addr:      fmt.Sprintf("0.0.0.0:%d", ac.webhook.Port),
 		Handler:   mux,
 		TLSConfig: conf,
 	}
+	serveErr := make(chan error, 1)
 	go func() {
 		ac.log.Info("starting admission controller", "address", srv.Addr)
-		if err := srv.ListenAndServeTLS(
-			filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"),
-		); err != nil && err != http.ErrServerClosed {
-			ac.log.Info("listen", "error", err)
-			return
+		// the certificate is served by the tls configuration
+		if err := srv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
 		}
 	}()
 
-	<-ctx.Done()
+	select {
+	case err := <-serveErr:
+		ac.log.Error(err, "admission controller failed")
+		return err
+	case <-ctx.Done():
+	}
 
 	ac.log.Info("shutting down admission controller")
 
@@ -0,0 +1,195 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+type testMutator func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error)
+
+func (f testMutator) Mutate(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+	return f(req)
+}
+
+func serveAdmissionReview(t *testing.T, mutator string, m Mutator) *httptest.ResponseRecorder {
+	review := &admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{UID: "a50d5ab4-3e3f-4d4c-8c5e-2b5c3a0e1f11", Name: "test"},
+	}
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	ac := NewAdmissionController(nil, nil, nil, config.StorageSync{}, config.SparkConf{}, config.AdmissionWebhook{}, nil, nil, log)
+	w := httptest.NewRecorder()
+	ac.GetHandlerFunc(mutator, m)(w, httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewReader(body)))
+	return w
+}
+
+func TestHandlerMetrics(t *testing.T) {
+
+	t.Run("whenPatched", func(tt *testing.T) {
+		w := serveAdmissionReview(tt, "test-patched", testMutator(func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+			return &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true, Patch: []byte(`[]`)}, nil
+		}))
+		assert.Equal(tt, http.StatusOK, w.Code)
+		assert.Equal(tt, float64(1), testutil.ToFloat64(admissionRequests.WithLabelValues("test-patched")))
+		assert.Equal(tt, float64(1), testutil.ToFloat64(admissionPatches.WithLabelValues("test-patched")))
+		assert.Equal(tt, float64(0), testutil.ToFloat64(admissionErrors.WithLabelValues("test-patched")))
+	})
+
+	t.Run("whenNotPatched", func(tt *testing.T) {
+		w := serveAdmissionReview(tt, "test-not-patched", testMutator(func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+			return &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}, nil
+		}))
+		assert.Equal(tt, http.StatusOK, w.Code)
+		assert.Equal(tt, float64(1), testutil.ToFloat64(admissionRequests.WithLabelValues("test-not-patched")))
+		assert.Equal(tt, float64(0), testutil.ToFloat64(admissionPatches.WithLabelValues("test-not-patched")))
+	})
+
+	t.Run("whenDenied", func(tt *testing.T) {
+		w := serveAdmissionReview(tt, "test-denied", testMutator(func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+			return &admissionv1.AdmissionResponse{UID: req.UID, Allowed: false}, nil
+		}))
+		assert.Equal(tt, http.StatusOK, w.Code)
+		assert.Equal(tt, float64(1), testutil.ToFloat64(admissionDenials.WithLabelValues("test-denied")))
+		assert.Equal(tt, float64(0), testutil.ToFloat64(admissionWarnings.WithLabelValues("test-denied")))
+	})
+
+	t.Run("whenWarned", func(tt *testing.T) {
+		w := serveAdmissionReview(tt, "test-warned", testMutator(func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+			return &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true, Warnings: []string{"test warning"}}, nil
+		}))
+		assert.Equal(tt, http.StatusOK, w.Code)
+		assert.Equal(tt, float64(0), testutil.ToFloat64(admissionDenials.WithLabelValues("test-warned")))
+		assert.Equal(tt, float64(1), testutil.ToFloat64(admissionWarnings.WithLabelValues("test-warned")))
+	})
+
+	t.Run("whenMutationFails", func(tt *testing.T) {
+		w := serveAdmissionReview(tt, "test-fails", testMutator(func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+			return nil, fmt.Errorf("test error")
+		}))
+		assert.Equal(tt, http.StatusInternalServerError, w.Code)
+		assert.Equal(tt, float64(1), testutil.ToFloat64(admissionRequests.WithLabelValues("test-fails")))
+		assert.Equal(tt, float64(1), testutil.ToFloat64(admissionErrors.WithLabelValues("test-fails")))
+	})
+}
+
+func TestAdmissionControllerTLS(t *testing.T) {
+	now := time.Now()
+	dir := getTestCertDir(t)
+	writeTestCertificate(t, dir, 1, now.Add(-time.Hour), now.Add(time.Hour))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	webhook := config.AdmissionWebhook{Port: port, CertDir: dir, MinTLSVersion: "1.3"}
+	ac := NewAdmissionController(nil, nil, nil, config.StorageSync{}, config.SparkConf{}, webhook, nil, nil, log)
+	assert.Error(t, ac.CheckCertificate(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ac.Start(ctx) }()
+
+	getServedCert := func(maxVersion uint16) (*x509.Certificate, error) {
+		conn, err := tls.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port), &tls.Config{
+			InsecureSkipVerify: true,
+			MaxVersion:         maxVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0], nil
+	}
+
+	require.Eventually(t, func() bool {
+		cert, err := getServedCert(tls.VersionTLS13)
+		return err == nil && cert.SerialNumber.Int64() == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.NoError(t, ac.CheckCertificate(nil))
+
+	// the minimum tls version is enforced
+	_, err = getServedCert(tls.VersionTLS12)
+	assert.Error(t, err)
+
+	// rotated certificates are served without a restart
+	writeTestCertificate(t, dir, 2, now.Add(-time.Hour), now.Add(time.Hour))
+	assert.Eventually(t, func() bool {
+		cert, err := getServedCert(tls.VersionTLS13)
+		return err == nil && cert.SerialNumber.Int64() == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestAdmissionControllerReadyBeforeLeaderElection(t *testing.T) {
+	now := time.Now()
+	dir := getTestCertDir(t)
+	writeTestCertificate(t, dir, 1, now.Add(-time.Hour), now.Add(time.Hour))
+
+	getFreePort := func() int {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		return listener.Addr().(*net.TCPAddr).Port
+	}
+	probePort := getFreePort()
+
+	// the api server is unreachable, so the leader election lease is never acquired
+	mgr, err := manager.New(&rest.Config{Host: "https://127.0.0.1:1"}, manager.Options{
+		MetricsBindAddress:      "0",
+		HealthProbeBindAddress:  fmt.Sprintf("127.0.0.1:%d", probePort),
+		LeaderElection:          true,
+		LeaderElectionNamespace: "default",
+		LeaderElectionID:        "wave-operator-test",
+		MapperProvider: func(_ *rest.Config) (meta.RESTMapper, error) {
+			return meta.NewDefaultRESTMapper(nil), nil
+		},
+	})
+	require.NoError(t, err)
+
+	webhook := config.AdmissionWebhook{Port: getFreePort(), CertDir: dir}
+	ac := NewAdmissionController(nil, nil, nil, config.StorageSync{}, config.SparkConf{}, webhook, nil, nil, log)
+	require.NoError(t, mgr.Add(ac))
+	require.NoError(t, mgr.AddReadyzCheck("webhook-certificate", ac.CheckCertificate))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = mgr.Start(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/readyz", probePort))
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+
+	select {
+	case <-mgr.Elected():
+		t.Fatal("leader election lease should not be acquired")
+	default:
+	}
+}
@@ -0,0 +1,164 @@
+package admission
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/magiconair/properties"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+const (
+	driverContainerName = "spark-kubernetes-driver"
+	// sparkConfVolumePrefix prefixes the name of the Spark properties config map volume of driver pods,
+	// spark-conf-volume in Spark 2.4 and spark-conf-volume-driver in Spark 3
+	sparkConfVolumePrefix = "spark-conf-volume"
+	// sparkSubmitOptsEnvVar holds the Java options of spark-submit, which runs the driver
+	sparkSubmitOptsEnvVar = "SPARK_SUBMIT_OPTS"
+)
+
+// applySparkConf merges the configured Spark properties into the application's properties.
+// Overrides replace the application's values, the namespace policy's overrides take precedence over the operator's,
+// which take precedence over the driver's annotations. Defaults are set if the application does not set them,
+// the driver's annotations take precedence over the namespace policy, which takes precedence over the operator.
+func applySparkConf(props *properties.Properties, operatorConf config.SparkConf, policy *v1alpha1.WaveSparkPolicy, driverAnnotations map[string]string) {
+	annotationDefaults, annotationOverrides := config.GetSparkConf(driverAnnotations)
+	var policyDefaults, policyOverrides map[string]string
+	if policy != nil {
+		policyDefaults = policy.Spec.SparkConf.Defaults
+		policyOverrides = policy.Spec.SparkConf.Overrides
+	}
+
+	for _, overrides := range []map[string]string{annotationOverrides, operatorConf.Overrides, policyOverrides} {
+		props.Merge(properties.LoadMap(overrides))
+	}
+	for _, defaults := range []map[string]string{annotationDefaults, policyDefaults, operatorConf.Defaults} {
+		mergeSparkConfDefaults(props, defaults)
+	}
+}
+
+// mergeSparkConfDefaults sets the properties the application does not set
+func mergeSparkConfDefaults(props *properties.Properties, defaults map[string]string) {
+	unset := make(map[string]string)
+	for k, v := range defaults {
+		if _, ok := props.Get(k); !ok {
+			unset[k] = v
+		}
+	}
+	props.Merge(properties.LoadMap(unset))
+}
+
+// getChangedSparkConf returns the sorted keys of the properties that were added or changed
+func getChangedSparkConf(original map[string]string, props *properties.Properties) []string {
+	changed := make([]string, 0)
+	for _, k := range props.Keys() {
+		if v, ok := original[k]; !ok || v != props.GetString(k, "") {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// buildSparkConfEnv sets the configured Spark properties of driver pods without a Spark properties config map,
+// e.g. client mode drivers, as Java system properties in the driver container's SPARK_SUBMIT_OPTS environment.
+// Spark reads them as defaults, the application's properties file and --conf arguments take precedence.
+func (m PodMutator) buildSparkConfEnv(pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) {
+	if hasSparkConfVolume(pod) || len(pod.Spec.Containers) == 0 {
+		return
+	}
+
+	container := &pod.Spec.Containers[0]
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == driverContainerName {
+			container = &pod.Spec.Containers[i]
+		}
+	}
+
+	envIndex := -1
+	for i, env := range container.Env {
+		if env.Name == sparkSubmitOptsEnvVar {
+			envIndex = i
+		}
+	}
+	existing := ""
+	if envIndex >= 0 {
+		if container.Env[envIndex].ValueFrom != nil {
+			log.Info(fmt.Sprintf("Container %q sets %s from a source, will not set spark properties", container.Name, sparkSubmitOptsEnvVar))
+			return
+		}
+		existing = container.Env[envIndex].Value
+	}
+
+	props := properties.NewProperties()
+	props.Merge(properties.LoadMap(parseJavaSystemProperties(existing)))
+	original := props.Map()
+	applySparkConf(props, m.sparkConf, policy, pod.Annotations)
+	mergeSparkConfDefaults(props, getZoneAffinityConf(pod))
+
+	changed := getChangedSparkConf(original, props)
+	if len(changed) == 0 {
+		return
+	}
+
+	log.Info(fmt.Sprintf("Setting spark properties in %s of container %q", sparkSubmitOptsEnvVar, container.Name), "keys", changed)
+	opts := make([]string, 0, len(changed)+1)
+	if existing != "" {
+		opts = append(opts, existing)
+	}
+	for _, k := range changed {
+		opts = append(opts, formatJavaSystemProperty(k, props.GetString(k, "")))
+	}
+	value := strings.Join(opts, " ")
+	if envIndex >= 0 {
+		container.Env[envIndex].Value = value
+	} else {
+		container.Env = append(container.Env, corev1.EnvVar{Name: sparkSubmitOptsEnvVar, Value: value})
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[config.WaveConfigAnnotationSparkConfChanged] = strings.Join(changed, ",")
+}
+
+// hasSparkConfVolume returns whether the pod mounts the Spark properties config map created by spark-submit
+func hasSparkConfVolume(pod *corev1.Pod) bool {
+	for _, v := range pod.Spec.Volumes {
+		if v.ConfigMap != nil && strings.HasPrefix(v.Name, sparkConfVolumePrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseJavaSystemProperties returns the Spark properties set as Java system properties in the options
+func parseJavaSystemProperties(opts string) map[string]string {
+	props := make(map[string]string)
+	for _, opt := range strings.Fields(opts) {
+		opt = strings.Trim(opt, `"'`)
+		if !strings.HasPrefix(opt, "-Dspark.") {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(opt, "-D"), "=", 2)
+		if len(kv) == 2 {
+			props[kv[0]] = kv[1]
+		}
+	}
+	return props
+}
+
+// formatJavaSystemProperty returns the Java option setting the system property,
+// quoted the way the Spark launcher parses options if necessary
+func formatJavaSystemProperty(key string, value string) string {
+	opt := "-D" + key + "=" + value
+	if !strings.ContainsAny(opt, " \t\n\"'\\") {
+		return opt
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(opt) + `"`
+}
@@ -0,0 +1,238 @@
+package admission
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/util"
+)
+
+func TestApplySparkConf(t *testing.T) {
+
+	getProps := func() *properties.Properties {
+		return properties.MustLoadString("spark.executor.memory=512m\nspark.executor.cores=1\n")
+	}
+
+	t.Run("whenNotConfigured", func(tt *testing.T) {
+		props := getProps()
+		applySparkConf(props, config.SparkConf{}, nil, nil)
+		assert.Equal(tt, getProps().Map(), props.Map())
+	})
+
+	t.Run("whenOperatorConf", func(tt *testing.T) {
+		props := getProps()
+		applySparkConf(props, config.SparkConf{
+			Defaults: map[string]string{
+				"spark.executor.memory":      "4g",
+				"spark.sql.adaptive.enabled": "true",
+			},
+			Overrides: map[string]string{
+				"spark.executor.cores": "4",
+			},
+		}, nil, nil)
+		assert.Equal(tt, "512m", props.MustGet("spark.executor.memory"))
+		assert.Equal(tt, "true", props.MustGet("spark.sql.adaptive.enabled"))
+		assert.Equal(tt, "4", props.MustGet("spark.executor.cores"))
+	})
+
+	t.Run("whenDefaultsPrecedence", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "default")
+		policy.Spec.SparkConf.Defaults = map[string]string{
+			"spark.sql.shuffle.partitions":      "400",
+			"spark.sql.adaptive.enabled":        "false",
+			"spark.shuffle.service.enabled":     "false",
+			"spark.sql.files.maxPartitionBytes": "64m",
+		}
+		operatorConf := config.SparkConf{Defaults: map[string]string{
+			"spark.sql.shuffle.partitions":                    "200",
+			"spark.sql.adaptive.enabled":                      "true",
+			"spark.shuffle.service.enabled":                   "true",
+			"spark.dynamicAllocation.shuffleTracking.enabled": "true",
+		}}
+		annotations := map[string]string{
+			config.WaveConfigAnnotationSparkConfPrefix + "spark.sql.shuffle.partitions": "800",
+		}
+		props := getProps()
+		applySparkConf(props, operatorConf, policy, annotations)
+		assert.Equal(tt, "800", props.MustGet("spark.sql.shuffle.partitions"))
+		assert.Equal(tt, "false", props.MustGet("spark.sql.adaptive.enabled"))
+		assert.Equal(tt, "false", props.MustGet("spark.shuffle.service.enabled"))
+		assert.Equal(tt, "64m", props.MustGet("spark.sql.files.maxPartitionBytes"))
+		assert.Equal(tt, "true", props.MustGet("spark.dynamicAllocation.shuffleTracking.enabled"))
+	})
+
+	t.Run("whenOverridesPrecedence", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "default")
+		policy.Spec.SparkConf.Overrides = map[string]string{
+			"spark.executor.cores": "3",
+		}
+		operatorConf := config.SparkConf{Overrides: map[string]string{
+			"spark.executor.cores":  "2",
+			"spark.executor.memory": "2g",
+		}}
+		annotations := map[string]string{
+			config.WaveConfigAnnotationSparkConfOverridePrefix + "spark.executor.cores":  "8",
+			config.WaveConfigAnnotationSparkConfOverridePrefix + "spark.executor.memory": "8g",
+			config.WaveConfigAnnotationSparkConfOverridePrefix + "spark.driver.memory":   "1g",
+		}
+		props := getProps()
+		applySparkConf(props, operatorConf, policy, annotations)
+		assert.Equal(tt, "3", props.MustGet("spark.executor.cores"))
+		assert.Equal(tt, "2g", props.MustGet("spark.executor.memory"))
+		assert.Equal(tt, "1g", props.MustGet("spark.driver.memory"))
+	})
+
+	t.Run("whenOverrideAndDefault", func(tt *testing.T) {
+		// defaults do not replace overridden properties
+		operatorConf := config.SparkConf{
+			Defaults:  map[string]string{"spark.speculation": "false"},
+			Overrides: map[string]string{"spark.speculation": "true"},
+		}
+		props := getProps()
+		applySparkConf(props, operatorConf, nil, nil)
+		assert.Equal(tt, "true", props.MustGet("spark.speculation"))
+	})
+}
+
+func TestMutateConfigMap_sparkConf(t *testing.T) {
+
+	mutate := func(t *testing.T, operatorConf config.SparkConf, driverAnnotations map[string]string) *corev1.ConfigMap {
+		cm := sparkConfigMap.DeepCopy()
+		driver := getDriverPod(cm.OwnerReferences[0].Name, cm.Namespace, false, "")
+		for k, v := range driverAnnotations {
+			driver.Annotations[k] = v
+		}
+		r, err := NewConfigMapMutator(log, k8sfake.NewSimpleClientset(driver), &util.FakeStorageProvider{}, newTestPolicyProvider(), operatorConf).Mutate(getAdmissionRequest(t, cm))
+		require.NoError(t, err)
+		obj, err := ApplyJsonPatch(r.Patch, cm)
+		require.NoError(t, err)
+		mutated, ok := obj.(*corev1.ConfigMap)
+		require.True(t, ok)
+		return mutated
+	}
+
+	t.Run("whenConfigured", func(tt *testing.T) {
+		operatorConf := config.SparkConf{
+			Defaults: map[string]string{
+				"spark.hadoop.fs.s3a.committer.name": "magic",
+				"spark.executor.memory":              "4g",
+			},
+			Overrides: map[string]string{
+				"spark.sql.adaptive.enabled": "true",
+			},
+		}
+		annotations := map[string]string{
+			config.WaveConfigAnnotationSparkConfOverridePrefix + "spark.executor.instances": "3",
+		}
+		mutated := mutate(tt, operatorConf, annotations)
+		props, err := properties.LoadString(mutated.Data["spark.properties"])
+		require.NoError(tt, err)
+		assert.Equal(tt, "magic", props.MustGet("spark.hadoop.fs.s3a.committer.name"))
+		assert.Equal(tt, "512m", props.MustGet("spark.executor.memory"))
+		assert.Equal(tt, "true", props.MustGet("spark.sql.adaptive.enabled"))
+		assert.Equal(tt, "3", props.MustGet("spark.executor.instances"))
+
+		assert.Equal(tt,
+			"spark.executor.instances,spark.hadoop.fs.s3a.committer.name,spark.metrics.appStatusSource.enabled,spark.sql.adaptive.enabled",
+			mutated.Annotations[config.WaveConfigAnnotationSparkConfChanged])
+	})
+
+	t.Run("whenNotConfigured", func(tt *testing.T) {
+		mutated := mutate(tt, config.SparkConf{}, nil)
+		assert.Equal(tt, "spark.metrics.appStatusSource.enabled", mutated.Annotations[config.WaveConfigAnnotationSparkConfChanged])
+	})
+
+	t.Run("whenPolicy", func(tt *testing.T) {
+		cm := sparkConfigMap.DeepCopy()
+		driver := getDriverPod(cm.OwnerReferences[0].Name, cm.Namespace, false, "")
+		policy := newTestPolicy("team-a", cm.Namespace)
+		policy.Spec.SparkConf = v1alpha1.SparkConfPolicy{
+			Overrides: map[string]string{"spark.executor.instances": "10"},
+		}
+		operatorConf := config.SparkConf{Overrides: map[string]string{"spark.executor.instances": "5"}}
+		r, err := NewConfigMapMutator(log, k8sfake.NewSimpleClientset(driver), &util.FakeStorageProvider{}, newTestPolicyProvider(policy), operatorConf).Mutate(getAdmissionRequest(tt, cm))
+		require.NoError(tt, err)
+		obj, err := ApplyJsonPatch(r.Patch, cm)
+		require.NoError(tt, err)
+		props, err := properties.LoadString(obj.(*corev1.ConfigMap).Data["spark.properties"])
+		require.NoError(tt, err)
+		assert.Equal(tt, "10", props.MustGet("spark.executor.instances"))
+	})
+}
+
+func TestMutateDriverPod_sparkConfEnv(t *testing.T) {
+
+	operatorConf := config.SparkConf{
+		Defaults:  map[string]string{"spark.sql.adaptive.enabled": "true"},
+		Overrides: map[string]string{"spark.executor.cores": "4"},
+	}
+
+	mutate := func(pod *corev1.Pod, conf config.SparkConf) *corev1.Pod {
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, conf, newTestPolicyProvider(), nil)
+		return m.mutateDriverPod(pod, nil, log)
+	}
+
+	getPod := func() *corev1.Pod {
+		pod := getSimplePod()
+		pod.Labels = map[string]string{SparkRoleLabel: SparkRoleDriverValue}
+		pod.Spec.Containers = []corev1.Container{{Name: driverContainerName}}
+		return pod
+	}
+
+	getEnv := func(pod *corev1.Pod) (string, bool) {
+		for _, env := range pod.Spec.Containers[0].Env {
+			if env.Name == sparkSubmitOptsEnvVar {
+				return env.Value, true
+			}
+		}
+		return "", false
+	}
+
+	t.Run("whenNoConfigMap", func(tt *testing.T) {
+		pod := getPod()
+		pod.Annotations[config.WaveConfigAnnotationSparkConfPrefix+"spark.app.description"] = "nightly etl"
+		mutated := mutate(pod, operatorConf)
+		value, ok := getEnv(mutated)
+		require.True(tt, ok)
+		assert.Equal(tt, `"-Dspark.app.description=nightly etl" -Dspark.executor.cores=4 -Dspark.sql.adaptive.enabled=true`, value)
+		assert.Equal(tt, "spark.app.description,spark.executor.cores,spark.sql.adaptive.enabled",
+			mutated.Annotations[config.WaveConfigAnnotationSparkConfChanged])
+	})
+
+	t.Run("whenOptionsSet", func(tt *testing.T) {
+		pod := getPod()
+		pod.Spec.Containers[0].Env = []corev1.EnvVar{{
+			Name:  sparkSubmitOptsEnvVar,
+			Value: "-Xmx1g -Dspark.sql.adaptive.enabled=false -Dspark.executor.cores=1",
+		}}
+		value, ok := getEnv(mutate(pod, operatorConf))
+		require.True(tt, ok)
+		assert.Equal(tt, "-Xmx1g -Dspark.sql.adaptive.enabled=false -Dspark.executor.cores=1 -Dspark.executor.cores=4", value)
+	})
+
+	t.Run("whenConfigMapVolume", func(tt *testing.T) {
+		pod := getPod()
+		pod.Spec.Volumes = []corev1.Volume{{
+			Name: "spark-conf-volume-driver",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "spark-drv-conf-map"}},
+			},
+		}}
+		mutated := mutate(pod, operatorConf)
+		_, ok := getEnv(mutated)
+		assert.False(tt, ok)
+		assert.Empty(tt, mutated.Annotations[config.WaveConfigAnnotationSparkConfChanged])
+	})
+
+	t.Run("whenNotConfigured", func(tt *testing.T) {
+		_, ok := getEnv(mutate(getPod(), config.SparkConf{}))
+		assert.False(tt, ok)
+	})
+}
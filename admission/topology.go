@@ -0,0 +1,193 @@
+package admission
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+const (
+	zoneTopologyKey     = "topology.kubernetes.io/zone"
+	hostnameTopologyKey = "kubernetes.io/hostname"
+
+	defaultTopologyMaxSkew = 1
+
+	// executorZoneAffinityWeight is the weight of the executors' preferred affinity to the zone of the driver,
+	// the maximum weight as the driver and executors exchange most traffic
+	executorZoneAffinityWeight = 100
+)
+
+func getTopologyKey(topology config.Topology) string {
+	switch topology {
+	case config.TopologyZone:
+		return zoneTopologyKey
+	case config.TopologyHostname:
+		return hostnameTopologyKey
+	default:
+		return ""
+	}
+}
+
+// resolveExecutorTopologySpread returns the topologies the executors are spread across, the pod's annotation
+// takes precedence over the policy's default
+func resolveExecutorTopologySpread(annotations map[string]string, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) []config.Topology {
+	topologies, configured := config.GetExecutorTopologySpread(annotations, log)
+	if configured || policy == nil {
+		return topologies
+	}
+	topologies, unknown := config.ParseTopologies(policy.Spec.Topology.ExecutorSpread)
+	if len(unknown) > 0 {
+		log.Info(fmt.Sprintf("Ignoring unknown spark policy executor spread topologies %v", unknown))
+	}
+	return topologies
+}
+
+// resolveDriverZone returns the zone of the driver, the pod's annotation takes precedence over the policy's default
+func resolveDriverZone(annotations map[string]string, policy *v1alpha1.WaveSparkPolicy) string {
+	zone := config.GetDriverZone(annotations)
+	if zone != "" || policy == nil {
+		return zone
+	}
+	return config.GetDriverZone(map[string]string{config.WaveConfigAnnotationDriverZone: policy.Spec.Topology.DriverZone})
+}
+
+// buildTopologySpreadExecutor spreads the application's executors across the configured topologies
+func (m PodMutator) buildTopologySpreadExecutor(pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) {
+	topologies := resolveExecutorTopologySpread(pod.Annotations, policy, log)
+	if len(topologies) == 0 {
+		return
+	}
+
+	appID := pod.Labels[SparkAppLabel]
+	if appID == "" {
+		log.Info(fmt.Sprintf("Executor pod has no %s label, topology spread constraints will not be added", SparkAppLabel))
+		return
+	}
+
+	maxSkew := int32(defaultTopologyMaxSkew)
+	whenUnsatisfiable := corev1.ScheduleAnyway
+	if policy != nil {
+		if policy.Spec.Topology.MaxSkew > 0 {
+			maxSkew = policy.Spec.Topology.MaxSkew
+		}
+		if policy.Spec.Topology.Enforced {
+			whenUnsatisfiable = corev1.DoNotSchedule
+		}
+	}
+
+	for _, topology := range topologies {
+		key := getTopologyKey(topology)
+		if isTopologySpreadKeySet(pod, key) {
+			log.Info(fmt.Sprintf("Topology spread constraint on %q already set, will not be mutated", key))
+			continue
+		}
+		constraint := corev1.TopologySpreadConstraint{
+			MaxSkew:           maxSkew,
+			TopologyKey:       key,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					SparkAppLabel:  appID,
+					SparkRoleLabel: SparkRoleExecutorValue,
+				},
+			},
+		}
+		log.Info(fmt.Sprintf("Adding topology spread constraint on %q", key))
+		pod.Spec.TopologySpreadConstraints = append(pod.Spec.TopologySpreadConstraints, constraint)
+	}
+}
+
+// buildZoneAffinityDriver places the driver in the configured zone
+func (m PodMutator) buildZoneAffinityDriver(pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) {
+	zone := resolveDriverZone(pod.Annotations, policy)
+	if zone == "" || zone == config.DriverZoneColocate {
+		// executors follow the driver, the driver is scheduled before any executor exists
+		return
+	}
+
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if isNodeAffinityKeySet(pod.Spec.Affinity.NodeAffinity, zoneTopologyKey) {
+		log.Info(fmt.Sprintf("Node affinity key %q already set, will not be mutated", zoneTopologyKey))
+		return
+	}
+	m.addNodeSelectorRequirement(pod.Spec.Affinity.NodeAffinity, corev1.NodeSelectorRequirement{
+		Key:      zoneTopologyKey,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{zone},
+	}, log)
+}
+
+// getZoneAffinityConf returns the Spark properties marking the executor pods of drivers annotated to colocate
+// their executors, nil if not configured by the driver's annotation
+func getZoneAffinityConf(driver *corev1.Pod) map[string]string {
+	if config.GetDriverZone(driver.Annotations) != config.DriverZoneColocate {
+		return nil
+	}
+	return map[string]string{
+		sparkExecutorAnnotationPrefix + config.WaveConfigAnnotationDriverZone: config.DriverZoneColocate,
+	}
+}
+
+// buildZoneAffinityExecutor prefers the zone of the driver for executors of applications colocating their executors.
+// Executors spread across zones get no zone affinity, the spread constraint would push them out of the zone the
+// affinity pulls them into, so zone spreading takes precedence.
+func (m PodMutator) buildZoneAffinityExecutor(pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) {
+	if resolveDriverZone(pod.Annotations, policy) != config.DriverZoneColocate {
+		return
+	}
+
+	for _, topology := range resolveExecutorTopologySpread(pod.Annotations, policy, log) {
+		if topology == config.TopologyZone {
+			log.Info("Executors are spread across zones, zone affinity to the driver will not be added")
+			return
+		}
+	}
+
+	appID := pod.Labels[SparkAppLabel]
+	if appID == "" {
+		log.Info(fmt.Sprintf("Executor pod has no %s label, zone affinity will not be added", SparkAppLabel))
+		return
+	}
+
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.PodAffinity == nil {
+		pod.Spec.Affinity.PodAffinity = &corev1.PodAffinity{}
+	}
+
+	log.Info("Adding preferred pod affinity to the zone of the application's driver")
+	pod.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		pod.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.WeightedPodAffinityTerm{
+			Weight: executorZoneAffinityWeight,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				TopologyKey: zoneTopologyKey,
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						SparkAppLabel:  appID,
+						SparkRoleLabel: SparkRoleDriverValue,
+					},
+				},
+			},
+		})
+}
+
+func isTopologySpreadKeySet(pod *corev1.Pod, key string) bool {
+	for _, c := range pod.Spec.TopologySpreadConstraints {
+		if c.TopologyKey == key {
+			return true
+		}
+	}
+	return false
+}
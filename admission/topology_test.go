@@ -0,0 +1,278 @@
+package admission
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/util"
+)
+
+func getAppSelector(sparkRole string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			SparkAppLabel:  "app-1",
+			SparkRoleLabel: sparkRole,
+		},
+	}
+}
+
+func TestMutateExecutorPod_topologySpread(t *testing.T) {
+
+	mutate := func(t *testing.T, pod *corev1.Pod, policies ...*v1alpha1.WaveSparkPolicy) *corev1.Pod {
+		var policy *v1alpha1.WaveSparkPolicy
+		if len(policies) > 0 {
+			policy = policies[0]
+		}
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil)
+		return m.mutateExecutorPod(pod, policy, log)
+	}
+
+	t.Run("whenNotConfigured", func(tt *testing.T) {
+		mutated := mutate(tt, getExecutorPod("app-1", "exec-1", ""))
+		assert.Empty(tt, mutated.Spec.TopologySpreadConstraints)
+	})
+
+	t.Run("whenAnnotated", func(tt *testing.T) {
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationExecutorTopologySpread] = "zone,hostname"
+		mutated := mutate(tt, pod)
+		expected := []corev1.TopologySpreadConstraint{
+			{
+				MaxSkew:           1,
+				TopologyKey:       zoneTopologyKey,
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+				LabelSelector:     getAppSelector(SparkRoleExecutorValue),
+			},
+			{
+				MaxSkew:           1,
+				TopologyKey:       hostnameTopologyKey,
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+				LabelSelector:     getAppSelector(SparkRoleExecutorValue),
+			},
+		}
+		assert.Equal(tt, expected, mutated.Spec.TopologySpreadConstraints)
+	})
+
+	t.Run("whenPolicy", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "default")
+		policy.Spec.Topology = v1alpha1.TopologyPolicy{
+			ExecutorSpread: []string{"zone", "rack"},
+			MaxSkew:        2,
+			Enforced:       true,
+		}
+		mutated := mutate(tt, getExecutorPod("app-1", "exec-1", ""), policy)
+		expected := []corev1.TopologySpreadConstraint{
+			{
+				MaxSkew:           2,
+				TopologyKey:       zoneTopologyKey,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     getAppSelector(SparkRoleExecutorValue),
+			},
+		}
+		assert.Equal(tt, expected, mutated.Spec.TopologySpreadConstraints)
+
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationExecutorTopologySpread] = "none"
+		mutated = mutate(tt, pod, policy)
+		assert.Empty(tt, mutated.Spec.TopologySpreadConstraints)
+	})
+
+	t.Run("whenAlreadySet", func(tt *testing.T) {
+		existing := corev1.TopologySpreadConstraint{
+			MaxSkew:           3,
+			TopologyKey:       zoneTopologyKey,
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+		}
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationExecutorTopologySpread] = "zone,hostname"
+		pod.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{existing}
+		mutated := mutate(tt, pod)
+		require.Len(tt, mutated.Spec.TopologySpreadConstraints, 2)
+		assert.Equal(tt, existing, mutated.Spec.TopologySpreadConstraints[0])
+		assert.Equal(tt, hostnameTopologyKey, mutated.Spec.TopologySpreadConstraints[1].TopologyKey)
+	})
+
+	t.Run("whenNoAppLabel", func(tt *testing.T) {
+		pod := getExecutorPod("", "exec-1", "")
+		delete(pod.Labels, SparkAppLabel)
+		pod.Annotations[config.WaveConfigAnnotationExecutorTopologySpread] = "zone"
+		mutated := mutate(tt, pod)
+		assert.Empty(tt, mutated.Spec.TopologySpreadConstraints)
+	})
+}
+
+func TestMutateDriverPod_zoneAffinity(t *testing.T) {
+
+	getDriverPod := func() *corev1.Pod {
+		pod := getSimplePod()
+		pod.Labels = map[string]string{
+			SparkAppLabel:  "app-1",
+			SparkRoleLabel: SparkRoleDriverValue,
+		}
+		return pod
+	}
+
+	mutate := func(t *testing.T, pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy) *corev1.Pod {
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil)
+		return m.mutateDriverPod(pod, policy, log)
+	}
+
+	t.Run("whenNotConfigured", func(tt *testing.T) {
+		mutated := mutate(tt, getDriverPod(), nil)
+		assert.Equal(tt, getOnDemandAffinity(), mutated.Spec.Affinity)
+	})
+
+	t.Run("whenPinnedZone", func(tt *testing.T) {
+		pod := getDriverPod()
+		pod.Annotations[config.WaveConfigAnnotationDriverZone] = "us-east-1a"
+		mutated := mutate(tt, pod, nil)
+		require.NotNil(tt, mutated.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+		terms := mutated.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		require.Len(tt, terms, 1)
+		assert.Contains(tt, terms[0].MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      zoneTopologyKey,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{"us-east-1a"},
+		})
+		assert.Nil(tt, mutated.Spec.Affinity.PodAffinity)
+	})
+
+	t.Run("whenZoneAlreadySet", func(tt *testing.T) {
+		pod := getDriverPod()
+		pod.Annotations[config.WaveConfigAnnotationDriverZone] = "us-east-1a"
+		pod.Spec.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      zoneTopologyKey,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{"us-east-1b"},
+						}},
+					}},
+				},
+			},
+		}
+		mutated := mutate(tt, pod, nil)
+		for _, expr := range getRequiredExpressions(mutated.Spec.Affinity.NodeAffinity, zoneTopologyKey) {
+			assert.Equal(tt, []string{"us-east-1b"}, expr.Values)
+		}
+	})
+
+	t.Run("whenColocate", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "default")
+		policy.Spec.Topology.DriverZone = config.DriverZoneColocate
+		mutated := mutate(tt, getDriverPod(), policy)
+		assert.Equal(tt, getOnDemandAffinity(), mutated.Spec.Affinity)
+
+		// the annotation takes precedence over the policy
+		pod := getDriverPod()
+		pod.Annotations[config.WaveConfigAnnotationDriverZone] = "us-east-1a"
+		mutated = mutate(tt, pod, policy)
+		assert.Nil(tt, mutated.Spec.Affinity.PodAffinity)
+		assert.Len(tt, getRequiredExpressions(mutated.Spec.Affinity.NodeAffinity, zoneTopologyKey), 1)
+	})
+}
+
+func TestMutateExecutorPod_zoneAffinity(t *testing.T) {
+
+	mutate := func(t *testing.T, pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy) *corev1.Pod {
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil)
+		return m.mutateExecutorPod(pod, policy, log)
+	}
+
+	getPreferredTerms := func(pod *corev1.Pod) []corev1.WeightedPodAffinityTerm {
+		if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAffinity == nil {
+			return nil
+		}
+		return pod.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	}
+
+	t.Run("whenNotConfigured", func(tt *testing.T) {
+		mutated := mutate(tt, getExecutorPod("app-1", "exec-1", ""), nil)
+		assert.Empty(tt, getPreferredTerms(mutated))
+	})
+
+	t.Run("whenColocate", func(tt *testing.T) {
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationDriverZone] = config.DriverZoneColocate
+		mutated := mutate(tt, pod, nil)
+		expected := []corev1.WeightedPodAffinityTerm{{
+			Weight: executorZoneAffinityWeight,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				TopologyKey:   zoneTopologyKey,
+				LabelSelector: getAppSelector(SparkRoleDriverValue),
+			},
+		}}
+		assert.Equal(tt, expected, getPreferredTerms(mutated))
+
+		// the term selects the application's driver, which exists when executors are scheduled
+		selector, err := metav1.LabelSelectorAsSelector(expected[0].PodAffinityTerm.LabelSelector)
+		require.NoError(tt, err)
+		driver := getDriverPod("driver", "default", false, "")
+		driver.Labels[SparkAppLabel] = "app-1"
+		assert.True(tt, selector.Matches(labels.Set(driver.Labels)))
+		assert.False(tt, selector.Matches(labels.Set(pod.Labels)))
+	})
+
+	t.Run("whenPolicy", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "default")
+		policy.Spec.Topology.DriverZone = config.DriverZoneColocate
+		mutated := mutate(tt, getExecutorPod("app-1", "exec-1", ""), policy)
+		assert.Len(tt, getPreferredTerms(mutated), 1)
+
+		policy.Spec.Topology.DriverZone = "us-east-1a"
+		mutated = mutate(tt, getExecutorPod("app-1", "exec-1", ""), policy)
+		assert.Empty(tt, getPreferredTerms(mutated))
+	})
+
+	t.Run("whenSpreadAcrossZones", func(tt *testing.T) {
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationDriverZone] = config.DriverZoneColocate
+		pod.Annotations[config.WaveConfigAnnotationExecutorTopologySpread] = "zone"
+		mutated := mutate(tt, pod, nil)
+		assert.Empty(tt, getPreferredTerms(mutated))
+		assert.Len(tt, mutated.Spec.TopologySpreadConstraints, 1)
+
+		// spreading across hosts keeps the executors in the driver's zone
+		pod.Annotations[config.WaveConfigAnnotationExecutorTopologySpread] = "hostname"
+		mutated = mutate(tt, pod, nil)
+		assert.Len(tt, getPreferredTerms(mutated), 1)
+	})
+}
+
+func TestZoneAffinityConfiguration(t *testing.T) {
+
+	mutate := func(t *testing.T, driverAnnotations map[string]string) *properties.Properties {
+		cm := sparkConfigMap.DeepCopy()
+		driver := getDriverPod(cm.OwnerReferences[0].Name, cm.Namespace, false, "")
+		for k, v := range driverAnnotations {
+			driver.Annotations[k] = v
+		}
+		r, err := NewConfigMapMutator(log, k8sfake.NewSimpleClientset(driver), &util.FakeStorageProvider{}, newTestPolicyProvider(), config.SparkConf{}).Mutate(getAdmissionRequest(t, cm))
+		require.NoError(t, err)
+		obj, err := ApplyJsonPatch(r.Patch, cm)
+		require.NoError(t, err)
+		props, err := properties.LoadString(obj.(*corev1.ConfigMap).Data["spark.properties"])
+		require.NoError(t, err)
+		return props
+	}
+
+	executorAnnotation := sparkExecutorAnnotationPrefix + config.WaveConfigAnnotationDriverZone
+
+	props := mutate(t, map[string]string{config.WaveConfigAnnotationDriverZone: "colocate"})
+	assert.Equal(t, config.DriverZoneColocate, props.GetString(executorAnnotation, ""))
+
+	props = mutate(t, map[string]string{config.WaveConfigAnnotationDriverZone: "us-east-1a"})
+	_, ok := props.Get(executorAnnotation)
+	assert.False(t, ok)
+}
@@ -0,0 +1,292 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/config/instances"
+)
+
+// validationMessagePrefix identifies the operator in warnings and rejections shown by kubectl and spark-submit
+const validationMessagePrefix = "wave: "
+
+type Validator interface {
+	Validate(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error)
+}
+
+// validatingMutator serves a validator with the webhook handler of mutators, validators never patch
+type validatingMutator struct {
+	validator Validator
+}
+
+func (m validatingMutator) Mutate(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+	return m.validator.Validate(req)
+}
+
+// PodValidator validates the wave configuration of Spark pods. Depending on the validation mode of the pod's
+// namespace, pods with invalid configuration are rejected or admitted with warnings.
+type PodValidator struct {
+	client              kubernetes.Interface
+	instanceTypeManager instances.InstanceTypeManager
+	policyProvider      PolicyProvider
+	baseLogger          logr.Logger
+}
+
+func NewPodValidator(log logr.Logger, client kubernetes.Interface, instanceTypeManager instances.InstanceTypeManager, policyProvider PolicyProvider) PodValidator {
+	return PodValidator{
+		client:              client,
+		instanceTypeManager: instanceTypeManager,
+		policyProvider:      policyProvider,
+		baseLogger:          log,
+	}
+}
+
+func (v PodValidator) Validate(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+
+	ctx := context.TODO()
+
+	gvk := corev1.SchemeGroupVersion.WithKind("Pod")
+	sourceObj := &corev1.Pod{}
+
+	_, _, err := deserializer.Decode(req.Object.Raw, &gvk, sourceObj)
+	if err != nil {
+		return nil, fmt.Errorf("deserialization failed, %w", err)
+	}
+
+	resp := &admissionv1.AdmissionResponse{
+		UID:     req.UID,
+		Allowed: true,
+	}
+
+	if sourceObj.Labels[SparkRoleLabel] == "" {
+		return resp, nil
+	}
+
+	namespace := sourceObj.Namespace
+	if namespace == "" {
+		namespace = req.Namespace
+	}
+	log := v.baseLogger.WithValues("pod", sourceObj.Name, "namespace", namespace)
+
+	policy := getPolicy(ctx, v.policyProvider, namespace, log)
+	issues := v.validatePod(sourceObj, policy, log)
+	if len(issues) == 0 {
+		return resp, nil
+	}
+
+	mode := config.ValidationModeWarn
+	ns, err := v.client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		log.Error(err, "could not get namespace, using default validation mode", "mode", mode)
+	} else {
+		mode = config.GetValidationMode(ns.Annotations, log)
+	}
+
+	switch mode {
+	case config.ValidationModeDisabled:
+		return resp, nil
+	case config.ValidationModeEnforce:
+		log.Info("Rejecting pod with invalid configuration", "issues", issues)
+		resp.Allowed = false
+		resp.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusForbidden,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: validationMessagePrefix + strings.Join(issues, "; "),
+		}
+	default:
+		log.Info("Admitting pod with invalid configuration", "issues", issues)
+		for _, issue := range issues {
+			resp.Warnings = append(resp.Warnings, validationMessagePrefix+issue)
+		}
+	}
+
+	return resp, nil
+}
+
+// validatePod returns the issues of the pod's wave configuration. The validating webhook runs after the mutating
+// webhook, so the pod's node affinity is checked against the lifecycle and instance types the mutating webhook
+// resolved from the annotations and the namespace policy, not against the raw annotations.
+func (v PodValidator) validatePod(pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, log logr.Logger) []string {
+	issues := make([]string, 0)
+
+	sparkRole := pod.Labels[SparkRoleLabel]
+	podPolicy := getPodPolicy(policy, sparkRole)
+
+	if value, ok := pod.Annotations[config.WaveConfigAnnotationInstanceLifecycle]; ok {
+		if config.ParseInstanceLifecycle(value) == "" {
+			issues = append(issues, fmt.Sprintf("unknown %s annotation value %q, must be %q or %q, the annotation is ignored",
+				config.WaveConfigAnnotationInstanceLifecycle, value, config.InstanceLifecycleOnDemand, config.InstanceLifecycleSpot))
+		} else if !isLifecycleConsistent(pod, getEffectiveInstanceLifecycle(pod, podPolicy, log)) {
+			issues = append(issues, fmt.Sprintf("node affinity or node selector on %q conflicts with the %s annotation, the annotation is ignored",
+				nodeLifeCycleKey, config.WaveConfigAnnotationInstanceLifecycle))
+		}
+	}
+
+	if value, ok := pod.Annotations[config.WaveConfigAnnotationInstanceType]; ok {
+		instanceTypes := make(map[string]bool)
+		for _, s := range strings.Split(value, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			if err := v.instanceTypeManager.ValidateInstanceType(s); err == nil {
+				instanceTypes[s] = true
+				continue
+			}
+			inFamily, err := v.instanceTypeManager.GetValidInstanceTypesInFamily(s)
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("instance type %q in the %s annotation is not allowed in the Ocean cluster, it is ignored",
+					s, config.WaveConfigAnnotationInstanceType))
+				continue
+			}
+			for _, it := range inFamily {
+				instanceTypes[it] = true
+			}
+		}
+		// instance types not allowed by the policy were replaced by the mutating webhook
+		if resolved := resolveInstanceTypes(pod.Annotations, podPolicy, v.instanceTypeManager, log); len(resolved) > 0 {
+			instanceTypes = make(map[string]bool, len(resolved))
+			for _, it := range resolved {
+				instanceTypes[it] = true
+			}
+		}
+		if len(instanceTypes) > 0 && !areInstanceTypesConsistent(pod, instanceTypes) {
+			issues = append(issues, fmt.Sprintf("node affinity or node selector on %q conflicts with the %s annotation, the annotation is ignored",
+				nodeInstanceTypeKey, config.WaveConfigAnnotationInstanceType))
+		}
+	}
+
+	if sparkRole == SparkRoleExecutorValue {
+		if _, _, err := config.GetExecutorOnDemandPercentage(pod.Annotations); err != nil {
+			issues = append(issues, fmt.Sprintf("%s, the annotation is ignored", err.Error()))
+		}
+		if value := pod.Annotations[config.WaveConfigAnnotationExecutorTopologySpread]; value != "" && strings.ToLower(strings.TrimSpace(value)) != config.TopologyNone {
+			_, unknown := config.ParseTopologies(strings.Split(value, ","))
+			for _, u := range unknown {
+				issues = append(issues, fmt.Sprintf("unknown topology %q in the %s annotation, must be %q or %q, it is ignored",
+					u, config.WaveConfigAnnotationExecutorTopologySpread, config.TopologyZone, config.TopologyHostname))
+			}
+		}
+	}
+
+	if sparkRole == SparkRoleDriverValue {
+		if value := pod.Annotations[config.WaveConfigAnnotationSyncEventLogs]; value != "" {
+			if _, configured := config.GetEventLogSync(pod.Annotations); !configured {
+				issues = append(issues, fmt.Sprintf("invalid %s annotation value %q, must be true or false, the annotation is ignored",
+					config.WaveConfigAnnotationSyncEventLogs, value))
+			}
+		}
+	}
+
+	return issues
+}
+
+// getEffectiveInstanceLifecycle returns the instance lifecycle the mutating webhook configured for the pod.
+// The lifecycle assigned to executors by their application's on-demand percentage takes precedence over the
+// annotation, lifecycles not allowed by the policy are replaced.
+func getEffectiveInstanceLifecycle(pod *corev1.Pod, podPolicy *v1alpha1.WaveSparkPodPolicy, log logr.Logger) config.InstanceLifecycle {
+	defaultLifecycle := config.InstanceLifecycleOnDemand
+	if pod.Labels[SparkRoleLabel] == SparkRoleExecutorValue {
+		if assigned := config.ParseInstanceLifecycle(pod.Annotations[config.WaveConfigAnnotationAssignedInstanceLifecycle]); assigned != "" {
+			return assigned
+		}
+		defaultLifecycle = config.InstanceLifecycleSpot
+	}
+	return resolveInstanceLifecycle(pod.Annotations, podPolicy, defaultLifecycle, log)
+}
+
+// isLifecycleConsistent returns whether the pod's node selector and node affinity on the lifecycle key,
+// if any, are the ones configured by the lifecycle annotation
+func isLifecycleConsistent(pod *corev1.Pod, lifecycle config.InstanceLifecycle) bool {
+	if value, ok := pod.Spec.NodeSelector[nodeLifeCycleKey]; ok && value != string(lifecycle) {
+		return false
+	}
+
+	na := getNodeAffinity(pod)
+	if na == nil || !isNodeAffinityKeySet(na, nodeLifeCycleKey) {
+		return true
+	}
+
+	switch lifecycle {
+	case config.InstanceLifecycleOnDemand:
+		for _, expr := range getRequiredExpressions(na, nodeLifeCycleKey) {
+			if expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 && expr.Values[0] == nodeLifeCycleValueOnDemand {
+				return true
+			}
+		}
+	case config.InstanceLifecycleSpot:
+		for _, pst := range na.PreferredDuringSchedulingIgnoredDuringExecution {
+			for _, expr := range pst.Preference.MatchExpressions {
+				if expr.Key == nodeLifeCycleKey && expr.Operator == corev1.NodeSelectorOpNotIn &&
+					len(expr.Values) == 1 && expr.Values[0] == nodeLifeCycleValueOnDemand {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// areInstanceTypesConsistent returns whether the pod's node selector and required node affinity on the instance type
+// key, if any, only select instance types of the instance type annotation
+func areInstanceTypesConsistent(pod *corev1.Pod, instanceTypes map[string]bool) bool {
+	if value, ok := pod.Spec.NodeSelector[nodeInstanceTypeKey]; ok && !instanceTypes[value] {
+		return false
+	}
+
+	na := getNodeAffinity(pod)
+	if na == nil || !isNodeAffinityKeySet(na, nodeInstanceTypeKey) {
+		return true
+	}
+
+	for _, expr := range getRequiredExpressions(na, nodeInstanceTypeKey) {
+		if expr.Operator != corev1.NodeSelectorOpIn {
+			continue
+		}
+		consistent := true
+		for _, value := range expr.Values {
+			if !instanceTypes[value] {
+				consistent = false
+				break
+			}
+		}
+		if consistent {
+			return true
+		}
+	}
+	return false
+}
+
+func getNodeAffinity(pod *corev1.Pod) *corev1.NodeAffinity {
+	if pod.Spec.Affinity == nil {
+		return nil
+	}
+	return pod.Spec.Affinity.NodeAffinity
+}
+
+// getRequiredExpressions returns the required node selector requirements on the key
+func getRequiredExpressions(na *corev1.NodeAffinity, key string) []corev1.NodeSelectorRequirement {
+	expressions := make([]corev1.NodeSelectorRequirement, 0)
+	if na.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return expressions
+	}
+	for _, term := range na.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == key {
+				expressions = append(expressions, expr)
+			}
+		}
+	}
+	return expressions
+}
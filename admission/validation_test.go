@@ -0,0 +1,320 @@
+package admission
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/util"
+)
+
+func getValidationNamespace(mode string) *corev1.Namespace {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+	if mode != "" {
+		ns.Annotations = map[string]string{config.WaveConfigAnnotationValidationMode: mode}
+	}
+	return ns
+}
+
+func getValidationPod(sparkRole string, annotations map[string]string) *corev1.Pod {
+	pod := getSimplePod()
+	pod.Labels = map[string]string{
+		SparkRoleLabel: sparkRole,
+	}
+	for k, v := range annotations {
+		pod.Annotations[k] = v
+	}
+	return pod
+}
+
+func TestValidatePod(t *testing.T) {
+
+	validate := func(t *testing.T, pod *corev1.Pod) []string {
+		return NewPodValidator(log, k8sfake.NewSimpleClientset(), &util.FakeInstanceTypeManager{}, newTestPolicyProvider()).validatePod(pod, nil, log)
+	}
+
+	t.Run("whenValid", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleDriverValue, map[string]string{
+			config.WaveConfigAnnotationInstanceLifecycle: "spot",
+			config.WaveConfigAnnotationInstanceType:      "m5.xlarge, h1",
+			config.WaveConfigAnnotationSyncEventLogs:     "true",
+		})
+		assert.Empty(tt, validate(tt, pod))
+	})
+
+	t.Run("whenUnknownLifecycle", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleExecutorValue, map[string]string{
+			config.WaveConfigAnnotationInstanceLifecycle: "reserved",
+		})
+		issues := validate(tt, pod)
+		require.Len(tt, issues, 1)
+		assert.Contains(tt, issues[0], `"reserved"`)
+	})
+
+	t.Run("whenInstanceTypeNotAllowed", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleExecutorValue, map[string]string{
+			config.WaveConfigAnnotationInstanceType: "m5.xlarge,x1.32xlarge,p3",
+		})
+		issues := validate(tt, pod)
+		require.Len(tt, issues, 2)
+		assert.Contains(tt, issues[0], `"x1.32xlarge"`)
+		assert.Contains(tt, issues[1], `"p3"`)
+	})
+
+	t.Run("whenInvalidEventLogSync", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleDriverValue, map[string]string{
+			config.WaveConfigAnnotationSyncEventLogs: "yes please",
+		})
+		issues := validate(tt, pod)
+		require.Len(tt, issues, 1)
+		assert.Contains(tt, issues[0], config.WaveConfigAnnotationSyncEventLogs)
+	})
+
+	t.Run("whenInvalidOnDemandPercentage", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleExecutorValue, map[string]string{
+			config.WaveConfigAnnotationExecutorOnDemandPercentage: "120",
+		})
+		issues := validate(tt, pod)
+		require.Len(tt, issues, 1)
+		assert.Contains(tt, issues[0], config.WaveConfigAnnotationExecutorOnDemandPercentage)
+	})
+
+	t.Run("whenUnknownTopology", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleExecutorValue, map[string]string{
+			config.WaveConfigAnnotationExecutorTopologySpread: "zone,rack",
+		})
+		issues := validate(tt, pod)
+		require.Len(tt, issues, 1)
+		assert.Contains(tt, issues[0], `"rack"`)
+	})
+
+	t.Run("whenNodeSelectorConflicts", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleExecutorValue, map[string]string{
+			config.WaveConfigAnnotationInstanceLifecycle: "spot",
+			config.WaveConfigAnnotationInstanceType:      "m5.xlarge",
+		})
+		pod.Spec.NodeSelector = map[string]string{
+			nodeLifeCycleKey:    nodeLifeCycleValueOnDemand,
+			nodeInstanceTypeKey: "t2.micro",
+		}
+		issues := validate(tt, pod)
+		require.Len(tt, issues, 2)
+		assert.Contains(tt, issues[0], nodeLifeCycleKey)
+		assert.Contains(tt, issues[1], nodeInstanceTypeKey)
+	})
+
+	t.Run("whenNodeSelectorConsistent", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleExecutorValue, map[string]string{
+			config.WaveConfigAnnotationInstanceLifecycle: "spot",
+			config.WaveConfigAnnotationInstanceType:      "h1",
+		})
+		pod.Spec.NodeSelector = map[string]string{
+			nodeLifeCycleKey:    "spot",
+			nodeInstanceTypeKey: "h1.small",
+		}
+		assert.Empty(tt, validate(tt, pod))
+	})
+
+	t.Run("whenNodeAffinityConflicts", func(tt *testing.T) {
+		pod := getValidationPod(SparkRoleDriverValue, map[string]string{
+			config.WaveConfigAnnotationInstanceLifecycle: "spot",
+			config.WaveConfigAnnotationInstanceType:      "m5.xlarge",
+		})
+		pod.Spec.Affinity = getOnDemandAffinity()
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions = append(
+			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions,
+			corev1.NodeSelectorRequirement{Key: nodeInstanceTypeKey, Operator: corev1.NodeSelectorOpIn, Values: []string{"m5.xlarge", "t2.micro"}})
+		issues := validate(tt, pod)
+		require.Len(tt, issues, 2)
+		assert.Contains(tt, issues[0], nodeLifeCycleKey)
+		assert.Contains(tt, issues[1], nodeInstanceTypeKey)
+	})
+
+	t.Run("whenMutatedPod", func(tt *testing.T) {
+		// The validating webhook sees the node affinity added by the mutating webhook
+		pod := getValidationPod(SparkRoleExecutorValue, map[string]string{
+			config.WaveConfigAnnotationInstanceLifecycle: "spot",
+			config.WaveConfigAnnotationInstanceType:      "h1",
+		})
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, newTestPolicyProvider(), nil)
+		r, err := m.Mutate(getAdmissionRequest(tt, pod))
+		require.NoError(tt, err)
+		obj, err := ApplyJsonPatch(r.Patch, pod)
+		require.NoError(tt, err)
+		mutated, ok := obj.(*corev1.Pod)
+		require.True(tt, ok)
+		require.NotNil(tt, mutated.Spec.Affinity)
+		assert.Empty(tt, validate(tt, mutated))
+
+		mutated.Annotations[config.WaveConfigAnnotationInstanceLifecycle] = "od"
+		assert.Len(tt, validate(tt, mutated), 1)
+	})
+}
+
+func TestPodValidator_modes(t *testing.T) {
+
+	invalidPod := getValidationPod(SparkRoleDriverValue, map[string]string{
+		config.WaveConfigAnnotationInstanceLifecycle: "reserved",
+	})
+
+	validate := func(t *testing.T, ns *corev1.Namespace, pod *corev1.Pod) *admissionv1.AdmissionResponse {
+		clientSet := k8sfake.NewSimpleClientset()
+		if ns != nil {
+			clientSet = k8sfake.NewSimpleClientset(ns)
+		}
+		r, err := NewPodValidator(log, clientSet, &util.FakeInstanceTypeManager{}, newTestPolicyProvider()).Validate(getAdmissionRequest(t, pod))
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		assert.Equal(t, pod.UID, r.UID)
+		assert.Nil(t, r.Patch)
+		return r
+	}
+
+	t.Run("whenValid", func(tt *testing.T) {
+		r := validate(tt, getValidationNamespace("enforce"), getValidationPod(SparkRoleDriverValue, nil))
+		assert.True(tt, r.Allowed)
+		assert.Empty(tt, r.Warnings)
+	})
+
+	t.Run("whenNonSparkPod", func(tt *testing.T) {
+		pod := getSimplePod()
+		pod.Annotations[config.WaveConfigAnnotationInstanceLifecycle] = "reserved"
+		r := validate(tt, getValidationNamespace("enforce"), pod)
+		assert.True(tt, r.Allowed)
+		assert.Empty(tt, r.Warnings)
+	})
+
+	t.Run("whenWarnMode", func(tt *testing.T) {
+		r := validate(tt, getValidationNamespace(""), invalidPod)
+		assert.True(tt, r.Allowed)
+		require.Len(tt, r.Warnings, 1)
+		assert.Contains(tt, r.Warnings[0], "wave: unknown wave.spot.io/instance-lifecycle annotation value")
+	})
+
+	t.Run("whenNamespaceNotFound", func(tt *testing.T) {
+		r := validate(tt, nil, invalidPod)
+		assert.True(tt, r.Allowed)
+		assert.Len(tt, r.Warnings, 1)
+	})
+
+	t.Run("whenEnforceMode", func(tt *testing.T) {
+		r := validate(tt, getValidationNamespace("enforce"), invalidPod)
+		assert.False(tt, r.Allowed)
+		assert.Empty(tt, r.Warnings)
+		require.NotNil(tt, r.Result)
+		assert.Equal(tt, int32(http.StatusForbidden), r.Result.Code)
+		assert.Contains(tt, r.Result.Message, "wave: unknown wave.spot.io/instance-lifecycle annotation value")
+	})
+
+	t.Run("whenDisabledMode", func(tt *testing.T) {
+		r := validate(tt, getValidationNamespace("disabled"), invalidPod)
+		assert.True(tt, r.Allowed)
+		assert.Empty(tt, r.Warnings)
+	})
+}
+
+func TestPodValidator_mutatedPods(t *testing.T) {
+
+	// mutateAndValidate validates the pod as admitted by the mutating webhook, in a namespace enforcing validation
+	mutateAndValidate := func(t *testing.T, pod *corev1.Pod, policy *v1alpha1.WaveSparkPolicy, placement ExecutorPlacement) *admissionv1.AdmissionResponse {
+		policyProvider := newTestPolicyProvider()
+		if policy != nil {
+			policyProvider = newTestPolicyProvider(policy)
+		}
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{}, policyProvider, placement)
+		r, err := m.Mutate(getAdmissionRequest(t, pod))
+		require.NoError(t, err)
+		obj, err := ApplyJsonPatch(r.Patch, pod)
+		require.NoError(t, err)
+		mutated, ok := obj.(*corev1.Pod)
+		require.True(t, ok)
+
+		clientSet := k8sfake.NewSimpleClientset(getValidationNamespace("enforce"))
+		r, err = NewPodValidator(log, clientSet, &util.FakeInstanceTypeManager{}, policyProvider).Validate(getAdmissionRequest(t, mutated))
+		require.NoError(t, err)
+		return r
+	}
+
+	t.Run("whenNotConstrained", func(tt *testing.T) {
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationInstanceLifecycle] = "od"
+		pod.Annotations[config.WaveConfigAnnotationInstanceType] = "m5.xlarge"
+		r := mutateAndValidate(tt, pod, nil, nil)
+		assert.True(tt, r.Allowed)
+		assert.Empty(tt, r.Warnings)
+	})
+
+	t.Run("whenLifecycleConstrainedByPolicy", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "default")
+		policy.Spec.Executor.AllowedInstanceLifecycles = []string{"od"}
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationInstanceLifecycle] = "spot"
+		r := mutateAndValidate(tt, pod, policy, nil)
+		assert.True(tt, r.Allowed)
+		assert.Empty(tt, r.Warnings)
+	})
+
+	t.Run("whenInstanceTypesConstrainedByPolicy", func(tt *testing.T) {
+		policy := newTestPolicy("policy", "default")
+		policy.Spec.Executor.AllowedInstanceTypes = []string{"m5.2xlarge"}
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationInstanceType] = "m5.xlarge"
+		r := mutateAndValidate(tt, pod, policy, nil)
+		assert.True(tt, r.Allowed)
+		assert.Empty(tt, r.Warnings)
+	})
+
+	t.Run("whenAffinityConflicts", func(tt *testing.T) {
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationInstanceLifecycle] = "spot"
+		pod.Spec.NodeSelector = map[string]string{nodeLifeCycleKey: nodeLifeCycleValueOnDemand}
+		r := mutateAndValidate(tt, pod, nil, nil)
+		assert.False(tt, r.Allowed)
+	})
+}
+
+func TestPodValidator_assignedLifecycle(t *testing.T) {
+
+	t.Run("whenPlacedOnDemand", func(tt *testing.T) {
+		pod := getExecutorPod("app-1", "exec-1", "")
+		pod.Annotations[config.WaveConfigAnnotationInstanceLifecycle] = "spot"
+		pod.Annotations[config.WaveConfigAnnotationExecutorOnDemandPercentage] = "100"
+
+		m := NewPodMutator(log, &util.FakeStorageProvider{}, &util.FakeInstanceTypeManager{}, config.StorageSync{}, config.SparkConf{},
+			newTestPolicyProvider(), newTestExecutorPlacement())
+		r, err := m.Mutate(getAdmissionRequest(tt, pod))
+		require.NoError(tt, err)
+		obj, err := ApplyJsonPatch(r.Patch, pod)
+		require.NoError(tt, err)
+		mutated := obj.(*corev1.Pod)
+		require.Equal(tt, "od", mutated.Annotations[config.WaveConfigAnnotationAssignedInstanceLifecycle])
+
+		clientSet := k8sfake.NewSimpleClientset(getValidationNamespace("enforce"))
+		r, err = NewPodValidator(log, clientSet, &util.FakeInstanceTypeManager{}, newTestPolicyProvider()).Validate(getAdmissionRequest(tt, mutated))
+		require.NoError(tt, err)
+		assert.True(tt, r.Allowed)
+		assert.Empty(tt, r.Warnings)
+	})
+
+	t.Run("whenAssignedLifecycleConflicts", func(tt *testing.T) {
+		pod := getExecutorPod("app-1", "exec-1", config.InstanceLifecycleOnDemand)
+		pod.Annotations[config.WaveConfigAnnotationInstanceLifecycle] = "spot"
+		pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: getOnDemandAntiAffinity().NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		}}
+		issues := NewPodValidator(log, k8sfake.NewSimpleClientset(), &util.FakeInstanceTypeManager{}, newTestPolicyProvider()).validatePod(pod, nil, log)
+		assert.Len(tt, issues, 1)
+	})
+}
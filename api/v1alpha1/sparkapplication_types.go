@@ -46,6 +46,27 @@ type SparkApplicationSpec struct {
 type SparkApplicationStatus struct {
 	//summarizes information about the spark application
 	Data SparkApplicationData `json:"data"`
+
+	//the latest observations of the application's state, such as DurationExceeded
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	//the executors decommissioned by the operator because their node was about to be reclaimed
+	// +optional
+	ExecutorDecommissions []ExecutorDecommission `json:"executorDecommissions,omitempty"`
+}
+
+type ExecutorDecommission struct {
+	//the name of the executor pod
+	PodName string `json:"podName"`
+	//the name of the node the executor was running on
+	NodeName string `json:"nodeName"`
+	//the signal that the node was about to be reclaimed, a taint, an annotation or a cordon
+	Reason string `json:"reason"`
+	//the time the executor was decommissioned
+	Time metav1.Time `json:"time"`
 }
 
 //SparkApplicationData
@@ -62,6 +83,81 @@ type SparkApplicationData struct {
 
 	//a list of references to the executor pods
 	Executors []Pod `json:"executors"`
+
+	//findings about the application's performance, such as data skew, spill and garbage collection pressure
+	Insights []Insight `json:"insights,omitempty"`
+
+	//the runtime versions, libraries and resource profiles of the application
+	Environment *RuntimeEnvironment `json:"environment,omitempty"`
+
+	//the datasets read and written by the application, derived from its SQL execution plans
+	Lineage *Lineage `json:"lineage,omitempty"`
+}
+
+type Lineage struct {
+	//the datasets read by the application
+	Inputs []Dataset `json:"inputs,omitempty"`
+	//the datasets written by the application
+	Outputs []Dataset `json:"outputs,omitempty"`
+}
+
+type Dataset struct {
+	//the dataset namespace following the OpenLineage naming conventions, e.g. s3://bucket
+	Namespace string `json:"namespace"`
+	//the dataset name within the namespace, e.g. the object key prefix
+	Name string `json:"name"`
+}
+
+type RuntimeEnvironment struct {
+	//the Java version and vendor of the driver
+	JavaVersion string `json:"javaVersion,omitempty"`
+	//the Scala version of the driver
+	ScalaVersion string `json:"scalaVersion,omitempty"`
+	//the Spark version of the application
+	SparkVersion string `json:"sparkVersion,omitempty"`
+	//the file names of the jars on the driver classpath, including the jars added by the application
+	Jars []string `json:"jars,omitempty"`
+	//the Maven coordinates of the packages requested by the application with spark.jars.packages
+	Packages []string `json:"packages,omitempty"`
+	//the resource profiles of the application, available from Spark 3.1
+	ResourceProfiles []ResourceProfile `json:"resourceProfiles,omitempty"`
+}
+
+type ResourceProfile struct {
+	//the resource profile ID
+	ID int64 `json:"id"`
+	//the resources requested for each executor
+	ExecutorResources []ResourceRequest `json:"executorResources,omitempty"`
+	//the resources requested for each task
+	TaskResources []ResourceRequest `json:"taskResources,omitempty"`
+}
+
+type ResourceRequest struct {
+	//the resource name, e.g. cores, memory or gpu
+	Name string `json:"name"`
+	//the requested amount, executor memory amounts are in MiB
+	Amount string `json:"amount"`
+}
+
+type InsightType string
+
+const (
+	InsightTypeDataSkew InsightType = "DataSkew"
+	InsightTypeSpill    InsightType = "Spill"
+	InsightTypeHeavyGC  InsightType = "HeavyGC"
+)
+
+type Insight struct {
+	//the kind of finding, one of DataSkew, Spill, HeavyGC
+	Type InsightType `json:"type"`
+	//the ID of the stage the finding is about
+	StageID int64 `json:"stageId"`
+	//the attempt ID of the stage the finding is about
+	StageAttemptID int64 `json:"stageAttemptId"`
+	//the name of the stage
+	StageName string `json:"stageName,omitempty"`
+	//a short human readable explanation of the finding
+	Message string `json:"message"`
 }
 
 type Statistics struct {
@@ -80,6 +176,72 @@ type Statistics struct {
 
 	//details of the application's executors
 	Executors []Executor `json:"executors"`
+
+	//summary of the application's jobs
+	Jobs *JobStatistics `json:"jobs,omitempty"`
+
+	//summary of the application's SQL executions
+	SQLExecutions *SQLStatistics `json:"sqlExecutions,omitempty"`
+}
+
+type JobStatistics struct {
+	//number of running jobs
+	Running int64 `json:"running"`
+	//number of succeeded jobs
+	Succeeded int64 `json:"succeeded"`
+	//number of failed jobs
+	Failed int64 `json:"failed"`
+	//number of jobs in unknown state
+	Unknown int64 `json:"unknown"`
+	//the finished job with the longest duration
+	Longest *Job `json:"longest,omitempty"`
+	//the most recent failed jobs
+	FailedJobs []Job `json:"failedJobs,omitempty"`
+}
+
+type Job struct {
+	//the job ID
+	JobID int64 `json:"jobId"`
+	//the job name, the call site of the action that submitted the job
+	Name string `json:"name"`
+	//the job description, set by the application
+	Description string `json:"description,omitempty"`
+	//the job group
+	JobGroup string `json:"jobGroup,omitempty"`
+	//the job status, one of RUNNING, SUCCEEDED, FAILED, UNKNOWN
+	Status string `json:"status"`
+	//the timestamp of job submission
+	SubmissionTime string `json:"submissionTime,omitempty"`
+	//elapsed time from job submission to completion (milliseconds), 0 for running jobs
+	Duration int64 `json:"duration"`
+}
+
+type SQLStatistics struct {
+	//number of running SQL executions
+	Running int64 `json:"running"`
+	//number of completed SQL executions
+	Completed int64 `json:"completed"`
+	//number of failed SQL executions
+	Failed int64 `json:"failed"`
+	//the SQL executions with the longest duration, longest first
+	Executions []SQLExecution `json:"executions,omitempty"`
+}
+
+type SQLExecution struct {
+	//the SQL execution ID
+	ID int64 `json:"id"`
+	//the SQL execution description, usually the query text
+	Description string `json:"description"`
+	//the SQL execution status, one of RUNNING, COMPLETED, FAILED
+	Status string `json:"status"`
+	//the timestamp of SQL execution submission
+	SubmissionTime string `json:"submissionTime,omitempty"`
+	//elapsed time of the SQL execution (milliseconds)
+	Duration int64 `json:"duration"`
+	//SHA-256 hash of the physical plan description, identifies executions of the same query plan
+	PlanDescriptionHash string `json:"planDescriptionHash,omitempty"`
+	//the IDs of the jobs run by the SQL execution
+	JobIDs []int64 `json:"jobIds,omitempty"`
 }
 
 type Attempt struct {
@@ -139,12 +301,25 @@ type Executor struct {
 	TotalShuffleRead int64 `json:"totalShuffleRead"`
 	//total shuffle write bytes summed in this executor
 	TotalShuffleWrite int64 `json:"totalShuffleWrite"`
-	//is the executor blacklisted (ignored during task scheduling)
+	//is the executor blacklisted (ignored during task scheduling), deprecated in favor of isExcluded
 	IsBlacklisted bool `json:"isBlacklisted"`
+	//is the executor excluded (ignored during task scheduling), same as isBlacklisted on all Spark versions
+	IsExcluded bool `json:"isExcluded"`
 	//total amount of memory available for storage (bytes)
 	MaxMemory int64 `json:"maxMemory"`
 	//current value of memory metrics
 	MemoryMetrics ExecutorMemoryMetrics `json:"memoryMetrics"`
+	//the ID of the executor's resource profile, available from Spark 3.1
+	ResourceProfileID int64 `json:"resourceProfileId"`
+	//the resources, such as GPUs, allocated to the executor, available from Spark 3.0
+	Resources []ExecutorResource `json:"resources,omitempty"`
+}
+
+type ExecutorResource struct {
+	//the resource name
+	Name string `json:"name"`
+	//the addresses of the allocated resource
+	Addresses []string `json:"addresses,omitempty"`
 }
 
 type ExecutorMemoryMetrics struct {
@@ -177,6 +352,12 @@ type Pod struct {
 	Labels map[string]string `json:"labels"`
 	//the pod's state history
 	StateHistory []PodStateHistoryEntry `json:"stateHistory"`
+	//the name of the node the pod is scheduled on
+	NodeName string `json:"nodeName,omitempty"`
+	//the lifecycle of the node the pod is scheduled on, one of od, spot or unknown
+	InstanceLifecycle string `json:"instanceLifecycle,omitempty"`
+	//the instance type of the node the pod is scheduled on
+	InstanceType string `json:"instanceType,omitempty"`
 }
 
 type PodStateHistoryEntry struct {
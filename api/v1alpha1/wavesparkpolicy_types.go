@@ -0,0 +1,110 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WaveSparkPolicySpec defines defaults and constraints for the Spark applications in the policy's namespace.
+//
+// Settings are resolved in order of precedence:
+//  1. constraints of the policy (allowed values, enforced event log sync, Spark property overrides)
+//  2. wave.spot.io annotations of the driver or executor pod
+//  3. defaults of the policy
+//  4. defaults of the operator
+type WaveSparkPolicySpec struct {
+
+	// driver pod defaults and constraints
+	Driver WaveSparkPodPolicy `json:"driver,omitempty"`
+
+	// executor pod defaults and constraints
+	Executor WaveSparkPodPolicy `json:"executor,omitempty"`
+
+	// event log sync of driver pods
+	EventLogSync EventLogSyncPolicy `json:"eventLogSync,omitempty"`
+
+	// Spark properties of applications
+	SparkConf SparkConfPolicy `json:"sparkConf,omitempty"`
+
+	// availability zone and node topology of applications
+	Topology TopologyPolicy `json:"topology,omitempty"`
+}
+
+type WaveSparkPodPolicy struct {
+
+	// default instance lifecycle of pods without the wave.spot.io/instance-lifecycle annotation, od or spot
+	// +kubebuilder:validation:Enum=od;spot
+	InstanceLifecycle string `json:"instanceLifecycle,omitempty"`
+
+	// instance lifecycles pods may run on, all if empty
+	AllowedInstanceLifecycles []string `json:"allowedInstanceLifecycles,omitempty"`
+
+	// default instance types or families of pods without the wave.spot.io/instance-type annotation
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+
+	// instance types or families pods may run on, all if empty
+	AllowedInstanceTypes []string `json:"allowedInstanceTypes,omitempty"`
+
+	// tolerations added to pods
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+type EventLogSyncPolicy struct {
+
+	// default of driver pods without the wave.spot.io/sync-event-logs annotation
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// whether enabled applies to all driver pods, ignoring their annotation
+	Enforced bool `json:"enforced,omitempty"`
+}
+
+type SparkConfPolicy struct {
+
+	// properties set for applications that do not set them
+	Defaults map[string]string `json:"defaults,omitempty"`
+
+	// properties set for all applications, replacing the application's values
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+type TopologyPolicy struct {
+
+	// default topologies, zone or hostname, the executors of an application are spread across,
+	// for executor pods without the wave.spot.io/executor-topology-spread annotation
+	ExecutorSpread []string `json:"executorSpread,omitempty"`
+
+	// maximum difference in the number of executors between topology domains, 1 if not set
+	// +kubebuilder:validation:Minimum=1
+	MaxSkew int32 `json:"maxSkew,omitempty"`
+
+	// whether executors are left pending when they cannot be spread, they are scheduled anyway if false
+	Enforced bool `json:"enforced,omitempty"`
+
+	// default zone of driver pods without the wave.spot.io/driver-zone annotation, a zone name, or colocate
+	// for executors to prefer the zone of their driver
+	DriverZone string `json:"driverZone,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=wsp
+
+// WaveSparkPolicy is the Schema for the wave spark policy API
+type WaveSparkPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WaveSparkPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WaveSparkPolicyList contains a list of WaveSparkPolicy
+type WaveSparkPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WaveSparkPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WaveSparkPolicy{}, &WaveSparkPolicyList{})
+}
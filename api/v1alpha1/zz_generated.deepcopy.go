@@ -22,6 +22,7 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -40,10 +41,52 @@ func (in *Attempt) DeepCopy() *Attempt {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dataset) DeepCopyInto(out *Dataset) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dataset.
+func (in *Dataset) DeepCopy() *Dataset {
+	if in == nil {
+		return nil
+	}
+	out := new(Dataset)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventLogSyncPolicy) DeepCopyInto(out *EventLogSyncPolicy) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventLogSyncPolicy.
+func (in *EventLogSyncPolicy) DeepCopy() *EventLogSyncPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(EventLogSyncPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Executor) DeepCopyInto(out *Executor) {
 	*out = *in
 	out.MemoryMetrics = in.MemoryMetrics
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ExecutorResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Executor.
@@ -56,6 +99,22 @@ func (in *Executor) DeepCopy() *Executor {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorDecommission) DeepCopyInto(out *ExecutorDecommission) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorDecommission.
+func (in *ExecutorDecommission) DeepCopy() *ExecutorDecommission {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorDecommission)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecutorMemoryMetrics) DeepCopyInto(out *ExecutorMemoryMetrics) {
 	*out = *in
@@ -71,6 +130,106 @@ func (in *ExecutorMemoryMetrics) DeepCopy() *ExecutorMemoryMetrics {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutorResource) DeepCopyInto(out *ExecutorResource) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorResource.
+func (in *ExecutorResource) DeepCopy() *ExecutorResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutorResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Insight) DeepCopyInto(out *Insight) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Insight.
+func (in *Insight) DeepCopy() *Insight {
+	if in == nil {
+		return nil
+	}
+	out := new(Insight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Job) DeepCopyInto(out *Job) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Job.
+func (in *Job) DeepCopy() *Job {
+	if in == nil {
+		return nil
+	}
+	out := new(Job)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobStatistics) DeepCopyInto(out *JobStatistics) {
+	*out = *in
+	if in.Longest != nil {
+		in, out := &in.Longest, &out.Longest
+		*out = new(Job)
+		**out = **in
+	}
+	if in.FailedJobs != nil {
+		in, out := &in.FailedJobs, &out.FailedJobs
+		*out = make([]Job, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobStatistics.
+func (in *JobStatistics) DeepCopy() *JobStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(JobStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Lineage) DeepCopyInto(out *Lineage) {
+	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]Dataset, len(*in))
+		copy(*out, *in)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]Dataset, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Lineage.
+func (in *Lineage) DeepCopy() *Lineage {
+	if in == nil {
+		return nil
+	}
+	out := new(Lineage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Pod) DeepCopyInto(out *Pod) {
 	*out = *in
@@ -155,6 +314,120 @@ func (in *PodStateHistoryEntry) DeepCopy() *PodStateHistoryEntry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceProfile) DeepCopyInto(out *ResourceProfile) {
+	*out = *in
+	if in.ExecutorResources != nil {
+		in, out := &in.ExecutorResources, &out.ExecutorResources
+		*out = make([]ResourceRequest, len(*in))
+		copy(*out, *in)
+	}
+	if in.TaskResources != nil {
+		in, out := &in.TaskResources, &out.TaskResources
+		*out = make([]ResourceRequest, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceProfile.
+func (in *ResourceProfile) DeepCopy() *ResourceProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRequest) DeepCopyInto(out *ResourceRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRequest.
+func (in *ResourceRequest) DeepCopy() *ResourceRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuntimeEnvironment) DeepCopyInto(out *RuntimeEnvironment) {
+	*out = *in
+	if in.Jars != nil {
+		in, out := &in.Jars, &out.Jars
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceProfiles != nil {
+		in, out := &in.ResourceProfiles, &out.ResourceProfiles
+		*out = make([]ResourceProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuntimeEnvironment.
+func (in *RuntimeEnvironment) DeepCopy() *RuntimeEnvironment {
+	if in == nil {
+		return nil
+	}
+	out := new(RuntimeEnvironment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLExecution) DeepCopyInto(out *SQLExecution) {
+	*out = *in
+	if in.JobIDs != nil {
+		in, out := &in.JobIDs, &out.JobIDs
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLExecution.
+func (in *SQLExecution) DeepCopy() *SQLExecution {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLExecution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLStatistics) DeepCopyInto(out *SQLStatistics) {
+	*out = *in
+	if in.Executions != nil {
+		in, out := &in.Executions, &out.Executions
+		*out = make([]SQLExecution, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLStatistics.
+func (in *SQLStatistics) DeepCopy() *SQLStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SparkApplication) DeepCopyInto(out *SparkApplication) {
 	*out = *in
@@ -201,6 +474,21 @@ func (in *SparkApplicationData) DeepCopyInto(out *SparkApplicationData) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Insights != nil {
+		in, out := &in.Insights, &out.Insights
+		*out = make([]Insight, len(*in))
+		copy(*out, *in)
+	}
+	if in.Environment != nil {
+		in, out := &in.Environment, &out.Environment
+		*out = new(RuntimeEnvironment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Lineage != nil {
+		in, out := &in.Lineage, &out.Lineage
+		*out = new(Lineage)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationData.
@@ -264,6 +552,20 @@ func (in *SparkApplicationSpec) DeepCopy() *SparkApplicationSpec {
 func (in *SparkApplicationStatus) DeepCopyInto(out *SparkApplicationStatus) {
 	*out = *in
 	in.Data.DeepCopyInto(&out.Data)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExecutorDecommissions != nil {
+		in, out := &in.ExecutorDecommissions, &out.ExecutorDecommissions
+		*out = make([]ExecutorDecommission, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkApplicationStatus.
@@ -276,6 +578,35 @@ func (in *SparkApplicationStatus) DeepCopy() *SparkApplicationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SparkConfPolicy) DeepCopyInto(out *SparkConfPolicy) {
+	*out = *in
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SparkConfPolicy.
+func (in *SparkConfPolicy) DeepCopy() *SparkConfPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SparkConfPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Statistics) DeepCopyInto(out *Statistics) {
 	*out = *in
@@ -287,7 +618,19 @@ func (in *Statistics) DeepCopyInto(out *Statistics) {
 	if in.Executors != nil {
 		in, out := &in.Executors, &out.Executors
 		*out = make([]Executor, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = new(JobStatistics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SQLExecutions != nil {
+		in, out := &in.SQLExecutions, &out.SQLExecutions
+		*out = new(SQLStatistics)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -301,6 +644,26 @@ func (in *Statistics) DeepCopy() *Statistics {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyPolicy) DeepCopyInto(out *TopologyPolicy) {
+	*out = *in
+	if in.ExecutorSpread != nil {
+		in, out := &in.ExecutorSpread, &out.ExecutorSpread
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyPolicy.
+func (in *TopologyPolicy) DeepCopy() *TopologyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WaveComponent) DeepCopyInto(out *WaveComponent) {
 	*out = *in
@@ -509,3 +872,118 @@ func (in *WaveEnvironmentStatus) DeepCopy() *WaveEnvironmentStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveSparkPodPolicy) DeepCopyInto(out *WaveSparkPodPolicy) {
+	*out = *in
+	if in.AllowedInstanceLifecycles != nil {
+		in, out := &in.AllowedInstanceLifecycles, &out.AllowedInstanceLifecycles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InstanceTypes != nil {
+		in, out := &in.InstanceTypes, &out.InstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedInstanceTypes != nil {
+		in, out := &in.AllowedInstanceTypes, &out.AllowedInstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveSparkPodPolicy.
+func (in *WaveSparkPodPolicy) DeepCopy() *WaveSparkPodPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveSparkPodPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveSparkPolicy) DeepCopyInto(out *WaveSparkPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveSparkPolicy.
+func (in *WaveSparkPolicy) DeepCopy() *WaveSparkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveSparkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WaveSparkPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveSparkPolicyList) DeepCopyInto(out *WaveSparkPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WaveSparkPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveSparkPolicyList.
+func (in *WaveSparkPolicyList) DeepCopy() *WaveSparkPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveSparkPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WaveSparkPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveSparkPolicySpec) DeepCopyInto(out *WaveSparkPolicySpec) {
+	*out = *in
+	in.Driver.DeepCopyInto(&out.Driver)
+	in.Executor.DeepCopyInto(&out.Executor)
+	in.EventLogSync.DeepCopyInto(&out.EventLogSync)
+	in.SparkConf.DeepCopyInto(&out.SparkConf)
+	in.Topology.DeepCopyInto(&out.Topology)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveSparkPolicySpec.
+func (in *WaveSparkPolicySpec) DeepCopy() *WaveSparkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveSparkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -1,6 +1,7 @@
 package cloudstorage
 
 import (
+	"io"
 	"time"
 )
 
@@ -9,6 +10,14 @@ type CloudStorageProvider interface {
 	GetStorageInfo() (*StorageInfo, error)
 }
 
+// ObjectStore provides read access to the objects in cloud storage
+type ObjectStore interface {
+	// ListObjects returns the keys of all objects in the bucket that start with the given prefix
+	ListObjects(bucket string, prefix string) ([]string, error)
+	// GetObject returns the contents of the object with the given key
+	GetObject(bucket string, key string) (io.ReadCloser, error)
+}
+
 type StorageType string
 
 const (
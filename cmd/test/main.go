@@ -11,6 +11,7 @@ import (
 	"github.com/spotinst/wave-operator/api/v1alpha1"
 	"github.com/spotinst/wave-operator/catalog"
 	"github.com/spotinst/wave-operator/install"
+	waveconfig "github.com/spotinst/wave-operator/internal/config"
 	"github.com/spotinst/wave-operator/internal/util"
 	sparkoperator "github.com/spotinst/wave-operator/sparkoperator.k8s.io/v1beta2"
 	"helm.sh/helm/v3/pkg/action"
@@ -209,10 +210,19 @@ serviceAccount:
 		if err != nil {
 			panic(err)
 		}
+		c, err := client.New(config, client.Options{Scheme: scheme})
+		if err != nil {
+			panic(err)
+		}
 		ac := admission.NewAdmissionController(
 			k,
 			&util.FakeStorageProvider{},
 			&util.FakeInstanceTypeManager{},
+			waveconfig.StorageSync{},
+			waveconfig.SparkConf{},
+			waveconfig.AdmissionWebhook{},
+			admission.NewPolicyProvider(c, logger),
+			admission.NewExecutorPlacement(c, logger),
 			logger,
 		)
 		ctx := ctrl.SetupSignalHandler()
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var sparkApplicationPatchConflicts = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "wave_sparkapplication_patch_conflicts_total",
+		Help: "Total number of SparkApplication patches rejected because the resource was modified concurrently",
+	},
+)
+
+var sparkApplicationDurationExceeded = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_sparkapplication_duration_exceeded_total",
+		Help: "Total number of running Spark applications that exceeded their expected or max duration",
+	},
+	[]string{"namespace", "limit"},
+)
+
+var executorDecommissions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_executor_decommissions_total",
+		Help: "Total number of Spark executors decommissioned because their node was about to be reclaimed",
+	},
+	[]string{"namespace"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(sparkApplicationPatchConflicts)
+	metrics.Registry.MustRegister(sparkApplicationDurationExceeded)
+	metrics.Registry.MustRegister(executorDecommissions)
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+const (
+	reasonExecutorDecommissioned = "ExecutorDecommissioned"
+
+	interruptionReasonCordoned = "Cordoned"
+)
+
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// NodeInterruptionReconciler decommissions the Spark executors on nodes about to be reclaimed.
+// Executor pods are deleted with a grace period, so executors of applications with decommissioning enabled
+// migrate their blocks in their pre-stop hook before the instance is terminated.
+type NodeInterruptionReconciler struct {
+	client.Client
+	conf         config.NodeInterruption
+	recorder     record.EventRecorder
+	timeProvider func() time.Time
+	Log          logr.Logger
+}
+
+func NewNodeInterruptionReconciler(
+	client client.Client,
+	conf config.NodeInterruption,
+	recorder record.EventRecorder,
+	timeProvider func() time.Time,
+	log logr.Logger) *NodeInterruptionReconciler {
+
+	return &NodeInterruptionReconciler{
+		Client:       client,
+		conf:         conf.WithDefaults(),
+		recorder:     recorder,
+		timeProvider: timeProvider,
+		Log:          log,
+	}
+}
+
+func (r *NodeInterruptionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("node", req.Name)
+
+	node := &corev1.Node{}
+	err := r.Get(ctx, req.NamespacedName, node)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "cannot get node")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	reason, interrupted := r.getInterruption(node)
+	if !interrupted {
+		return ctrl.Result{}, nil
+	}
+
+	pods := &corev1.PodList{}
+	err = r.List(ctx, pods, client.MatchingLabels{SparkRoleLabel: ExecutorRole})
+	if err != nil {
+		log.Error(err, "cannot list executor pods")
+		return ctrl.Result{}, err
+	}
+
+	var lastErr error
+	decommissioned := make(map[client.ObjectKey][]*corev1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != node.Name || !pod.DeletionTimestamp.IsZero() ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if err := r.decommission(ctx, pod, reason, log); err != nil {
+			log.Error(err, "could not decommission executor", "pod", pod.Name, "namespace", pod.Namespace)
+			lastErr = err
+			continue
+		}
+		key := client.ObjectKey{Namespace: pod.Namespace, Name: pod.Labels[SparkAppLabel]}
+		decommissioned[key] = append(decommissioned[key], pod)
+	}
+
+	// The decommissions of an application are recorded in one patch, the executors of an application
+	// are often on the same node
+	for key, appPods := range decommissioned {
+		if err := r.recordDecommissions(ctx, key, appPods, node, reason); err != nil {
+			log.Error(err, "could not record executor decommissions", "application", key.Name, "namespace", key.Namespace)
+			lastErr = err
+		}
+	}
+
+	return ctrl.Result{}, lastErr
+}
+
+// getInterruption returns the signal that the node is about to be reclaimed, if any
+func (r *NodeInterruptionReconciler) getInterruption(node *corev1.Node) (string, bool) {
+	for _, key := range r.conf.Taints {
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == key {
+				return fmt.Sprintf("Taint %s", key), true
+			}
+		}
+	}
+	for _, key := range r.conf.Annotations {
+		if _, ok := node.Annotations[key]; ok {
+			return fmt.Sprintf("Annotation %s", key), true
+		}
+	}
+	if node.Spec.Unschedulable && !r.conf.IgnoreCordon {
+		return interruptionReasonCordoned, true
+	}
+	return "", false
+}
+
+func (r *NodeInterruptionReconciler) decommission(ctx context.Context, pod *corev1.Pod, reason string, log logr.Logger) error {
+	gracePeriodSeconds := int64(r.conf.GracePeriod.Seconds())
+	err := r.Delete(ctx, pod, client.GracePeriodSeconds(gracePeriodSeconds))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("could not delete executor pod, %w", err)
+	}
+
+	log.Info("Decommissioned executor", "pod", pod.Name, "namespace", pod.Namespace, "reason", reason)
+	executorDecommissions.WithLabelValues(pod.Namespace).Inc()
+	return nil
+}
+
+// recordDecommissions appends the executor decommissions to the application's status, the patch is retried
+// on conflicts so concurrent status updates are not lost
+func (r *NodeInterruptionReconciler) recordDecommissions(ctx context.Context, key client.ObjectKey, pods []*corev1.Pod, node *corev1.Node, reason string) error {
+	cr := &v1alpha1.SparkApplication{}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, key, cr); err != nil {
+			return err
+		}
+		deepCopy := cr.DeepCopy()
+		for _, pod := range pods {
+			deepCopy.Status.ExecutorDecommissions = append(deepCopy.Status.ExecutorDecommissions, v1alpha1.ExecutorDecommission{
+				PodName:  pod.Name,
+				NodeName: node.Name,
+				Reason:   reason,
+				Time:     metav1.NewTime(r.timeProvider()),
+			})
+		}
+		return r.Patch(ctx, deepCopy, client.MergeFromWithOptions(cr, client.MergeFromWithOptimisticLock{}))
+	})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("could not record executor decommissions, %w", err)
+	}
+
+	for _, pod := range pods {
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, reasonExecutorDecommissioned,
+			"Decommissioned executor pod %s, node %s is about to be reclaimed (%s)", pod.Name, node.Name, reason)
+	}
+	return nil
+}
+
+func (r *NodeInterruptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("node-interruption").
+		For(&corev1.Node{}).
+		Complete(r)
+}
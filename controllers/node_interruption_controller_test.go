@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlrt "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlrt_fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+// conflictingClient fails the first patches with a conflict, as if the object was updated concurrently
+type conflictingClient struct {
+	client.Client
+	conflicts int
+}
+
+func (c *conflictingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if c.conflicts > 0 {
+		c.conflicts--
+		return k8serrors.NewConflict(v1alpha1.GroupVersion.WithResource("sparkapplications").GroupResource(), obj.GetName(), fmt.Errorf("conflict"))
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestNodeInterruptionReconciler(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	req := ctrlrt.Request{NamespacedName: types.NamespacedName{Name: "node-1"}}
+
+	newNode := func() *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	}
+
+	newPod := func(name string, role string, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "spark-jobs",
+				Labels: map[string]string{
+					SparkAppLabel:  "spark-123",
+					SparkRoleLabel: role,
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+			},
+		}
+	}
+
+	newApplication := func() *v1alpha1.SparkApplication {
+		return &v1alpha1.SparkApplication{ObjectMeta: metav1.ObjectMeta{Name: "spark-123", Namespace: "spark-jobs"}}
+	}
+
+	reconcile := func(tt *testing.T, conf config.NodeInterruption, node *corev1.Node, objects ...runtime.Object) (client.Client, *record.FakeRecorder) {
+		ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, append(objects, node)...)
+		recorder := record.NewFakeRecorder(10)
+		controller := NewNodeInterruptionReconciler(ctrlClient, conf, recorder, func() time.Time { return now }, getTestLogger())
+		res, err := controller.Reconcile(ctx, req)
+		require.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		return ctrlClient, recorder
+	}
+
+	isDeleted := func(tt *testing.T, c client.Client, name string) bool {
+		err := c.Get(ctx, client.ObjectKey{Namespace: "spark-jobs", Name: name}, &corev1.Pod{})
+		if k8serrors.IsNotFound(err) {
+			return true
+		}
+		require.NoError(tt, err)
+		return false
+	}
+
+	getApplication := func(tt *testing.T, c client.Client) *v1alpha1.SparkApplication {
+		cr := &v1alpha1.SparkApplication{}
+		require.NoError(tt, c.Get(ctx, client.ObjectKey{Namespace: "spark-jobs", Name: "spark-123"}, cr))
+		return cr
+	}
+
+	t.Run("whenNodeNotInterrupted", func(tt *testing.T) {
+		node := newNode()
+		node.Spec.Taints = []corev1.Taint{{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule}}
+		c, recorder := reconcile(tt, config.NodeInterruption{}, node, newApplication(), newPod("exec-1", ExecutorRole, "node-1"))
+		assert.False(tt, isDeleted(tt, c, "exec-1"))
+		assert.Empty(tt, getApplication(tt, c).Status.ExecutorDecommissions)
+		assert.Empty(tt, recorder.Events)
+	})
+
+	t.Run("whenNodeTainted", func(tt *testing.T) {
+		before := testutil.ToFloat64(executorDecommissions.WithLabelValues("spark-jobs"))
+
+		node := newNode()
+		node.Spec.Taints = []corev1.Taint{{Key: "aws-node-termination-handler/spot-itn", Effect: corev1.TaintEffectNoSchedule}}
+		finished := newPod("exec-3", ExecutorRole, "node-1")
+		finished.Status.Phase = corev1.PodSucceeded
+		c, recorder := reconcile(tt, config.NodeInterruption{}, node,
+			newApplication(),
+			newPod("driver", DriverRole, "node-1"),
+			newPod("exec-1", ExecutorRole, "node-1"),
+			newPod("exec-2", ExecutorRole, "node-2"),
+			finished)
+
+		assert.True(tt, isDeleted(tt, c, "exec-1"))
+		assert.False(tt, isDeleted(tt, c, "exec-2"))
+		assert.False(tt, isDeleted(tt, c, "exec-3"))
+		assert.False(tt, isDeleted(tt, c, "driver"))
+
+		decommissions := getApplication(tt, c).Status.ExecutorDecommissions
+		require.Len(tt, decommissions, 1)
+		assert.Equal(tt, "exec-1", decommissions[0].PodName)
+		assert.Equal(tt, "node-1", decommissions[0].NodeName)
+		assert.Equal(tt, "Taint aws-node-termination-handler/spot-itn", decommissions[0].Reason)
+		assert.True(tt, now.Equal(decommissions[0].Time.Time))
+
+		require.Len(tt, recorder.Events, 1)
+		assert.Contains(tt, <-recorder.Events, "Normal ExecutorDecommissioned Decommissioned executor pod exec-1")
+		assert.Equal(tt, before+1, testutil.ToFloat64(executorDecommissions.WithLabelValues("spark-jobs")))
+	})
+
+	t.Run("whenSeveralExecutorsOfApplication", func(tt *testing.T) {
+		node := newNode()
+		node.Spec.Unschedulable = true
+		app := newApplication()
+		app.Status.ExecutorDecommissions = []v1alpha1.ExecutorDecommission{{PodName: "exec-0", NodeName: "node-0", Reason: "Cordoned"}}
+		c, recorder := reconcile(tt, config.NodeInterruption{}, node,
+			app,
+			newPod("exec-1", ExecutorRole, "node-1"),
+			newPod("exec-2", ExecutorRole, "node-1"))
+
+		assert.True(tt, isDeleted(tt, c, "exec-1"))
+		assert.True(tt, isDeleted(tt, c, "exec-2"))
+
+		decommissions := getApplication(tt, c).Status.ExecutorDecommissions
+		require.Len(tt, decommissions, 3)
+		podNames := []string{decommissions[0].PodName, decommissions[1].PodName, decommissions[2].PodName}
+		assert.ElementsMatch(tt, []string{"exec-0", "exec-1", "exec-2"}, podNames)
+		assert.Len(tt, recorder.Events, 2)
+	})
+
+	t.Run("whenStatusConflict", func(tt *testing.T) {
+		node := newNode()
+		node.Spec.Unschedulable = true
+		c := &conflictingClient{
+			Client:    ctrlrt_fake.NewFakeClientWithScheme(testScheme, node, newApplication(), newPod("exec-1", ExecutorRole, "node-1")),
+			conflicts: 1,
+		}
+		controller := NewNodeInterruptionReconciler(c, config.NodeInterruption{}, record.NewFakeRecorder(10), func() time.Time { return now }, getTestLogger())
+		_, err := controller.Reconcile(ctx, req)
+		require.NoError(tt, err)
+		assert.Equal(tt, 0, c.conflicts)
+		assert.Len(tt, getApplication(tt, c).Status.ExecutorDecommissions, 1)
+	})
+
+	t.Run("whenNodeAnnotated", func(tt *testing.T) {
+		node := newNode()
+		node.Annotations = map[string]string{config.DefaultNodeInterruptionAnnotation: "2021-03-01T12:02:00Z"}
+		c, _ := reconcile(tt, config.NodeInterruption{}, node, newApplication(), newPod("exec-1", ExecutorRole, "node-1"))
+		assert.True(tt, isDeleted(tt, c, "exec-1"))
+		decommissions := getApplication(tt, c).Status.ExecutorDecommissions
+		require.Len(tt, decommissions, 1)
+		assert.Equal(tt, "Annotation spotinst.io/interruption", decommissions[0].Reason)
+	})
+
+	t.Run("whenNodeCordoned", func(tt *testing.T) {
+		node := newNode()
+		node.Spec.Unschedulable = true
+		c, _ := reconcile(tt, config.NodeInterruption{}, node, newApplication(), newPod("exec-1", ExecutorRole, "node-1"))
+		assert.True(tt, isDeleted(tt, c, "exec-1"))
+		decommissions := getApplication(tt, c).Status.ExecutorDecommissions
+		require.Len(tt, decommissions, 1)
+		assert.Equal(tt, interruptionReasonCordoned, decommissions[0].Reason)
+
+		c, _ = reconcile(tt, config.NodeInterruption{IgnoreCordon: true}, node, newApplication(), newPod("exec-1", ExecutorRole, "node-1"))
+		assert.False(tt, isDeleted(tt, c, "exec-1"))
+	})
+
+	t.Run("whenConfiguredTaints", func(tt *testing.T) {
+		node := newNode()
+		node.Spec.Taints = []corev1.Taint{{Key: "aws-node-termination-handler/spot-itn", Effect: corev1.TaintEffectNoSchedule}}
+		conf := config.NodeInterruption{Taints: []string{"example.com/reclaim"}}
+		c, _ := reconcile(tt, conf, node, newApplication(), newPod("exec-1", ExecutorRole, "node-1"))
+		assert.False(tt, isDeleted(tt, c, "exec-1"))
+
+		node.Spec.Taints = []corev1.Taint{{Key: "example.com/reclaim", Effect: corev1.TaintEffectNoExecute}}
+		c, _ = reconcile(tt, conf, node, newApplication(), newPod("exec-1", ExecutorRole, "node-1"))
+		assert.True(tt, isDeleted(tt, c, "exec-1"))
+	})
+
+	t.Run("whenApplicationNotFound", func(tt *testing.T) {
+		node := newNode()
+		node.Spec.Unschedulable = true
+		c, recorder := reconcile(tt, config.NodeInterruption{}, node, newPod("exec-1", ExecutorRole, "node-1"))
+		assert.True(tt, isDeleted(tt, c, "exec-1"))
+		assert.Empty(tt, recorder.Events)
+	})
+
+	t.Run("whenNodeNotFound", func(tt *testing.T) {
+		ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme)
+		controller := NewNodeInterruptionReconciler(ctrlClient, config.NodeInterruption{}, record.NewFakeRecorder(10), time.Now, getTestLogger())
+		res, err := controller.Reconcile(ctx, req)
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+	})
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+const (
+	// collectTimeout bounds listing the SparkApplication resources on a metrics scrape
+	collectTimeout = 10 * time.Second
+
+	driverExecutorID = "driver"
+)
+
+// SparkApplicationCollector is a prometheus collector of cluster wide Spark metrics,
+// aggregated over the SparkApplication resources in the controller's cache.
+// All metrics are gauges of the current resources, applications stop being counted once
+// their resource is deleted, so they are not suited for rate() or increase().
+// The per application metrics are exported by the sparkapi package.
+type SparkApplicationCollector struct {
+	reader       ctrlclient.Reader
+	timeProvider func() time.Time
+	log          logr.Logger
+
+	applicationsRunning           *prometheus.Desc
+	applicationResources          *prometheus.Desc
+	applicationResourcesSucceeded *prometheus.Desc
+	applicationResourcesFailed    *prometheus.Desc
+	executorsActive               *prometheus.Desc
+	executorCoresActive           *prometheus.Desc
+	executorHours                 *prometheus.Desc
+}
+
+// NewSparkApplicationCollector creates a collector that lists SparkApplication resources with the given reader on every scrape
+func NewSparkApplicationCollector(reader ctrlclient.Reader, timeProvider func() time.Time, log logr.Logger) *SparkApplicationCollector {
+	return &SparkApplicationCollector{
+		reader:       reader,
+		timeProvider: timeProvider,
+		log:          log,
+		applicationsRunning: prometheus.NewDesc(
+			"wave_spark_applications_running",
+			"Current count of running Spark applications",
+			[]string{"namespace", "heritage", "workload_type"}, nil),
+		applicationResources: prometheus.NewDesc(
+			"wave_spark_application_resources",
+			"Current count of SparkApplication resources",
+			[]string{"namespace"}, nil),
+		applicationResourcesSucceeded: prometheus.NewDesc(
+			"wave_spark_application_resources_succeeded",
+			"Current count of SparkApplication resources of Spark applications that succeeded",
+			[]string{"namespace"}, nil),
+		applicationResourcesFailed: prometheus.NewDesc(
+			"wave_spark_application_resources_failed",
+			"Current count of SparkApplication resources of Spark applications that failed",
+			[]string{"namespace"}, nil),
+		executorsActive: prometheus.NewDesc(
+			"wave_spark_executors_active",
+			"Current count of active executors of running Spark applications",
+			nil, nil),
+		executorCoresActive: prometheus.NewDesc(
+			"wave_spark_executor_cores_active",
+			"Current count of cores of active executors of running Spark applications",
+			nil, nil),
+		executorHours: prometheus.NewDesc(
+			"wave_spark_application_resources_executor_hours",
+			"Current executor pod running time in hours of the Spark applications with a SparkApplication resource, by node lifecycle",
+			[]string{"lifecycle"}, nil),
+	}
+}
+
+func (c *SparkApplicationCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.applicationsRunning
+	descs <- c.applicationResources
+	descs <- c.applicationResourcesSucceeded
+	descs <- c.applicationResourcesFailed
+	descs <- c.executorsActive
+	descs <- c.executorCoresActive
+	descs <- c.executorHours
+}
+
+type runningApplicationKey struct {
+	namespace    string
+	heritage     string
+	workloadType string
+}
+
+func (c *SparkApplicationCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	apps := &v1alpha1.SparkApplicationList{}
+	if err := c.reader.List(ctx, apps); err != nil {
+		c.log.Error(err, "could not list spark applications, cluster metrics not collected")
+		return
+	}
+
+	now := c.timeProvider()
+
+	running := make(map[runningApplicationKey]int)
+	resources := make(map[string]int)
+	succeeded := make(map[string]int)
+	failed := make(map[string]int)
+	executorHours := map[string]float64{
+		string(config.InstanceLifecycleOnDemand): 0,
+		string(config.InstanceLifecycleSpot):     0,
+		instanceLifecycleUnknown:                 0,
+	}
+	var activeExecutors, activeCores int64
+
+	for _, app := range apps.Items {
+		namespace := app.Namespace
+		resources[namespace]++
+
+		switch getApplicationPhase(&app) {
+		case corev1.PodRunning:
+			running[runningApplicationKey{
+				namespace:    namespace,
+				heritage:     string(app.Spec.Heritage),
+				workloadType: app.Annotations[config.WaveConfigAnnotationWorkloadType],
+			}]++
+			for _, executor := range app.Status.Data.RunStatistics.Executors {
+				if executor.IsActive && executor.ID != driverExecutorID {
+					activeExecutors++
+					activeCores += executor.TotalCores
+				}
+			}
+		case corev1.PodSucceeded:
+			succeeded[namespace]++
+		case corev1.PodFailed:
+			failed[namespace]++
+		}
+
+		for _, executor := range app.Status.Data.Executors {
+			lifecycle := executor.InstanceLifecycle
+			if lifecycle == "" {
+				lifecycle = instanceLifecycleUnknown
+			}
+			executorHours[lifecycle] += getPodRunningTime(executor, now).Hours()
+		}
+	}
+
+	for key, count := range running {
+		metrics <- prometheus.MustNewConstMetric(c.applicationsRunning, prometheus.GaugeValue, float64(count),
+			key.namespace, key.heritage, key.workloadType)
+	}
+	for namespace, count := range resources {
+		metrics <- prometheus.MustNewConstMetric(c.applicationResources, prometheus.GaugeValue, float64(count), namespace)
+		metrics <- prometheus.MustNewConstMetric(c.applicationResourcesSucceeded, prometheus.GaugeValue, float64(succeeded[namespace]), namespace)
+		metrics <- prometheus.MustNewConstMetric(c.applicationResourcesFailed, prometheus.GaugeValue, float64(failed[namespace]), namespace)
+	}
+	metrics <- prometheus.MustNewConstMetric(c.executorsActive, prometheus.GaugeValue, float64(activeExecutors))
+	metrics <- prometheus.MustNewConstMetric(c.executorCoresActive, prometheus.GaugeValue, float64(activeCores))
+	for lifecycle, hours := range executorHours {
+		metrics <- prometheus.MustNewConstMetric(c.executorHours, prometheus.GaugeValue, hours, lifecycle)
+	}
+}
+
+// getApplicationPhase returns the phase of the application's driver pod,
+// a deleted driver pod that was still running is not considered running
+func getApplicationPhase(app *v1alpha1.SparkApplication) corev1.PodPhase {
+	driver := app.Status.Data.Driver
+	if driver.Phase == corev1.PodRunning && driver.DeletionTimestamp != nil {
+		return corev1.PodUnknown
+	}
+	return driver.Phase
+}
+
+// getPodRunningTime returns the time the pod spent running, from its state history
+func getPodRunningTime(pod v1alpha1.Pod, now time.Time) time.Duration {
+	var start *time.Time
+	for _, entry := range pod.StateHistory {
+		switch entry.Phase {
+		case corev1.PodRunning:
+			if start == nil {
+				t := entry.Timestamp.Time
+				start = &t
+			}
+		case corev1.PodSucceeded, corev1.PodFailed:
+			if start == nil {
+				return 0
+			}
+			return entry.Timestamp.Sub(*start)
+		}
+	}
+
+	if start == nil {
+		return 0
+	}
+	end := now
+	if pod.DeletionTimestamp != nil {
+		end = pod.DeletionTimestamp.Time
+	}
+	if end.Before(*start) {
+		return 0
+	}
+	return end.Sub(*start)
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlrt_fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+func TestSparkApplicationCollector(t *testing.T) {
+
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	running := newCollectorTestApplication("spark-1", "ns-1", corev1.PodRunning)
+	running.Spec.Heritage = v1alpha1.SparkHeritageSubmit
+	running.Annotations = map[string]string{config.WaveConfigAnnotationWorkloadType: "spark-streaming"}
+	running.Status.Data.RunStatistics.Executors = []v1alpha1.Executor{
+		{ID: driverExecutorID, IsActive: true, TotalCores: 1},
+		{ID: "1", IsActive: true, TotalCores: 4},
+		{ID: "2", IsActive: false, TotalCores: 4},
+	}
+	running.Status.Data.Executors = []v1alpha1.Pod{
+		newCollectorTestExecutor("spot", now.Add(-2*time.Hour), nil),
+		newCollectorTestExecutor("", now.Add(-time.Hour), nil),
+	}
+
+	succeeded := newCollectorTestApplication("spark-2", "ns-1", corev1.PodSucceeded)
+	end := now.Add(-30 * time.Minute)
+	succeeded.Status.Data.Executors = []v1alpha1.Pod{
+		newCollectorTestExecutor("od", now.Add(-90*time.Minute), &end),
+	}
+
+	failed := newCollectorTestApplication("spark-3", "ns-2", corev1.PodFailed)
+
+	deleted := newCollectorTestApplication("spark-4", "ns-2", corev1.PodRunning)
+	deleted.Status.Data.Driver.DeletionTimestamp = &metav1.Time{Time: now}
+
+	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, running, succeeded, failed, deleted)
+	collector := NewSparkApplicationCollector(ctrlClient, func() time.Time { return now }, getTestLogger())
+
+	expected := `
+# HELP wave_spark_application_resources Current count of SparkApplication resources
+# TYPE wave_spark_application_resources gauge
+wave_spark_application_resources{namespace="ns-1"} 2
+wave_spark_application_resources{namespace="ns-2"} 2
+# HELP wave_spark_application_resources_executor_hours Current executor pod running time in hours of the Spark applications with a SparkApplication resource, by node lifecycle
+# TYPE wave_spark_application_resources_executor_hours gauge
+wave_spark_application_resources_executor_hours{lifecycle="od"} 1
+wave_spark_application_resources_executor_hours{lifecycle="spot"} 2
+wave_spark_application_resources_executor_hours{lifecycle="unknown"} 1
+# HELP wave_spark_application_resources_failed Current count of SparkApplication resources of Spark applications that failed
+# TYPE wave_spark_application_resources_failed gauge
+wave_spark_application_resources_failed{namespace="ns-1"} 0
+wave_spark_application_resources_failed{namespace="ns-2"} 1
+# HELP wave_spark_application_resources_succeeded Current count of SparkApplication resources of Spark applications that succeeded
+# TYPE wave_spark_application_resources_succeeded gauge
+wave_spark_application_resources_succeeded{namespace="ns-1"} 1
+wave_spark_application_resources_succeeded{namespace="ns-2"} 0
+# HELP wave_spark_applications_running Current count of running Spark applications
+# TYPE wave_spark_applications_running gauge
+wave_spark_applications_running{heritage="spark-submit",namespace="ns-1",workload_type="spark-streaming"} 1
+# HELP wave_spark_executor_cores_active Current count of cores of active executors of running Spark applications
+# TYPE wave_spark_executor_cores_active gauge
+wave_spark_executor_cores_active 4
+# HELP wave_spark_executors_active Current count of active executors of running Spark applications
+# TYPE wave_spark_executors_active gauge
+wave_spark_executors_active 1
+`
+
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
+
+func TestGetPodRunningTime(t *testing.T) {
+
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("whenNeverRunning", func(tt *testing.T) {
+		pod := v1alpha1.Pod{
+			StateHistory: []v1alpha1.PodStateHistoryEntry{
+				{Timestamp: metav1.Time{Time: now.Add(-time.Hour)}, Phase: corev1.PodPending},
+				{Timestamp: metav1.Time{Time: now.Add(-time.Minute)}, Phase: corev1.PodFailed},
+			},
+		}
+		assert.Equal(tt, time.Duration(0), getPodRunningTime(pod, now))
+	})
+
+	t.Run("whenTerminated", func(tt *testing.T) {
+		pod := v1alpha1.Pod{
+			StateHistory: []v1alpha1.PodStateHistoryEntry{
+				{Timestamp: metav1.Time{Time: now.Add(-time.Hour)}, Phase: corev1.PodPending},
+				{Timestamp: metav1.Time{Time: now.Add(-50 * time.Minute)}, Phase: corev1.PodRunning},
+				{Timestamp: metav1.Time{Time: now.Add(-40 * time.Minute)}, Phase: corev1.PodRunning},
+				{Timestamp: metav1.Time{Time: now.Add(-20 * time.Minute)}, Phase: corev1.PodSucceeded},
+			},
+		}
+		assert.Equal(tt, 30*time.Minute, getPodRunningTime(pod, now))
+	})
+
+	t.Run("whenRunning", func(tt *testing.T) {
+		pod := newCollectorTestExecutor("", now.Add(-time.Hour), nil)
+		assert.Equal(tt, time.Hour, getPodRunningTime(pod, now))
+	})
+}
+
+func newCollectorTestApplication(name string, namespace string, driverPhase corev1.PodPhase) *v1alpha1.SparkApplication {
+	return &v1alpha1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.SparkApplicationSpec{
+			ApplicationID: name,
+			Heritage:      v1alpha1.SparkHeritageOperator,
+		},
+		Status: v1alpha1.SparkApplicationStatus{
+			Data: v1alpha1.SparkApplicationData{
+				Driver: v1alpha1.Pod{
+					Phase: driverPhase,
+				},
+			},
+		},
+	}
+}
+
+func newCollectorTestExecutor(lifecycle string, start time.Time, deleted *time.Time) v1alpha1.Pod {
+	pod := v1alpha1.Pod{
+		InstanceLifecycle: lifecycle,
+		StateHistory: []v1alpha1.PodStateHistoryEntry{
+			{Timestamp: metav1.Time{Time: start}, Phase: corev1.PodRunning},
+		},
+	}
+	if deleted != nil {
+		pod.DeletionTimestamp = &metav1.Time{Time: *deleted}
+	}
+	return pod
+}
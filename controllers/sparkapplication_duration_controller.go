@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+const (
+	ConditionTypeDurationExceeded = "DurationExceeded"
+
+	reasonExpectedDurationExceeded = "ExpectedDurationExceeded"
+	reasonMaxDurationExceeded      = "MaxDurationExceeded"
+	reasonWithinDuration           = "WithinDuration"
+	reasonDriverTerminated         = "DriverTerminated"
+
+	durationLimitExpected = "expected"
+	durationLimitMax      = "max"
+)
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SparkApplicationDurationReconciler marks running Spark applications that exceed the expected or max duration
+// configured in their annotations, and optionally terminates the driver of applications reaching their max duration
+type SparkApplicationDurationReconciler struct {
+	client.Client
+	recorder     record.EventRecorder
+	timeProvider func() time.Time
+	Log          logr.Logger
+}
+
+func NewSparkApplicationDurationReconciler(
+	client client.Client,
+	recorder record.EventRecorder,
+	timeProvider func() time.Time,
+	log logr.Logger) *SparkApplicationDurationReconciler {
+
+	return &SparkApplicationDurationReconciler{
+		Client:       client,
+		recorder:     recorder,
+		timeProvider: timeProvider,
+		Log:          log,
+	}
+}
+
+func (r *SparkApplicationDurationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("sparkapplication", req.NamespacedName)
+
+	cr := &v1alpha1.SparkApplication{}
+	err := r.Get(ctx, req.NamespacedName, cr)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "cannot get spark application")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	expected, err := config.GetDuration(cr.Annotations, config.WaveConfigAnnotationExpectedDuration)
+	if err != nil {
+		log.Info(fmt.Sprintf("Ignoring expected duration, %s", err.Error()))
+	}
+	max, err := config.GetDuration(cr.Annotations, config.WaveConfigAnnotationMaxDuration)
+	if err != nil {
+		log.Info(fmt.Sprintf("Ignoring max duration, %s", err.Error()))
+	}
+	if expected == 0 && max == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	if getApplicationPhase(cr) != corev1.PodRunning {
+		return ctrl.Result{}, nil
+	}
+	start, ok := getPodStartTime(cr.Status.Data.Driver)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+	runningFor := r.timeProvider().Sub(start)
+
+	var next time.Duration
+	for _, limit := range []time.Duration{expected, max} {
+		if wait := limit - runningFor; wait > 0 && (next == 0 || wait < next) {
+			next = wait
+		}
+	}
+
+	limitName, limit, reason := "", time.Duration(0), ""
+	switch {
+	case max > 0 && runningFor >= max:
+		limitName, limit, reason = durationLimitMax, max, reasonMaxDurationExceeded
+	case expected > 0 && runningFor >= expected:
+		limitName, limit, reason = durationLimitExpected, expected, reasonExpectedDurationExceeded
+	}
+
+	existing := meta.FindStatusCondition(cr.Status.Conditions, ConditionTypeDurationExceeded)
+
+	if reason == "" {
+		// The limits may have been raised since the condition was set
+		if existing != nil && existing.Status == metav1.ConditionTrue {
+			err = r.setCondition(ctx, cr, metav1.ConditionFalse, reasonWithinDuration, "Driver is running within its duration limits")
+			if err != nil {
+				log.Error(err, "could not update duration condition")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: next}, nil
+	}
+
+	terminate := limitName == durationLimitMax && config.IsTerminateOnMaxDurationEnabled(cr.Annotations)
+	if terminate {
+		driver := &corev1.Pod{}
+		driver.Name = cr.Status.Data.Driver.Name
+		driver.Namespace = cr.Namespace
+		err = r.Delete(ctx, driver)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			log.Error(err, "could not terminate driver pod")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if existing != nil && existing.Status == metav1.ConditionTrue && existing.Reason == reason {
+		return ctrl.Result{RequeueAfter: next}, nil
+	}
+
+	message := fmt.Sprintf("Driver has been running for %s, longer than its %s duration of %s",
+		runningFor.Round(time.Second), limitName, limit)
+	err = r.setCondition(ctx, cr, metav1.ConditionTrue, reason, message)
+	if err != nil {
+		log.Error(err, "could not update duration condition")
+		return ctrl.Result{}, err
+	}
+
+	sparkApplicationDurationExceeded.WithLabelValues(cr.Namespace, limitName).Inc()
+	r.recorder.Event(cr, corev1.EventTypeWarning, reason, message)
+	log.Info(message)
+	if terminate {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, reasonDriverTerminated, "Terminated driver pod %s after max duration of %s", cr.Status.Data.Driver.Name, max)
+		log.Info("Terminated driver pod", "pod", cr.Status.Data.Driver.Name)
+	}
+
+	return ctrl.Result{RequeueAfter: next}, nil
+}
+
+func (r *SparkApplicationDurationReconciler) setCondition(ctx context.Context, cr *v1alpha1.SparkApplication, status metav1.ConditionStatus, reason string, message string) error {
+	deepCopy := cr.DeepCopy()
+	meta.SetStatusCondition(&deepCopy.Status.Conditions, metav1.Condition{
+		Type:               ConditionTypeDurationExceeded,
+		Status:             status,
+		ObservedGeneration: cr.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return r.Client.Patch(ctx, deepCopy, client.MergeFrom(cr))
+}
+
+func (r *SparkApplicationDurationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sparkapplication-duration").
+		For(&v1alpha1.SparkApplication{}).
+		Complete(r)
+}
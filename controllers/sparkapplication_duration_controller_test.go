@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlrt "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlrt_fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+func TestSparkApplicationDurationReconciler(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	req := ctrlrt.Request{NamespacedName: types.NamespacedName{Namespace: "spark-jobs", Name: "spark-123"}}
+
+	// newApplication returns an application whose driver has been running for an hour
+	newApplication := func(annotations map[string]string) *v1alpha1.SparkApplication {
+		cr := newTracingTestApplication(corev1.PodRunning, now, false)
+		cr.Annotations = annotations
+		cr.Status.Data.Driver.Name = "driver-pod"
+		return cr
+	}
+
+	reconcile := func(tt *testing.T, cr *v1alpha1.SparkApplication) (ctrlrt.Result, *v1alpha1.SparkApplication, *record.FakeRecorder, client.Client, error) {
+		driver := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "driver-pod", Namespace: "spark-jobs"}}
+		ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, cr, driver)
+		recorder := record.NewFakeRecorder(10)
+		controller := NewSparkApplicationDurationReconciler(ctrlClient, recorder, func() time.Time { return now }, getTestLogger())
+		res, err := controller.Reconcile(ctx, req)
+
+		updated := &v1alpha1.SparkApplication{}
+		require.NoError(tt, ctrlClient.Get(ctx, req.NamespacedName, updated))
+		return res, updated, recorder, ctrlClient, err
+	}
+
+	t.Run("whenNoDurations", func(tt *testing.T) {
+		res, updated, recorder, _, err := reconcile(tt, newApplication(nil))
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		assert.Empty(tt, updated.Status.Conditions)
+		assert.Empty(tt, recorder.Events)
+	})
+
+	t.Run("whenWithinDurations", func(tt *testing.T) {
+		res, updated, recorder, _, err := reconcile(tt, newApplication(map[string]string{
+			config.WaveConfigAnnotationExpectedDuration: "90m",
+			config.WaveConfigAnnotationMaxDuration:      "3h",
+		}))
+		assert.NoError(tt, err)
+		assert.Equal(tt, 30*time.Minute, res.RequeueAfter)
+		assert.Empty(tt, updated.Status.Conditions)
+		assert.Empty(tt, recorder.Events)
+	})
+
+	t.Run("whenExpectedDurationExceeded", func(tt *testing.T) {
+		before := testutil.ToFloat64(sparkApplicationDurationExceeded.WithLabelValues("spark-jobs", durationLimitExpected))
+
+		res, updated, recorder, ctrlClient, err := reconcile(tt, newApplication(map[string]string{
+			config.WaveConfigAnnotationExpectedDuration:       "30m",
+			config.WaveConfigAnnotationMaxDuration:            "3h",
+			config.WaveConfigAnnotationTerminateOnMaxDuration: "true",
+		}))
+		assert.NoError(tt, err)
+		assert.Equal(tt, 2*time.Hour, res.RequeueAfter)
+
+		condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeDurationExceeded)
+		require.NotNil(tt, condition)
+		assert.Equal(tt, metav1.ConditionTrue, condition.Status)
+		assert.Equal(tt, reasonExpectedDurationExceeded, condition.Reason)
+		assert.Equal(tt, "Driver has been running for 1h0m0s, longer than its expected duration of 30m0s", condition.Message)
+
+		require.Len(tt, recorder.Events, 1)
+		assert.Contains(tt, <-recorder.Events, "Warning ExpectedDurationExceeded")
+		assert.Equal(tt, before+1, testutil.ToFloat64(sparkApplicationDurationExceeded.WithLabelValues("spark-jobs", durationLimitExpected)))
+
+		// The driver is only terminated at the max duration
+		err = ctrlClient.Get(ctx, types.NamespacedName{Namespace: "spark-jobs", Name: "driver-pod"}, &corev1.Pod{})
+		assert.NoError(tt, err)
+	})
+
+	t.Run("whenAlreadyMarked", func(tt *testing.T) {
+		cr := newApplication(map[string]string{config.WaveConfigAnnotationExpectedDuration: "30m"})
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    ConditionTypeDurationExceeded,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonExpectedDurationExceeded,
+			Message: "exceeded",
+		})
+
+		_, updated, recorder, _, err := reconcile(tt, cr)
+		assert.NoError(tt, err)
+		assert.Empty(tt, recorder.Events)
+		assert.Equal(tt, "exceeded", updated.Status.Conditions[0].Message)
+	})
+
+	t.Run("whenMaxDurationExceeded", func(tt *testing.T) {
+		before := testutil.ToFloat64(sparkApplicationDurationExceeded.WithLabelValues("spark-jobs", durationLimitMax))
+
+		res, updated, recorder, ctrlClient, err := reconcile(tt, newApplication(map[string]string{
+			config.WaveConfigAnnotationExpectedDuration: "30m",
+			config.WaveConfigAnnotationMaxDuration:      "45m",
+		}))
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+
+		condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeDurationExceeded)
+		require.NotNil(tt, condition)
+		assert.Equal(tt, reasonMaxDurationExceeded, condition.Reason)
+		require.Len(tt, recorder.Events, 1)
+		assert.Equal(tt, before+1, testutil.ToFloat64(sparkApplicationDurationExceeded.WithLabelValues("spark-jobs", durationLimitMax)))
+
+		err = ctrlClient.Get(ctx, types.NamespacedName{Namespace: "spark-jobs", Name: "driver-pod"}, &corev1.Pod{})
+		assert.NoError(tt, err)
+	})
+
+	t.Run("whenMaxDurationExceededAndTerminate", func(tt *testing.T) {
+		_, updated, recorder, ctrlClient, err := reconcile(tt, newApplication(map[string]string{
+			config.WaveConfigAnnotationMaxDuration:            "45m",
+			config.WaveConfigAnnotationTerminateOnMaxDuration: "true",
+		}))
+		assert.NoError(tt, err)
+		assert.Equal(tt, reasonMaxDurationExceeded, updated.Status.Conditions[0].Reason)
+
+		require.Len(tt, recorder.Events, 2)
+		assert.Contains(tt, <-recorder.Events, "Warning MaxDurationExceeded")
+		assert.Contains(tt, <-recorder.Events, "Warning DriverTerminated")
+
+		err = ctrlClient.Get(ctx, types.NamespacedName{Namespace: "spark-jobs", Name: "driver-pod"}, &corev1.Pod{})
+		assert.True(tt, k8serrors.IsNotFound(err))
+	})
+
+	t.Run("whenDurationRaised", func(tt *testing.T) {
+		cr := newApplication(map[string]string{config.WaveConfigAnnotationExpectedDuration: "2h"})
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    ConditionTypeDurationExceeded,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonExpectedDurationExceeded,
+			Message: "exceeded",
+		})
+
+		res, updated, _, _, err := reconcile(tt, cr)
+		assert.NoError(tt, err)
+		assert.Equal(tt, time.Hour, res.RequeueAfter)
+		assert.Equal(tt, metav1.ConditionFalse, updated.Status.Conditions[0].Status)
+		assert.Equal(tt, reasonWithinDuration, updated.Status.Conditions[0].Reason)
+	})
+
+	t.Run("whenDurationInvalid", func(tt *testing.T) {
+		res, updated, _, _, err := reconcile(tt, newApplication(map[string]string{
+			config.WaveConfigAnnotationExpectedDuration: "half an hour",
+			config.WaveConfigAnnotationMaxDuration:      "3h",
+		}))
+		assert.NoError(tt, err)
+		assert.Equal(tt, 2*time.Hour, res.RequeueAfter)
+		assert.Empty(tt, updated.Status.Conditions)
+	})
+
+	t.Run("whenFinished", func(tt *testing.T) {
+		cr := newTracingTestApplication(corev1.PodSucceeded, now, true)
+		cr.Annotations = map[string]string{config.WaveConfigAnnotationExpectedDuration: "30m"}
+
+		res, updated, _, _, err := reconcile(tt, cr)
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		assert.Empty(tt, updated.Status.Conditions)
+	})
+}
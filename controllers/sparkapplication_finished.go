@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+)
+
+const (
+	// finishedApplicationGracePeriod is how long handling a finished application waits for the
+	// Spark API data of its last attempt to be collected
+	finishedApplicationGracePeriod = 10 * time.Minute
+	// finishedApplicationMaxAge is how long after the driver finished an application is still handled,
+	// so applications that finished before a controller was enabled are skipped
+	finishedApplicationMaxAge = 24 * time.Hour
+)
+
+// isApplicationFinished returns whether the application's driver succeeded or failed
+func isApplicationFinished(cr *v1alpha1.SparkApplication) bool {
+	phase := getApplicationPhase(cr)
+	return phase == corev1.PodSucceeded || phase == corev1.PodFailed
+}
+
+// getRecentFinishTime returns the time the finished application's driver finished,
+// and whether it finished within finishedApplicationMaxAge
+func getRecentFinishTime(cr *v1alpha1.SparkApplication, now time.Time) (time.Time, bool) {
+	finished, ok := getPodFinishTime(cr.Status.Data.Driver)
+	if !ok || now.Sub(finished) > finishedApplicationMaxAge {
+		return time.Time{}, false
+	}
+	return finished, true
+}
+
+// getApplicationDataWait returns how long to wait for the Spark API data of the finished application's last attempt
+// to be collected, zero once the attempt is completed or the grace period has passed
+func getApplicationDataWait(cr *v1alpha1.SparkApplication, finished time.Time, now time.Time) time.Duration {
+	if isLatestAttemptCompleted(cr) || now.Sub(finished) >= finishedApplicationGracePeriod {
+		return 0
+	}
+	return finished.Add(finishedApplicationGracePeriod).Sub(now)
+}
+
+// getPodFinishTime returns the time the pod was first seen succeeded or failed
+func getPodFinishTime(pod v1alpha1.Pod) (time.Time, bool) {
+	for _, entry := range pod.StateHistory {
+		if entry.Phase == corev1.PodSucceeded || entry.Phase == corev1.PodFailed {
+			return entry.Timestamp.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func isLatestAttemptCompleted(cr *v1alpha1.SparkApplication) bool {
+	attempts := cr.Status.Data.RunStatistics.Attempts
+	return len(attempts) > 0 && attempts[0].Completed
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFinishedApplication(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("whenRunning", func(tt *testing.T) {
+		cr := newTracingTestApplication(corev1.PodRunning, now, false)
+		assert.False(tt, isApplicationFinished(cr))
+		_, ok := getRecentFinishTime(cr, now)
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenRecentlyFinished", func(tt *testing.T) {
+		cr := newTracingTestApplication(corev1.PodFailed, now.Add(-time.Minute), false)
+		assert.True(tt, isApplicationFinished(cr))
+		finished, ok := getRecentFinishTime(cr, now)
+		assert.True(tt, ok)
+		assert.Equal(tt, now.Add(-time.Minute), finished)
+		assert.Equal(tt, finishedApplicationGracePeriod-time.Minute, getApplicationDataWait(cr, finished, now))
+	})
+
+	t.Run("whenAttemptCompleted", func(tt *testing.T) {
+		cr := newTracingTestApplication(corev1.PodSucceeded, now.Add(-time.Minute), true)
+		finished, ok := getRecentFinishTime(cr, now)
+		assert.True(tt, ok)
+		assert.Equal(tt, time.Duration(0), getApplicationDataWait(cr, finished, now))
+	})
+
+	t.Run("whenGracePeriodPassed", func(tt *testing.T) {
+		cr := newTracingTestApplication(corev1.PodSucceeded, now.Add(-finishedApplicationGracePeriod), false)
+		finished, ok := getRecentFinishTime(cr, now)
+		assert.True(tt, ok)
+		assert.Equal(tt, time.Duration(0), getApplicationDataWait(cr, finished, now))
+	})
+
+	t.Run("whenTooOld", func(tt *testing.T) {
+		cr := newTracingTestApplication(corev1.PodSucceeded, now.Add(-2*finishedApplicationMaxAge), true)
+		assert.True(tt, isApplicationFinished(cr))
+		_, ok := getRecentFinishTime(cr, now)
+		assert.False(tt, ok)
+	})
+}
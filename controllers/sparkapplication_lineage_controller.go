@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/lineage"
+)
+
+// lineageEventAnnotation holds the type of the last run event emitted for the application
+const lineageEventAnnotation = "wave.spot.io/lineage-event"
+
+// SparkApplicationLineageReconciler emits an OpenLineage START event when a Spark application starts running,
+// and a COMPLETE or FAIL event once it has finished
+type SparkApplicationLineageReconciler struct {
+	client.Client
+	emitter      lineage.Emitter
+	timeProvider func() time.Time
+	Log          logr.Logger
+}
+
+func NewSparkApplicationLineageReconciler(
+	client client.Client,
+	emitter lineage.Emitter,
+	timeProvider func() time.Time,
+	log logr.Logger) *SparkApplicationLineageReconciler {
+
+	return &SparkApplicationLineageReconciler{
+		Client:       client,
+		emitter:      emitter,
+		timeProvider: timeProvider,
+		Log:          log,
+	}
+}
+
+func (r *SparkApplicationLineageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("sparkapplication", req.NamespacedName)
+
+	cr := &v1alpha1.SparkApplication{}
+	err := r.Get(ctx, req.NamespacedName, cr)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "cannot get spark application")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	lastEvent := lineage.EventType(cr.Annotations[lineageEventAnnotation])
+	if lastEvent == lineage.EventTypeComplete || lastEvent == lineage.EventTypeFail {
+		return ctrl.Result{}, nil
+	}
+
+	now := r.timeProvider()
+	phase := getApplicationPhase(cr)
+	finished := isApplicationFinished(cr)
+
+	var finishTime time.Time
+	if finished {
+		var ok bool
+		finishTime, ok = getRecentFinishTime(cr, now)
+		if !ok {
+			return ctrl.Result{}, nil
+		}
+	} else if phase != corev1.PodRunning {
+		return ctrl.Result{}, nil
+	}
+
+	if lastEvent == "" {
+		startTime, ok := getPodStartTime(cr.Status.Data.Driver)
+		if !ok {
+			startTime = cr.CreationTimestamp.Time
+		}
+		cr, err = r.emit(ctx, cr, lineage.EventTypeStart, startTime)
+		if err != nil {
+			log.Error(err, "could not emit lineage start event")
+			return ctrl.Result{}, err
+		}
+		log.Info("Emitted lineage start event")
+	}
+
+	if !finished {
+		return ctrl.Result{}, nil
+	}
+
+	// The completion event includes the datasets of the last attempt
+	if wait := getApplicationDataWait(cr, finishTime, now); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	eventType := lineage.EventTypeComplete
+	if phase == corev1.PodFailed {
+		eventType = lineage.EventTypeFail
+	}
+	_, err = r.emit(ctx, cr, eventType, finishTime)
+	if err != nil {
+		log.Error(err, "could not emit lineage completion event", "eventType", eventType)
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Emitted lineage completion event", "eventType", eventType)
+	return ctrl.Result{}, nil
+}
+
+// emit emits the run event and records it on the application, returning the updated application
+func (r *SparkApplicationLineageReconciler) emit(ctx context.Context, cr *v1alpha1.SparkApplication, eventType lineage.EventType, eventTime time.Time) (*v1alpha1.SparkApplication, error) {
+	err := r.emitter.Emit(ctx, cr, eventType, eventTime)
+	if err != nil {
+		return cr, err
+	}
+
+	deepCopy := cr.DeepCopy()
+	if deepCopy.Annotations == nil {
+		deepCopy.Annotations = make(map[string]string)
+	}
+	deepCopy.Annotations[lineageEventAnnotation] = string(eventType)
+	err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(cr))
+	if err != nil {
+		return cr, err
+	}
+	return deepCopy, nil
+}
+
+func (r *SparkApplicationLineageReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sparkapplication-lineage").
+		For(&v1alpha1.SparkApplication{}).
+		Complete(r)
+}
+
+// getPodStartTime returns the time the pod was first seen running
+func getPodStartTime(pod v1alpha1.Pod) (time.Time, bool) {
+	for _, entry := range pod.StateHistory {
+		if entry.Phase == corev1.PodRunning {
+			return entry.Timestamp.Time, true
+		}
+	}
+	return time.Time{}, false
+}
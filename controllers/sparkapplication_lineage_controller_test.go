@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlrt "sigs.k8s.io/controller-runtime"
+	ctrlrt_fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/lineage"
+	"github.com/spotinst/wave-operator/internal/lineage/mock_lineage"
+)
+
+func TestSparkApplicationLineageReconciler(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	req := ctrlrt.Request{NamespacedName: types.NamespacedName{Namespace: "spark-jobs", Name: "spark-123"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reconcile := func(tt *testing.T, cr *v1alpha1.SparkApplication, emitter *mock_lineage.MockEmitter) (ctrlrt.Result, *v1alpha1.SparkApplication, error) {
+		ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, cr)
+		controller := NewSparkApplicationLineageReconciler(ctrlClient, emitter, func() time.Time { return now }, getTestLogger())
+		res, err := controller.Reconcile(ctx, req)
+
+		updated := &v1alpha1.SparkApplication{}
+		require.NoError(tt, ctrlClient.Get(ctx, req.NamespacedName, updated))
+		return res, updated, err
+	}
+
+	// expectEmit expects an event emitted at the given time, times are compared with time.Equal
+	// as they lose their location when the application is stored
+	expectEmit := func(tt *testing.T, emitter *mock_lineage.MockEmitter, eventType lineage.EventType, eventTime time.Time) *gomock.Call {
+		return emitter.EXPECT().Emit(gomock.Any(), gomock.Any(), eventType, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *v1alpha1.SparkApplication, _ lineage.EventType, t time.Time) error {
+				assert.True(tt, eventTime.Equal(t), "expected event time %s, got %s", eventTime, t)
+				return nil
+			})
+	}
+
+	withLastEvent := func(cr *v1alpha1.SparkApplication, eventType lineage.EventType) *v1alpha1.SparkApplication {
+		cr.Annotations = map[string]string{lineageEventAnnotation: string(eventType)}
+		return cr
+	}
+
+	t.Run("whenPending", func(tt *testing.T) {
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		emitter.EXPECT().Emit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		res, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodPending, now, false), emitter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		assert.Empty(tt, updated.Annotations[lineageEventAnnotation])
+	})
+
+	t.Run("whenRunning", func(tt *testing.T) {
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		expectEmit(tt, emitter, lineage.EventTypeStart, now.Add(-time.Hour)).Times(1)
+
+		res, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodRunning, now, false), emitter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		assert.Equal(tt, "START", updated.Annotations[lineageEventAnnotation])
+	})
+
+	t.Run("whenRunningAndStarted", func(tt *testing.T) {
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		emitter.EXPECT().Emit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		cr := withLastEvent(newTracingTestApplication(corev1.PodRunning, now, false), lineage.EventTypeStart)
+		_, updated, err := reconcile(tt, cr, emitter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "START", updated.Annotations[lineageEventAnnotation])
+	})
+
+	t.Run("whenSucceededAndStarted", func(tt *testing.T) {
+		finished := now.Add(-time.Minute)
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		expectEmit(tt, emitter, lineage.EventTypeComplete, finished).Times(1)
+
+		cr := withLastEvent(newTracingTestApplication(corev1.PodSucceeded, finished, true), lineage.EventTypeStart)
+		res, updated, err := reconcile(tt, cr, emitter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		assert.Equal(tt, "COMPLETE", updated.Annotations[lineageEventAnnotation])
+	})
+
+	t.Run("whenFailedWithoutStart", func(tt *testing.T) {
+		finished := now.Add(-time.Minute)
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		gomock.InOrder(
+			expectEmit(tt, emitter, lineage.EventTypeStart, finished.Add(-time.Hour)),
+			expectEmit(tt, emitter, lineage.EventTypeFail, finished),
+		)
+
+		_, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodFailed, finished, true), emitter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "FAIL", updated.Annotations[lineageEventAnnotation])
+	})
+
+	t.Run("whenFinishedAndWaitingForSparkApi", func(tt *testing.T) {
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		emitter.EXPECT().Emit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		cr := withLastEvent(newTracingTestApplication(corev1.PodSucceeded, now.Add(-time.Minute), false), lineage.EventTypeStart)
+		res, updated, err := reconcile(tt, cr, emitter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, finishedApplicationGracePeriod-time.Minute, res.RequeueAfter)
+		assert.Equal(tt, "START", updated.Annotations[lineageEventAnnotation])
+	})
+
+	t.Run("whenGracePeriodPassed", func(tt *testing.T) {
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		emitter.EXPECT().Emit(gomock.Any(), gomock.Any(), lineage.EventTypeComplete, gomock.Any()).Return(nil).Times(1)
+
+		cr := withLastEvent(newTracingTestApplication(corev1.PodSucceeded, now.Add(-finishedApplicationGracePeriod), false), lineage.EventTypeStart)
+		_, updated, err := reconcile(tt, cr, emitter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "COMPLETE", updated.Annotations[lineageEventAnnotation])
+	})
+
+	t.Run("whenAlreadyCompleted", func(tt *testing.T) {
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		emitter.EXPECT().Emit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		cr := withLastEvent(newTracingTestApplication(corev1.PodSucceeded, now.Add(-time.Minute), true), lineage.EventTypeComplete)
+		_, _, err := reconcile(tt, cr, emitter)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("whenTooOld", func(tt *testing.T) {
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		emitter.EXPECT().Emit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodSucceeded, now.Add(-2*finishedApplicationMaxAge), true), emitter)
+		assert.NoError(tt, err)
+		assert.Empty(tt, updated.Annotations[lineageEventAnnotation])
+	})
+
+	t.Run("whenEmitFails", func(tt *testing.T) {
+		emitter := mock_lineage.NewMockEmitter(ctrl)
+		emitter.EXPECT().Emit(gomock.Any(), gomock.Any(), lineage.EventTypeStart, gomock.Any()).Return(fmt.Errorf("test error")).Times(1)
+
+		_, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodSucceeded, now.Add(-time.Minute), true), emitter)
+		assert.Error(tt, err)
+		assert.Empty(tt, updated.Annotations[lineageEventAnnotation])
+	})
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/notifications"
+)
+
+// notificationsSentAnnotation holds the comma separated keys of the notifications sent for the application
+const notificationsSentAnnotation = "wave.spot.io/notifications-sent"
+
+// SparkApplicationNotificationReconciler sends webhook notifications when a Spark application succeeds, fails or
+// runs longer than a threshold. Each notification is sent once, failed notifications are retried on later reconciles
+// with the controller's rate limiting backoff.
+type SparkApplicationNotificationReconciler struct {
+	client.Client
+	router       *notifications.Router
+	sender       notifications.Sender
+	timeProvider func() time.Time
+	Log          logr.Logger
+}
+
+func NewSparkApplicationNotificationReconciler(
+	client client.Client,
+	router *notifications.Router,
+	sender notifications.Sender,
+	timeProvider func() time.Time,
+	log logr.Logger) *SparkApplicationNotificationReconciler {
+
+	return &SparkApplicationNotificationReconciler{
+		Client:       client,
+		router:       router,
+		sender:       sender,
+		timeProvider: timeProvider,
+		Log:          log,
+	}
+}
+
+func (r *SparkApplicationNotificationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("sparkapplication", req.NamespacedName)
+
+	cr := &v1alpha1.SparkApplication{}
+	err := r.Get(ctx, req.NamespacedName, cr)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "cannot get spark application")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	now := r.timeProvider()
+	if isApplicationFinished(cr) {
+		if _, ok := getRecentFinishTime(cr, now); !ok {
+			return ctrl.Result{}, nil
+		}
+	}
+
+	due, next := r.router.Route(cr, now)
+
+	sent := getSentNotifications(cr)
+	newlySent := make([]string, 0)
+	var sendErr error
+	for _, n := range due {
+		if sent[n.Key()] {
+			continue
+		}
+		err := r.sender.Send(ctx, n)
+		if err != nil {
+			log.Error(err, "could not send notification", "rule", n.Rule, "event", n.Event)
+			sendErr = err
+			continue
+		}
+		log.Info("Sent notification", "rule", n.Rule, "event", n.Event)
+		newlySent = append(newlySent, n.Key())
+	}
+
+	if len(newlySent) > 0 {
+		for _, key := range newlySent {
+			sent[key] = true
+		}
+		deepCopy := cr.DeepCopy()
+		if deepCopy.Annotations == nil {
+			deepCopy.Annotations = make(map[string]string)
+		}
+		deepCopy.Annotations[notificationsSentAnnotation] = formatSentNotifications(sent)
+		err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(cr))
+		if err != nil {
+			log.Error(err, "could not mark notifications sent")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if sendErr != nil {
+		// Requeue with the rate limiter's exponential backoff to retry the failed notifications,
+		// the sender posts each notification once so the reconcile does not wait between attempts
+		return ctrl.Result{}, sendErr
+	}
+
+	return ctrl.Result{RequeueAfter: next}, nil
+}
+
+func (r *SparkApplicationNotificationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sparkapplication-notification").
+		For(&v1alpha1.SparkApplication{}).
+		Complete(r)
+}
+
+func getSentNotifications(cr *v1alpha1.SparkApplication) map[string]bool {
+	sent := make(map[string]bool)
+	for _, key := range strings.Split(cr.Annotations[notificationsSentAnnotation], ",") {
+		if key != "" {
+			sent[key] = true
+		}
+	}
+	return sent
+}
+
+func formatSentNotifications(sent map[string]bool) string {
+	keys := make([]string, 0, len(sent))
+	for key := range sent {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlrt "sigs.k8s.io/controller-runtime"
+	ctrlrt_fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/notifications"
+	"github.com/spotinst/wave-operator/internal/notifications/mock_notifications"
+)
+
+func TestSparkApplicationNotificationReconciler(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	req := ctrlrt.Request{NamespacedName: types.NamespacedName{Namespace: "spark-jobs", Name: "spark-123"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	router, err := notifications.NewRouter(config.Notifications{Rules: []config.NotificationRule{
+		{
+			Name:    "chat",
+			Events:  []config.NotificationEvent{config.NotificationEventSucceeded, config.NotificationEventFailed},
+			Webhook: config.Webhook{URL: "http://chat"},
+		},
+		{
+			Name:    "pager",
+			Events:  []config.NotificationEvent{config.NotificationEventFailed},
+			Webhook: config.Webhook{URL: "http://pager"},
+		},
+		{
+			Name:                 "slow",
+			Events:               []config.NotificationEvent{config.NotificationEventLongRunning},
+			LongRunningThreshold: 90 * time.Minute,
+			Webhook:              config.Webhook{URL: "http://chat"},
+		},
+	}})
+	require.NoError(t, err)
+
+	reconcile := func(tt *testing.T, cr *v1alpha1.SparkApplication, sender *mock_notifications.MockSender) (ctrlrt.Result, *v1alpha1.SparkApplication, error) {
+		ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, cr)
+		controller := NewSparkApplicationNotificationReconciler(ctrlClient, router, sender, func() time.Time { return now }, getTestLogger())
+		res, err := controller.Reconcile(ctx, req)
+
+		updated := &v1alpha1.SparkApplication{}
+		require.NoError(tt, ctrlClient.Get(ctx, req.NamespacedName, updated))
+		return res, updated, err
+	}
+
+	expectSend := func(sender *mock_notifications.MockSender, key string, err error) *gomock.Call {
+		return sender.EXPECT().Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, n *notifications.Notification) error {
+				assert.Equal(t, key, n.Key())
+				return err
+			})
+	}
+
+	t.Run("whenRunningBelowThreshold", func(tt *testing.T) {
+		sender := mock_notifications.NewMockSender(ctrl)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+
+		res, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodRunning, now.Add(30*time.Minute), false), sender)
+		assert.NoError(tt, err)
+		assert.Equal(tt, 60*time.Minute, res.RequeueAfter)
+		assert.Empty(tt, updated.Annotations[notificationsSentAnnotation])
+	})
+
+	t.Run("whenRunningLong", func(tt *testing.T) {
+		sender := mock_notifications.NewMockSender(ctrl)
+		expectSend(sender, "LongRunning/slow", nil).Times(1)
+
+		res, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodRunning, now.Add(-time.Hour), false), sender)
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		assert.Equal(tt, "LongRunning/slow", updated.Annotations[notificationsSentAnnotation])
+	})
+
+	t.Run("whenFailed", func(tt *testing.T) {
+		sender := mock_notifications.NewMockSender(ctrl)
+		gomock.InOrder(
+			expectSend(sender, "Failed/chat", nil),
+			expectSend(sender, "Failed/pager", nil),
+		)
+
+		cr := newTracingTestApplication(corev1.PodFailed, now.Add(-time.Minute), false)
+		cr.Annotations = map[string]string{notificationsSentAnnotation: "LongRunning/slow"}
+		_, updated, err := reconcile(tt, cr, sender)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "Failed/chat,Failed/pager,LongRunning/slow", updated.Annotations[notificationsSentAnnotation])
+	})
+
+	t.Run("whenAlreadySent", func(tt *testing.T) {
+		sender := mock_notifications.NewMockSender(ctrl)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+
+		cr := newTracingTestApplication(corev1.PodSucceeded, now.Add(-time.Minute), true)
+		cr.Annotations = map[string]string{notificationsSentAnnotation: "Succeeded/chat"}
+		_, _, err := reconcile(tt, cr, sender)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("whenSendFails", func(tt *testing.T) {
+		sender := mock_notifications.NewMockSender(ctrl)
+		gomock.InOrder(
+			expectSend(sender, "Failed/chat", fmt.Errorf("test error")),
+			expectSend(sender, "Failed/pager", nil),
+		)
+
+		_, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodFailed, now.Add(-time.Minute), false), sender)
+		assert.Error(tt, err)
+		assert.Equal(tt, "Failed/pager", updated.Annotations[notificationsSentAnnotation])
+	})
+
+	t.Run("whenTooOld", func(tt *testing.T) {
+		sender := mock_notifications.NewMockSender(ctrl)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+
+		_, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodFailed, now.Add(-2*finishedApplicationMaxAge), false), sender)
+		assert.NoError(tt, err)
+		assert.Empty(tt, updated.Annotations[notificationsSentAnnotation])
+	})
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/tracing"
+)
+
+const traceExportedAnnotation = "wave.spot.io/trace-exported"
+
+// SparkApplicationTraceReconciler exports each finished Spark application as a trace, once
+type SparkApplicationTraceReconciler struct {
+	client.Client
+	exporter     tracing.Exporter
+	timeProvider func() time.Time
+	Log          logr.Logger
+}
+
+func NewSparkApplicationTraceReconciler(
+	client client.Client,
+	exporter tracing.Exporter,
+	timeProvider func() time.Time,
+	log logr.Logger) *SparkApplicationTraceReconciler {
+
+	return &SparkApplicationTraceReconciler{
+		Client:       client,
+		exporter:     exporter,
+		timeProvider: timeProvider,
+		Log:          log,
+	}
+}
+
+func (r *SparkApplicationTraceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("sparkapplication", req.NamespacedName)
+
+	cr := &v1alpha1.SparkApplication{}
+	err := r.Get(ctx, req.NamespacedName, cr)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "cannot get spark application")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if cr.Annotations[traceExportedAnnotation] != "" {
+		return ctrl.Result{}, nil
+	}
+
+	if !isApplicationFinished(cr) {
+		return ctrl.Result{}, nil
+	}
+
+	now := r.timeProvider()
+	finished, ok := getRecentFinishTime(cr, now)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	if wait := getApplicationDataWait(cr, finished, now); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	err = r.exporter.Export(ctx, cr)
+	if err != nil {
+		log.Error(err, "could not export trace")
+		return ctrl.Result{}, err
+	}
+
+	deepCopy := cr.DeepCopy()
+	if deepCopy.Annotations == nil {
+		deepCopy.Annotations = make(map[string]string)
+	}
+	deepCopy.Annotations[traceExportedAnnotation] = now.UTC().Format(time.RFC3339)
+	err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(cr))
+	if err != nil {
+		log.Error(err, "could not mark trace exported")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Exported trace")
+	return ctrl.Result{}, nil
+}
+
+func (r *SparkApplicationTraceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sparkapplication-tracing").
+		For(&v1alpha1.SparkApplication{}).
+		Complete(r)
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlrt "sigs.k8s.io/controller-runtime"
+	ctrlrt_fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/tracing/mock_tracing"
+)
+
+func newTracingTestApplication(phase corev1.PodPhase, finished time.Time, completed bool) *v1alpha1.SparkApplication {
+	cr := &v1alpha1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-123",
+			Namespace: "spark-jobs",
+		},
+	}
+	cr.Status.Data.Driver.Phase = phase
+	cr.Status.Data.Driver.StateHistory = []v1alpha1.PodStateHistoryEntry{
+		{Timestamp: metav1.NewTime(finished.Add(-time.Hour)), Phase: corev1.PodRunning},
+	}
+	if phase == corev1.PodSucceeded || phase == corev1.PodFailed {
+		cr.Status.Data.Driver.StateHistory = append(cr.Status.Data.Driver.StateHistory,
+			v1alpha1.PodStateHistoryEntry{Timestamp: metav1.NewTime(finished), Phase: phase})
+	}
+	cr.Status.Data.RunStatistics.Attempts = []v1alpha1.Attempt{{Completed: completed}}
+	return cr
+}
+
+func TestSparkApplicationTraceReconciler(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	req := ctrlrt.Request{NamespacedName: types.NamespacedName{Namespace: "spark-jobs", Name: "spark-123"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reconcile := func(tt *testing.T, cr *v1alpha1.SparkApplication, exporter *mock_tracing.MockExporter) (ctrlrt.Result, *v1alpha1.SparkApplication, error) {
+		ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, cr)
+		controller := NewSparkApplicationTraceReconciler(ctrlClient, exporter, func() time.Time { return now }, getTestLogger())
+		res, err := controller.Reconcile(ctx, req)
+
+		updated := &v1alpha1.SparkApplication{}
+		require.NoError(tt, ctrlClient.Get(ctx, req.NamespacedName, updated))
+		return res, updated, err
+	}
+
+	t.Run("whenRunning", func(tt *testing.T) {
+		exporter := mock_tracing.NewMockExporter(ctrl)
+		exporter.EXPECT().Export(gomock.Any(), gomock.Any()).Times(0)
+
+		res, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodRunning, now, false), exporter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		assert.Empty(tt, updated.Annotations[traceExportedAnnotation])
+	})
+
+	t.Run("whenFinishedAndCompleted", func(tt *testing.T) {
+		exporter := mock_tracing.NewMockExporter(ctrl)
+		exporter.EXPECT().Export(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		res, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodSucceeded, now.Add(-time.Minute), true), exporter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, ctrlrt.Result{}, res)
+		assert.Equal(tt, "2021-03-01T12:00:00Z", updated.Annotations[traceExportedAnnotation])
+	})
+
+	t.Run("whenFinishedAndWaitingForSparkApi", func(tt *testing.T) {
+		exporter := mock_tracing.NewMockExporter(ctrl)
+		exporter.EXPECT().Export(gomock.Any(), gomock.Any()).Times(0)
+
+		res, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodFailed, now.Add(-time.Minute), false), exporter)
+		assert.NoError(tt, err)
+		assert.Equal(tt, finishedApplicationGracePeriod-time.Minute, res.RequeueAfter)
+		assert.Empty(tt, updated.Annotations[traceExportedAnnotation])
+	})
+
+	t.Run("whenGracePeriodPassed", func(tt *testing.T) {
+		exporter := mock_tracing.NewMockExporter(ctrl)
+		exporter.EXPECT().Export(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		_, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodFailed, now.Add(-finishedApplicationGracePeriod), false), exporter)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, updated.Annotations[traceExportedAnnotation])
+	})
+
+	t.Run("whenAlreadyExported", func(tt *testing.T) {
+		exporter := mock_tracing.NewMockExporter(ctrl)
+		exporter.EXPECT().Export(gomock.Any(), gomock.Any()).Times(0)
+
+		cr := newTracingTestApplication(corev1.PodSucceeded, now.Add(-time.Minute), true)
+		cr.Annotations = map[string]string{traceExportedAnnotation: "2021-03-01T11:59:00Z"}
+		_, _, err := reconcile(tt, cr, exporter)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("whenTooOld", func(tt *testing.T) {
+		exporter := mock_tracing.NewMockExporter(ctrl)
+		exporter.EXPECT().Export(gomock.Any(), gomock.Any()).Times(0)
+
+		_, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodSucceeded, now.Add(-2*finishedApplicationMaxAge), true), exporter)
+		assert.NoError(tt, err)
+		assert.Empty(tt, updated.Annotations[traceExportedAnnotation])
+	})
+
+	t.Run("whenExportFails", func(tt *testing.T) {
+		exporter := mock_tracing.NewMockExporter(ctrl)
+		exporter.EXPECT().Export(gomock.Any(), gomock.Any()).Return(fmt.Errorf("test error")).Times(1)
+
+		_, updated, err := reconcile(tt, newTracingTestApplication(corev1.PodSucceeded, now.Add(-time.Minute), true), exporter)
+		assert.Error(tt, err)
+		assert.Empty(tt, updated.Annotations[traceExportedAnnotation])
+	})
+}
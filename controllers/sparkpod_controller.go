@@ -19,6 +19,7 @@ import (
 	"github.com/spotinst/wave-operator/internal/config"
 	"github.com/spotinst/wave-operator/internal/sparkapi"
 	"github.com/spotinst/wave-operator/internal/storagesync"
+	sparkoperator "github.com/spotinst/wave-operator/sparkoperator.k8s.io/v1beta2"
 )
 
 const (
@@ -41,19 +42,35 @@ const (
 	sparkOperatorAppNameLabel   = "sparkoperator.k8s.io/app-name"
 
 	stageMetricsAggregationAnnotation = "wave.spot.io/stageMetricsAggregation"
-	workloadTypeAnnotation            = "wave.spot.io/workloadType"
+
+	nodeLifecycleLabel       = "spotinst.io/node-lifecycle"
+	nodeInstanceTypeLabel    = "node.kubernetes.io/instance-type"
+	instanceLifecycleUnknown = "unknown"
 
 	requeueAfterTimeout                  = 10 * time.Second
 	podDeletionTimeout                   = 5 * time.Minute
 	maxSparkApiCommunicationAttemptCount = 20
 )
 
+// durationAnnotations configure the duration limits of an application, they are copied to the SparkApplication
+var durationAnnotations = []string{
+	config.WaveConfigAnnotationExpectedDuration,
+	config.WaveConfigAnnotationMaxDuration,
+	config.WaveConfigAnnotationTerminateOnMaxDuration,
+}
+
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/proxy;services/proxy,verbs=get
 // +kubebuilder:rbac:groups=wave.spot.io,resources=sparkapplications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sparkoperator.k8s.io,resources=sparkapplications,verbs=get
 
 // SparkPodReconciler reconciles Pod objects to discover Spark applications
 type SparkPodReconciler struct {
 	client.Client
+	// apiReader reads uncached, for objects the operator may get but not watch
+	apiReader              client.Reader
 	ClientSet              kubernetes.Interface
 	getSparkApiManager     SparkApiManagerGetter
 	Log                    logr.Logger
@@ -63,6 +80,7 @@ type SparkPodReconciler struct {
 
 func NewSparkPodReconciler(
 	client client.Client,
+	apiReader client.Reader,
 	clientSet kubernetes.Interface,
 	sparkApiManagerGetter SparkApiManagerGetter,
 	log logr.Logger,
@@ -70,6 +88,7 @@ func NewSparkPodReconciler(
 
 	return &SparkPodReconciler{
 		Client:                 client,
+		apiReader:              apiReader,
 		ClientSet:              clientSet,
 		getSparkApiManager:     sparkApiManagerGetter,
 		Log:                    log,
@@ -79,7 +98,7 @@ func NewSparkPodReconciler(
 }
 
 // SparkApiManagerGetter is a factory function that returns an implementation of sparkapi.Manager
-type SparkApiManagerGetter func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapi.Manager, error)
+type SparkApiManagerGetter func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (sparkapi.Manager, error)
 
 func (r *SparkPodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("pod", req.NamespacedName)
@@ -235,6 +254,9 @@ func (r *SparkPodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			} else if sparkapi.IsApiNotAvailableError(err) {
 				// Spark API is not available, don't want to requeue
 				log.Info(fmt.Sprintf("Spark API not available: %s", err.Error()))
+			} else if k8serrors.IsConflict(err) {
+				log.Info(fmt.Sprintf("could not update spark application cr, conflict error: %s", err.Error()))
+				return ctrl.Result{}, err
 			} else {
 				log.Error(err, "error handling driver pod")
 				return ctrl.Result{}, err
@@ -254,7 +276,11 @@ func (r *SparkPodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	case ExecutorRole:
 		err = r.handleExecutor(ctx, p, cr, log)
 		if err != nil {
-			log.Error(err, "error handling executor pod")
+			if k8serrors.IsConflict(err) {
+				log.Info(fmt.Sprintf("could not update spark application cr, conflict error: %s", err.Error()))
+			} else {
+				log.Error(err, "error handling executor pod")
+			}
 			return ctrl.Result{}, err
 		}
 	default:
@@ -301,12 +327,13 @@ func (r *SparkPodReconciler) handleDriver(ctx context.Context, pod *corev1.Pod,
 	deepCopy := cr.DeepCopy()
 
 	updatedDriverPodCR := newPodCR(pod, &deepCopy.Status.Data.Driver, log)
+	r.setInstanceLifecycle(ctx, &updatedDriverPodCR, log)
 	deepCopy.Status.Data.Driver = updatedDriverPodCR
 
 	// Fetch information from Spark API
 	// Let's update the driver pod information even though the Spark API call fails
 	var sparkApiError error
-	sparkApiApplicationInfo, err := r.getSparkApiApplicationInfo(r.ClientSet, pod, cr.Spec.ApplicationID, log)
+	sparkApiApplicationInfo, err := r.getSparkApiApplicationInfo(r.ClientSet, pod, cr, log)
 	if err != nil {
 		sparkApiError = fmt.Errorf("could not get spark api application information, %w", err)
 	} else {
@@ -324,9 +351,9 @@ func (r *SparkPodReconciler) handleDriver(ctx context.Context, pod *corev1.Pod,
 
 	deepCopy.Annotations[config.WaveConfigAnnotationApplicationName] = sparkApplicationName
 
-	err = r.Client.Patch(ctx, deepCopy, client.MergeFrom(cr))
+	err = r.patchSparkApplication(ctx, deepCopy, cr)
 	if err != nil {
-		return fmt.Errorf("patch error, %w", err)
+		return err
 	}
 
 	if sparkApiError != nil {
@@ -446,23 +473,37 @@ func (r *SparkPodReconciler) handleExecutor(ctx context.Context, pod *corev1.Pod
 	if foundIDx == -1 {
 		// Create new executor entry
 		newExecutor := newPodCR(pod, nil, log)
+		r.setInstanceLifecycle(ctx, &newExecutor, log)
 		newExecutors := append(deepCopy.Status.Data.Executors, newExecutor)
 		deepCopy.Status.Data.Executors = newExecutors
 	} else {
 		// Update existing executor entry
 		existingExecutor := &deepCopy.Status.Data.Executors[foundIDx]
 		updatedExecutor := newPodCR(pod, existingExecutor, log)
+		r.setInstanceLifecycle(ctx, &updatedExecutor, log)
 		deepCopy.Status.Data.Executors[foundIDx] = updatedExecutor
 	}
 
-	err := r.Client.Patch(ctx, deepCopy, client.MergeFrom(cr))
+	err := r.patchSparkApplication(ctx, deepCopy, cr)
 	if err != nil {
-		return fmt.Errorf("patch error, %w", err)
+		return err
 	}
 
 	return nil
 }
 
+// patchSparkApplication patches the Spark application CR, counting patches rejected with a conflict
+func (r *SparkPodReconciler) patchSparkApplication(ctx context.Context, updated *v1alpha1.SparkApplication, original *v1alpha1.SparkApplication) error {
+	err := r.Client.Patch(ctx, updated, client.MergeFrom(original))
+	if err != nil {
+		if k8serrors.IsConflict(err) {
+			sparkApplicationPatchConflicts.Inc()
+		}
+		return fmt.Errorf("patch error, %w", err)
+	}
+	return nil
+}
+
 func newPodCR(pod *corev1.Pod, existingPodCR *v1alpha1.Pod, log logr.Logger) v1alpha1.Pod {
 	podCR := v1alpha1.Pod{}
 
@@ -475,6 +516,12 @@ func newPodCR(pod *corev1.Pod, existingPodCR *v1alpha1.Pod, log logr.Logger) v1a
 	podCR.DeletionTimestamp = pod.DeletionTimestamp
 	podCR.Labels = pod.Labels
 	podCR.StateHistory = getUpdatedPodStateHistory(pod, existingPodCR, log)
+	podCR.NodeName = pod.Spec.NodeName
+
+	if existingPodCR != nil && existingPodCR.NodeName == podCR.NodeName {
+		podCR.InstanceLifecycle = existingPodCR.InstanceLifecycle
+		podCR.InstanceType = existingPodCR.InstanceType
+	}
 
 	if podCR.Statuses == nil {
 		podCR.Statuses = make([]corev1.ContainerStatus, 0)
@@ -487,6 +534,33 @@ func newPodCR(pod *corev1.Pod, existingPodCR *v1alpha1.Pod, log logr.Logger) v1a
 	return podCR
 }
 
+// setInstanceLifecycle records the lifecycle and instance type of the node the pod is scheduled on, once per node.
+// The lifecycle is unknown if the node is not managed by Ocean.
+func (r *SparkPodReconciler) setInstanceLifecycle(ctx context.Context, podCR *v1alpha1.Pod, log logr.Logger) {
+	if podCR.NodeName == "" || podCR.InstanceLifecycle != "" {
+		return
+	}
+
+	node, err := r.ClientSet.CoreV1().Nodes().Get(ctx, podCR.NodeName, v1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			podCR.InstanceLifecycle = instanceLifecycleUnknown
+		} else {
+			log.Error(err, "could not get node", "node", podCR.NodeName)
+		}
+		return
+	}
+
+	podCR.InstanceType = node.Labels[nodeInstanceTypeLabel]
+
+	switch lifecycle := config.InstanceLifecycle(node.Labels[nodeLifecycleLabel]); lifecycle {
+	case config.InstanceLifecycleOnDemand, config.InstanceLifecycleSpot:
+		podCR.InstanceLifecycle = string(lifecycle)
+	default:
+		podCR.InstanceLifecycle = instanceLifecycleUnknown
+	}
+}
+
 func getUpdatedPodStateHistory(pod *corev1.Pod, existingPodCR *v1alpha1.Pod, log logr.Logger) []v1alpha1.PodStateHistoryEntry {
 	var stateHistory []v1alpha1.PodStateHistoryEntry
 
@@ -569,14 +643,14 @@ func podStateHistoryEntryEqual(a v1alpha1.PodStateHistoryEntry, b v1alpha1.PodSt
 	return true
 }
 
-func (r *SparkPodReconciler) getSparkApiApplicationInfo(clientSet kubernetes.Interface, driverPod *corev1.Pod, applicationID string, logger logr.Logger) (*sparkapi.ApplicationInfo, error) {
+func (r *SparkPodReconciler) getSparkApiApplicationInfo(clientSet kubernetes.Interface, driverPod *corev1.Pod, cr *v1alpha1.SparkApplication, logger logr.Logger) (*sparkapi.ApplicationInfo, error) {
 
-	manager, err := r.getSparkApiManager(clientSet, driverPod, logger)
+	manager, err := r.getSparkApiManager(clientSet, driverPod, cr, logger)
 	if err != nil {
 		return nil, fmt.Errorf("could not get spark api manager, %w", err)
 	}
 
-	applicationInfo, err := manager.GetApplicationInfo(applicationID)
+	applicationInfo, err := manager.GetApplicationInfo(cr.Spec.ApplicationID)
 	if err != nil {
 		return nil, fmt.Errorf("could not get spark api application info, %w", err)
 	}
@@ -586,6 +660,7 @@ func (r *SparkPodReconciler) getSparkApiApplicationInfo(clientSet kubernetes.Int
 
 func setSparkApiApplicationInfo(deepCopy *v1alpha1.SparkApplication, sparkApiInfo *sparkapi.ApplicationInfo) {
 	deepCopy.Status.Data.SparkProperties = sparkApiInfo.SparkProperties
+	deepCopy.Status.Data.Environment = sparkApiInfo.Environment
 
 	attempts := make([]v1alpha1.Attempt, 0, len(sparkApiInfo.Attempts))
 	for _, apiAttempt := range sparkApiInfo.Attempts {
@@ -624,7 +699,10 @@ func setSparkApiApplicationInfo(deepCopy *v1alpha1.SparkApplication, sparkApiInf
 			TotalShuffleRead:  apiExecutor.TotalShuffleRead,
 			TotalShuffleWrite: apiExecutor.TotalShuffleWrite,
 			IsBlacklisted:     apiExecutor.IsBlacklisted,
+			IsExcluded:        apiExecutor.IsExcluded,
 			MaxMemory:         apiExecutor.MaxMemory,
+			ResourceProfileID: int64(apiExecutor.ResourceProfileID),
+			Resources:         newExecutorResources(apiExecutor.Resources),
 			MemoryMetrics: v1alpha1.ExecutorMemoryMetrics{
 				UsedOnHeapStorageMemory:   apiExecutor.MemoryMetrics.UsedOnHeapStorageMemory,
 				UsedOffHeapStorageMemory:  apiExecutor.MemoryMetrics.UsedOffHeapStorageMemory,
@@ -637,11 +715,49 @@ func setSparkApiApplicationInfo(deepCopy *v1alpha1.SparkApplication, sparkApiInf
 
 	deepCopy.Status.Data.RunStatistics.Executors = executors
 
+	deepCopy.Status.Data.RunStatistics.Jobs = newJobStatistics(sparkApiInfo.Jobs)
+	deepCopy.Status.Data.RunStatistics.SQLExecutions = newSQLStatistics(sparkApiInfo.SQLExecutions)
+	deepCopy.Status.Data.Lineage = sparkApiInfo.Lineage
+	deepCopy.Status.Data.Insights = sparkApiInfo.Insights
+
 	if sparkApiInfo.WorkloadType != "" {
 		setWorkloadType(deepCopy, sparkApiInfo.WorkloadType)
 	}
 }
 
+// getDurationAnnotations returns the duration limit annotations of the driver pod or,
+// if the driver pod has none, of the spark-operator SparkApplication that launched it
+func (r *SparkPodReconciler) getDurationAnnotations(ctx context.Context, driverPod *corev1.Pod, log logr.Logger) map[string]string {
+	annotations := getAnnotations(driverPod.Annotations, durationAnnotations)
+	if len(annotations) > 0 {
+		return annotations
+	}
+
+	operatorAppName := driverPod.Labels[sparkOperatorAppNameLabel]
+	if operatorAppName == "" {
+		return annotations
+	}
+	// Read uncached, the operator is only allowed to get spark-operator applications, a cached read would
+	// start an informer that never syncs
+	operatorApp := &sparkoperator.SparkApplication{}
+	err := r.apiReader.Get(ctx, ctrlclient.ObjectKey{Namespace: driverPod.Namespace, Name: operatorAppName}, operatorApp)
+	if err != nil {
+		log.Info(fmt.Sprintf("Could not get spark-operator application %q, %s", operatorAppName, err.Error()))
+		return annotations
+	}
+	return getAnnotations(operatorApp.Annotations, durationAnnotations)
+}
+
+func getAnnotations(annotations map[string]string, keys []string) map[string]string {
+	res := make(map[string]string)
+	for _, key := range keys {
+		if value := annotations[key]; value != "" {
+			res[key] = value
+		}
+	}
+	return res
+}
+
 func getSparkApplicationName(driverPod *corev1.Pod, sparkApiInfo *sparkapi.ApplicationInfo) string {
 	var sparkApplicationName string
 
@@ -714,6 +830,16 @@ func (r *SparkPodReconciler) createNewSparkApplicationCR(ctx context.Context, dr
 	//set "wave.spot.io/application-name" annotation as an application name
 	cr.Annotations[config.WaveConfigAnnotationApplicationName] = sparkApplicationName
 
+	//the trace context of the workflow that submitted the application, if any
+	if traceParent := driverPod.Annotations[config.WaveConfigAnnotationTraceParent]; traceParent != "" {
+		cr.Annotations[config.WaveConfigAnnotationTraceParent] = traceParent
+	}
+
+	//the duration limits of the application
+	for key, value := range r.getDurationAnnotations(ctx, driverPod, log) {
+		cr.Annotations[key] = value
+	}
+
 	//set "wave.spot.io/wave-application-id" label
 	waveApplicationId := getWaveApplicationId(driverPod)
 
@@ -746,5 +872,5 @@ func setWorkloadType(cr *v1alpha1.SparkApplication, workloadType sparkapi.Worklo
 	if cr.Annotations == nil {
 		cr.Annotations = make(map[string]string)
 	}
-	cr.Annotations[workloadTypeAnnotation] = string(workloadType)
+	cr.Annotations[config.WaveConfigAnnotationWorkloadType] = string(workloadType)
 }
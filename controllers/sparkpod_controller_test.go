@@ -8,11 +8,14 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
@@ -29,11 +32,13 @@ import (
 	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
 	"github.com/spotinst/wave-operator/internal/sparkapi/mock_sparkapi"
 	"github.com/spotinst/wave-operator/internal/version"
+	sparkoperator "github.com/spotinst/wave-operator/sparkoperator.k8s.io/v1beta2"
 )
 
 func init() {
 	_ = clientgoscheme.AddToScheme(testScheme)
 	_ = v1alpha1.AddToScheme(testScheme)
+	_ = sparkoperator.AddToScheme(testScheme)
 	_ = apiextensions.AddToScheme(testScheme)
 
 	version.BuildVersion = "v0.0.0-test"
@@ -56,7 +61,7 @@ func TestReconcile_sparkAppIDMissing(t *testing.T) {
 	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, pod)
 	clientSet := k8sfake.NewSimpleClientset()
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, nil, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, nil, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -92,7 +97,7 @@ func TestReconcile_unknownRole(t *testing.T) {
 	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, pod)
 	clientSet := k8sfake.NewSimpleClientset()
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, nil, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, nil, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -132,11 +137,11 @@ func TestReconcile_driver_garbageCollectionEvent(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	m := mock_sparkapi.NewMockManager(ctrl)
-	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapi.Manager, error) {
+	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (sparkapi.Manager, error) {
 		return m, nil
 	}
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -187,11 +192,11 @@ func TestReconcile_driver_whenSparkApiCommunicationFails(t *testing.T) {
 	m := mock_sparkapi.NewMockManager(ctrl)
 	m.EXPECT().GetApplicationInfo(sparkAppID).Return(nil, fmt.Errorf("test error")).Times(1)
 
-	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapi.Manager, error) {
+	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (sparkapi.Manager, error) {
 		return m, nil
 	}
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -229,11 +234,11 @@ func TestReconcile_driver_whenSparkApiError(t *testing.T) {
 	defer ctrl.Finish()
 	m := mock_sparkapi.NewMockManager(ctrl)
 
-	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapi.Manager, error) {
+	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (sparkapi.Manager, error) {
 		return m, nil
 	}
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
 
 	testReconcile := func(podPhase corev1.PodPhase, sparkApiError error) (ctrlrt.Result, error) {
 
@@ -378,11 +383,11 @@ func TestReconcile_driver_whenSuccessful(t *testing.T) {
 	m := mock_sparkapi.NewMockManager(ctrl)
 	m.EXPECT().GetApplicationInfo(sparkAppID).Return(getTestApplicationInfo(), nil).Times(1)
 
-	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapi.Manager, error) {
+	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (sparkapi.Manager, error) {
 		return m, nil
 	}
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -440,9 +445,12 @@ func TestReconcile_driver_whenSuccessful(t *testing.T) {
 	assert.Equal(t, getTestApplicationInfo().ApplicationName, createdCR.Spec.ApplicationName)
 	assert.Equal(t, sparkAppID, createdCR.Spec.ApplicationID)
 	assert.Equal(t, getTestApplicationInfo().SparkProperties, createdCR.Status.Data.SparkProperties)
-	assert.Equal(t, string(getTestApplicationInfo().WorkloadType), createdCR.Annotations[workloadTypeAnnotation])
+	assert.Equal(t, string(getTestApplicationInfo().WorkloadType), createdCR.Annotations[config.WaveConfigAnnotationWorkloadType])
 	verifyCRAttempts(t, getTestApplicationInfo().Attempts, createdCR.Status.Data.RunStatistics.Attempts)
 	verifyCRExecutors(t, getTestApplicationInfo().Executors, createdCR.Status.Data.RunStatistics.Executors)
+	assert.Equal(t, getTestApplicationInfo().Insights, createdCR.Status.Data.Insights)
+	assert.Equal(t, getTestApplicationInfo().Lineage, createdCR.Status.Data.Lineage)
+	assert.Equal(t, getTestApplicationInfo().Environment, createdCR.Status.Data.Environment)
 }
 
 func TestReconcile_driver_whenPodDeletionTimeoutPassed(t *testing.T) {
@@ -464,11 +472,11 @@ func TestReconcile_driver_whenPodDeletionTimeoutPassed(t *testing.T) {
 	m := mock_sparkapi.NewMockManager(ctrl)
 	m.EXPECT().GetApplicationInfo(sparkAppID).Return(getTestApplicationInfo(), nil).Times(0)
 
-	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapi.Manager, error) {
+	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (sparkapi.Manager, error) {
 		return m, nil
 	}
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -495,7 +503,7 @@ func TestReconcile_driver_setApplicationName(t *testing.T) {
 	defer ctrl.Finish()
 	m := mock_sparkapi.NewMockManager(ctrl)
 	m.EXPECT().GetApplicationInfo(sparkAppID).Return(getTestApplicationInfo(), nil).AnyTimes()
-	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapi.Manager, error) {
+	var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (sparkapi.Manager, error) {
 		return m, nil
 	}
 
@@ -503,7 +511,7 @@ func TestReconcile_driver_setApplicationName(t *testing.T) {
 
 		ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, pod)
 		clientSet := k8sfake.NewSimpleClientset()
-		controller := NewSparkPodReconciler(ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
+		controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
 
 		req := ctrlrt.Request{
 			NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -584,7 +592,7 @@ func TestReconcile_executor_whenSuccessful(t *testing.T) {
 	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, exec1, exec2, cr)
 	clientSet := k8sfake.NewSimpleClientset()
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, nil, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, nil, getTestLogger(), testScheme)
 
 	// Executor 1
 
@@ -664,7 +672,7 @@ func TestReconcile_executor_whenCRDoesntExist(t *testing.T) {
 	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, pod)
 	clientSet := k8sfake.NewSimpleClientset()
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, nil, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, nil, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -688,7 +696,7 @@ func TestReconcile_finalizer_add(t *testing.T) {
 	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, pod, cr)
 	clientSet := k8sfake.NewSimpleClientset()
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, nil, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, nil, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -718,7 +726,7 @@ func TestReconcile_finalizer_remove(t *testing.T) {
 	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, pod, cr)
 	clientSet := k8sfake.NewSimpleClientset()
 
-	controller := NewSparkPodReconciler(ctrlClient, clientSet, nil, getTestLogger(), testScheme)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, nil, getTestLogger(), testScheme)
 
 	req := ctrlrt.Request{
 		NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -872,11 +880,11 @@ func TestReconcile_ownerReference_add(t *testing.T) {
 			m := mock_sparkapi.NewMockManager(ctrl)
 			m.EXPECT().GetApplicationInfo(sparkAppID).Return(getTestApplicationInfo(), nil).AnyTimes()
 
-			var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapi.Manager, error) {
+			var getMockSparkApiManager SparkApiManagerGetter = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (sparkapi.Manager, error) {
 				return m, nil
 			}
 
-			controller := NewSparkPodReconciler(ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
+			controller := NewSparkPodReconciler(ctrlClient, ctrlClient, clientSet, getMockSparkApiManager, getTestLogger(), testScheme)
 
 			req := ctrlrt.Request{
 				NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
@@ -1526,6 +1534,194 @@ func getTestApplicationInfo() *sparkapi.ApplicationInfo {
 				IsActive:    true,
 			},
 		},
+		Environment: &v1alpha1.RuntimeEnvironment{
+			JavaVersion:  "1.8.0_252 (Oracle Corporation)",
+			SparkVersion: "3.0.0",
+			Jars:         []string{"log4j-1.2.17.jar"},
+		},
+		Insights: []v1alpha1.Insight{
+			{
+				Type:      v1alpha1.InsightTypeHeavyGC,
+				StageID:   4,
+				StageName: "count at Job.scala:10",
+				Message:   "Stage 4: tasks spent 25% of their run time in garbage collection (2m30s of 10m0s)",
+			},
+		},
+		Lineage: &v1alpha1.Lineage{
+			Inputs: []v1alpha1.Dataset{{Namespace: "s3://input-bucket", Name: "events"}},
+		},
 		WorkloadType: "my-workload-type",
 	}
 }
+
+func TestSetInstanceLifecycle(t *testing.T) {
+	ctx := context.TODO()
+
+	newNode := func(name string, lifecycle string) *corev1.Node {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{nodeInstanceTypeLabel: "m5.xlarge"},
+			},
+		}
+		if lifecycle != "" {
+			node.Labels[nodeLifecycleLabel] = lifecycle
+		}
+		return node
+	}
+
+	clientSet := k8sfake.NewSimpleClientset(newNode("spot-node", "spot"), newNode("od-node", "od"), newNode("other-node", ""))
+	controller := NewSparkPodReconciler(ctrlrt_fake.NewFakeClientWithScheme(testScheme), ctrlrt_fake.NewFakeClientWithScheme(testScheme), clientSet, nil, getTestLogger(), testScheme)
+
+	tests := map[string]string{
+		"spot-node":    "spot",
+		"od-node":      "od",
+		"other-node":   instanceLifecycleUnknown,
+		"missing-node": instanceLifecycleUnknown,
+		"":             "",
+	}
+
+	for nodeName, expected := range tests {
+		podCR := &v1alpha1.Pod{NodeName: nodeName}
+		controller.setInstanceLifecycle(ctx, podCR, getTestLogger())
+		assert.Equal(t, expected, podCR.InstanceLifecycle, nodeName)
+		if nodeName != "" && nodeName != "missing-node" {
+			assert.Equal(t, "m5.xlarge", podCR.InstanceType, nodeName)
+		} else {
+			assert.Empty(t, podCR.InstanceType, nodeName)
+		}
+	}
+
+	t.Run("whenAlreadySet", func(tt *testing.T) {
+		podCR := &v1alpha1.Pod{NodeName: "spot-node", InstanceLifecycle: "od"}
+		controller.setInstanceLifecycle(ctx, podCR, getTestLogger())
+		assert.Equal(tt, "od", podCR.InstanceLifecycle)
+	})
+}
+
+func TestPatchSparkApplication(t *testing.T) {
+	ctx := context.TODO()
+
+	cr := &v1alpha1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-123",
+			Namespace: "test-ns",
+		},
+	}
+	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, cr)
+
+	original := &v1alpha1.SparkApplication{}
+	err := ctrlClient.Get(ctx, client.ObjectKeyFromObject(cr), original)
+	require.NoError(t, err)
+
+	t.Run("whenOutdated", func(tt *testing.T) {
+		controller := NewSparkPodReconciler(ctrlClient, ctrlClient, k8sfake.NewSimpleClientset(), nil, getTestLogger(), testScheme)
+		conflicts := testutil.ToFloat64(sparkApplicationPatchConflicts)
+
+		first := original.DeepCopy()
+		first.Spec.ApplicationName = "first"
+		require.NoError(tt, controller.patchSparkApplication(ctx, first, original))
+
+		// merge patches are not rejected when the resource was modified concurrently
+		second := original.DeepCopy()
+		second.Annotations = map[string]string{"second": "true"}
+		require.NoError(tt, controller.patchSparkApplication(ctx, second, original))
+		assert.Equal(tt, conflicts, testutil.ToFloat64(sparkApplicationPatchConflicts))
+
+		patched := &v1alpha1.SparkApplication{}
+		require.NoError(tt, ctrlClient.Get(ctx, client.ObjectKeyFromObject(cr), patched))
+		assert.Equal(tt, "first", patched.Spec.ApplicationName)
+		assert.Equal(tt, "true", patched.Annotations["second"])
+	})
+
+	t.Run("whenConflict", func(tt *testing.T) {
+		conflictClient := &conflictingClient{Client: ctrlClient, conflicts: 1}
+		controller := NewSparkPodReconciler(conflictClient, ctrlClient, k8sfake.NewSimpleClientset(), nil, getTestLogger(), testScheme)
+		conflicts := testutil.ToFloat64(sparkApplicationPatchConflicts)
+
+		updated := original.DeepCopy()
+		updated.Spec.ApplicationName = "third"
+		err := controller.patchSparkApplication(ctx, updated, original)
+		require.Error(tt, err)
+		assert.True(tt, k8serrors.IsConflict(err))
+		assert.Equal(tt, conflicts+1, testutil.ToFloat64(sparkApplicationPatchConflicts))
+	})
+}
+
+func TestCreateNewSparkApplicationCR_traceParent(t *testing.T) {
+	ctx := context.TODO()
+	sparkAppID := "spark-123456"
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	pod := getTestPod("test-ns", "driver-pod", "uid-1", DriverRole, sparkAppID, false)
+	pod.Annotations = map[string]string{config.WaveConfigAnnotationTraceParent: traceParent}
+
+	ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, pod)
+	controller := NewSparkPodReconciler(ctrlClient, ctrlClient, k8sfake.NewSimpleClientset(), nil, getTestLogger(), testScheme)
+
+	err := controller.createNewSparkApplicationCR(ctx, pod, sparkAppID, getTestLogger())
+	require.NoError(t, err)
+
+	createdCR := &v1alpha1.SparkApplication{}
+	err = ctrlClient.Get(ctx, client.ObjectKey{Name: sparkAppID, Namespace: pod.Namespace}, createdCR)
+	require.NoError(t, err)
+	assert.Equal(t, traceParent, createdCR.Annotations[config.WaveConfigAnnotationTraceParent])
+}
+
+func TestCreateNewSparkApplicationCR_durationAnnotations(t *testing.T) {
+	ctx := context.TODO()
+	sparkAppID := "spark-123456"
+
+	create := func(tt *testing.T, pod *corev1.Pod, objects ...runtime.Object) *v1alpha1.SparkApplication {
+		// spark-operator applications are only available to the uncached reader
+		ctrlClient := ctrlrt_fake.NewFakeClientWithScheme(testScheme, pod)
+		apiReader := ctrlrt_fake.NewFakeClientWithScheme(testScheme, objects...)
+		controller := NewSparkPodReconciler(ctrlClient, apiReader, k8sfake.NewSimpleClientset(), nil, getTestLogger(), testScheme)
+
+		err := controller.createNewSparkApplicationCR(ctx, pod, sparkAppID, getTestLogger())
+		require.NoError(tt, err)
+
+		createdCR := &v1alpha1.SparkApplication{}
+		err = ctrlClient.Get(ctx, client.ObjectKey{Name: sparkAppID, Namespace: pod.Namespace}, createdCR)
+		require.NoError(tt, err)
+		return createdCR
+	}
+
+	operatorApp := &sparkoperator.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-operator-app",
+			Namespace: "test-ns",
+			Annotations: map[string]string{
+				config.WaveConfigAnnotationMaxDuration:            "4h",
+				config.WaveConfigAnnotationTerminateOnMaxDuration: "true",
+			},
+		},
+	}
+
+	t.Run("whenDriverPodAnnotated", func(tt *testing.T) {
+		pod := getTestPod("test-ns", "driver-pod", "uid-1", DriverRole, sparkAppID, false)
+		pod.Labels[sparkOperatorAppNameLabel] = "my-operator-app"
+		pod.Annotations = map[string]string{config.WaveConfigAnnotationExpectedDuration: "1h"}
+
+		cr := create(tt, pod, operatorApp)
+		assert.Equal(tt, "1h", cr.Annotations[config.WaveConfigAnnotationExpectedDuration])
+		assert.Empty(tt, cr.Annotations[config.WaveConfigAnnotationMaxDuration])
+	})
+
+	t.Run("whenSparkOperatorApplicationAnnotated", func(tt *testing.T) {
+		pod := getTestPod("test-ns", "driver-pod", "uid-1", DriverRole, sparkAppID, false)
+		pod.Labels[sparkOperatorAppNameLabel] = "my-operator-app"
+
+		cr := create(tt, pod, operatorApp)
+		assert.Equal(tt, "4h", cr.Annotations[config.WaveConfigAnnotationMaxDuration])
+		assert.Equal(tt, "true", cr.Annotations[config.WaveConfigAnnotationTerminateOnMaxDuration])
+	})
+
+	t.Run("whenSparkOperatorApplicationMissing", func(tt *testing.T) {
+		pod := getTestPod("test-ns", "driver-pod", "uid-1", DriverRole, sparkAppID, false)
+		pod.Labels[sparkOperatorAppNameLabel] = "my-operator-app"
+
+		cr := create(tt, pod)
+		assert.Empty(tt, cr.Annotations[config.WaveConfigAnnotationMaxDuration])
+	})
+}
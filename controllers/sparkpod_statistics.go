@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+const (
+	// maxFailedJobs is the number of failed jobs kept in the CR
+	maxFailedJobs = 10
+	// maxSQLExecutions is the number of SQL executions kept in the CR
+	maxSQLExecutions = 20
+	// maxDescriptionLength is the length job and SQL execution descriptions are truncated to
+	maxDescriptionLength = 1024
+)
+
+// newJobStatistics summarizes the application's jobs, returns nil if the application has no jobs.
+// Durations are only set for finished jobs, so the statistics of an application do not change between reconciles
+// while its jobs are running.
+func newJobStatistics(apiJobs []sparkapiclient.Job) *v1alpha1.JobStatistics {
+	if len(apiJobs) == 0 {
+		return nil
+	}
+
+	stats := &v1alpha1.JobStatistics{}
+	failedJobs := make([]v1alpha1.Job, 0)
+
+	for _, apiJob := range apiJobs {
+		job := newJob(apiJob)
+
+		switch apiJob.Status {
+		case sparkapiclient.JobStatusRunning:
+			stats.Running++
+		case sparkapiclient.JobStatusSucceeded:
+			stats.Succeeded++
+		case sparkapiclient.JobStatusFailed:
+			stats.Failed++
+			failedJobs = append(failedJobs, job)
+		default:
+			stats.Unknown++
+		}
+
+		if apiJob.CompletionTime == "" {
+			continue
+		}
+		if stats.Longest == nil || job.Duration > stats.Longest.Duration ||
+			(job.Duration == stats.Longest.Duration && job.JobID < stats.Longest.JobID) {
+			longest := job
+			stats.Longest = &longest
+		}
+	}
+
+	// Keep the most recent failed jobs
+	sort.Slice(failedJobs, func(i, j int) bool {
+		return failedJobs[i].JobID > failedJobs[j].JobID
+	})
+	if len(failedJobs) > maxFailedJobs {
+		failedJobs = failedJobs[:maxFailedJobs]
+	}
+	if len(failedJobs) > 0 {
+		stats.FailedJobs = failedJobs
+	}
+
+	return stats
+}
+
+func newJob(apiJob sparkapiclient.Job) v1alpha1.Job {
+	job := v1alpha1.Job{
+		JobID:          apiJob.JobID,
+		Name:           apiJob.Name,
+		Description:    truncate(apiJob.Description, maxDescriptionLength),
+		JobGroup:       apiJob.JobGroup,
+		Status:         apiJob.Status,
+		SubmissionTime: apiJob.SubmissionTime,
+	}
+
+	if apiJob.CompletionTime == "" {
+		return job
+	}
+
+	submissionTime, err := sparkapiclient.ParseTime(apiJob.SubmissionTime)
+	if err != nil {
+		return job
+	}
+	completionTime, err := sparkapiclient.ParseTime(apiJob.CompletionTime)
+	if err != nil {
+		return job
+	}
+
+	if completionTime.After(submissionTime) {
+		job.Duration = completionTime.Sub(submissionTime).Milliseconds()
+	}
+
+	return job
+}
+
+// newSQLStatistics summarizes the application's SQL executions, returns nil if the application has no SQL executions
+func newSQLStatistics(apiExecutions []sparkapiclient.SQLExecution) *v1alpha1.SQLStatistics {
+	if len(apiExecutions) == 0 {
+		return nil
+	}
+
+	stats := &v1alpha1.SQLStatistics{}
+	executions := make([]v1alpha1.SQLExecution, 0, len(apiExecutions))
+
+	for _, apiExecution := range apiExecutions {
+		switch apiExecution.Status {
+		case sparkapiclient.SQLExecutionStatusRunning:
+			stats.Running++
+		case sparkapiclient.SQLExecutionStatusCompleted:
+			stats.Completed++
+		case sparkapiclient.SQLExecutionStatusFailed:
+			stats.Failed++
+		}
+		executions = append(executions, newSQLExecution(apiExecution))
+	}
+
+	// Keep the slowest executions
+	sort.Slice(executions, func(i, j int) bool {
+		if executions[i].Duration != executions[j].Duration {
+			return executions[i].Duration > executions[j].Duration
+		}
+		return executions[i].ID < executions[j].ID
+	})
+	if len(executions) > maxSQLExecutions {
+		executions = executions[:maxSQLExecutions]
+	}
+	stats.Executions = executions
+
+	return stats
+}
+
+func newSQLExecution(apiExecution sparkapiclient.SQLExecution) v1alpha1.SQLExecution {
+	execution := v1alpha1.SQLExecution{
+		ID:             apiExecution.ID,
+		Description:    truncate(apiExecution.Description, maxDescriptionLength),
+		Status:         apiExecution.Status,
+		SubmissionTime: apiExecution.SubmissionTime,
+		Duration:       apiExecution.Duration,
+	}
+
+	if apiExecution.PlanDescription != "" {
+		hash := sha256.Sum256([]byte(apiExecution.PlanDescription))
+		execution.PlanDescriptionHash = hex.EncodeToString(hash[:])
+	}
+
+	jobIDs := make([]int64, 0, len(apiExecution.RunningJobIDs)+len(apiExecution.SuccessJobIDs)+len(apiExecution.FailedJobIDs))
+	jobIDs = append(jobIDs, apiExecution.RunningJobIDs...)
+	jobIDs = append(jobIDs, apiExecution.SuccessJobIDs...)
+	jobIDs = append(jobIDs, apiExecution.FailedJobIDs...)
+	if len(jobIDs) > 0 {
+		sort.Slice(jobIDs, func(i, j int) bool {
+			return jobIDs[i] < jobIDs[j]
+		})
+		execution.JobIDs = jobIDs
+	}
+
+	return execution
+}
+
+// truncate shortens s to at most maxLength bytes, without splitting a UTF-8 encoded character
+func truncate(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	for maxLength > 0 && !utf8.RuneStart(s[maxLength]) {
+		maxLength--
+	}
+	return s[:maxLength]
+}
+
+// newExecutorResources returns the executor's resources sorted by name, returns nil if the executor has no resources
+func newExecutorResources(apiResources map[string]sparkapiclient.ResourceInformation) []v1alpha1.ExecutorResource {
+	if len(apiResources) == 0 {
+		return nil
+	}
+
+	resources := make([]v1alpha1.ExecutorResource, 0, len(apiResources))
+	for name, apiResource := range apiResources {
+		resources = append(resources, v1alpha1.ExecutorResource{
+			Name:      name,
+			Addresses: apiResource.Addresses,
+		})
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Name < resources[j].Name
+	})
+
+	return resources
+}
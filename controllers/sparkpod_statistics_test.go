@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+func TestNewJobStatistics(t *testing.T) {
+
+	t.Run("whenNoJobs", func(tt *testing.T) {
+		assert.Nil(tt, newJobStatistics(nil))
+	})
+
+	t.Run("whenJobs", func(tt *testing.T) {
+		jobs := []sparkapiclient.Job{
+			{
+				JobID:          3,
+				Name:           "count at <console>:1",
+				SubmissionTime: "2020-11-24T17:19:30.000GMT",
+				Status:         sparkapiclient.JobStatusRunning,
+			},
+			{
+				JobID:          2,
+				Name:           "save at Job.scala:20",
+				Description:    "write results",
+				SubmissionTime: "2020-11-24T17:19:00.000GMT",
+				CompletionTime: "2020-11-24T17:19:10.000GMT",
+				Status:         sparkapiclient.JobStatusFailed,
+			},
+			{
+				JobID:          1,
+				Name:           "collect at Job.scala:10",
+				JobGroup:       "group",
+				SubmissionTime: "2020-11-24T17:17:00.000GMT",
+				CompletionTime: "2020-11-24T17:18:00.500GMT",
+				Status:         sparkapiclient.JobStatusSucceeded,
+			},
+			{
+				JobID:  0,
+				Status: sparkapiclient.JobStatusUnknown,
+			},
+		}
+
+		stats := newJobStatistics(jobs)
+		require.NotNil(tt, stats)
+		assert.Equal(tt, int64(1), stats.Running)
+		assert.Equal(tt, int64(1), stats.Succeeded)
+		assert.Equal(tt, int64(1), stats.Failed)
+		assert.Equal(tt, int64(1), stats.Unknown)
+
+		require.NotNil(tt, stats.Longest)
+		assert.Equal(tt, int64(1), stats.Longest.JobID)
+		assert.Equal(tt, "collect at Job.scala:10", stats.Longest.Name)
+		assert.Equal(tt, "group", stats.Longest.JobGroup)
+		assert.Equal(tt, int64(60500), stats.Longest.Duration)
+
+		require.Len(tt, stats.FailedJobs, 1)
+		assert.Equal(tt, int64(2), stats.FailedJobs[0].JobID)
+		assert.Equal(tt, "write results", stats.FailedJobs[0].Description)
+		assert.Equal(tt, int64(10000), stats.FailedJobs[0].Duration)
+	})
+
+	t.Run("whenRunningJobIsLongest", func(tt *testing.T) {
+		jobs := []sparkapiclient.Job{
+			{
+				JobID:          1,
+				SubmissionTime: "2020-11-24T17:10:00.000GMT",
+				Status:         sparkapiclient.JobStatusRunning,
+			},
+			{
+				JobID:          0,
+				SubmissionTime: "2020-11-24T17:00:00.000GMT",
+				CompletionTime: "2020-11-24T17:01:00.000GMT",
+				Status:         sparkapiclient.JobStatusSucceeded,
+			},
+		}
+
+		// The running job is not the longest, its duration is not known yet
+		stats := newJobStatistics(jobs)
+		require.NotNil(tt, stats.Longest)
+		assert.Equal(tt, int64(0), stats.Longest.JobID)
+		assert.Equal(tt, int64(60000), stats.Longest.Duration)
+		assert.Nil(tt, stats.FailedJobs)
+	})
+
+	t.Run("whenOnlyRunningJobs", func(tt *testing.T) {
+		jobs := []sparkapiclient.Job{
+			{
+				JobID:          0,
+				SubmissionTime: "2020-11-24T17:10:00.000GMT",
+				Status:         sparkapiclient.JobStatusRunning,
+			},
+		}
+
+		stats := newJobStatistics(jobs)
+		require.NotNil(tt, stats)
+		assert.Equal(tt, int64(1), stats.Running)
+		assert.Nil(tt, stats.Longest)
+	})
+
+	t.Run("whenManyFailedJobs", func(tt *testing.T) {
+		jobs := make([]sparkapiclient.Job, 0)
+		for i := 0; i < maxFailedJobs+5; i++ {
+			jobs = append(jobs, sparkapiclient.Job{
+				JobID:  int64(i),
+				Status: sparkapiclient.JobStatusFailed,
+			})
+		}
+
+		stats := newJobStatistics(jobs)
+		assert.Equal(tt, int64(maxFailedJobs+5), stats.Failed)
+		require.Len(tt, stats.FailedJobs, maxFailedJobs)
+		assert.Equal(tt, int64(maxFailedJobs+4), stats.FailedJobs[0].JobID)
+		assert.Equal(tt, int64(5), stats.FailedJobs[maxFailedJobs-1].JobID)
+	})
+}
+
+func TestNewSQLStatistics(t *testing.T) {
+
+	t.Run("whenNoExecutions", func(tt *testing.T) {
+		assert.Nil(tt, newSQLStatistics(nil))
+	})
+
+	t.Run("whenExecutions", func(tt *testing.T) {
+		executions := []sparkapiclient.SQLExecution{
+			{
+				ID:              0,
+				Status:          sparkapiclient.SQLExecutionStatusCompleted,
+				Description:     "select 1",
+				PlanDescription: "== Physical Plan ==",
+				SubmissionTime:  "2020-11-24T17:19:00.000GMT",
+				Duration:        100,
+				SuccessJobIDs:   []int64{2, 0},
+			},
+			{
+				ID:              1,
+				Status:          sparkapiclient.SQLExecutionStatusFailed,
+				Description:     "select * from slow",
+				PlanDescription: "== Physical Plan ==",
+				Duration:        5000,
+				SuccessJobIDs:   []int64{3},
+				FailedJobIDs:    []int64{4},
+			},
+			{
+				ID:            2,
+				Status:        sparkapiclient.SQLExecutionStatusRunning,
+				Description:   "select 2",
+				Duration:      100,
+				RunningJobIDs: []int64{5},
+			},
+		}
+
+		stats := newSQLStatistics(executions)
+		require.NotNil(tt, stats)
+		assert.Equal(tt, int64(1), stats.Running)
+		assert.Equal(tt, int64(1), stats.Completed)
+		assert.Equal(tt, int64(1), stats.Failed)
+
+		require.Len(tt, stats.Executions, 3)
+		assert.Equal(tt, int64(1), stats.Executions[0].ID)
+		assert.Equal(tt, "select * from slow", stats.Executions[0].Description)
+		assert.Equal(tt, sparkapiclient.SQLExecutionStatusFailed, stats.Executions[0].Status)
+		assert.Equal(tt, int64(5000), stats.Executions[0].Duration)
+		assert.Equal(tt, []int64{3, 4}, stats.Executions[0].JobIDs)
+
+		assert.Equal(tt, int64(0), stats.Executions[1].ID)
+		assert.Equal(tt, "2020-11-24T17:19:00.000GMT", stats.Executions[1].SubmissionTime)
+		assert.Equal(tt, []int64{0, 2}, stats.Executions[1].JobIDs)
+		assert.Equal(tt, int64(2), stats.Executions[2].ID)
+
+		// Executions of the same plan have the same hash
+		assert.Len(tt, stats.Executions[0].PlanDescriptionHash, 64)
+		assert.Equal(tt, stats.Executions[0].PlanDescriptionHash, stats.Executions[1].PlanDescriptionHash)
+		assert.Empty(tt, stats.Executions[2].PlanDescriptionHash)
+	})
+
+	t.Run("whenManyExecutions", func(tt *testing.T) {
+		executions := make([]sparkapiclient.SQLExecution, 0)
+		for i := 0; i < maxSQLExecutions+5; i++ {
+			executions = append(executions, sparkapiclient.SQLExecution{
+				ID:          int64(i),
+				Status:      sparkapiclient.SQLExecutionStatusCompleted,
+				Description: fmt.Sprintf("select %d", i),
+				Duration:    int64(i),
+			})
+		}
+
+		stats := newSQLStatistics(executions)
+		assert.Equal(tt, int64(maxSQLExecutions+5), stats.Completed)
+		require.Len(tt, stats.Executions, maxSQLExecutions)
+		assert.Equal(tt, int64(maxSQLExecutions+4), stats.Executions[0].ID)
+		assert.Equal(tt, int64(5), stats.Executions[maxSQLExecutions-1].ID)
+	})
+
+	t.Run("whenLongDescription", func(tt *testing.T) {
+		executions := []sparkapiclient.SQLExecution{
+			{
+				ID:          0,
+				Description: strings.Repeat("a", maxDescriptionLength-1) + "é",
+			},
+		}
+
+		stats := newSQLStatistics(executions)
+		assert.Equal(tt, strings.Repeat("a", maxDescriptionLength-1), stats.Executions[0].Description)
+	})
+}
+
+func TestNewExecutorResources(t *testing.T) {
+
+	t.Run("whenResources", func(tt *testing.T) {
+		resources := newExecutorResources(map[string]sparkapiclient.ResourceInformation{
+			"gpu":  {Name: "gpu", Addresses: []string{"0", "1"}},
+			"fpga": {Name: "fpga", Addresses: []string{"f1"}},
+		})
+		assert.Equal(tt, []v1alpha1.ExecutorResource{
+			{Name: "fpga", Addresses: []string{"f1"}},
+			{Name: "gpu", Addresses: []string{"0", "1"}},
+		}, resources)
+	})
+
+	t.Run("whenNoResources", func(tt *testing.T) {
+		assert.Nil(tt, newExecutorResources(nil))
+	})
+}
@@ -116,6 +116,7 @@ var _ = BeforeSuite(func(done Done) {
 
 	sparkPodController := NewSparkPodReconciler(
 		k8sManager.GetClient(),
+		k8sManager.GetAPIReader(),
 		clientSet,
 		sparkapi.GetManager,
 		ctrl.Log.WithName("controllers").WithName("SparkPod"),
@@ -5,12 +5,15 @@ go 1.16
 require (
 	github.com/aws/aws-sdk-go v1.38.35
 	github.com/evanphx/json-patch/v5 v5.1.0
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/go-logr/logr v0.4.0
 	github.com/go-logr/zapr v0.3.0 // indirect
 	github.com/golang/mock v1.4.4
+	github.com/golang/snappy v0.0.1
 	github.com/google/go-cmp v0.5.5
 	github.com/gorilla/mux v1.8.0 // indirect
 	github.com/jetstack/cert-manager v1.3.1
+	github.com/klauspost/compress v1.13.6
 	github.com/magiconair/properties v1.8.1
 	github.com/mattbaird/jsonpatch v0.0.0-20200820163806-098863c1fc24
 	github.com/mitchellh/go-homedir v1.1.0
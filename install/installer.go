@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -165,6 +166,13 @@ func (i *HelmInstaller) getActionConfig(namespace string) (*action.Configuration
 }
 
 func (i *HelmInstaller) Upgrade(chartName string, repository string, version string, values string) error {
+	start := time.Now()
+	err := i.upgrade(chartName, repository, version, values)
+	observeOperation(operationUpgrade, chartName, start, err)
+	return err
+}
+
+func (i *HelmInstaller) upgrade(chartName string, repository string, version string, values string) error {
 
 	var vals map[string]interface{}
 	err := yaml.Unmarshal([]byte(values), &vals)
@@ -223,6 +231,13 @@ func (i *HelmInstaller) Upgrade(chartName string, repository string, version str
 }
 
 func (i *HelmInstaller) Install(chartName string, repository string, version string, values string) error {
+	start := time.Now()
+	err := i.install(chartName, repository, version, values)
+	observeOperation(operationInstall, chartName, start, err)
+	return err
+}
+
+func (i *HelmInstaller) install(chartName string, repository string, version string, values string) error {
 
 	var vals map[string]interface{}
 	err := yaml.Unmarshal([]byte(values), &vals)
@@ -297,6 +312,19 @@ func (i *HelmInstaller) GetReleaseName(name string) string {
 }
 
 func (i *HelmInstaller) Delete(chartName string, repository string, version string, values string) error {
+	start := time.Now()
+	err := i.delete(chartName, values)
+	observeOperation(operationDelete, chartName, start, err)
+
+	var uninstallErr uninstallError
+	if errors.As(err, &uninstallErr) {
+		i.Log.Error(err, fmt.Sprintf("ignoring deletion error, %s", err.Error()))
+		return nil
+	}
+	return err
+}
+
+func (i *HelmInstaller) delete(chartName string, values string) error {
 
 	var vals map[string]interface{}
 	err := yaml.Unmarshal([]byte(values), &vals)
@@ -314,14 +342,22 @@ func (i *HelmInstaller) Delete(chartName string, repository string, version stri
 	getAction := action.NewUninstall(cfg)
 	_, err = getAction.Run(releaseName)
 	if err != nil {
-		i.Log.Error(err, fmt.Sprintf("ignoring deletion error, %s", err.Error()))
-	} else {
-		i.Log.Info("removed", "release", releaseName)
+		return uninstallError{err}
 	}
+	i.Log.Info("removed", "release", releaseName)
 
 	return nil
 }
 
+// uninstallError is a failed helm uninstall, it is counted as a failed deletion but not returned
+type uninstallError struct {
+	error
+}
+
+func (e uninstallError) Unwrap() error {
+	return e.error
+}
+
 func (i *HelmInstaller) IsUpgrade(comp *v1alpha1.WaveComponent, inst *Installation) bool {
 	if comp.Spec.Version != inst.Version {
 		return true
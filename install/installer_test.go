@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/spotinst/wave-operator/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
@@ -178,3 +179,22 @@ func TestUnmarshalJsonValues(t *testing.T) {
 	assert.Equal(t, expected["tag"], vals2["tag"])
 
 }
+
+func TestOperationMetrics(t *testing.T) {
+
+	logger := zap.New(zap.UseDevMode(true)).WithValues("test", t.Name())
+	i := &HelmInstaller{
+		prefix:       "wave",
+		ClientGetter: nil,
+		Log:          logger,
+	}
+
+	err := i.Upgrade("test-upgrade", "repo", "v1.0.0", ":unparseable: yaml:")
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(helmOperations.WithLabelValues(operationUpgrade, "test-upgrade", resultFailure)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(helmOperations.WithLabelValues(operationUpgrade, "test-upgrade", resultSuccess)))
+
+	err = i.Delete("test-delete", "repo", "v1.0.0", ":unparseable: yaml:")
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(helmOperations.WithLabelValues(operationDelete, "test-delete", resultFailure)))
+}
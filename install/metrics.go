@@ -0,0 +1,48 @@
+package install
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	operationInstall = "install"
+	operationUpgrade = "upgrade"
+	operationDelete  = "delete"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+var helmOperations = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_helm_operations_total",
+		Help: "Total number of helm operations on component charts, by result",
+	},
+	[]string{"operation", "chart", "result"},
+)
+
+var helmOperationDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "wave_helm_operation_duration_seconds",
+		Help:    "Duration of helm operations on component charts in seconds",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	},
+	[]string{"operation", "chart"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(helmOperations, helmOperationDuration)
+}
+
+// observeOperation records the result and duration of a helm operation that started at the given time
+func observeOperation(operation string, chartName string, start time.Time, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultFailure
+	}
+	helmOperations.WithLabelValues(operation, chartName, result).Inc()
+	helmOperationDuration.WithLabelValues(operation, chartName).Observe(time.Since(start).Seconds())
+}
@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"text/template"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/spotinst/wave-operator/cloudstorage"
@@ -49,6 +51,67 @@ func (s *s3Provider) GetStorageInfo() (*cloudstorage.StorageInfo, error) {
 	return s.storageInfo, nil
 }
 
+// ListObjects returns the keys of all objects in the bucket that start with the given prefix
+func (s *s3Provider) ListObjects(bucket string, prefix string) ([]string, error) {
+	svc, err := newS3Service()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	input := &s3.ListObjectsV2Input{
+		Bucket: &bucket,
+		Prefix: &prefix,
+	}
+	err = svc.ListObjectsV2PagesWithContext(context.TODO(), input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.StringValue(obj.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetObject returns the contents of the object with the given key
+func (s *s3Provider) GetObject(bucket string, key string) (io.ReadCloser, error) {
+	svc, err := newS3Service()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := svc.GetObjectWithContext(context.TODO(), &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func newS3Service() (*s3.S3, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	region := aws.StringValue(sess.Config.Region)
+	if region == "" {
+		region, err = getRegionFromMetadata()
+		if err != nil {
+			return nil, err
+		}
+		sess.Config.Region = &region
+	}
+
+	return s3.New(sess), nil
+}
+
 func createBucket(name string) (*cloudstorage.StorageInfo, error) {
 
 	sess, err := session.NewSessionWithOptions(session.Options{
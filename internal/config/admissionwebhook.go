@@ -0,0 +1,81 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultAdmissionWebhookPort    = 9443
+	DefaultAdmissionWebhookCertDir = "/etc/webhook/certs"
+)
+
+// AdmissionWebhook configures the listener of the admission webhook server. The server's certificate and key
+// are read from tls.crt and tls.key in the certificate directory, and reloaded when they change.
+type AdmissionWebhook struct {
+	// Port the webhook server listens on, defaults to DefaultAdmissionWebhookPort
+	Port int `yaml:"port"`
+	// CertDir is the directory of the server certificate and key, defaults to DefaultAdmissionWebhookCertDir
+	CertDir string `yaml:"certDir"`
+	// MinTLSVersion is the minimum TLS version accepted, 1.2 or 1.3, defaults to 1.2
+	MinTLSVersion string `yaml:"minTLSVersion"`
+	// CipherSuites are the names of the cipher suites accepted for TLS 1.2, defaults to Go's secure cipher suites
+	CipherSuites []string `yaml:"cipherSuites"`
+}
+
+// WithDefaults returns the configuration with defaults for unset settings
+func (a AdmissionWebhook) WithDefaults() AdmissionWebhook {
+	if a.Port == 0 {
+		a.Port = DefaultAdmissionWebhookPort
+	}
+	if a.CertDir == "" {
+		a.CertDir = DefaultAdmissionWebhookCertDir
+	}
+	return a
+}
+
+// GetMinTLSVersion returns the minimum TLS version accepted
+func (a AdmissionWebhook) GetMinTLSVersion() (uint16, error) {
+	switch strings.TrimSpace(a.MinTLSVersion) {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid minTLSVersion %q, must be one of 1.2, 1.3", a.MinTLSVersion)
+	}
+}
+
+// GetCipherSuites returns the IDs of the cipher suites accepted, nil for Go's defaults
+func (a AdmissionWebhook) GetCipherSuites() ([]uint16, error) {
+	if len(a.CipherSuites) == 0 {
+		return nil, nil
+	}
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(a.CipherSuites))
+	for _, name := range a.CipherSuites {
+		id, ok := suites[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (a AdmissionWebhook) validate() error {
+	if a.Port < 0 || a.Port > 65535 {
+		return fmt.Errorf("invalid port %d", a.Port)
+	}
+	if _, err := a.GetMinTLSVersion(); err != nil {
+		return err
+	}
+	if _, err := a.GetCipherSuites(); err != nil {
+		return err
+	}
+	return nil
+}
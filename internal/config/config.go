@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 
@@ -17,16 +18,95 @@ const (
 	WaveConfigAnnotationInstanceType      = "wave.spot.io/instance-type"
 	WaveConfigAnnotationInstanceLifecycle = "wave.spot.io/instance-lifecycle"
 	WaveConfigAnnotationApplicationName   = "wave.spot.io/application-name"
+	// WaveConfigAnnotationTraceParent is a W3C trace context traceparent value, e.g. set by the workflow that submits
+	// the application, the driver pod's value is copied to the SparkApplication and used as parent of its trace
+	WaveConfigAnnotationTraceParent = "wave.spot.io/traceparent"
+	// WaveConfigAnnotationExpectedDuration and WaveConfigAnnotationMaxDuration are Go durations, e.g. 2h30m,
+	// the application is marked DurationExceeded when its driver runs longer than either
+	WaveConfigAnnotationExpectedDuration = "wave.spot.io/expected-duration"
+	WaveConfigAnnotationMaxDuration      = "wave.spot.io/max-duration"
+	// WaveConfigAnnotationTerminateOnMaxDuration opts in to deleting the driver pod when the max duration is reached
+	WaveConfigAnnotationTerminateOnMaxDuration = "wave.spot.io/terminate-on-max-duration"
+	// WaveConfigAnnotationExecutorOnDemandPercentage is the percentage, 0 to 100, of the application's executors placed
+	// on on-demand instances, the other executors prefer spot instances
+	WaveConfigAnnotationExecutorOnDemandPercentage = "wave.spot.io/executor-on-demand-percentage"
+	// WaveConfigAnnotationAssignedInstanceLifecycle is set by the operator to the instance lifecycle the executor was
+	// placed on to hold the application's on-demand percentage
+	WaveConfigAnnotationAssignedInstanceLifecycle = "wave.spot.io/assigned-instance-lifecycle"
+	// WaveConfigAnnotationExecutorTopologySpread is a comma separated list of topologies, zone or hostname, the
+	// application's executors are spread across, none to not spread executors
+	WaveConfigAnnotationExecutorTopologySpread = "wave.spot.io/executor-topology-spread"
+	// WaveConfigAnnotationDriverZone is the zone of the driver, or colocate for the application's executors to
+	// prefer the zone of the driver. The operator sets colocate on the executor pods of annotated drivers.
+	WaveConfigAnnotationDriverZone = "wave.spot.io/driver-zone"
+	// WaveConfigAnnotationExecutorDecommission is true or false, whether executors on spot instances are gracefully
+	// decommissioned, defaults to true for Spark 3.1 and later. It is set by the operator on executor pods of
+	// applications with decommissioning enabled.
+	WaveConfigAnnotationExecutorDecommission = "wave.spot.io/executor-decommission"
+	// WaveConfigAnnotationDecommissionFallbackStorage is true or false, whether decommissioned executors migrate their
+	// shuffle blocks to the wave storage bucket if no other executor can take them, defaults to false
+	WaveConfigAnnotationDecommissionFallbackStorage = "wave.spot.io/decommission-fallback-storage"
+	// WaveConfigAnnotationSparkVersion is the Spark version of the driver's application, for drivers without
+	// the spark-version or version labels. Executor decommissioning is only enabled for known Spark versions.
+	WaveConfigAnnotationSparkVersion = "wave.spot.io/spark-version"
+	// WaveConfigAnnotationSparkConfPrefix prefixes Spark property keys set for the driver's application
+	// if the application does not set them, e.g. wave.spot.io/spark-conf.spark.sql.adaptive.enabled
+	// Annotation names are limited to 63 characters after the prefix wave.spot.io/, longer keys need a policy.
+	WaveConfigAnnotationSparkConfPrefix = "wave.spot.io/spark-conf."
+	// WaveConfigAnnotationSparkConfOverridePrefix prefixes Spark property keys set for the driver's application,
+	// replacing the application's values
+	WaveConfigAnnotationSparkConfOverridePrefix = "wave.spot.io/spark-conf-override."
+	// WaveConfigAnnotationSparkConfChanged is set by the operator on the driver's Spark properties config map,
+	// or on driver pods without one, to the comma separated keys of the properties the operator set or changed
+	WaveConfigAnnotationSparkConfChanged = "wave.spot.io/spark-conf-changed"
+	// WaveConfigAnnotationSparkPolicy is set by the operator to the name of the WaveSparkPolicy applied to the pod
+	WaveConfigAnnotationSparkPolicy = "wave.spot.io/spark-policy"
+
+	// SparkApplication annotations
+	// WaveConfigAnnotationWorkloadType is set by the operator to the application's workload type, e.g. spark-streaming
+	WaveConfigAnnotationWorkloadType = "wave.spot.io/workloadType"
+
+	// Namespace annotations
+	WaveConfigAnnotationSparkApiTransport   = "wave.spot.io/spark-api-transport"
+	WaveConfigAnnotationSparkApiServiceHost = "wave.spot.io/spark-api-service-host"
+	// WaveConfigAnnotationValidationMode is enforce, warn or disabled, whether Spark pods with invalid wave
+	// configuration are rejected or admitted with warnings, defaults to warn
+	WaveConfigAnnotationValidationMode = "wave.spot.io/validation-mode"
+
+	// SyncedEventLogDir is the event log directory of applications with event log sync enabled
+	SyncedEventLogDir = "file:///var/log/spark"
 
 	InstanceLifecycleOnDemand InstanceLifecycle = "od"
 	InstanceLifecycleSpot     InstanceLifecycle = "spot"
+
+	TopologyZone     Topology = "zone"
+	TopologyHostname Topology = "hostname"
+	// TopologyNone disables the topology spread of the application's executors
+	TopologyNone = "none"
+
+	// DriverZoneColocate places the application's executors in the zone of the driver, if possible
+	DriverZoneColocate = "colocate"
+
+	ValidationModeEnforce  ValidationMode = "enforce"
+	ValidationModeWarn     ValidationMode = "warn"
+	ValidationModeDisabled ValidationMode = "disabled"
 )
 
 type InstanceLifecycle string
 
+type ValidationMode string
+
+type Topology string
+
 func IsEventLogSyncEnabled(annotations map[string]string) bool {
+	enabled, _ := GetEventLogSync(annotations)
+	return enabled
+}
+
+// GetEventLogSync returns whether event log sync is enabled, and whether it is configured by the annotations
+func GetEventLogSync(annotations map[string]string) (enabled bool, configured bool) {
 	if annotations == nil {
-		return false
+		return false, false
 	}
 	conf := annotations[WaveConfigAnnotationSyncEventLogs]
 
@@ -36,26 +116,170 @@ func IsEventLogSyncEnabled(annotations map[string]string) bool {
 	}
 
 	enabled, err := strconv.ParseBool(conf)
+	if err != nil {
+		return false, false
+	}
+	return enabled, true
+}
+
+// GetDuration returns the duration configured by the annotation, zero if the annotation is not set
+func GetDuration(annotations map[string]string, annotation string) (time.Duration, error) {
+	conf := strings.TrimSpace(annotations[annotation])
+	if conf == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(conf)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation, %w", annotation, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q, must be positive", annotation, conf)
+	}
+	return d, nil
+}
+
+// GetExecutorOnDemandPercentage returns the on-demand percentage of the annotations, and whether it is configured
+func GetExecutorOnDemandPercentage(annotations map[string]string) (int, bool, error) {
+	conf := strings.TrimSpace(annotations[WaveConfigAnnotationExecutorOnDemandPercentage])
+	if conf == "" {
+		return 0, false, nil
+	}
+	percentage, err := strconv.Atoi(strings.TrimSuffix(conf, "%"))
+	if err != nil || percentage < 0 || percentage > 100 {
+		return 0, false, fmt.Errorf("invalid %s annotation %q, must be a percentage between 0 and 100",
+			WaveConfigAnnotationExecutorOnDemandPercentage, conf)
+	}
+	return percentage, true, nil
+}
+
+// GetExecutorDecommission returns whether executor decommissioning is enabled, and whether it is configured by the annotations
+func GetExecutorDecommission(annotations map[string]string) (enabled bool, configured bool) {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(annotations[WaveConfigAnnotationExecutorDecommission]))
+	if err != nil {
+		return false, false
+	}
+	return enabled, true
+}
+
+func IsDecommissionFallbackStorageEnabled(annotations map[string]string) bool {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(annotations[WaveConfigAnnotationDecommissionFallbackStorage]))
 	if err != nil {
 		return false
 	}
 	return enabled
 }
 
+// GetSparkConf returns the Spark property defaults and overrides of the annotations
+func GetSparkConf(annotations map[string]string) (defaults map[string]string, overrides map[string]string) {
+	defaults = make(map[string]string)
+	overrides = make(map[string]string)
+	for k, v := range annotations {
+		if key := strings.TrimPrefix(k, WaveConfigAnnotationSparkConfOverridePrefix); key != k && key != "" {
+			overrides[key] = v
+		} else if key := strings.TrimPrefix(k, WaveConfigAnnotationSparkConfPrefix); key != k && key != "" {
+			defaults[key] = v
+		}
+	}
+	return defaults, overrides
+}
+
+func IsTerminateOnMaxDurationEnabled(annotations map[string]string) bool {
+	enabled, err := strconv.ParseBool(annotations[WaveConfigAnnotationTerminateOnMaxDuration])
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// GetValidationMode returns the validation mode of the namespace annotations, warn if not configured
+func GetValidationMode(annotations map[string]string, log logr.Logger) ValidationMode {
+	conf := strings.ToLower(strings.TrimSpace(annotations[WaveConfigAnnotationValidationMode]))
+	switch ValidationMode(conf) {
+	case "":
+		return ValidationModeWarn
+	case ValidationModeEnforce, ValidationModeWarn, ValidationModeDisabled:
+		return ValidationMode(conf)
+	default:
+		log.Info(fmt.Sprintf("Unknown validation mode configuration value: %q", conf))
+		return ValidationModeWarn
+	}
+}
+
 func GetInstanceLifecycle(annotations map[string]string, log logr.Logger) InstanceLifecycle {
 	conf := annotations[WaveConfigAnnotationInstanceLifecycle]
 	if conf == "" {
 		return ""
 	}
-	conf = strings.ToLower(conf)
-	conf = strings.TrimSpace(conf)
-	switch conf {
+	lifecycle := ParseInstanceLifecycle(conf)
+	if lifecycle == "" {
+		log.Info(fmt.Sprintf("Unknown instance lifecycle configuration value: %q", conf))
+	}
+	return lifecycle
+}
+
+// GetExecutorTopologySpread returns the topologies of the annotation, and whether the annotation is configured.
+// The topologies are empty if the annotation is none.
+func GetExecutorTopologySpread(annotations map[string]string, log logr.Logger) ([]Topology, bool) {
+	conf := strings.TrimSpace(annotations[WaveConfigAnnotationExecutorTopologySpread])
+	if conf == "" {
+		return nil, false
+	}
+	if strings.ToLower(conf) == TopologyNone {
+		return []Topology{}, true
+	}
+	topologies, unknown := ParseTopologies(strings.Split(conf, ","))
+	for _, u := range unknown {
+		log.Info(fmt.Sprintf("Unknown topology spread configuration value: %q", u))
+	}
+	if len(topologies) == 0 {
+		return nil, false
+	}
+	return topologies, true
+}
+
+// ParseTopologies returns the topologies of the values, and the unknown values
+func ParseTopologies(values []string) (topologies []Topology, unknown []string) {
+	topologies = make([]Topology, 0, len(values))
+	unknown = make([]string, 0)
+	seen := make(map[Topology]bool)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		topology := Topology(strings.ToLower(value))
+		switch topology {
+		case TopologyZone, TopologyHostname:
+			if !seen[topology] {
+				seen[topology] = true
+				topologies = append(topologies, topology)
+			}
+		default:
+			unknown = append(unknown, value)
+		}
+	}
+	return topologies, unknown
+}
+
+// GetDriverZone returns the driver zone of the annotations, a zone name or colocate, empty if not configured
+func GetDriverZone(annotations map[string]string) string {
+	zone := strings.TrimSpace(annotations[WaveConfigAnnotationDriverZone])
+	if strings.ToLower(zone) == DriverZoneColocate {
+		return DriverZoneColocate
+	}
+	return zone
+}
+
+// ParseInstanceLifecycle returns the instance lifecycle of the value, od or spot, empty if unknown
+func ParseInstanceLifecycle(value string) InstanceLifecycle {
+	value = strings.ToLower(value)
+	value = strings.TrimSpace(value)
+	switch value {
 	case "od":
 		return InstanceLifecycleOnDemand
 	case "spot":
 		return InstanceLifecycleSpot
 	default:
-		log.Info(fmt.Sprintf("Unknown instance lifecycle configuration value: %q", conf))
 		return ""
 	}
 }
@@ -68,9 +292,13 @@ func GetConfiguredInstanceTypes(annotations map[string]string, instanceTypeManag
 	if conf == "" {
 		return []string{}
 	}
+	return GetInstanceTypes(strings.Split(conf, ","), instanceTypeManager, log)
+}
+
+// GetInstanceTypes returns the valid instance types of the values, instance families are expanded to their types
+func GetInstanceTypes(values []string, instanceTypeManager instances.InstanceTypeManager, log logr.Logger) []string {
 	instanceTypes := make(map[string]bool)
-	split := strings.Split(conf, ",")
-	for _, s := range split {
+	for _, s := range values {
 		trimmed := strings.TrimSpace(s)
 		// Is this a valid instance type?
 		err := instanceTypeManager.ValidateInstanceType(trimmed)
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
@@ -55,6 +56,117 @@ func TestIsEventLogSyncEnabled(t *testing.T) {
 
 }
 
+func TestGetDuration(t *testing.T) {
+
+	d, err := GetDuration(nil, WaveConfigAnnotationMaxDuration)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), d)
+
+	d, err = GetDuration(map[string]string{WaveConfigAnnotationMaxDuration: " 2h30m "}, WaveConfigAnnotationMaxDuration)
+	assert.NoError(t, err)
+	assert.Equal(t, 150*time.Minute, d)
+
+	_, err = GetDuration(map[string]string{WaveConfigAnnotationMaxDuration: "2 hours"}, WaveConfigAnnotationMaxDuration)
+	assert.Error(t, err)
+
+	_, err = GetDuration(map[string]string{WaveConfigAnnotationMaxDuration: "-1h"}, WaveConfigAnnotationMaxDuration)
+	assert.Error(t, err)
+}
+
+func TestIsTerminateOnMaxDurationEnabled(t *testing.T) {
+	assert.False(t, IsTerminateOnMaxDurationEnabled(nil))
+	assert.False(t, IsTerminateOnMaxDurationEnabled(map[string]string{WaveConfigAnnotationTerminateOnMaxDuration: "yes"}))
+	assert.True(t, IsTerminateOnMaxDurationEnabled(map[string]string{WaveConfigAnnotationTerminateOnMaxDuration: "true"}))
+}
+
+func TestGetExecutorOnDemandPercentage(t *testing.T) {
+	_, configured, err := GetExecutorOnDemandPercentage(nil)
+	assert.NoError(t, err)
+	assert.False(t, configured)
+
+	for value, expected := range map[string]int{"0": 0, " 30 ": 30, "50%": 50, "100": 100} {
+		percentage, configured, err := GetExecutorOnDemandPercentage(map[string]string{WaveConfigAnnotationExecutorOnDemandPercentage: value})
+		assert.NoError(t, err, value)
+		assert.True(t, configured, value)
+		assert.Equal(t, expected, percentage, value)
+	}
+
+	for _, value := range []string{"-1", "101", "half", "0.5"} {
+		_, configured, err := GetExecutorOnDemandPercentage(map[string]string{WaveConfigAnnotationExecutorOnDemandPercentage: value})
+		assert.Error(t, err, value)
+		assert.False(t, configured, value)
+	}
+}
+
+func TestGetExecutorTopologySpread(t *testing.T) {
+	_, configured := GetExecutorTopologySpread(nil, getTestLogger())
+	assert.False(t, configured)
+
+	topologies, configured := GetExecutorTopologySpread(map[string]string{WaveConfigAnnotationExecutorTopologySpread: " Zone, hostname,zone "}, getTestLogger())
+	assert.True(t, configured)
+	assert.Equal(t, []Topology{TopologyZone, TopologyHostname}, topologies)
+
+	topologies, configured = GetExecutorTopologySpread(map[string]string{WaveConfigAnnotationExecutorTopologySpread: "None"}, getTestLogger())
+	assert.True(t, configured)
+	assert.Empty(t, topologies)
+
+	topologies, configured = GetExecutorTopologySpread(map[string]string{WaveConfigAnnotationExecutorTopologySpread: "rack,hostname"}, getTestLogger())
+	assert.True(t, configured)
+	assert.Equal(t, []Topology{TopologyHostname}, topologies)
+
+	_, configured = GetExecutorTopologySpread(map[string]string{WaveConfigAnnotationExecutorTopologySpread: "rack"}, getTestLogger())
+	assert.False(t, configured)
+}
+
+func TestGetDriverZone(t *testing.T) {
+	assert.Equal(t, "", GetDriverZone(nil))
+	assert.Equal(t, "us-east-1a", GetDriverZone(map[string]string{WaveConfigAnnotationDriverZone: " us-east-1a "}))
+	assert.Equal(t, DriverZoneColocate, GetDriverZone(map[string]string{WaveConfigAnnotationDriverZone: "Colocate"}))
+}
+
+func TestGetExecutorDecommission(t *testing.T) {
+	_, configured := GetExecutorDecommission(nil)
+	assert.False(t, configured)
+
+	enabled, configured := GetExecutorDecommission(map[string]string{WaveConfigAnnotationExecutorDecommission: "false"})
+	assert.True(t, configured)
+	assert.False(t, enabled)
+
+	enabled, configured = GetExecutorDecommission(map[string]string{WaveConfigAnnotationExecutorDecommission: " true"})
+	assert.True(t, configured)
+	assert.True(t, enabled)
+
+	_, configured = GetExecutorDecommission(map[string]string{WaveConfigAnnotationExecutorDecommission: "sometimes"})
+	assert.False(t, configured)
+
+	assert.False(t, IsDecommissionFallbackStorageEnabled(nil))
+	assert.False(t, IsDecommissionFallbackStorageEnabled(map[string]string{WaveConfigAnnotationDecommissionFallbackStorage: "no"}))
+	assert.True(t, IsDecommissionFallbackStorageEnabled(map[string]string{WaveConfigAnnotationDecommissionFallbackStorage: "true"}))
+}
+
+func TestGetSparkConf(t *testing.T) {
+	defaults, overrides := GetSparkConf(map[string]string{
+		"wave.spot.io/spark-conf.spark.sql.adaptive.enabled":    "true",
+		"wave.spot.io/spark-conf-override.spark.executor.cores": "4",
+		"wave.spot.io/spark-conf.":                              "ignored",
+		WaveConfigAnnotationInstanceLifecycle:                   "spot",
+	})
+	assert.Equal(t, map[string]string{"spark.sql.adaptive.enabled": "true"}, defaults)
+	assert.Equal(t, map[string]string{"spark.executor.cores": "4"}, overrides)
+
+	defaults, overrides = GetSparkConf(nil)
+	assert.Empty(t, defaults)
+	assert.Empty(t, overrides)
+}
+
+func TestGetValidationMode(t *testing.T) {
+	logger := getTestLogger()
+	assert.Equal(t, ValidationModeWarn, GetValidationMode(nil, logger))
+	assert.Equal(t, ValidationModeEnforce, GetValidationMode(map[string]string{WaveConfigAnnotationValidationMode: " Enforce "}, logger))
+	assert.Equal(t, ValidationModeDisabled, GetValidationMode(map[string]string{WaveConfigAnnotationValidationMode: "disabled"}, logger))
+	assert.Equal(t, ValidationModeWarn, GetValidationMode(map[string]string{WaveConfigAnnotationValidationMode: "strict"}, logger))
+}
+
 func TestGetConfiguredInstanceTypes(t *testing.T) {
 
 	logger := getTestLogger()
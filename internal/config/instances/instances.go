@@ -127,6 +127,7 @@ func (m *manager) GetValidInstanceTypesInFamily(family string) ([]string, error)
 func (m *manager) refreshAllowedInstanceTypes() error {
 	m.log.Info("Refreshing allowed instance types ...")
 	allowed, err := m.fetchAllowedInstanceTypes()
+	recordRefresh(err)
 	if err != nil {
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -69,13 +70,19 @@ func TestRefreshAllowedInstanceTypes(t *testing.T) {
 			log:               logger.New(),
 		}
 
+		successes := testutil.ToFloat64(refreshes.WithLabelValues(refreshResultSuccess))
+		failures := testutil.ToFloat64(refreshes.WithLabelValues(refreshResultFailure))
+
 		err := manager.refreshAllowedInstanceTypes()
 		if tc.expectedError != "" {
 			require.Error(tt, err)
 			assert.Contains(tt, err.Error(), tc.expectedError)
+			assert.Equal(tt, failures+1, testutil.ToFloat64(refreshes.WithLabelValues(refreshResultFailure)))
 		} else {
 			require.NoError(tt, err)
 			assert.Equal(tt, tc.expected, manager.allowedInstanceTypes.m)
+			assert.Equal(tt, successes+1, testutil.ToFloat64(refreshes.WithLabelValues(refreshResultSuccess)))
+			assert.Less(tt, getCacheAge(), float64(60))
 		}
 	}
 
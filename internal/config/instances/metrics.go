@@ -0,0 +1,55 @@
+package instances
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	refreshResultSuccess = "success"
+	refreshResultFailure = "failure"
+)
+
+// lastRefreshUnixNano is the time of the last successful refresh of the instance type cache, zero if never refreshed
+var lastRefreshUnixNano int64
+
+var refreshes = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_instance_types_refresh_total",
+		Help: "Total number of allowed instance type refreshes, by result",
+	},
+	[]string{"result"},
+)
+
+var cacheAge = prometheus.NewGaugeFunc(
+	prometheus.GaugeOpts{
+		Name: "wave_instance_types_cache_age_seconds",
+		Help: "Seconds since the allowed instance types were last refreshed successfully, zero if never refreshed",
+	},
+	getCacheAge,
+)
+
+func init() {
+	metrics.Registry.MustRegister(refreshes, cacheAge)
+}
+
+// recordRefresh counts the refresh and remembers the time of a successful refresh
+func recordRefresh(err error) {
+	if err != nil {
+		refreshes.WithLabelValues(refreshResultFailure).Inc()
+		return
+	}
+	refreshes.WithLabelValues(refreshResultSuccess).Inc()
+	atomic.StoreInt64(&lastRefreshUnixNano, time.Now().UnixNano())
+}
+
+func getCacheAge() float64 {
+	last := atomic.LoadInt64(&lastRefreshUnixNano)
+	if last == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, last)).Seconds()
+}
@@ -0,0 +1,362 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// OperatorConfig is the operator wide configuration, read from the operator configuration file
+type OperatorConfig struct {
+	// HistoryServers are the Spark history servers used to get information on finished applications.
+	// The first history server matching an application is used.
+	HistoryServers []HistoryServer `yaml:"historyServers"`
+	// EventLogs configures reading Spark event logs directly
+	EventLogs EventLogs `yaml:"eventLogs"`
+	// Redaction configures the redaction of secrets from Spark properties
+	Redaction Redaction `yaml:"redaction"`
+	// Tracing configures the export of finished Spark applications as OpenTelemetry traces
+	Tracing Tracing `yaml:"tracing"`
+	// OpenLineage configures the emission of OpenLineage run events for Spark applications
+	OpenLineage OpenLineage `yaml:"openLineage"`
+	// Notifications configures webhook notifications on Spark application events
+	Notifications Notifications `yaml:"notifications"`
+	// StorageSync configures the storage sync sidecar container of driver pods
+	StorageSync StorageSync `yaml:"storageSync"`
+	// NodeInterruption configures the decommissioning of Spark executors on nodes about to be reclaimed
+	NodeInterruption NodeInterruption `yaml:"nodeInterruption"`
+	// SparkConf configures the Spark properties of all applications
+	SparkConf SparkConf `yaml:"sparkConf"`
+	// AdmissionWebhook configures the listener of the admission webhook server
+	AdmissionWebhook AdmissionWebhook `yaml:"admissionWebhook"`
+}
+
+// SparkConf are Spark properties set for applications by the admission webhook. The properties of a namespace's
+// WaveSparkPolicy and of the driver's wave.spot.io/spark-conf annotations take precedence, except overrides,
+// which take precedence over the annotations.
+type SparkConf struct {
+	// Defaults are set for applications that do not set them
+	Defaults map[string]string `yaml:"defaults"`
+	// Overrides are set for all applications, replacing the application's values
+	Overrides map[string]string `yaml:"overrides"`
+}
+
+const (
+	// DefaultNodeInterruptionAnnotation marks nodes Ocean is about to reclaim
+	DefaultNodeInterruptionAnnotation = "spotinst.io/interruption"
+	// DefaultNodeInterruptionGracePeriod matches the spot interruption notice
+	DefaultNodeInterruptionGracePeriod = 120 * time.Second
+)
+
+// DefaultNodeInterruptionTaints mark nodes about to be reclaimed, by Ocean or the AWS node termination handler
+var DefaultNodeInterruptionTaints = []string{
+	"spotinst.io/interruption",
+	"aws-node-termination-handler/spot-itn",
+	"aws-node-termination-handler/rebalance-recommendation",
+}
+
+// NodeInterruption configures the decommissioning of Spark executors on nodes about to be reclaimed.
+// Nodes are about to be reclaimed if they have one of the taints or annotations, or are cordoned.
+// Executor pods on such nodes are deleted with a grace period, running the decommission pre-stop hook
+// of applications with executor decommissioning enabled.
+type NodeInterruption struct {
+	// Enabled determines if executors on nodes about to be reclaimed are decommissioned
+	Enabled bool `yaml:"enabled"`
+	// Taints are the taint keys of nodes about to be reclaimed, defaults to DefaultNodeInterruptionTaints
+	Taints []string `yaml:"taints"`
+	// Annotations are the annotation keys of nodes about to be reclaimed, defaults to DefaultNodeInterruptionAnnotation
+	Annotations []string `yaml:"annotations"`
+	// IgnoreCordon determines if cordoned nodes are not considered about to be reclaimed, e.g. when nodes
+	// are cordoned for maintenance
+	IgnoreCordon bool `yaml:"ignoreCordon"`
+	// GracePeriod of the executor pod deletion, defaults to DefaultNodeInterruptionGracePeriod
+	GracePeriod time.Duration `yaml:"gracePeriod"`
+}
+
+// WithDefaults returns the configuration with defaults for unset settings
+func (n NodeInterruption) WithDefaults() NodeInterruption {
+	if len(n.Taints) == 0 {
+		n.Taints = DefaultNodeInterruptionTaints
+	}
+	if len(n.Annotations) == 0 {
+		n.Annotations = []string{DefaultNodeInterruptionAnnotation}
+	}
+	if n.GracePeriod == 0 {
+		n.GracePeriod = DefaultNodeInterruptionGracePeriod
+	}
+	return n
+}
+
+type NotificationEvent string
+
+const (
+	NotificationEventSucceeded   NotificationEvent = "Succeeded"
+	NotificationEventFailed      NotificationEvent = "Failed"
+	NotificationEventLongRunning NotificationEvent = "LongRunning"
+)
+
+// Notifications configures webhook notifications on Spark application events.
+// Each event of an application is notified once per matching rule.
+type Notifications struct {
+	// Rules route application events to webhooks, all matching rules are notified
+	Rules []NotificationRule `yaml:"rules"`
+}
+
+// NotificationRule matches application events and the webhook they are sent to,
+// empty match fields match all applications
+type NotificationRule struct {
+	// Name identifies the rule, notifications are sent once per application, event and rule
+	Name string `yaml:"name"`
+
+	// Namespaces restricts the rule to applications in the given namespaces
+	Namespaces []string `yaml:"namespaces"`
+	// Heritages restricts the rule to applications with the given heritage, e.g. spark-operator
+	Heritages []string `yaml:"heritages"`
+	// ApplicationName is a regular expression, in Go syntax, matched against the application name
+	ApplicationName string `yaml:"applicationName"`
+	// Events are the events notified, Succeeded, Failed and LongRunning
+	Events []NotificationEvent `yaml:"events"`
+	// LongRunningThreshold is how long the driver runs before the LongRunning event, required for LongRunning events
+	LongRunningThreshold time.Duration `yaml:"longRunningThreshold"`
+
+	// Webhook receives the notifications
+	Webhook Webhook `yaml:"webhook"`
+}
+
+// Webhook describes an HTTP endpoint notifications are posted to
+type Webhook struct {
+	// URL of the endpoint
+	URL string `yaml:"url"`
+	// Headers are added to notification requests, e.g. for authentication
+	Headers map[string]string `yaml:"headers"`
+	// Template is a Go text/template rendering the request body from the notification,
+	// the notification is sent as JSON if no template is set.
+	// The template is validated when the notification controller starts
+	Template string `yaml:"template"`
+	// ContentType of the request body, defaults to application/json
+	ContentType string `yaml:"contentType"`
+}
+
+// OpenLineage configures the emission of OpenLineage run events for Spark applications over HTTP.
+// OpenLineage is disabled if no URL is configured.
+type OpenLineage struct {
+	// URL is the base URL of the OpenLineage HTTP backend, e.g. http://marquez:5000,
+	// events are sent to the /api/v1/lineage path of the URL
+	URL string `yaml:"url"`
+	// Headers are added to event requests, e.g. for authentication
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Tracing configures the export of finished Spark applications as OpenTelemetry traces over OTLP/HTTP.
+// Tracing is disabled if no endpoint is configured.
+type Tracing struct {
+	// Endpoint is the base URL of the OTLP/HTTP receiver, e.g. http://otel-collector:4318,
+	// traces are sent to the /v1/traces path of the endpoint
+	Endpoint string `yaml:"endpoint"`
+	// Headers are added to export requests, e.g. for authentication
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Redaction configures the redaction of Spark property values before they are stored in
+// SparkApplication resources or exported. Properties are always redacted according to the
+// application's spark.redaction.regex, or Spark's default redaction regex.
+type Redaction struct {
+	// Patterns are additional regular expressions, in Go syntax, matched against Spark property keys and values
+	Patterns []string `yaml:"patterns"`
+	// HashKeyFile is the path of a file holding the key of the HMAC kept in redacted values, e.g. mounted from a
+	// secret, so changed values can be detected. Without a key, redacted values are replaced by a marker only.
+	HashKeyFile string `yaml:"hashKeyFile"`
+}
+
+// LoadHashKey reads the key of the HMAC kept in redacted values, returns nil if no key file is configured
+func (r Redaction) LoadHashKey() ([]byte, error) {
+	if r.HashKeyFile == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(r.HashKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read redaction hash key, %w", err)
+	}
+
+	key := bytes.TrimSpace(data)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("redaction hash key file %q is empty", r.HashKeyFile)
+	}
+	return key, nil
+}
+
+// EventLogs configures reading Spark event logs directly, to get information on finished applications
+// without a history server
+type EventLogs struct {
+	// Enabled determines if event logs are read when no history server is available for an application.
+	// Event logs are read from the wave storage bucket for applications with event log sync enabled,
+	// otherwise from the application's spark.eventLog.dir, which must be a local path or an S3 location.
+	Enabled bool `yaml:"enabled"`
+}
+
+// HistoryServer describes a Spark history server and the applications it serves
+type HistoryServer struct {
+	// Name identifies the history server
+	Name string `yaml:"name"`
+
+	// Namespace is the namespace of the history server service, defaults to the wave system namespace
+	Namespace string `yaml:"namespace"`
+	// Selector is a label selector for the history server service,
+	// defaults to the label of the history server installed by wave.
+	// If several services match, the first service by name is used.
+	Selector string `yaml:"selector"`
+	// URL of a history server running outside the cluster, takes precedence over namespace and selector
+	URL string `yaml:"url"`
+
+	// EventLogDirs are the event log directories the history server reads, matched as prefixes
+	// of an application's spark.eventLog.dir property.
+	// Applications with wave event log sync enabled are matched on SyncedEventLogDir
+	EventLogDirs []string `yaml:"eventLogDirs"`
+	// ApplicationNamespaces restricts the history server to applications in the given namespaces
+	ApplicationNamespaces []string `yaml:"applicationNamespaces"`
+	// SparkVersions restricts the history server to applications running the given Spark versions,
+	// matched as prefixes of the application's Spark version, e.g. "2" or "3.1"
+	SparkVersions []string `yaml:"sparkVersions"`
+}
+
+// LoadOperatorConfig reads the operator configuration file at the given path,
+// an empty path results in an empty configuration
+func LoadOperatorConfig(path string) (*OperatorConfig, error) {
+	if path == "" {
+		return &OperatorConfig{}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open operator configuration file, %w", err)
+	}
+	defer f.Close()
+
+	return ParseOperatorConfig(f)
+}
+
+// ParseOperatorConfig parses and validates the operator configuration
+func ParseOperatorConfig(r io.Reader) (*OperatorConfig, error) {
+	conf := &OperatorConfig{}
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(conf); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not parse operator configuration, %w", err)
+	}
+
+	if err := conf.validate(); err != nil {
+		return nil, fmt.Errorf("invalid operator configuration, %w", err)
+	}
+
+	return conf, nil
+}
+
+func (c *OperatorConfig) validate() error {
+	if c.Tracing.Endpoint != "" {
+		u, err := url.Parse(c.Tracing.Endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid tracing endpoint, %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid tracing endpoint %q, must be an http or https url", c.Tracing.Endpoint)
+		}
+	}
+	if c.OpenLineage.URL != "" {
+		u, err := url.Parse(c.OpenLineage.URL)
+		if err != nil {
+			return fmt.Errorf("invalid openLineage url, %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid openLineage url %q, must be an http or https url", c.OpenLineage.URL)
+		}
+	}
+	if err := c.Notifications.validate(); err != nil {
+		return err
+	}
+	if err := c.StorageSync.validate(); err != nil {
+		return fmt.Errorf("storage sync: %w", err)
+	}
+	if err := c.AdmissionWebhook.validate(); err != nil {
+		return fmt.Errorf("admission webhook: %w", err)
+	}
+	for _, conf := range []map[string]string{c.SparkConf.Defaults, c.SparkConf.Overrides} {
+		for k := range conf {
+			if strings.TrimSpace(k) == "" {
+				return fmt.Errorf("spark conf: empty property key")
+			}
+		}
+	}
+	if c.NodeInterruption.GracePeriod < 0 {
+		return fmt.Errorf("node interruption: invalid gracePeriod %s, must not be negative", c.NodeInterruption.GracePeriod)
+	}
+	for _, pattern := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redaction pattern %q, %w", pattern, err)
+		}
+	}
+	for i, hs := range c.HistoryServers {
+		if hs.Name == "" {
+			return fmt.Errorf("history server %d: name missing", i)
+		}
+		if hs.URL != "" && (hs.Namespace != "" || hs.Selector != "") {
+			return fmt.Errorf("history server %q: url can not be combined with namespace or selector", hs.Name)
+		}
+		if hs.Selector != "" {
+			if _, err := labels.Parse(hs.Selector); err != nil {
+				return fmt.Errorf("history server %q: invalid selector, %w", hs.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (n *Notifications) validate() error {
+	names := make(map[string]bool)
+	for i, rule := range n.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("notification rule %d: name missing", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("notification rule %q: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if len(rule.Events) == 0 {
+			return fmt.Errorf("notification rule %q: events missing", rule.Name)
+		}
+		for _, event := range rule.Events {
+			switch event {
+			case NotificationEventSucceeded, NotificationEventFailed:
+			case NotificationEventLongRunning:
+				if rule.LongRunningThreshold <= 0 {
+					return fmt.Errorf("notification rule %q: longRunningThreshold required for %s events", rule.Name, event)
+				}
+			default:
+				return fmt.Errorf("notification rule %q: unknown event %q, must be one of %q, %q, %q", rule.Name, event,
+					NotificationEventSucceeded, NotificationEventFailed, NotificationEventLongRunning)
+			}
+		}
+		if _, err := regexp.Compile(rule.ApplicationName); err != nil {
+			return fmt.Errorf("notification rule %q: invalid applicationName, %w", rule.Name, err)
+		}
+
+		u, err := url.Parse(rule.Webhook.URL)
+		if err != nil {
+			return fmt.Errorf("notification rule %q: invalid webhook url, %w", rule.Name, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notification rule %q: invalid webhook url %q, must be an http or https url", rule.Name, rule.Webhook.URL)
+		}
+	}
+	return nil
+}
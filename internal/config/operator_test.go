@@ -0,0 +1,359 @@
+package config
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestLoadOperatorConfig(t *testing.T) {
+
+	t.Run("whenPathEmpty", func(tt *testing.T) {
+		conf, err := LoadOperatorConfig("")
+		require.NoError(tt, err)
+		assert.Empty(tt, conf.HistoryServers)
+	})
+
+	t.Run("whenFileMissing", func(tt *testing.T) {
+		_, err := LoadOperatorConfig(filepath.Join(tt.TempDir(), "missing.yaml"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenFileExists", func(tt *testing.T) {
+		path := filepath.Join(tt.TempDir(), "config.yaml")
+		err := os.WriteFile(path, []byte(`
+historyServers:
+- name: team-a
+  namespace: team-a
+  selector: app.kubernetes.io/name=spark-history-server
+  eventLogDirs:
+  - s3a://team-a-spark-logs/
+  applicationNamespaces:
+  - team-a
+- name: legacy
+  url: https://spark-history.example.com
+  sparkVersions:
+  - "2"
+`), 0600)
+		require.NoError(tt, err)
+
+		conf, err := LoadOperatorConfig(path)
+		require.NoError(tt, err)
+		require.Equal(tt, 2, len(conf.HistoryServers))
+		assert.Equal(tt, HistoryServer{
+			Name:                  "team-a",
+			Namespace:             "team-a",
+			Selector:              "app.kubernetes.io/name=spark-history-server",
+			EventLogDirs:          []string{"s3a://team-a-spark-logs/"},
+			ApplicationNamespaces: []string{"team-a"},
+		}, conf.HistoryServers[0])
+		assert.Equal(tt, HistoryServer{
+			Name:          "legacy",
+			URL:           "https://spark-history.example.com",
+			SparkVersions: []string{"2"},
+		}, conf.HistoryServers[1])
+	})
+}
+
+func TestParseOperatorConfig(t *testing.T) {
+
+	t.Run("whenEmpty", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(""))
+		require.NoError(tt, err)
+		assert.Empty(tt, conf.HistoryServers)
+		assert.False(tt, conf.EventLogs.Enabled)
+	})
+
+	t.Run("whenEventLogsEnabled", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader("eventLogs:\n  enabled: true"))
+		require.NoError(tt, err)
+		assert.True(tt, conf.EventLogs.Enabled)
+	})
+
+	t.Run("whenRedactionPatterns", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader("redaction:\n  patterns:\n  - (?i)jdbc\n  - credential"))
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"(?i)jdbc", "credential"}, conf.Redaction.Patterns)
+	})
+
+	t.Run("whenRedactionPatternInvalid", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("redaction:\n  patterns:\n  - secret(\n"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenRedactionHashKeyFile", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader("redaction:\n  hashKeyFile: /etc/wave-operator-redaction/hash-key"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "/etc/wave-operator-redaction/hash-key", conf.Redaction.HashKeyFile)
+	})
+
+	t.Run("whenTracingEndpoint", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader("tracing:\n  endpoint: http://otel-collector:4318\n  headers:\n    x-api-key: abc"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "http://otel-collector:4318", conf.Tracing.Endpoint)
+		assert.Equal(tt, map[string]string{"x-api-key": "abc"}, conf.Tracing.Headers)
+	})
+
+	t.Run("whenTracingEndpointInvalid", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("tracing:\n  endpoint: otel-collector:4318"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenOpenLineageURL", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader("openLineage:\n  url: http://marquez:5000\n  headers:\n    Authorization: Bearer abc"))
+		require.NoError(tt, err)
+		assert.Equal(tt, "http://marquez:5000", conf.OpenLineage.URL)
+		assert.Equal(tt, map[string]string{"Authorization": "Bearer abc"}, conf.OpenLineage.Headers)
+	})
+
+	t.Run("whenOpenLineageURLInvalid", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("openLineage:\n  url: marquez"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenNotificationRules", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(`
+notifications:
+  rules:
+  - name: team-a-failures
+    namespaces: [team-a]
+    applicationName: ^etl-
+    events: [Failed, LongRunning]
+    longRunningThreshold: 2h
+    webhook:
+      url: https://hooks.example.com/team-a
+      template: '{"text": {{ .Application.Name | json }}}'
+`))
+		require.NoError(tt, err)
+		require.Len(tt, conf.Notifications.Rules, 1)
+		rule := conf.Notifications.Rules[0]
+		assert.Equal(tt, []string{"team-a"}, rule.Namespaces)
+		assert.Equal(tt, []NotificationEvent{NotificationEventFailed, NotificationEventLongRunning}, rule.Events)
+		assert.Equal(tt, 2*time.Hour, rule.LongRunningThreshold)
+		assert.Equal(tt, "https://hooks.example.com/team-a", rule.Webhook.URL)
+	})
+
+	t.Run("whenNotificationRuleInvalid", func(tt *testing.T) {
+		rules := map[string]string{
+			"nameMissing":       "- events: [Failed]\n    webhook: {url: http://a}",
+			"duplicateName":     "- name: a\n    events: [Failed]\n    webhook: {url: http://a}\n  - name: a\n    events: [Failed]\n    webhook: {url: http://a}",
+			"eventsMissing":     "- name: a\n    webhook: {url: http://a}",
+			"eventUnknown":      "- name: a\n    events: [Running]\n    webhook: {url: http://a}",
+			"thresholdMissing":  "- name: a\n    events: [LongRunning]\n    webhook: {url: http://a}",
+			"applicationName":   "- name: a\n    applicationName: a(\n    events: [Failed]\n    webhook: {url: http://a}",
+			"webhookURLMissing": "- name: a\n    events: [Failed]",
+		}
+		for name, rule := range rules {
+			_, err := ParseOperatorConfig(strings.NewReader("notifications:\n  rules:\n  " + rule))
+			assert.Error(tt, err, name)
+		}
+	})
+
+	t.Run("whenStorageSync", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(`
+storageSync:
+  image: registry.example.com/cloud-storage-sync:v1
+  imagePullPolicy: Always
+  imagePullSecrets: [registry]
+  resources:
+    requests:
+      cpu: 100m
+      memory: 128Mi
+  securityContext:
+    runAsNonRoot: true
+  syncInterval: 10s
+  terminationGracePeriod: 10m
+`))
+		require.NoError(tt, err)
+		s := conf.StorageSync
+		assert.Equal(tt, "registry.example.com/cloud-storage-sync:v1", s.Image)
+		assert.Equal(tt, corev1.PullAlways, s.ImagePullPolicy)
+		assert.Equal(tt, []string{"registry"}, s.ImagePullSecrets)
+		assert.Equal(tt, resource.MustParse("100m"), s.Resources.Requests[corev1.ResourceCPU])
+		assert.Equal(tt, resource.MustParse("128Mi"), s.Resources.Requests[corev1.ResourceMemory])
+		require.NotNil(tt, s.SecurityContext)
+		assert.True(tt, *s.SecurityContext.RunAsNonRoot)
+		assert.Equal(tt, 10*time.Second, s.SyncInterval)
+		assert.Equal(tt, 10*time.Minute, s.TerminationGracePeriod)
+	})
+
+	t.Run("whenStorageSyncDefaults", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(""))
+		require.NoError(tt, err)
+		s := conf.StorageSync.WithDefaults()
+		assert.Equal(tt, DefaultStorageSyncImage, s.Image)
+		assert.Equal(tt, corev1.PullIfNotPresent, s.ImagePullPolicy)
+		assert.Equal(tt, 300*time.Second, s.TerminationGracePeriod)
+		assert.Equal(tt, time.Duration(0), s.SyncInterval)
+	})
+
+	t.Run("whenStorageSyncInvalid", func(tt *testing.T) {
+		configs := map[string]string{
+			"pullPolicy":      "imagePullPolicy: Sometimes",
+			"resources":       "resources: {requests: {cpu: lots}}",
+			"resourcesField":  "resources: {request: {cpu: 1}}",
+			"securityContext": "securityContext: {runAsRoot: true}",
+			"syncInterval":    "syncInterval: -1s",
+			"gracePeriod":     "terminationGracePeriod: -1s",
+		}
+		for name, c := range configs {
+			_, err := ParseOperatorConfig(strings.NewReader("storageSync:\n  " + c))
+			assert.Error(tt, err, name)
+		}
+	})
+
+	t.Run("whenNodeInterruption", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(`
+nodeInterruption:
+  enabled: true
+  taints: [example.com/reclaim]
+  ignoreCordon: true
+  gracePeriod: 90s
+`))
+		require.NoError(tt, err)
+		n := conf.NodeInterruption.WithDefaults()
+		assert.True(tt, n.Enabled)
+		assert.Equal(tt, []string{"example.com/reclaim"}, n.Taints)
+		assert.Equal(tt, []string{DefaultNodeInterruptionAnnotation}, n.Annotations)
+		assert.True(tt, n.IgnoreCordon)
+		assert.Equal(tt, 90*time.Second, n.GracePeriod)
+	})
+
+	t.Run("whenNodeInterruptionDefaults", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(""))
+		require.NoError(tt, err)
+		n := conf.NodeInterruption.WithDefaults()
+		assert.False(tt, n.Enabled)
+		assert.Equal(tt, DefaultNodeInterruptionTaints, n.Taints)
+		assert.False(tt, n.IgnoreCordon)
+		assert.Equal(tt, 120*time.Second, n.GracePeriod)
+	})
+
+	t.Run("whenNodeInterruptionInvalid", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("nodeInterruption:\n  gracePeriod: -1s"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenAdmissionWebhook", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(`
+admissionWebhook:
+  port: 8443
+  minTLSVersion: "1.3"
+  cipherSuites:
+  - TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+`))
+		require.NoError(tt, err)
+		a := conf.AdmissionWebhook.WithDefaults()
+		assert.Equal(tt, 8443, a.Port)
+		assert.Equal(tt, DefaultAdmissionWebhookCertDir, a.CertDir)
+		version, err := a.GetMinTLSVersion()
+		require.NoError(tt, err)
+		assert.Equal(tt, uint16(tls.VersionTLS13), version)
+		suites, err := a.GetCipherSuites()
+		require.NoError(tt, err)
+		assert.Equal(tt, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, suites)
+	})
+
+	t.Run("whenAdmissionWebhookDefaults", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(""))
+		require.NoError(tt, err)
+		a := conf.AdmissionWebhook.WithDefaults()
+		assert.Equal(tt, DefaultAdmissionWebhookPort, a.Port)
+		assert.Equal(tt, DefaultAdmissionWebhookCertDir, a.CertDir)
+		version, err := a.GetMinTLSVersion()
+		require.NoError(tt, err)
+		assert.Equal(tt, uint16(tls.VersionTLS12), version)
+		suites, err := a.GetCipherSuites()
+		require.NoError(tt, err)
+		assert.Nil(tt, suites)
+	})
+
+	t.Run("whenAdmissionWebhookInvalid", func(tt *testing.T) {
+		configs := map[string]string{
+			"port":         "port: 70000",
+			"version":      "minTLSVersion: \"1.0\"",
+			"cipherSuites": "cipherSuites: [TLS_RSA_WITH_RC4_128_SHA]",
+		}
+		for name, c := range configs {
+			_, err := ParseOperatorConfig(strings.NewReader("admissionWebhook:\n  " + c))
+			assert.Error(tt, err, name)
+		}
+	})
+
+	t.Run("whenSparkConf", func(tt *testing.T) {
+		conf, err := ParseOperatorConfig(strings.NewReader(`
+sparkConf:
+  defaults:
+    spark.sql.adaptive.enabled: "true"
+  overrides:
+    spark.hadoop.fs.s3a.committer.name: magic
+`))
+		require.NoError(tt, err)
+		assert.Equal(tt, map[string]string{"spark.sql.adaptive.enabled": "true"}, conf.SparkConf.Defaults)
+		assert.Equal(tt, map[string]string{"spark.hadoop.fs.s3a.committer.name": "magic"}, conf.SparkConf.Overrides)
+	})
+
+	t.Run("whenSparkConfInvalid", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("sparkConf:\n  defaults:\n    \"\": value"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenUnknownField", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("historyServer: []"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenHistoryServerNameMissing", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("historyServers:\n- namespace: team-a"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenHistoryServerURLAndSelector", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("historyServers:\n- name: a\n  url: http://a\n  selector: a=b"))
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenHistoryServerSelectorInvalid", func(tt *testing.T) {
+		_, err := ParseOperatorConfig(strings.NewReader("historyServers:\n- name: a\n  selector: a=(b"))
+		assert.Error(tt, err)
+	})
+}
+
+func TestRedactionLoadHashKey(t *testing.T) {
+
+	t.Run("whenNoKeyFile", func(tt *testing.T) {
+		key, err := Redaction{}.LoadHashKey()
+		require.NoError(tt, err)
+		assert.Nil(tt, key)
+	})
+
+	t.Run("whenKeyFile", func(tt *testing.T) {
+		path := filepath.Join(tt.TempDir(), "hash-key")
+		require.NoError(tt, os.WriteFile(path, []byte("s3cr3t\n"), 0600))
+
+		key, err := Redaction{HashKeyFile: path}.LoadHashKey()
+		require.NoError(tt, err)
+		assert.Equal(tt, []byte("s3cr3t"), key)
+	})
+
+	t.Run("whenKeyFileEmpty", func(tt *testing.T) {
+		path := filepath.Join(tt.TempDir(), "hash-key")
+		require.NoError(tt, os.WriteFile(path, []byte("\n"), 0600))
+
+		_, err := Redaction{HashKeyFile: path}.LoadHashKey()
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenKeyFileMissing", func(tt *testing.T) {
+		_, err := Redaction{HashKeyFile: filepath.Join(tt.TempDir(), "missing")}.LoadHashKey()
+		assert.Error(tt, err)
+	})
+}
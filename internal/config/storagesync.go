@@ -0,0 +1,184 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	DefaultStorageSyncImage                  = "public.ecr.aws/l8m2k1n1/netapp/cloud-storage-sync:v0.4.0"
+	DefaultStorageSyncImagePullPolicy        = corev1.PullIfNotPresent
+	DefaultStorageSyncTerminationGracePeriod = 300 * time.Second
+
+	// Driver pod annotations overriding the operator's storage sync configuration
+	WaveConfigAnnotationStorageSyncImage                  = "wave.spot.io/storage-sync-image"
+	WaveConfigAnnotationStorageSyncImagePullPolicy        = "wave.spot.io/storage-sync-image-pull-policy"
+	WaveConfigAnnotationStorageSyncImagePullSecrets       = "wave.spot.io/storage-sync-image-pull-secrets"
+	WaveConfigAnnotationStorageSyncResources              = "wave.spot.io/storage-sync-resources"
+	WaveConfigAnnotationStorageSyncSecurityContext        = "wave.spot.io/storage-sync-security-context"
+	WaveConfigAnnotationStorageSyncInterval               = "wave.spot.io/storage-sync-interval"
+	WaveConfigAnnotationStorageSyncTerminationGracePeriod = "wave.spot.io/storage-sync-termination-grace-period"
+)
+
+// StorageSync configures the storage sync sidecar container added to driver pods with event log sync enabled.
+// Each setting can be overridden per driver pod with the corresponding wave.spot.io/storage-sync-* annotation.
+type StorageSync struct {
+	// Image of the storage sync container
+	Image string `yaml:"image"`
+	// ImagePullPolicy of the storage sync container, Always, IfNotPresent or Never
+	ImagePullPolicy corev1.PullPolicy `yaml:"imagePullPolicy"`
+	// ImagePullSecrets are the names of secrets added to the driver pod's image pull secrets
+	ImagePullSecrets []string `yaml:"imagePullSecrets"`
+	// Resources of the storage sync container, in the format of a container's resources
+	Resources ResourceRequirements `yaml:"resources"`
+	// SecurityContext of the storage sync container, in the format of a container's security context
+	SecurityContext *SecurityContext `yaml:"securityContext"`
+	// SyncInterval is how often event logs are synced while the driver runs, defaults to the image's interval.
+	// The interval is passed in the SYNC_INTERVAL_SECONDS environment variable, which requires cloud-storage-sync
+	// v0.5.0 or later, the default v0.4.0 image ignores it.
+	SyncInterval time.Duration `yaml:"syncInterval"`
+	// TerminationGracePeriod is the minimum termination grace period of the driver pod,
+	// giving the storage sync container time to sync the final event log
+	TerminationGracePeriod time.Duration `yaml:"terminationGracePeriod"`
+}
+
+// ResourceRequirements are container resources, decoded in their Kubernetes format
+type ResourceRequirements struct {
+	corev1.ResourceRequirements
+}
+
+func (r *ResourceRequirements) UnmarshalYAML(value *yaml.Node) error {
+	return decodeAsJSON(value, &r.ResourceRequirements)
+}
+
+// SecurityContext is a container security context, decoded in its Kubernetes format
+type SecurityContext struct {
+	corev1.SecurityContext
+}
+
+func (s *SecurityContext) UnmarshalYAML(value *yaml.Node) error {
+	return decodeAsJSON(value, &s.SecurityContext)
+}
+
+// decodeAsJSON decodes the YAML node into a Kubernetes type through its JSON encoding,
+// so field names and quantities are read as in Kubernetes manifests
+func decodeAsJSON(value *yaml.Node, out interface{}) error {
+	var v interface{}
+	if err := value.Decode(&v); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return unmarshalStrict(b, out)
+}
+
+func unmarshalStrict(b []byte, out interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
+
+// WithDefaults returns the configuration with defaults for unset settings
+func (s StorageSync) WithDefaults() StorageSync {
+	if s.Image == "" {
+		s.Image = DefaultStorageSyncImage
+	}
+	if s.ImagePullPolicy == "" {
+		s.ImagePullPolicy = DefaultStorageSyncImagePullPolicy
+	}
+	if s.TerminationGracePeriod == 0 {
+		s.TerminationGracePeriod = DefaultStorageSyncTerminationGracePeriod
+	}
+	return s
+}
+
+func (s StorageSync) validate() error {
+	if err := validatePullPolicy(s.ImagePullPolicy); err != nil {
+		return err
+	}
+	if s.SyncInterval < 0 {
+		return fmt.Errorf("invalid syncInterval %s, must not be negative", s.SyncInterval)
+	}
+	if s.TerminationGracePeriod < 0 {
+		return fmt.Errorf("invalid terminationGracePeriod %s, must not be negative", s.TerminationGracePeriod)
+	}
+	return nil
+}
+
+func validatePullPolicy(policy corev1.PullPolicy) error {
+	switch policy {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return nil
+	default:
+		return fmt.Errorf("invalid image pull policy %q, must be one of %q, %q, %q", policy,
+			corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever)
+	}
+}
+
+// GetStorageSyncConfig returns the storage sync configuration of a driver pod, the operator configuration
+// with the overrides in the pod's annotations. Invalid annotations are ignored.
+func GetStorageSyncConfig(annotations map[string]string, conf StorageSync, log logr.Logger) StorageSync {
+	conf = conf.WithDefaults()
+
+	if image := strings.TrimSpace(annotations[WaveConfigAnnotationStorageSyncImage]); image != "" {
+		conf.Image = image
+	}
+
+	if policy := corev1.PullPolicy(strings.TrimSpace(annotations[WaveConfigAnnotationStorageSyncImagePullPolicy])); policy != "" {
+		if err := validatePullPolicy(policy); err != nil {
+			log.Info(fmt.Sprintf("Ignoring %s annotation, %s", WaveConfigAnnotationStorageSyncImagePullPolicy, err.Error()))
+		} else {
+			conf.ImagePullPolicy = policy
+		}
+	}
+
+	if secrets := annotations[WaveConfigAnnotationStorageSyncImagePullSecrets]; secrets != "" {
+		conf.ImagePullSecrets = nil
+		for _, secret := range strings.Split(secrets, ",") {
+			if secret = strings.TrimSpace(secret); secret != "" {
+				conf.ImagePullSecrets = append(conf.ImagePullSecrets, secret)
+			}
+		}
+	}
+
+	if resources := annotations[WaveConfigAnnotationStorageSyncResources]; resources != "" {
+		r := corev1.ResourceRequirements{}
+		if err := unmarshalStrict([]byte(resources), &r); err != nil {
+			log.Info(fmt.Sprintf("Ignoring %s annotation, %s", WaveConfigAnnotationStorageSyncResources, err.Error()))
+		} else {
+			conf.Resources = ResourceRequirements{r}
+		}
+	}
+
+	if securityContext := annotations[WaveConfigAnnotationStorageSyncSecurityContext]; securityContext != "" {
+		sc := corev1.SecurityContext{}
+		if err := unmarshalStrict([]byte(securityContext), &sc); err != nil {
+			log.Info(fmt.Sprintf("Ignoring %s annotation, %s", WaveConfigAnnotationStorageSyncSecurityContext, err.Error()))
+		} else {
+			conf.SecurityContext = &SecurityContext{sc}
+		}
+	}
+
+	if interval, err := GetDuration(annotations, WaveConfigAnnotationStorageSyncInterval); err != nil {
+		log.Info(fmt.Sprintf("Ignoring %s", err.Error()))
+	} else if interval > 0 {
+		conf.SyncInterval = interval
+	}
+
+	if gracePeriod, err := GetDuration(annotations, WaveConfigAnnotationStorageSyncTerminationGracePeriod); err != nil {
+		log.Info(fmt.Sprintf("Ignoring %s", err.Error()))
+	} else if gracePeriod > 0 {
+		conf.TerminationGracePeriod = gracePeriod
+	}
+
+	return conf
+}
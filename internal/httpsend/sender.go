@@ -0,0 +1,68 @@
+package httpsend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 15 * time.Second
+
+	// maxErrorBodyLength bounds the part of an error response included in send errors
+	maxErrorBodyLength = 512
+)
+
+// Sender posts request bodies to HTTP endpoints, such as OpenLineage backends, OTLP receivers and webhooks
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a sender with the default request timeout
+func NewSender() *Sender {
+	return &Sender{
+		client: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}
+}
+
+// PostJSON posts the JSON encoding of v to the url
+func (s *Sender) PostJSON(ctx context.Context, url string, headers map[string]string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not marshal request body, %w", err)
+	}
+	return s.Post(ctx, url, "application/json", headers, body)
+}
+
+// Post posts the body to the url, responses with a non 2xx status code are returned as errors
+// including the beginning of the response body
+func (s *Sender) Post(ctx context.Context, url string, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create request, %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		return fmt.Errorf("request rejected, code: %d, %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
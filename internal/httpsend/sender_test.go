@@ -0,0 +1,76 @@
+package httpsend
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostJSON(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("whenAccepted", func(tt *testing.T) {
+		var received map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, http.MethodPost, r.Method)
+			assert.Equal(tt, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(tt, "secret", r.Header.Get("X-Api-Key"))
+			require.NoError(tt, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		err := NewSender().PostJSON(ctx, server.URL, map[string]string{"X-Api-Key": "secret"}, map[string]string{"key": "value"})
+		require.NoError(tt, err)
+		assert.Equal(tt, map[string]string{"key": "value"}, received)
+	})
+
+	t.Run("whenNotMarshalable", func(tt *testing.T) {
+		err := NewSender().PostJSON(ctx, "http://localhost", nil, func() {})
+		assert.Error(tt, err)
+	})
+}
+
+func TestPost(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("whenAccepted", func(tt *testing.T) {
+		var received string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, "text/plain", r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			received = string(body)
+		}))
+		defer server.Close()
+
+		require.NoError(tt, NewSender().Post(ctx, server.URL, "text/plain", nil, []byte("hello")))
+		assert.Equal(tt, "hello", received)
+	})
+
+	t.Run("whenRejected", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid payload\n" + strings.Repeat("a", 2*maxErrorBodyLength)))
+		}))
+		defer server.Close()
+
+		err := NewSender().Post(ctx, server.URL, "text/plain", nil, []byte("hello"))
+		require.Error(tt, err)
+		assert.Contains(tt, err.Error(), "code: 400, invalid payload")
+		assert.Less(tt, len(err.Error()), 2*maxErrorBodyLength)
+	})
+
+	t.Run("whenUnreachable", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		assert.Error(tt, NewSender().Post(ctx, server.URL, "text/plain", nil, []byte("hello")))
+	})
+}
@@ -0,0 +1,139 @@
+package lineage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/version"
+)
+
+const (
+	producer = "https://github.com/spotinst/wave-operator"
+
+	runEventSchemaURL         = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/RunEvent"
+	runFacetSchemaURL         = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/RunFacet"
+	processingEngineSchemaURL = "https://openlineage.io/spec/facets/1-1-1/ProcessingEngineRunFacet.json#/$defs/ProcessingEngineRunFacet"
+	jobTypeSchemaURL          = "https://openlineage.io/spec/facets/2-0-2/JobTypeJobFacet.json#/$defs/JobTypeJobFacet"
+
+	workloadTypeStreaming = "spark-streaming"
+
+	eventTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// NewRunEvent returns the OpenLineage run event of the Spark application.
+// The run is identified by the SparkApplication resource, the job by the application name within its namespace,
+// so runs of the same application are grouped into one job.
+// Datasets are the file datasets found in the application's SQL execution plans, if any.
+func NewRunEvent(app *v1alpha1.SparkApplication, eventType EventType, eventTime time.Time) *RunEvent {
+	data := app.Status.Data
+
+	name := app.Spec.ApplicationName
+	if name == "" {
+		name = app.Spec.ApplicationID
+	}
+
+	event := &RunEvent{
+		EventType: eventType,
+		EventTime: eventTime.UTC().Format(eventTimeFormat),
+		Run: Run{
+			RunID:  getRunID(app),
+			Facets: getRunFacets(app),
+		},
+		Job: Job{
+			Namespace: app.Namespace,
+			Name:      name,
+			Facets: JobFacets{
+				JobType: &JobTypeJobFacet{
+					Facet:          newFacet(jobTypeSchemaURL),
+					ProcessingType: getProcessingType(app),
+					Integration:    "SPARK",
+					JobType:        "APPLICATION",
+				},
+			},
+		},
+		Inputs:    []Dataset{},
+		Outputs:   []Dataset{},
+		Producer:  producer,
+		SchemaURL: runEventSchemaURL,
+	}
+
+	if data.Lineage != nil {
+		event.Inputs = newDatasets(data.Lineage.Inputs)
+		event.Outputs = newDatasets(data.Lineage.Outputs)
+	}
+
+	return event
+}
+
+func getRunFacets(app *v1alpha1.SparkApplication) RunFacets {
+	data := app.Status.Data
+	facets := RunFacets{}
+
+	sparkVersion := ""
+	if data.Environment != nil {
+		sparkVersion = data.Environment.SparkVersion
+	}
+	if sparkVersion != "" {
+		facets.ProcessingEngine = &ProcessingEngineRunFacet{
+			Facet:   newFacet(processingEngineSchemaURL),
+			Name:    "spark",
+			Version: sparkVersion,
+		}
+	}
+
+	// Spark properties are redacted before they are stored in the SparkApplication resource
+	if len(data.SparkProperties) > 0 {
+		facets.SparkProperties = &SparkPropertiesRunFacet{
+			Facet:      newFacet(runFacetSchemaURL),
+			Properties: data.SparkProperties,
+		}
+	}
+
+	runtime := &WaveRuntimeRunFacet{
+		Facet:           newFacet(runFacetSchemaURL),
+		Heritage:        string(app.Spec.Heritage),
+		OperatorVersion: version.BuildVersion,
+	}
+	if data.Environment != nil {
+		runtime.JavaVersion = data.Environment.JavaVersion
+		runtime.ScalaVersion = data.Environment.ScalaVersion
+		runtime.Packages = data.Environment.Packages
+	}
+	facets.WaveRuntime = runtime
+
+	return facets
+}
+
+func getProcessingType(app *v1alpha1.SparkApplication) string {
+	if app.Annotations[config.WaveConfigAnnotationWorkloadType] == workloadTypeStreaming {
+		return "STREAMING"
+	}
+	return "BATCH"
+}
+
+// getRunID returns the UID of the SparkApplication resource, which is a UUID as required for run IDs,
+// or a UUID derived from the resource name if the UID is not set
+func getRunID(app *v1alpha1.SparkApplication) string {
+	if app.UID != "" {
+		return string(app.UID)
+	}
+	sum := sha256.Sum256([]byte(app.Namespace + "/" + app.Name))
+	sum[6] = (sum[6] & 0x0f) | 0x50 // version 5
+	sum[8] = (sum[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func newDatasets(datasets []v1alpha1.Dataset) []Dataset {
+	result := make([]Dataset, 0, len(datasets))
+	for _, dataset := range datasets {
+		result = append(result, Dataset{Namespace: dataset.Namespace, Name: dataset.Name})
+	}
+	return result
+}
+
+func newFacet(schemaURL string) Facet {
+	return Facet{Producer: producer, SchemaURL: schemaURL}
+}
@@ -0,0 +1,110 @@
+package lineage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+var testEventTime = time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestApplication() *v1alpha1.SparkApplication {
+	app := &v1alpha1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "spark-123",
+			Namespace:   "spark-jobs",
+			UID:         "8d4b9b0a-5c57-4b3e-9a55-1f6a2c0d7e11",
+			Annotations: map[string]string{},
+		},
+		Spec: v1alpha1.SparkApplicationSpec{
+			ApplicationID:   "spark-123",
+			ApplicationName: "daily-report",
+			Heritage:        v1alpha1.SparkHeritageOperator,
+		},
+	}
+	app.Status.Data.SparkProperties = map[string]string{
+		"spark.app.name":       "daily-report",
+		"spark.executor.cores": "2",
+	}
+	app.Status.Data.Environment = &v1alpha1.RuntimeEnvironment{
+		JavaVersion:  "1.8.0_275 (Oracle Corporation)",
+		ScalaVersion: "version 2.12.10",
+		SparkVersion: "3.0.1",
+		Packages:     []string{"org.apache.hadoop:hadoop-aws:3.2.0"},
+	}
+	app.Status.Data.Lineage = &v1alpha1.Lineage{
+		Inputs:  []v1alpha1.Dataset{{Namespace: "s3://input-bucket", Name: "events"}},
+		Outputs: []v1alpha1.Dataset{{Namespace: "s3://output-bucket", Name: "reports/daily"}},
+	}
+	return app
+}
+
+func TestNewRunEvent(t *testing.T) {
+
+	t.Run("whenFinished", func(tt *testing.T) {
+		event := NewRunEvent(newTestApplication(), EventTypeComplete, testEventTime)
+
+		assert.Equal(tt, EventTypeComplete, event.EventType)
+		assert.Equal(tt, "2021-03-01T10:00:00.000Z", event.EventTime)
+		assert.Equal(tt, "8d4b9b0a-5c57-4b3e-9a55-1f6a2c0d7e11", event.Run.RunID)
+		assert.Equal(tt, "spark-jobs", event.Job.Namespace)
+		assert.Equal(tt, "daily-report", event.Job.Name)
+		assert.Equal(tt, producer, event.Producer)
+		assert.Equal(tt, runEventSchemaURL, event.SchemaURL)
+
+		require.NotNil(tt, event.Job.Facets.JobType)
+		assert.Equal(tt, "BATCH", event.Job.Facets.JobType.ProcessingType)
+		assert.Equal(tt, "SPARK", event.Job.Facets.JobType.Integration)
+
+		require.NotNil(tt, event.Run.Facets.ProcessingEngine)
+		assert.Equal(tt, "3.0.1", event.Run.Facets.ProcessingEngine.Version)
+		require.NotNil(tt, event.Run.Facets.SparkProperties)
+		assert.Equal(tt, "2", event.Run.Facets.SparkProperties.Properties["spark.executor.cores"])
+		require.NotNil(tt, event.Run.Facets.WaveRuntime)
+		assert.Equal(tt, "spark-operator", event.Run.Facets.WaveRuntime.Heritage)
+		assert.Equal(tt, []string{"org.apache.hadoop:hadoop-aws:3.2.0"}, event.Run.Facets.WaveRuntime.Packages)
+
+		assert.Equal(tt, []Dataset{{Namespace: "s3://input-bucket", Name: "events"}}, event.Inputs)
+		assert.Equal(tt, []Dataset{{Namespace: "s3://output-bucket", Name: "reports/daily"}}, event.Outputs)
+	})
+
+	t.Run("whenNoSparkApiData", func(tt *testing.T) {
+		app := newTestApplication()
+		app.Spec.ApplicationName = ""
+		app.Status.Data = v1alpha1.SparkApplicationData{}
+
+		event := NewRunEvent(app, EventTypeStart, testEventTime)
+		assert.Equal(tt, "spark-123", event.Job.Name)
+		assert.Nil(tt, event.Run.Facets.ProcessingEngine)
+		assert.Nil(tt, event.Run.Facets.SparkProperties)
+		assert.NotNil(tt, event.Run.Facets.WaveRuntime)
+		assert.NotNil(tt, event.Inputs)
+		assert.Empty(tt, event.Inputs)
+		assert.NotNil(tt, event.Outputs)
+		assert.Empty(tt, event.Outputs)
+	})
+
+	t.Run("whenStreaming", func(tt *testing.T) {
+		app := newTestApplication()
+		app.Annotations[config.WaveConfigAnnotationWorkloadType] = workloadTypeStreaming
+
+		event := NewRunEvent(app, EventTypeStart, testEventTime)
+		assert.Equal(tt, "STREAMING", event.Job.Facets.JobType.ProcessingType)
+	})
+
+	t.Run("whenUIDMissing", func(tt *testing.T) {
+		app := newTestApplication()
+		app.UID = ""
+
+		first := NewRunEvent(app, EventTypeStart, testEventTime)
+		second := NewRunEvent(app, EventTypeComplete, testEventTime)
+		assert.Regexp(tt, `^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, first.Run.RunID)
+		assert.Equal(tt, first.Run.RunID, second.Run.RunID)
+	})
+}
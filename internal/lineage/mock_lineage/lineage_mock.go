@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/spotinst/wave-operator/internal/lineage (interfaces: Emitter)
+
+// Package mock_lineage is a generated GoMock package.
+package mock_lineage
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	v1alpha1 "github.com/spotinst/wave-operator/api/v1alpha1"
+	lineage "github.com/spotinst/wave-operator/internal/lineage"
+	reflect "reflect"
+	time "time"
+)
+
+// MockEmitter is a mock of Emitter interface
+type MockEmitter struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmitterMockRecorder
+}
+
+// MockEmitterMockRecorder is the mock recorder for MockEmitter
+type MockEmitterMockRecorder struct {
+	mock *MockEmitter
+}
+
+// NewMockEmitter creates a new mock instance
+func NewMockEmitter(ctrl *gomock.Controller) *MockEmitter {
+	mock := &MockEmitter{ctrl: ctrl}
+	mock.recorder = &MockEmitterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockEmitter) EXPECT() *MockEmitterMockRecorder {
+	return m.recorder
+}
+
+// Emit mocks base method
+func (m *MockEmitter) Emit(arg0 context.Context, arg1 *v1alpha1.SparkApplication, arg2 lineage.EventType, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Emit", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Emit indicates an expected call of Emit
+func (mr *MockEmitterMockRecorder) Emit(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Emit", reflect.TypeOf((*MockEmitter)(nil).Emit), arg0, arg1, arg2, arg3)
+}
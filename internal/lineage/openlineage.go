@@ -0,0 +1,125 @@
+//go:generate mockgen -destination=mock_lineage/lineage_mock.go . Emitter
+
+package lineage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/httpsend"
+)
+
+const lineagePath = "api/v1/lineage"
+
+type EventType string
+
+const (
+	EventTypeStart    EventType = "START"
+	EventTypeComplete EventType = "COMPLETE"
+	EventTypeFail     EventType = "FAIL"
+)
+
+// Emitter emits OpenLineage run events for Spark applications
+type Emitter interface {
+	Emit(ctx context.Context, app *v1alpha1.SparkApplication, eventType EventType, eventTime time.Time) error
+}
+
+type httpEmitter struct {
+	sender  *httpsend.Sender
+	url     string
+	headers map[string]string
+}
+
+// NewHTTPEmitter creates an emitter sending run events to the OpenLineage HTTP backend at the given base URL,
+// e.g. http://marquez:5000
+func NewHTTPEmitter(url string, headers map[string]string) Emitter {
+	return &httpEmitter{
+		sender:  httpsend.NewSender(),
+		url:     strings.TrimSuffix(url, "/") + "/" + lineagePath,
+		headers: headers,
+	}
+}
+
+func (e *httpEmitter) Emit(ctx context.Context, app *v1alpha1.SparkApplication, eventType EventType, eventTime time.Time) error {
+	err := e.sender.PostJSON(ctx, e.url, e.headers, NewRunEvent(app, eventType, eventTime))
+	if err != nil {
+		return fmt.Errorf("could not send run event, %w", err)
+	}
+	return nil
+}
+
+// The OpenLineage run event model, see https://openlineage.io/spec/2-0-2/OpenLineage.json
+// Facets carry the producer and schema of their definition.
+
+type RunEvent struct {
+	EventType EventType `json:"eventType"`
+	EventTime string    `json:"eventTime"`
+	Run       Run       `json:"run"`
+	Job       Job       `json:"job"`
+	Inputs    []Dataset `json:"inputs"`
+	Outputs   []Dataset `json:"outputs"`
+	Producer  string    `json:"producer"`
+	SchemaURL string    `json:"schemaURL"`
+}
+
+type Run struct {
+	RunID  string    `json:"runId"`
+	Facets RunFacets `json:"facets"`
+}
+
+type RunFacets struct {
+	ProcessingEngine *ProcessingEngineRunFacet `json:"processing_engine,omitempty"`
+	SparkProperties  *SparkPropertiesRunFacet  `json:"spark_properties,omitempty"`
+	WaveRuntime      *WaveRuntimeRunFacet      `json:"wave_runtime,omitempty"`
+}
+
+type Job struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Facets    JobFacets `json:"facets"`
+}
+
+type JobFacets struct {
+	JobType *JobTypeJobFacet `json:"jobType,omitempty"`
+}
+
+type Dataset struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type Facet struct {
+	Producer  string `json:"_producer"`
+	SchemaURL string `json:"_schemaURL"`
+}
+
+type ProcessingEngineRunFacet struct {
+	Facet
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type SparkPropertiesRunFacet struct {
+	Facet
+	Properties map[string]string `json:"properties"`
+}
+
+// WaveRuntimeRunFacet describes the runtime environment of the application, as collected by wave
+type WaveRuntimeRunFacet struct {
+	Facet
+	Heritage        string   `json:"heritage,omitempty"`
+	JavaVersion     string   `json:"javaVersion,omitempty"`
+	ScalaVersion    string   `json:"scalaVersion,omitempty"`
+	Packages        []string `json:"packages,omitempty"`
+	OperatorVersion string   `json:"operatorVersion,omitempty"`
+}
+
+type JobTypeJobFacet struct {
+	Facet
+	ProcessingType string `json:"processingType"`
+	Integration    string `json:"integration"`
+	JobType        string `json:"jobType"`
+}
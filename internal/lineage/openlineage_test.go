@@ -0,0 +1,49 @@
+package lineage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmit(t *testing.T) {
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+		var received RunEvent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, http.MethodPost, r.Method)
+			assert.Equal(tt, "/api/v1/lineage", r.URL.Path)
+			assert.Equal(tt, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(tt, "Bearer secret", r.Header.Get("Authorization"))
+			require.NoError(tt, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		emitter := NewHTTPEmitter(server.URL+"/", map[string]string{"Authorization": "Bearer secret"})
+		err := emitter.Emit(context.TODO(), newTestApplication(), EventTypeStart, testEventTime)
+		require.NoError(tt, err)
+		assert.Equal(tt, EventTypeStart, received.EventType)
+		assert.Equal(tt, "daily-report", received.Job.Name)
+		assert.Len(tt, received.Outputs, 1)
+	})
+
+	t.Run("whenRejected", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte("invalid event"))
+		}))
+		defer server.Close()
+
+		emitter := NewHTTPEmitter(server.URL, nil)
+		err := emitter.Emit(context.TODO(), newTestApplication(), EventTypeFail, testEventTime)
+		require.Error(tt, err)
+		assert.Contains(tt, err.Error(), "code: 422")
+		assert.Contains(tt, err.Error(), "invalid event")
+	})
+}
@@ -0,0 +1,103 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+// Notification is the notification of an application event for a rule,
+// it is the JSON payload sent to webhooks and the data of webhook templates
+type Notification struct {
+	// Rule is the name of the rule the notification was routed by
+	Rule string `json:"rule"`
+	// Event is the application event, Succeeded, Failed or LongRunning
+	Event config.NotificationEvent `json:"event"`
+	// DedupeKey identifies the event of the application, the same for all rules
+	DedupeKey string `json:"dedupeKey"`
+	// Timestamp is the time the notification was created
+	Timestamp time.Time `json:"timestamp"`
+	// Application describes the application
+	Application Application `json:"application"`
+
+	target *target
+}
+
+type Application struct {
+	Name         string     `json:"name"`
+	ID           string     `json:"id"`
+	Namespace    string     `json:"namespace"`
+	ResourceName string     `json:"resourceName"`
+	UID          string     `json:"uid"`
+	Heritage     string     `json:"heritage"`
+	Phase        string     `json:"phase"`
+	StartTime    *time.Time `json:"startTime,omitempty"`
+	FinishTime   *time.Time `json:"finishTime,omitempty"`
+	// DurationSeconds is the time the driver ran, until the notification if the application is running
+	DurationSeconds int64 `json:"durationSeconds"`
+}
+
+func newNotification(app *v1alpha1.SparkApplication, event config.NotificationEvent, rule *rule, now time.Time) *Notification {
+	driver := app.Status.Data.Driver
+
+	n := &Notification{
+		Rule:      rule.Name,
+		Event:     event,
+		DedupeKey: string(app.UID) + "/" + string(event),
+		Timestamp: now.UTC(),
+		Application: Application{
+			Name:         app.Spec.ApplicationName,
+			ID:           app.Spec.ApplicationID,
+			Namespace:    app.Namespace,
+			ResourceName: app.Name,
+			UID:          string(app.UID),
+			Heritage:     string(app.Spec.Heritage),
+			Phase:        string(driver.Phase),
+		},
+		target: rule.target,
+	}
+
+	end := now
+	if finish, ok := getPodPhaseTime(driver, driver.Phase); ok && driver.Phase != corev1.PodRunning {
+		finish = finish.UTC()
+		n.Application.FinishTime = &finish
+		end = finish
+	}
+	if start, ok := getPodPhaseTime(driver, corev1.PodRunning); ok {
+		start = start.UTC()
+		n.Application.StartTime = &start
+		if end.After(start) {
+			n.Application.DurationSeconds = int64(end.Sub(start).Seconds())
+		}
+	}
+
+	return n
+}
+
+// Key identifies the notification of the application, notifications are sent once per key
+func (n *Notification) Key() string {
+	return string(n.Event) + "/" + n.Rule
+}
+
+// render returns the request body of the notification
+func (n *Notification) render() ([]byte, error) {
+	if n.target.template == nil {
+		body, err := json.Marshal(n)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal notification, %w", err)
+		}
+		return body, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := n.target.template.Execute(buf, n); err != nil {
+		return nil, fmt.Errorf("could not render notification template, %w", err)
+	}
+	return buf.Bytes(), nil
+}
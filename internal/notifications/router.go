@@ -0,0 +1,156 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+// templateFuncs are available in webhook templates, json encodes a value, e.g. to quote a string
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Router matches application events against the notification rules
+type Router struct {
+	rules []*rule
+}
+
+type rule struct {
+	config.NotificationRule
+	applicationName *regexp.Regexp
+	target          *target
+}
+
+// target is the webhook a notification is sent to
+type target struct {
+	url         string
+	headers     map[string]string
+	contentType string
+	template    *template.Template
+}
+
+// NewRouter compiles the notification rules
+func NewRouter(conf config.Notifications) (*Router, error) {
+	r := &Router{}
+	for _, ruleConf := range conf.Rules {
+		applicationName, err := regexp.Compile(ruleConf.ApplicationName)
+		if err != nil {
+			return nil, fmt.Errorf("notification rule %q: invalid applicationName, %w", ruleConf.Name, err)
+		}
+
+		t := &target{
+			url:         ruleConf.Webhook.URL,
+			headers:     ruleConf.Webhook.Headers,
+			contentType: ruleConf.Webhook.ContentType,
+		}
+		if t.contentType == "" {
+			t.contentType = "application/json"
+		}
+		if ruleConf.Webhook.Template != "" {
+			t.template, err = template.New(ruleConf.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(ruleConf.Webhook.Template)
+			if err != nil {
+				return nil, fmt.Errorf("notification rule %q: invalid webhook template, %w", ruleConf.Name, err)
+			}
+		}
+
+		r.rules = append(r.rules, &rule{
+			NotificationRule: ruleConf,
+			applicationName:  applicationName,
+			target:           t,
+		})
+	}
+	return r, nil
+}
+
+// Route returns the notifications due for the application at the given time,
+// and how long until the next LongRunning notification of a running application is due, zero if none
+func (r *Router) Route(app *v1alpha1.SparkApplication, now time.Time) ([]*Notification, time.Duration) {
+	driver := app.Status.Data.Driver
+
+	var event config.NotificationEvent
+	var runningFor time.Duration
+	switch driver.Phase {
+	case corev1.PodSucceeded:
+		event = config.NotificationEventSucceeded
+	case corev1.PodFailed:
+		event = config.NotificationEventFailed
+	case corev1.PodRunning:
+		start, ok := getPodPhaseTime(driver, corev1.PodRunning)
+		if !ok || driver.DeletionTimestamp != nil {
+			return nil, 0
+		}
+		event = config.NotificationEventLongRunning
+		runningFor = now.Sub(start)
+	default:
+		return nil, 0
+	}
+
+	var due []*Notification
+	var next time.Duration
+	for _, rule := range r.rules {
+		if !rule.matches(app, event) {
+			continue
+		}
+		if event == config.NotificationEventLongRunning && runningFor < rule.LongRunningThreshold {
+			if wait := rule.LongRunningThreshold - runningFor; next == 0 || wait < next {
+				next = wait
+			}
+			continue
+		}
+		due = append(due, newNotification(app, event, rule, now))
+	}
+
+	return due, next
+}
+
+func (r *rule) matches(app *v1alpha1.SparkApplication, event config.NotificationEvent) bool {
+	if !containsEvent(r.Events, event) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !containsString(r.Namespaces, app.Namespace) {
+		return false
+	}
+	if len(r.Heritages) > 0 && !containsString(r.Heritages, string(app.Spec.Heritage)) {
+		return false
+	}
+	return r.applicationName.MatchString(app.Spec.ApplicationName)
+}
+
+func containsEvent(events []config.NotificationEvent, event config.NotificationEvent) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// getPodPhaseTime returns the time the pod was first seen in the given phase
+func getPodPhaseTime(pod v1alpha1.Pod, phase corev1.PodPhase) (time.Time, bool) {
+	for _, entry := range pod.StateHistory {
+		if entry.Phase == phase {
+			return entry.Timestamp.Time, true
+		}
+	}
+	return time.Time{}, false
+}
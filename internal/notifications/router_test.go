@@ -0,0 +1,143 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+var testStart = time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestApplication(phase corev1.PodPhase, runFor time.Duration) *v1alpha1.SparkApplication {
+	app := &v1alpha1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-123",
+			Namespace: "team-a",
+			UID:       "app-uid",
+		},
+		Spec: v1alpha1.SparkApplicationSpec{
+			ApplicationID:   "spark-123",
+			ApplicationName: "etl-daily",
+			Heritage:        v1alpha1.SparkHeritageOperator,
+		},
+	}
+	app.Status.Data.Driver.Phase = phase
+	app.Status.Data.Driver.StateHistory = []v1alpha1.PodStateHistoryEntry{
+		{Timestamp: metav1.NewTime(testStart.Add(-time.Minute)), Phase: corev1.PodPending},
+		{Timestamp: metav1.NewTime(testStart), Phase: corev1.PodRunning},
+	}
+	if phase != corev1.PodRunning {
+		app.Status.Data.Driver.StateHistory = append(app.Status.Data.Driver.StateHistory,
+			v1alpha1.PodStateHistoryEntry{Timestamp: metav1.NewTime(testStart.Add(runFor)), Phase: phase})
+	}
+	return app
+}
+
+func newTestRouter(tt *testing.T, rules ...config.NotificationRule) *Router {
+	for i := range rules {
+		rules[i].Webhook.URL = "http://hooks.example.com/" + rules[i].Name
+	}
+	router, err := NewRouter(config.Notifications{Rules: rules})
+	require.NoError(tt, err)
+	return router
+}
+
+func getRules(notifications []*Notification) []string {
+	rules := make([]string, 0, len(notifications))
+	for _, n := range notifications {
+		rules = append(rules, n.Rule)
+	}
+	return rules
+}
+
+func TestRoute(t *testing.T) {
+	now := testStart.Add(3 * time.Hour)
+
+	t.Run("whenFailed", func(tt *testing.T) {
+		router := newTestRouter(tt,
+			config.NotificationRule{Name: "all-failures", Events: []config.NotificationEvent{config.NotificationEventFailed}},
+			config.NotificationRule{Name: "successes", Events: []config.NotificationEvent{config.NotificationEventSucceeded}},
+			config.NotificationRule{Name: "team-a", Namespaces: []string{"team-a"}, Events: []config.NotificationEvent{config.NotificationEventFailed, config.NotificationEventSucceeded}},
+			config.NotificationRule{Name: "team-b", Namespaces: []string{"team-b"}, Events: []config.NotificationEvent{config.NotificationEventFailed}},
+			config.NotificationRule{Name: "etl", ApplicationName: "^etl-", Events: []config.NotificationEvent{config.NotificationEventFailed}},
+			config.NotificationRule{Name: "reports", ApplicationName: "^report-", Events: []config.NotificationEvent{config.NotificationEventFailed}},
+			config.NotificationRule{Name: "jupyter", Heritages: []string{"jupyter-notebook"}, Events: []config.NotificationEvent{config.NotificationEventFailed}},
+		)
+
+		due, next := router.Route(newTestApplication(corev1.PodFailed, 10*time.Minute), now)
+		assert.Equal(tt, []string{"all-failures", "team-a", "etl"}, getRules(due))
+		assert.Zero(tt, next)
+
+		n := due[0]
+		assert.Equal(tt, config.NotificationEventFailed, n.Event)
+		assert.Equal(tt, "Failed/all-failures", n.Key())
+		assert.Equal(tt, "app-uid/Failed", n.DedupeKey)
+		assert.Equal(tt, "etl-daily", n.Application.Name)
+		assert.Equal(tt, "Failed", n.Application.Phase)
+		assert.Equal(tt, testStart, *n.Application.StartTime)
+		assert.Equal(tt, testStart.Add(10*time.Minute), *n.Application.FinishTime)
+		assert.Equal(tt, int64(600), n.Application.DurationSeconds)
+	})
+
+	t.Run("whenRunning", func(tt *testing.T) {
+		router := newTestRouter(tt,
+			config.NotificationRule{Name: "failures", Events: []config.NotificationEvent{config.NotificationEventFailed}},
+			config.NotificationRule{Name: "2h", Events: []config.NotificationEvent{config.NotificationEventLongRunning}, LongRunningThreshold: 2 * time.Hour},
+			config.NotificationRule{Name: "4h", Events: []config.NotificationEvent{config.NotificationEventLongRunning}, LongRunningThreshold: 4 * time.Hour},
+			config.NotificationRule{Name: "5h", Events: []config.NotificationEvent{config.NotificationEventLongRunning}, LongRunningThreshold: 5 * time.Hour},
+		)
+
+		due, next := router.Route(newTestApplication(corev1.PodRunning, 0), now)
+		assert.Equal(tt, []string{"2h"}, getRules(due))
+		assert.Equal(tt, time.Hour, next)
+
+		n := due[0]
+		assert.Equal(tt, config.NotificationEventLongRunning, n.Event)
+		assert.Nil(tt, n.Application.FinishTime)
+		assert.Equal(tt, int64(3*60*60), n.Application.DurationSeconds)
+	})
+
+	t.Run("whenPending", func(tt *testing.T) {
+		router := newTestRouter(tt,
+			config.NotificationRule{Name: "1h", Events: []config.NotificationEvent{config.NotificationEventLongRunning}, LongRunningThreshold: time.Hour},
+		)
+
+		app := newTestApplication(corev1.PodRunning, 0)
+		app.Status.Data.Driver.Phase = corev1.PodPending
+		app.Status.Data.Driver.StateHistory = app.Status.Data.Driver.StateHistory[:1]
+		due, next := router.Route(app, now)
+		assert.Empty(tt, due)
+		assert.Zero(tt, next)
+	})
+
+	t.Run("whenDriverDeleted", func(tt *testing.T) {
+		router := newTestRouter(tt,
+			config.NotificationRule{Name: "1h", Events: []config.NotificationEvent{config.NotificationEventLongRunning}, LongRunningThreshold: time.Hour},
+		)
+
+		app := newTestApplication(corev1.PodRunning, 0)
+		deleted := metav1.NewTime(now)
+		app.Status.Data.Driver.DeletionTimestamp = &deleted
+		due, _ := router.Route(app, now)
+		assert.Empty(tt, due)
+	})
+}
+
+func TestNewRouter(t *testing.T) {
+
+	t.Run("whenTemplateInvalid", func(tt *testing.T) {
+		_, err := NewRouter(config.Notifications{Rules: []config.NotificationRule{{
+			Name:    "a",
+			Events:  []config.NotificationEvent{config.NotificationEventFailed},
+			Webhook: config.Webhook{URL: "http://a", Template: "{{ .Application.Name "},
+		}}})
+		assert.Error(tt, err)
+	})
+}
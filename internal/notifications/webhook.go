@@ -0,0 +1,40 @@
+//go:generate mockgen -destination=mock_notifications/notifications_mock.go . Sender
+
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spotinst/wave-operator/internal/httpsend"
+)
+
+// Sender sends notifications to their webhooks
+type Sender interface {
+	Send(ctx context.Context, n *Notification) error
+}
+
+type webhookSender struct {
+	sender *httpsend.Sender
+}
+
+// NewWebhookSender creates a sender posting notifications to their webhooks,
+// each notification is posted once and failed notifications are retried by the caller
+func NewWebhookSender() Sender {
+	return &webhookSender{
+		sender: httpsend.NewSender(),
+	}
+}
+
+func (s *webhookSender) Send(ctx context.Context, n *Notification) error {
+	body, err := n.render()
+	if err != nil {
+		return err
+	}
+
+	err = s.sender.Post(ctx, n.target.url, n.target.contentType, n.target.headers, body)
+	if err != nil {
+		return fmt.Errorf("could not send notification, %w", err)
+	}
+	return nil
+}
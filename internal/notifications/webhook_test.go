@@ -0,0 +1,113 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/httpsend"
+)
+
+func newTestNotification(tt *testing.T, url string, webhook config.Webhook) *Notification {
+	webhook.URL = url
+	router, err := NewRouter(config.Notifications{Rules: []config.NotificationRule{{
+		Name:    "team-a",
+		Events:  []config.NotificationEvent{config.NotificationEventFailed},
+		Webhook: webhook,
+	}}})
+	require.NoError(tt, err)
+
+	due, _ := router.Route(newTestApplication(corev1.PodFailed, time.Minute), testStart.Add(time.Hour))
+	require.Len(tt, due, 1)
+	return due[0]
+}
+
+func newTestSender() *webhookSender {
+	return &webhookSender{sender: httpsend.NewSender()}
+}
+
+func TestSend(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("whenJSON", func(tt *testing.T) {
+		var received map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, http.MethodPost, r.Method)
+			assert.Equal(tt, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(tt, "secret", r.Header.Get("X-Api-Key"))
+			require.NoError(tt, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		n := newTestNotification(tt, server.URL, config.Webhook{Headers: map[string]string{"X-Api-Key": "secret"}})
+		require.NoError(tt, newTestSender().Send(ctx, n))
+		assert.Equal(tt, "Failed", received["event"])
+		assert.Equal(tt, "team-a", received["rule"])
+		assert.Equal(tt, "app-uid/Failed", received["dedupeKey"])
+		assert.Equal(tt, "etl-daily", received["application"].(map[string]interface{})["name"])
+	})
+
+	t.Run("whenTemplate", func(tt *testing.T) {
+		var received string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, "text/plain", r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			received = string(body)
+		}))
+		defer server.Close()
+
+		n := newTestNotification(tt, server.URL, config.Webhook{
+			ContentType: "text/plain",
+			Template:    `{{ .Application.Name | json }} in {{ .Application.Namespace }}: {{ .Event }}`,
+		})
+		require.NoError(tt, newTestSender().Send(ctx, n))
+		assert.Equal(tt, `"etl-daily" in team-a: Failed`, received)
+	})
+
+	t.Run("whenServerErrorNotRetried", func(tt *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		n := newTestNotification(tt, server.URL, config.Webhook{})
+		err := newTestSender().Send(ctx, n)
+		require.Error(tt, err)
+		assert.Contains(tt, err.Error(), "code: 429")
+		assert.Equal(tt, int32(1), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("whenRejected", func(tt *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid payload"))
+		}))
+		defer server.Close()
+
+		n := newTestNotification(tt, server.URL, config.Webhook{})
+		err := newTestSender().Send(ctx, n)
+		require.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid payload")
+		assert.Equal(tt, int32(1), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("whenTemplateFails", func(tt *testing.T) {
+		n := newTestNotification(tt, "http://localhost", config.Webhook{Template: "{{ .Missing }}"})
+		assert.Error(tt, newTestSender().Send(ctx, n))
+	})
+}
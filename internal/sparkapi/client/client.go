@@ -5,29 +5,52 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
 )
 
 const (
 	apiVersionUrl = "api/v1"
+
+	// sqlExecutionsPageLength is the number of SQL executions requested at a time,
+	// the Spark API returns 20 by default
+	sqlExecutionsPageLength = 100
 )
 
 type Client interface {
 	GetApplication(applicationID string) (*Application, error)
 	GetEnvironment(applicationID string) (*Environment, error)
 	GetStages(applicationID string) ([]Stage, error)
+	GetStageTaskSummary(applicationID string, stageID int, attemptID int, quantiles []float64) (*TaskSummary, error)
 	GetAllExecutors(applicationID string) ([]Executor, error)
+	// GetExecutors returns the active executors only, much smaller than all executors for long running applications
+	GetExecutors(applicationID string) ([]Executor, error)
+	GetJobs(applicationID string) ([]Job, error)
+	GetSQLExecutions(applicationID string) ([]SQLExecution, error)
 }
 
 type client struct {
 	transportClient transport.Client
+	// source is the kind of Spark API server, used as a metrics label
+	source string
+	// sparkVersion is detected from the first application response
+	sparkVersion string
+}
+
+// TransportConfig determines how a client reaches the Spark API
+type TransportConfig struct {
+	// Mode is the transport mode, defaults to direct
+	Mode transport.Mode
+	// ServiceHost is the DNS name of the Spark API server, only used in service mode
+	ServiceHost string
 }
 
 func (c *client) GetApplication(applicationID string) (*Application, error) {
 
 	path := c.getApplicationURLPath(applicationID)
-	resp, err := c.transportClient.Get(path)
+	resp, err := c.get(endpointApplication, path)
 	if err != nil {
 		return nil, err
 	}
@@ -38,13 +61,17 @@ func (c *client) GetApplication(applicationID string) (*Application, error) {
 		return nil, err
 	}
 
+	if c.sparkVersion == "" {
+		c.sparkVersion = GetSparkVersion(application)
+	}
+
 	return application, nil
 }
 
 func (c *client) GetEnvironment(applicationID string) (*Environment, error) {
 
 	path := c.getEnvironmentURLPath(applicationID)
-	resp, err := c.transportClient.Get(path)
+	resp, err := c.get(endpointEnvironment, path)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +88,7 @@ func (c *client) GetEnvironment(applicationID string) (*Environment, error) {
 func (c *client) GetStages(applicationID string) ([]Stage, error) {
 
 	path := c.getStagesURLPath(applicationID)
-	resp, err := c.transportClient.Get(path)
+	resp, err := c.get(endpointStages, path)
 	if err != nil {
 		return nil, err
 	}
@@ -75,10 +102,27 @@ func (c *client) GetStages(applicationID string) ([]Stage, error) {
 	return stages, nil
 }
 
+func (c *client) GetStageTaskSummary(applicationID string, stageID int, attemptID int, quantiles []float64) (*TaskSummary, error) {
+
+	path := c.getStageTaskSummaryURLPath(applicationID, stageID, attemptID, quantiles)
+	resp, err := c.get(endpointTaskSummary, path)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &TaskSummary{}
+	err = json.Unmarshal(resp, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
 func (c *client) GetAllExecutors(applicationID string) ([]Executor, error) {
 
 	path := c.getAllExecutorsURLPath(applicationID)
-	resp, err := c.transportClient.Get(path)
+	resp, err := c.get(endpointAllExecutors, path)
 	if err != nil {
 		return nil, err
 	}
@@ -89,9 +133,71 @@ func (c *client) GetAllExecutors(applicationID string) ([]Executor, error) {
 		return nil, err
 	}
 
+	normalizeExecutors(executors, c.sparkVersion)
+
 	return executors, nil
 }
 
+func (c *client) GetExecutors(applicationID string) ([]Executor, error) {
+
+	path := c.getExecutorsURLPath(applicationID)
+	resp, err := c.get(endpointExecutors, path)
+	if err != nil {
+		return nil, err
+	}
+
+	executors := make([]Executor, 0)
+	err = json.Unmarshal(resp, &executors)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeExecutors(executors, c.sparkVersion)
+
+	return executors, nil
+}
+
+func (c *client) GetJobs(applicationID string) ([]Job, error) {
+
+	path := c.getJobsURLPath(applicationID)
+	resp, err := c.get(endpointJobs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]Job, 0)
+	err = json.Unmarshal(resp, &jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// GetSQLExecutions returns all SQL executions of the application, the endpoint is available from Spark 3.0
+func (c *client) GetSQLExecutions(applicationID string) ([]SQLExecution, error) {
+
+	executions := make([]SQLExecution, 0)
+	for offset := 0; ; offset += sqlExecutionsPageLength {
+		path := c.getSQLExecutionsURLPath(applicationID, offset, sqlExecutionsPageLength)
+		resp, err := c.get(endpointSQL, path)
+		if err != nil {
+			return nil, err
+		}
+
+		page := make([]SQLExecution, 0)
+		err = json.Unmarshal(resp, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		executions = append(executions, page...)
+		if len(page) < sqlExecutionsPageLength {
+			return executions, nil
+		}
+	}
+}
+
 func (c *client) getEnvironmentURLPath(applicationID string) string {
 	return fmt.Sprintf("%s/applications/%s/environment", apiVersionUrl, applicationID)
 }
@@ -104,6 +210,26 @@ func (c *client) getStagesURLPath(applicationID string) string {
 	return fmt.Sprintf("%s/applications/%s/stages", apiVersionUrl, applicationID)
 }
 
+func (c *client) getStageTaskSummaryURLPath(applicationID string, stageID int, attemptID int, quantiles []float64) string {
+	q := make([]string, 0, len(quantiles))
+	for _, quantile := range quantiles {
+		q = append(q, strconv.FormatFloat(quantile, 'f', -1, 64))
+	}
+	return fmt.Sprintf("%s/applications/%s/stages/%d/%d/taskSummary?quantiles=%s", apiVersionUrl, applicationID, stageID, attemptID, strings.Join(q, ","))
+}
+
 func (c *client) getAllExecutorsURLPath(applicationID string) string {
 	return fmt.Sprintf("%s/applications/%s/allexecutors", apiVersionUrl, applicationID)
 }
+
+func (c *client) getExecutorsURLPath(applicationID string) string {
+	return fmt.Sprintf("%s/applications/%s/executors", apiVersionUrl, applicationID)
+}
+
+func (c *client) getJobsURLPath(applicationID string) string {
+	return fmt.Sprintf("%s/applications/%s/jobs", apiVersionUrl, applicationID)
+}
+
+func (c *client) getSQLExecutionsURLPath(applicationID string, offset int, length int) string {
+	return fmt.Sprintf("%s/applications/%s/sql?details=false&planDescription=true&offset=%d&length=%d", apiVersionUrl, applicationID, offset, length)
+}
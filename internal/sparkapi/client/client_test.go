@@ -20,7 +20,7 @@ func TestGetApplication(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123").Return(nil, fmt.Errorf("test error")).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 
 		res, err := client.GetApplication("spark-123")
 		assert.Error(tt, err)
@@ -33,7 +33,7 @@ func TestGetApplication(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123").Return(getApplicationResponse(), nil).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 
 		res, err := client.GetApplication("spark-123")
 		assert.NoError(tt, err)
@@ -62,7 +62,7 @@ func TestGetStages(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123/stages").Return(nil, fmt.Errorf("test error")).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 
 		res, err := client.GetStages("spark-123")
 		assert.Error(tt, err)
@@ -75,7 +75,7 @@ func TestGetStages(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123/stages").Return(getStagesResponse(), nil).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 
 		res, err := client.GetStages("spark-123")
 		assert.NoError(tt, err)
@@ -86,11 +86,62 @@ func TestGetStages(t *testing.T) {
 		assert.Equal(tt, int64(147527368), stage.ExecutorCpuTime)
 		assert.Equal(tt, 9, stage.AttemptID)
 		assert.Equal(tt, 7, stage.StageID)
+		assert.Equal(tt, "reduce at SparkPi.scala:38", stage.Name)
+		assert.Equal(tt, int64(2), stage.NumTasks)
+		assert.Equal(tt, int64(2), stage.NumCompleteTasks)
+		assert.Equal(tt, int64(177), stage.ExecutorRunTime)
+		assert.Equal(tt, int64(12), stage.JvmGcTime)
+		assert.Equal(tt, int64(4096), stage.MemoryBytesSpilled)
+		assert.Equal(tt, int64(1024), stage.DiskBytesSpilled)
 
 	})
 
 }
 
+func TestGetStageTaskSummary(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("whenError", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/stages/7/9/taskSummary?quantiles=0.5,1").Return(nil, fmt.Errorf("test error")).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetStageTaskSummary("spark-123", 7, 9, []float64{0.5, 1.0})
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "test error")
+		assert.Nil(tt, res)
+	})
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/stages/7/9/taskSummary?quantiles=0.5,1").Return(getStageTaskSummaryResponse(), nil).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetStageTaskSummary("spark-123", 7, 9, []float64{0.5, 1.0})
+		assert.NoError(tt, err)
+		assert.Equal(tt, []float64{0.5, 1.0}, res.Quantiles)
+		assert.Equal(tt, []float64{70.0, 107.0}, res.ExecutorRunTime)
+		assert.Equal(tt, []float64{0.0, 12.0}, res.JvmGcTime)
+		assert.Equal(tt, []float64{0.0, 4096.0}, res.MemoryBytesSpilled)
+		assert.Equal(tt, []float64{0.0, 1024.0}, res.DiskBytesSpilled)
+		assert.Equal(tt, []float64{1000.0, 2333.0}, res.InputMetrics.BytesRead)
+		assert.Equal(tt, []float64{0.0, 0.0}, res.ShuffleReadMetrics.ReadBytes)
+
+		median, ok := res.Quantile(res.ExecutorRunTime, 0.5)
+		assert.True(tt, ok)
+		assert.Equal(tt, 70.0, median)
+		_, ok = res.Quantile(res.ExecutorRunTime, 0.75)
+		assert.False(tt, ok)
+	})
+
+}
+
 func TestGetEnvironment(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -101,7 +152,7 @@ func TestGetEnvironment(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123/environment").Return(nil, fmt.Errorf("test error")).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 
 		res, err := client.GetEnvironment("spark-123")
 		assert.Error(tt, err)
@@ -114,7 +165,7 @@ func TestGetEnvironment(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123/environment").Return(getEnvironmentResponse(), nil).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 
 		res, err := client.GetEnvironment("spark-123")
 		assert.NoError(tt, err)
@@ -122,6 +173,13 @@ func TestGetEnvironment(t *testing.T) {
 		for _, prop := range res.SparkProperties {
 			assert.Equal(tt, 2, len(prop))
 		}
+		assert.Equal(tt, "1.8.0_252 (Oracle Corporation)", res.Runtime.JavaVersion)
+		assert.Equal(tt, "version 2.12.10", res.Runtime.ScalaVersion)
+		assert.Equal(tt, 3, len(res.SystemProperties))
+		assert.Equal(tt, []string{"/opt/spark/jars/velocity-1.5.jar", ClasspathSourceSystem}, res.ClasspathEntries[0])
+		assert.Equal(tt, 1, len(res.ResourceProfiles))
+		assert.Equal(tt, int64(1024), res.ResourceProfiles[0].ExecutorResources["memory"].Amount)
+		assert.Equal(tt, 1.0, res.ResourceProfiles[0].TaskResources["cpus"].Amount)
 	})
 
 }
@@ -136,7 +194,7 @@ func TestGetAllExecutors(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123/allexecutors").Return(nil, fmt.Errorf("test error")).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 
 		res, err := client.GetAllExecutors("spark-123")
 		assert.Error(tt, err)
@@ -149,7 +207,7 @@ func TestGetAllExecutors(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123/allexecutors").Return(getExecutorsResponse(), nil).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 
 		res, err := client.GetAllExecutors("spark-123")
 		assert.NoError(tt, err)
@@ -176,6 +234,71 @@ func TestGetAllExecutors(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("whenSparkVersionDetected", func(tt *testing.T) {
+
+		// Spark 3.0 returns isBlacklisted, isExcluded is ignored
+		executors := []byte(`[
+    {
+        "id": "1",
+        "isBlacklisted": true,
+        "blacklistedInStages": [2],
+        "isExcluded": false,
+        "resources": {"gpu": {"name": "gpu", "addresses": ["0", "1"]}}
+    }
+]`)
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123").Return(getApplicationResponse(), nil).Times(1)
+		m.EXPECT().Get("api/v1/applications/spark-123/allexecutors").Return(executors, nil).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		_, err := client.GetApplication("spark-123")
+		assert.NoError(tt, err)
+
+		res, err := client.GetAllExecutors("spark-123")
+		assert.NoError(tt, err)
+		assert.Equal(tt, 1, len(res))
+		assert.True(tt, res[0].IsExcluded)
+		assert.True(tt, res[0].IsBlacklisted)
+		assert.Equal(tt, []int{2}, res[0].ExcludedInStages)
+		assert.Equal(tt, []string{"0", "1"}, res[0].Resources["gpu"].Addresses)
+	})
+}
+
+func TestGetExecutors(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("whenError", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/executors").Return(nil, fmt.Errorf("test error")).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetExecutors("spark-123")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "test error")
+		assert.Nil(tt, res)
+	})
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+
+		executors := []byte(`[{"id": "driver", "isActive": true}, {"id": "2", "isActive": true, "isBlacklisted": true}]`)
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/executors").Return(executors, nil).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetExecutors("spark-123")
+		assert.NoError(tt, err)
+		assert.Equal(tt, 2, len(res))
+		assert.True(tt, res[1].IsExcluded)
+	})
 }
 
 func getApplicationResponse() []byte {
@@ -213,6 +336,7 @@ func getStagesResponse() []byte {
         "numCompletedIndices": 2,
         "executorRunTime": 177,
         "executorCpuTime": 147527368,
+        "jvmGcTime": 12,
         "submissionTime": "2020-11-24T17:19:18.512GMT",
         "firstTaskLaunchedTime": "2020-11-24T17:19:18.779GMT",
         "completionTime": "2020-11-24T17:19:19.899GMT",
@@ -224,8 +348,8 @@ func getStagesResponse() []byte {
         "shuffleReadRecords": 0,
         "shuffleWriteBytes": 0,
         "shuffleWriteRecords": 0,
-        "memoryBytesSpilled": 0,
-        "diskBytesSpilled": 0,
+        "memoryBytesSpilled": 4096,
+        "diskBytesSpilled": 1024,
         "name": "reduce at SparkPi.scala:38",
         "details": "org.apache.spark.rdd.RDD.reduce(RDD.scala:1076)\norg.apache.spark.examples.SparkPi$.main(SparkPi.scala:38)\norg.apache.spark.examples.SparkPi.main(SparkPi.scala)\nsun.reflect.NativeMethodAccessorImpl.invoke0(Native Method)\nsun.reflect.NativeMethodAccessorImpl.invoke(NativeMethodAccessorImpl.java:62)\nsun.reflect.DelegatingMethodAccessorImpl.invoke(DelegatingMethodAccessorImpl.java:43)\njava.lang.reflect.Method.invoke(Method.java:498)\norg.apache.spark.deploy.JavaMainApplication.start(SparkApplication.scala:52)\norg.apache.spark.deploy.SparkSubmit.org$apache$spark$deploy$SparkSubmit$$runMain(SparkSubmit.scala:928)\norg.apache.spark.deploy.SparkSubmit.doRunMain$1(SparkSubmit.scala:180)\norg.apache.spark.deploy.SparkSubmit.submit(SparkSubmit.scala:203)\norg.apache.spark.deploy.SparkSubmit.doSubmit(SparkSubmit.scala:90)\norg.apache.spark.deploy.SparkSubmit$$anon$2.doSubmit(SparkSubmit.scala:1007)\norg.apache.spark.deploy.SparkSubmit$.main(SparkSubmit.scala:1016)\norg.apache.spark.deploy.SparkSubmit.main(SparkSubmit.scala)",
         "schedulingPool": "default",
@@ -239,6 +363,47 @@ func getStagesResponse() []byte {
 ]`)
 }
 
+func getStageTaskSummaryResponse() []byte {
+	return []byte(`{
+  "quantiles" : [ 0.5, 1.0 ],
+  "executorDeserializeTime" : [ 377.0, 383.0 ],
+  "executorDeserializeCpuTime" : [ 264387553.0, 265045133.0 ],
+  "executorRunTime" : [ 70.0, 107.0 ],
+  "executorCpuTime" : [ 62133536.0, 85393832.0 ],
+  "resultSize" : [ 1.0, 1.0 ],
+  "jvmGcTime" : [ 0.0, 12.0 ],
+  "resultSerializationTime" : [ 0.0, 1.0 ],
+  "gettingResultTime" : [ 0.0, 0.0 ],
+  "schedulerDelay" : [ 34.0, 49.0 ],
+  "peakExecutionMemory" : [ 0.0, 0.0 ],
+  "memoryBytesSpilled" : [ 0.0, 4096.0 ],
+  "diskBytesSpilled" : [ 0.0, 1024.0 ],
+  "inputMetrics" : {
+    "bytesRead" : [ 1000.0, 2333.0 ],
+    "recordsRead" : [ 0.0, 0.0 ]
+  },
+  "outputMetrics" : {
+    "bytesWritten" : [ 0.0, 5555.0 ],
+    "recordsWritten" : [ 0.0, 0.0 ]
+  },
+  "shuffleReadMetrics" : {
+    "readBytes" : [ 0.0, 0.0 ],
+    "readRecords" : [ 0.0, 0.0 ],
+    "remoteBlocksFetched" : [ 0.0, 0.0 ],
+    "localBlocksFetched" : [ 0.0, 0.0 ],
+    "fetchWaitTime" : [ 0.0, 0.0 ],
+    "remoteBytesRead" : [ 0.0, 0.0 ],
+    "remoteBytesReadToDisk" : [ 0.0, 0.0 ],
+    "totalBlocksFetched" : [ 0.0, 0.0 ]
+  },
+  "shuffleWriteMetrics" : {
+    "writeBytes" : [ 0.0, 0.0 ],
+    "writeRecords" : [ 0.0, 0.0 ],
+    "writeTime" : [ 0.0, 0.0 ]
+  }
+}`)
+}
+
 func getEnvironmentResponse() []byte {
 	return []byte(`{
     "runtime": {
@@ -295,6 +460,31 @@ func getEnvironmentResponse() []byte {
             "/opt/spark/jars/zjsonpatch-0.3.0.jar",
             "System Classpath"
         ]
+    ],
+    "resourceProfiles": [
+        {
+            "id": 0,
+            "executorResources": {
+                "cores": {
+                    "resourceName": "cores",
+                    "amount": 1,
+                    "discoveryScript": "",
+                    "vendor": ""
+                },
+                "memory": {
+                    "resourceName": "memory",
+                    "amount": 1024,
+                    "discoveryScript": "",
+                    "vendor": ""
+                }
+            },
+            "taskResources": {
+                "cpus": {
+                    "resourceName": "cpus",
+                    "amount": 1.0
+                }
+            }
+        }
     ]
 }`)
 }
@@ -441,3 +631,153 @@ func getExecutorsResponse() []byte {
   "resources" : { }
 } ]`)
 }
+
+func TestGetJobs(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("whenError", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/jobs").Return(nil, fmt.Errorf("test error")).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetJobs("spark-123")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "test error")
+		assert.Nil(tt, res)
+	})
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/jobs").Return(getJobsResponse(), nil).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetJobs("spark-123")
+		assert.NoError(tt, err)
+		assert.Equal(tt, 1, len(res))
+		job := res[0]
+		assert.Equal(tt, int64(1), job.JobID)
+		assert.Equal(tt, "count at NativeMethodAccessorImpl.java:0", job.Name)
+		assert.Equal(tt, "count the rows", job.Description)
+		assert.Equal(tt, "2020-11-24T17:19:18.512GMT", job.SubmissionTime)
+		assert.Equal(tt, "2020-11-24T17:19:19.899GMT", job.CompletionTime)
+		assert.Equal(tt, []int{1, 2}, job.StageIDs)
+		assert.Equal(tt, "notebook", job.JobGroup)
+		assert.Equal(tt, JobStatusFailed, job.Status)
+		assert.Equal(tt, int64(10), job.NumTasks)
+		assert.Equal(tt, int64(3), job.NumFailedTasks)
+	})
+
+}
+
+func TestGetSQLExecutions(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("whenError", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/sql?details=false&planDescription=true&offset=0&length=100").Return(nil, fmt.Errorf("test error")).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetSQLExecutions("spark-123")
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "test error")
+		assert.Nil(tt, res)
+	})
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/sql?details=false&planDescription=true&offset=0&length=100").Return(getSQLExecutionsResponse(), nil).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetSQLExecutions("spark-123")
+		assert.NoError(tt, err)
+		assert.Equal(tt, 1, len(res))
+		execution := res[0]
+		assert.Equal(tt, int64(4), execution.ID)
+		assert.Equal(tt, SQLExecutionStatusCompleted, execution.Status)
+		assert.Equal(tt, "select count(*) from table", execution.Description)
+		assert.Equal(tt, "== Physical Plan ==\nAdaptiveSparkPlan", execution.PlanDescription)
+		assert.Equal(tt, "2020-11-24T17:19:18.512GMT", execution.SubmissionTime)
+		assert.Equal(tt, int64(1387), execution.Duration)
+		assert.Equal(tt, []int64{}, execution.RunningJobIDs)
+		assert.Equal(tt, []int64{1, 2}, execution.SuccessJobIDs)
+		assert.Equal(tt, []int64{}, execution.FailedJobIDs)
+	})
+
+	t.Run("whenPaged", func(tt *testing.T) {
+
+		page := make([]byte, 0)
+		page = append(page, '[')
+		for i := 0; i < sqlExecutionsPageLength; i++ {
+			if i > 0 {
+				page = append(page, ',')
+			}
+			page = append(page, []byte(fmt.Sprintf(`{"id": %d}`, i))...)
+		}
+		page = append(page, ']')
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/sql?details=false&planDescription=true&offset=0&length=100").Return(page, nil).Times(1)
+		m.EXPECT().Get("api/v1/applications/spark-123/sql?details=false&planDescription=true&offset=100&length=100").Return([]byte("[]"), nil).Times(1)
+
+		client := &driver{&client{transportClient: m}}
+
+		res, err := client.GetSQLExecutions("spark-123")
+		assert.NoError(tt, err)
+		assert.Equal(tt, sqlExecutionsPageLength, len(res))
+		assert.Equal(tt, int64(sqlExecutionsPageLength-1), res[sqlExecutionsPageLength-1].ID)
+	})
+
+}
+
+func getJobsResponse() []byte {
+	return []byte(`[ {
+  "jobId" : 1,
+  "name" : "count at NativeMethodAccessorImpl.java:0",
+  "description" : "count the rows",
+  "submissionTime" : "2020-11-24T17:19:18.512GMT",
+  "completionTime" : "2020-11-24T17:19:19.899GMT",
+  "stageIds" : [ 1, 2 ],
+  "jobGroup" : "notebook",
+  "status" : "FAILED",
+  "numTasks" : 10,
+  "numActiveTasks" : 0,
+  "numCompletedTasks" : 7,
+  "numSkippedTasks" : 0,
+  "numFailedTasks" : 3,
+  "numKilledTasks" : 0,
+  "numCompletedIndices" : 7,
+  "numActiveStages" : 0,
+  "numCompletedStages" : 1,
+  "numSkippedStages" : 0,
+  "numFailedStages" : 1,
+  "killedTasksSummary" : { }
+} ]`)
+}
+
+func getSQLExecutionsResponse() []byte {
+	return []byte(`[ {
+  "id" : 4,
+  "status" : "COMPLETED",
+  "description" : "select count(*) from table",
+  "planDescription" : "== Physical Plan ==\nAdaptiveSparkPlan",
+  "submissionTime" : "2020-11-24T17:19:18.512GMT",
+  "duration" : 1387,
+  "runningJobIds" : [ ],
+  "successJobIds" : [ 1, 2 ],
+  "failedJobIds" : [ ],
+  "nodes" : [ ],
+  "edges" : [ ]
+} ]`)
+}
@@ -23,12 +23,20 @@ type driver struct {
 	*client
 }
 
-func NewDriverPodClient(pod *corev1.Pod, clientSet kubernetes.Interface) DriverClient {
-	//tc := transport.NewProxyClient(transport.Pod, pod.Name, pod.Namespace, driverPort, clientSet)
-	tc := transport.NewHTTPClientTransport(pod.Status.PodIP, driverPort)
+func NewDriverPodClient(pod *corev1.Pod, clientSet kubernetes.Interface, conf TransportConfig) DriverClient {
+	var tc transport.Client
+	switch conf.Mode {
+	case transport.ProxyMode:
+		tc = transport.NewProxyClient(transport.Pod, pod.Name, pod.Namespace, driverPort, clientSet)
+	case transport.ServiceMode:
+		tc = transport.NewHTTPClientTransport(conf.ServiceHost, driverPort)
+	default:
+		tc = transport.NewHTTPClientTransport(pod.Status.PodIP, driverPort)
+	}
 	c := &driver{
 		client: &client{
 			transportClient: tc,
+			source:          sourceDriver,
 		},
 	}
 	return c
@@ -37,7 +45,7 @@ func NewDriverPodClient(pod *corev1.Pod, clientSet kubernetes.Interface) DriverC
 func (dc *driver) GetStreamingStatistics(applicationID string) (*StreamingStatistics, error) {
 
 	path := dc.getStreamingStatisticsURLPath(applicationID)
-	resp, err := dc.transportClient.Get(path)
+	resp, err := dc.get(endpointStreamingStatistics, path)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +60,7 @@ func (dc *driver) GetStreamingStatistics(applicationID string) (*StreamingStatis
 }
 
 func (dc *driver) GetMetrics() (Metrics, error) {
-	resp, err := dc.transportClient.Get("metrics/json/")
+	resp, err := dc.get(endpointMetrics, "metrics/json/")
 	if err != nil {
 		return Metrics{}, err
 	}
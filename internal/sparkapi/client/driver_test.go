@@ -17,7 +17,7 @@ func TestDriverMetrics(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("metrics/json/").Return(getMetricsResponse(), nil).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 		metrics, err := client.GetMetrics()
 		require.NoError(tt, err)
 		assert.NotNil(tt, metrics)
@@ -35,7 +35,7 @@ func TestDriverMetrics(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("metrics/json/").Return(nil, errors.New("failed-to-get-metrics")).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 		metrics, err := client.GetMetrics()
 		require.Error(tt, err)
 		assert.NotNil(tt, metrics)
@@ -52,7 +52,7 @@ func TestDriverStreamingStatistics(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123/streaming/statistics").Return(getStreamingStatisticsResponse(), nil).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 		stats, err := client.GetStreamingStatistics("spark-123")
 		require.NoError(tt, err)
 		assert.NotNil(tt, stats)
@@ -65,7 +65,7 @@ func TestDriverStreamingStatistics(t *testing.T) {
 		m := mock_transport.NewMockClient(ctrl)
 		m.EXPECT().Get("api/v1/applications/spark-123/streaming/statistics").Return(nil, errors.New("streaming-statistics-err")).Times(1)
 
-		client := &driver{&client{m}}
+		client := &driver{&client{transportClient: m}}
 		stats, err := client.GetStreamingStatistics("spark-123")
 		require.Error(tt, err)
 		assert.Nil(tt, stats)
@@ -14,13 +14,37 @@ type historyServer struct {
 	*client
 }
 
-func NewHistoryServerClient(service *corev1.Service, clientSet kubernetes.Interface) Client {
-	//tc := transport.NewProxyClient(transport.Service, service.Name, service.Namespace, historyServerPort, clientSet)
-	tc := transport.NewHTTPClientTransport(fmt.Sprintf("%s.%s", service.Name, service.Namespace), historyServerPort)
+func NewHistoryServerClient(service *corev1.Service, clientSet kubernetes.Interface, conf TransportConfig) Client {
+	var tc transport.Client
+	switch conf.Mode {
+	case transport.ProxyMode:
+		tc = transport.NewProxyClient(transport.Service, service.Name, service.Namespace, historyServerPort, clientSet)
+	case transport.ServiceMode:
+		tc = transport.NewHTTPClientTransport(conf.ServiceHost, historyServerPort)
+	default:
+		tc = transport.NewHTTPClientTransport(fmt.Sprintf("%s.%s", service.Name, service.Namespace), historyServerPort)
+	}
 	c := &historyServer{
 		client: &client{
 			transportClient: tc,
+			source:          sourceHistoryServer,
 		},
 	}
 	return c
 }
+
+// NewExternalHistoryServerClient returns a client for a history server reachable on the given URL,
+// e.g. a history server running outside the cluster
+func NewExternalHistoryServerClient(baseURL string) (Client, error) {
+	tc, err := transport.NewHTTPClientTransportFromURL(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not create transport, %w", err)
+	}
+	c := &historyServer{
+		client: &client{
+			transportClient: tc,
+			source:          sourceHistoryServer,
+		},
+	}
+	return c, nil
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
+)
+
+const (
+	sourceDriver        = "driver"
+	sourceHistoryServer = "history-server"
+
+	endpointApplication         = "application"
+	endpointEnvironment         = "environment"
+	endpointStages              = "stages"
+	endpointTaskSummary         = "taskSummary"
+	endpointAllExecutors        = "allexecutors"
+	endpointExecutors           = "executors"
+	endpointJobs                = "jobs"
+	endpointSQL                 = "sql"
+	endpointStreamingStatistics = "streaming/statistics"
+	endpointMetrics             = "metrics"
+
+	// codeNone is the status code label of requests that did not get a response
+	codeNone = "none"
+)
+
+var fetchedBytes = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_spark_api_fetched_bytes_total",
+		Help: "Total number of response bytes fetched from the Spark API",
+	},
+	[]string{"source", "endpoint"},
+)
+
+var requestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "wave_spark_api_request_duration_seconds",
+		Help:    "Latency of Spark API requests in seconds",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"source", "endpoint"},
+)
+
+var requests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_spark_api_requests_total",
+		Help: "Total number of Spark API requests, by response status code",
+	},
+	[]string{"source", "endpoint", "code"},
+)
+
+var requestErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "wave_spark_api_request_errors_total",
+		Help: "Total number of failed Spark API requests",
+	},
+	[]string{"source", "endpoint"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(fetchedBytes, requestDuration, requests, requestErrors)
+}
+
+// get fetches the path from the Spark API, observes the request and counts the response bytes
+func (c *client) get(endpoint string, path string) ([]byte, error) {
+	start := time.Now()
+	resp, err := c.transportClient.Get(path)
+	requestDuration.WithLabelValues(c.source, endpoint).Observe(time.Since(start).Seconds())
+
+	code := strconv.Itoa(http.StatusOK)
+	if err != nil {
+		code = codeNone
+		if statusCode, ok := transport.GetStatusCode(err); ok {
+			code = strconv.Itoa(statusCode)
+		}
+	}
+	requests.WithLabelValues(c.source, endpoint, code).Inc()
+
+	if err != nil {
+		requestErrors.WithLabelValues(c.source, endpoint).Inc()
+		return nil, err
+	}
+	fetchedBytes.WithLabelValues(c.source, endpoint).Add(float64(len(resp)))
+	return resp, nil
+}
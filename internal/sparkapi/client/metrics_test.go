@@ -0,0 +1,60 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport/mock_transport"
+)
+
+func TestGet(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123").Return([]byte("12345"), nil).Times(1)
+
+		c := &client{transportClient: m, source: "test-successful"}
+
+		_, err := c.get(endpointApplication, "api/v1/applications/spark-123")
+		assert.NoError(tt, err)
+		assert.Equal(tt, float64(1), testutil.ToFloat64(requests.WithLabelValues("test-successful", endpointApplication, "200")))
+		assert.Equal(tt, float64(0), testutil.ToFloat64(requestErrors.WithLabelValues("test-successful", endpointApplication)))
+		assert.Equal(tt, float64(5), testutil.ToFloat64(fetchedBytes.WithLabelValues("test-successful", endpointApplication)))
+	})
+
+	t.Run("whenStatusError", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/jobs").
+			Return(nil, transport.NewNotFoundError(transport.NewStatusError(404, fmt.Errorf("test error")))).Times(1)
+
+		c := &client{transportClient: m, source: "test-status-error"}
+
+		_, err := c.get(endpointJobs, "api/v1/applications/spark-123/jobs")
+		assert.Error(tt, err)
+		assert.Equal(tt, float64(1), testutil.ToFloat64(requests.WithLabelValues("test-status-error", endpointJobs, "404")))
+		assert.Equal(tt, float64(1), testutil.ToFloat64(requestErrors.WithLabelValues("test-status-error", endpointJobs)))
+	})
+
+	t.Run("whenNoResponse", func(tt *testing.T) {
+
+		m := mock_transport.NewMockClient(ctrl)
+		m.EXPECT().Get("api/v1/applications/spark-123/jobs").Return(nil, fmt.Errorf("test error")).Times(1)
+
+		c := &client{transportClient: m, source: "test-no-response"}
+
+		_, err := c.get(endpointJobs, "api/v1/applications/spark-123/jobs")
+		assert.Error(tt, err)
+		assert.Equal(tt, float64(1), testutil.ToFloat64(requests.WithLabelValues("test-no-response", endpointJobs, codeNone)))
+		assert.Equal(tt, float64(1), testutil.ToFloat64(requestErrors.WithLabelValues("test-no-response", endpointJobs)))
+	})
+}
@@ -78,6 +78,66 @@ func (mr *MockClientMockRecorder) GetEnvironment(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnvironment", reflect.TypeOf((*MockClient)(nil).GetEnvironment), arg0)
 }
 
+// GetExecutors mocks base method
+func (m *MockClient) GetExecutors(arg0 string) ([]client.Executor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExecutors", arg0)
+	ret0, _ := ret[0].([]client.Executor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExecutors indicates an expected call of GetExecutors
+func (mr *MockClientMockRecorder) GetExecutors(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExecutors", reflect.TypeOf((*MockClient)(nil).GetExecutors), arg0)
+}
+
+// GetJobs mocks base method
+func (m *MockClient) GetJobs(arg0 string) ([]client.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobs", arg0)
+	ret0, _ := ret[0].([]client.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobs indicates an expected call of GetJobs
+func (mr *MockClientMockRecorder) GetJobs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobs", reflect.TypeOf((*MockClient)(nil).GetJobs), arg0)
+}
+
+// GetSQLExecutions mocks base method
+func (m *MockClient) GetSQLExecutions(arg0 string) ([]client.SQLExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSQLExecutions", arg0)
+	ret0, _ := ret[0].([]client.SQLExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSQLExecutions indicates an expected call of GetSQLExecutions
+func (mr *MockClientMockRecorder) GetSQLExecutions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSQLExecutions", reflect.TypeOf((*MockClient)(nil).GetSQLExecutions), arg0)
+}
+
+// GetStageTaskSummary mocks base method
+func (m *MockClient) GetStageTaskSummary(arg0 string, arg1, arg2 int, arg3 []float64) (*client.TaskSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStageTaskSummary", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*client.TaskSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStageTaskSummary indicates an expected call of GetStageTaskSummary
+func (mr *MockClientMockRecorder) GetStageTaskSummary(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStageTaskSummary", reflect.TypeOf((*MockClient)(nil).GetStageTaskSummary), arg0, arg1, arg2, arg3)
+}
+
 // GetStages mocks base method
 func (m *MockClient) GetStages(arg0 string) ([]client.Stage, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +78,36 @@ func (mr *MockDriverClientMockRecorder) GetEnvironment(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnvironment", reflect.TypeOf((*MockDriverClient)(nil).GetEnvironment), arg0)
 }
 
+// GetExecutors mocks base method
+func (m *MockDriverClient) GetExecutors(arg0 string) ([]client.Executor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExecutors", arg0)
+	ret0, _ := ret[0].([]client.Executor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExecutors indicates an expected call of GetExecutors
+func (mr *MockDriverClientMockRecorder) GetExecutors(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExecutors", reflect.TypeOf((*MockDriverClient)(nil).GetExecutors), arg0)
+}
+
+// GetJobs mocks base method
+func (m *MockDriverClient) GetJobs(arg0 string) ([]client.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobs", arg0)
+	ret0, _ := ret[0].([]client.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobs indicates an expected call of GetJobs
+func (mr *MockDriverClientMockRecorder) GetJobs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobs", reflect.TypeOf((*MockDriverClient)(nil).GetJobs), arg0)
+}
+
 // GetMetrics mocks base method
 func (m *MockDriverClient) GetMetrics() (client.Metrics, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +123,36 @@ func (mr *MockDriverClientMockRecorder) GetMetrics() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetrics", reflect.TypeOf((*MockDriverClient)(nil).GetMetrics))
 }
 
+// GetSQLExecutions mocks base method
+func (m *MockDriverClient) GetSQLExecutions(arg0 string) ([]client.SQLExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSQLExecutions", arg0)
+	ret0, _ := ret[0].([]client.SQLExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSQLExecutions indicates an expected call of GetSQLExecutions
+func (mr *MockDriverClientMockRecorder) GetSQLExecutions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSQLExecutions", reflect.TypeOf((*MockDriverClient)(nil).GetSQLExecutions), arg0)
+}
+
+// GetStageTaskSummary mocks base method
+func (m *MockDriverClient) GetStageTaskSummary(arg0 string, arg1, arg2 int, arg3 []float64) (*client.TaskSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStageTaskSummary", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*client.TaskSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStageTaskSummary indicates an expected call of GetStageTaskSummary
+func (mr *MockDriverClientMockRecorder) GetStageTaskSummary(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStageTaskSummary", reflect.TypeOf((*MockDriverClient)(nil).GetStageTaskSummary), arg0, arg1, arg2, arg3)
+}
+
 // GetStages mocks base method
 func (m *MockDriverClient) GetStages(arg0 string) ([]client.Stage, error) {
 	m.ctrl.T.Helper()
@@ -7,13 +7,16 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
 type HttpClientTransport struct {
-	client *http.Client
-	host   string
-	port   string
+	client   *http.Client
+	scheme   string
+	host     string
+	port     string
+	basePath string
 }
 
 type HttpClientTransportOpt func(t *HttpClientTransport)
@@ -37,8 +40,9 @@ func NewHTTPClientTransport(host string, port string, opts ...HttpClientTranspor
 		client: &http.Client{
 			Timeout: defaultTimeout,
 		},
-		port: port,
-		host: host,
+		scheme: "http",
+		port:   port,
+		host:   host,
 	}
 
 	for _, opt := range opts {
@@ -48,8 +52,40 @@ func NewHTTPClientTransport(host string, port string, opts ...HttpClientTranspor
 	return c
 }
 
+// NewHTTPClientTransportFromURL creates a transport for a server reachable on the given base URL,
+// e.g. https://spark-history.example.com/team-a
+func NewHTTPClientTransportFromURL(baseURL string, opts ...HttpClientTransportOpt) (*HttpClientTransport, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse url, %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("url host missing")
+	}
+
+	c := NewHTTPClientTransport(u.Hostname(), u.Port(), opts...)
+	c.scheme = u.Scheme
+	c.basePath = strings.Trim(u.Path, "/")
+
+	return c, nil
+}
+
 func (h HttpClientTransport) Get(path string) ([]byte, error) {
-	pathURL, err := url.Parse(fmt.Sprintf("http://%s/%s", net.JoinHostPort(h.host, h.port), path))
+	hostPort := h.host
+	if h.port != "" {
+		hostPort = net.JoinHostPort(h.host, h.port)
+	}
+
+	if h.basePath != "" {
+		path = h.basePath + "/" + path
+	}
+
+	pathURL, err := url.Parse(fmt.Sprintf("%s://%s/%s", h.scheme, hostPort, path))
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +109,21 @@ func (h HttpClientTransport) Get(path string) ([]byte, error) {
 		return nil, err
 	}
 
-	if resp.StatusCode == 404 {
-		return nil, NotFoundError{fmt.Errorf("%s", pathURL)}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, NotFoundError{NewStatusError(resp.StatusCode, fmt.Errorf("%s", pathURL))}
+	}
+
+	// The history server answers 503 while it loads an application, the driver while its UI starts or stops,
+	// the request is retried like a request without a response
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return nil, ServiceUnavailableError{NewStatusError(resp.StatusCode, fmt.Errorf("%s", pathURL))}
+	}
+
+	// Error responses are HTML or plain text pages, not the JSON the callers decode
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, NewStatusError(resp.StatusCode, fmt.Errorf("code: %d, %s", resp.StatusCode, pathURL))
 	}
 
 	return io.ReadAll(resp.Body)
@@ -94,5 +94,72 @@ func TestHttpClientGet(t *testing.T) {
 		_, err := t.Get("not-found")
 		require.Error(tt, err)
 		assert.ErrorAs(tt, err, &NotFoundError{})
+		code, ok := GetStatusCode(err)
+		assert.True(tt, ok)
+		assert.Equal(tt, http.StatusNotFound, code)
+	})
+	t.Run("ReturnsStatusErrorWhenResponseIsUnsuccessful", func(tt *testing.T) {
+		t := NewHTTPClientTransport(host, port, WithTransport(transportTestFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       io.NopCloser(bytes.NewBufferString("Internal Server Error")),
+			}, nil
+		})))
+
+		_, err := t.Get("fails")
+		require.Error(tt, err)
+		code, ok := GetStatusCode(err)
+		assert.True(tt, ok)
+		assert.Equal(tt, http.StatusInternalServerError, code)
+	})
+	t.Run("ReturnsServiceUnavailableWhenResponseIs503", func(tt *testing.T) {
+		t := NewHTTPClientTransport(host, port, WithTransport(transportTestFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+			}, nil
+		})))
+
+		_, err := t.Get("unavailable")
+		require.Error(tt, err)
+		assert.ErrorAs(tt, err, &ServiceUnavailableError{})
+		code, ok := GetStatusCode(err)
+		assert.True(tt, ok)
+		assert.Equal(tt, http.StatusServiceUnavailable, code)
+	})
+}
+
+func TestHttpClientFromURL(t *testing.T) {
+	t.Run("GetsPathRelativeToBaseURL", func(tt *testing.T) {
+		t, err := NewHTTPClientTransportFromURL("https://history.example.com/team-a/", WithTransport(transportTestFunc(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(tt, "https://history.example.com/team-a/api/v1/applications", req.URL.String())
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewBufferString("Success")),
+			}, nil
+		})))
+		require.NoError(tt, err)
+
+		body, err := t.Get("api/v1/applications")
+		require.NoError(tt, err)
+		assert.Equal(tt, "Success", string(body))
+	})
+	t.Run("KeepsPort", func(tt *testing.T) {
+		t, err := NewHTTPClientTransportFromURL("http://10.0.0.1:18080", WithTransport(transportTestFunc(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(tt, "http://10.0.0.1:18080/api/v1/applications", req.URL.String())
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewBufferString("Success")),
+			}, nil
+		})))
+		require.NoError(tt, err)
+
+		_, err = t.Get("api/v1/applications")
+		require.NoError(tt, err)
+	})
+	t.Run("ReturnsErrorOnInvalidURL", func(tt *testing.T) {
+		for _, u := range []string{"history.example.com", "ftp://history.example.com", "https://", "://"} {
+			_, err := NewHTTPClientTransportFromURL(u)
+			assert.Error(tt, err, u)
+		}
 	})
 }
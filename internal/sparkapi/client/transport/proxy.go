@@ -2,6 +2,7 @@ package transport
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -70,7 +71,7 @@ func decorateError(err error, statusError *k8serrors.StatusError) error {
 		}
 	}
 
-	wrappedErr := fmt.Errorf("code: %d, reason: %s, causes: %s, %w", code, reason, causeMessages, err)
+	var wrappedErr error = NewStatusError(int(code), fmt.Errorf("code: %d, reason: %s, causes: %s, %w", code, reason, causeMessages, err))
 
 	if k8serrors.IsNotFound(statusError) {
 		wrappedErr = NewNotFoundError(wrappedErr)
@@ -114,3 +115,35 @@ func (e ServiceUnavailableError) Error() string {
 func (e ServiceUnavailableError) Unwrap() error {
 	return e.err
 }
+
+// StatusError indicates that the Spark API responded with an unsuccessful HTTP status code
+type StatusError struct {
+	code int
+	err  error
+}
+
+func NewStatusError(code int, err error) StatusError {
+	return StatusError{code: code, err: err}
+}
+
+func (e StatusError) StatusCode() int {
+	return e.code
+}
+
+func (e StatusError) Error() string {
+	return e.err.Error()
+}
+
+func (e StatusError) Unwrap() error {
+	return e.err
+}
+
+// GetStatusCode returns the HTTP status code of the Spark API response that caused the error,
+// returns false if no response was received
+func GetStatusCode(err error) (int, bool) {
+	var statusErr StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode(), true
+	}
+	return 0, false
+}
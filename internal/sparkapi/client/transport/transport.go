@@ -1,5 +1,33 @@
 package transport
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Client interface {
 	Get(path string) ([]byte, error)
 }
+
+// Mode determines how the Spark API is reached
+type Mode string
+
+const (
+	// DirectMode reaches the Spark API over HTTP on the pod IP (driver) or the service name (history server)
+	DirectMode Mode = "direct"
+	// ProxyMode reaches the Spark API through the Kubernetes API server proxy
+	ProxyMode Mode = "proxy"
+	// ServiceMode reaches the Spark API over HTTP on a configurable service DNS name
+	ServiceMode Mode = "service"
+)
+
+// ParseMode returns the transport mode represented by the given string
+func ParseMode(mode string) (Mode, error) {
+	m := Mode(strings.ToLower(strings.TrimSpace(mode)))
+	switch m {
+	case DirectMode, ProxyMode, ServiceMode:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown transport mode %q, must be one of %q, %q, %q", mode, DirectMode, ProxyMode, ServiceMode)
+	}
+}
@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	t.Run("ParsesKnownModes", func(tt *testing.T) {
+		for in, expected := range map[string]Mode{
+			"direct":    DirectMode,
+			"proxy":     ProxyMode,
+			"service":   ServiceMode,
+			" Proxy ":   ProxyMode,
+			"SERVICE":   ServiceMode,
+			"\tdirect ": DirectMode,
+		} {
+			mode, err := ParseMode(in)
+			require.NoError(tt, err)
+			assert.Equal(tt, expected, mode)
+		}
+	})
+	t.Run("ReturnsErrorOnUnknownMode", func(tt *testing.T) {
+		for _, in := range []string{"", "ingress", "direct,proxy"} {
+			_, err := ParseMode(in)
+			assert.Error(tt, err)
+		}
+	})
+}
@@ -1,10 +1,61 @@
 package client
 
-// Environment is the Spark API representation of a Spark application's environment
+import "time"
+
+// TimeFormat is the format of the timestamps in Spark API responses, e.g. 2020-11-24T17:18:58.797GMT
+const TimeFormat = "2006-01-02T15:04:05.000GMT"
+
+// ParseTime parses a Spark API timestamp
+func ParseTime(value string) (time.Time, error) {
+	return time.ParseInLocation(TimeFormat, value, time.UTC)
+}
+
+// Environment is the Spark API representation of a Spark application's environment.
+// Properties and classpath entries are lists of key value pairs.
 type Environment struct {
-	SparkProperties [][]string `json:"sparkProperties"`
+	Runtime          Runtime           `json:"runtime"`
+	SparkProperties  [][]string        `json:"sparkProperties"`
+	HadoopProperties [][]string        `json:"hadoopProperties"`
+	SystemProperties [][]string        `json:"systemProperties"`
+	ClasspathEntries [][]string        `json:"classpathEntries"`
+	ResourceProfiles []ResourceProfile `json:"resourceProfiles"`
+}
+
+// Runtime is the Spark API representation of a Spark application's JVM runtime
+type Runtime struct {
+	JavaVersion  string `json:"javaVersion"`
+	JavaHome     string `json:"javaHome"`
+	ScalaVersion string `json:"scalaVersion"`
+}
+
+// ResourceProfile is the Spark API representation of a resource profile, available from Spark 3.1
+type ResourceProfile struct {
+	ID                int                                `json:"id"`
+	ExecutorResources map[string]ExecutorResourceRequest `json:"executorResources"`
+	TaskResources     map[string]TaskResourceRequest     `json:"taskResources"`
+}
+
+// ExecutorResourceRequest is the amount of a resource requested for each executor
+type ExecutorResourceRequest struct {
+	ResourceName    string `json:"resourceName"`
+	Amount          int64  `json:"amount"`
+	DiscoveryScript string `json:"discoveryScript"`
+	Vendor          string `json:"vendor"`
 }
 
+// TaskResourceRequest is the amount of a resource requested for each task
+type TaskResourceRequest struct {
+	ResourceName string  `json:"resourceName"`
+	Amount       float64 `json:"amount"`
+}
+
+const (
+	// ClasspathSourceSystem is the source of classpath entries on the JVM classpath
+	ClasspathSourceSystem = "System Classpath"
+	// ClasspathSourceUser is the source of classpath entries added by the application, e.g. with spark.jars
+	ClasspathSourceUser = "Added By User"
+)
+
 // Application is the Spark API representation of a Spark application
 type Application struct {
 	ID       string    `json:"id"`
@@ -25,12 +76,92 @@ type Attempt struct {
 
 // Stage is the Spark API representation of a Spark application stage
 type Stage struct {
-	Status          string `json:"status"`
-	StageID         int    `json:"stageID"`
-	AttemptID       int    `json:"attemptID"`
-	InputBytes      int64  `json:"inputBytes"`
-	OutputBytes     int64  `json:"outputBytes"`
-	ExecutorCpuTime int64  `json:"executorCpuTime"`
+	Status             string `json:"status"`
+	StageID            int    `json:"stageID"`
+	AttemptID          int    `json:"attemptID"`
+	Name               string `json:"name"`
+	NumTasks           int64  `json:"numTasks"`
+	NumCompleteTasks   int64  `json:"numCompleteTasks"`
+	InputBytes         int64  `json:"inputBytes"`
+	OutputBytes        int64  `json:"outputBytes"`
+	ShuffleReadBytes   int64  `json:"shuffleReadBytes"`
+	ExecutorRunTime    int64  `json:"executorRunTime"`
+	ExecutorCpuTime    int64  `json:"executorCpuTime"`
+	JvmGcTime          int64  `json:"jvmGcTime"`
+	MemoryBytesSpilled int64  `json:"memoryBytesSpilled"`
+	DiskBytesSpilled   int64  `json:"diskBytesSpilled"`
+}
+
+const (
+	StageStatusActive   = "ACTIVE"
+	StageStatusComplete = "COMPLETE"
+	StageStatusFailed   = "FAILED"
+	StageStatusPending  = "PENDING"
+	StageStatusSkipped  = "SKIPPED"
+)
+
+// TaskSummary is the Spark API representation of the distribution of a stage's task metrics,
+// computed over the stage's successful tasks. Each metric holds one value per requested quantile.
+type TaskSummary struct {
+	Quantiles          []float64 `json:"quantiles"`
+	ExecutorRunTime    []float64 `json:"executorRunTime"`
+	JvmGcTime          []float64 `json:"jvmGcTime"`
+	MemoryBytesSpilled []float64 `json:"memoryBytesSpilled"`
+	DiskBytesSpilled   []float64 `json:"diskBytesSpilled"`
+	InputMetrics       struct {
+		BytesRead []float64 `json:"bytesRead"`
+	} `json:"inputMetrics"`
+	ShuffleReadMetrics struct {
+		ReadBytes []float64 `json:"readBytes"`
+	} `json:"shuffleReadMetrics"`
+}
+
+// Quantile returns the value of the given metric at quantile q, if q was requested
+func (s *TaskSummary) Quantile(metric []float64, q float64) (float64, bool) {
+	for i, quantile := range s.Quantiles {
+		if quantile == q && i < len(metric) {
+			return metric[i], true
+		}
+	}
+	return 0, false
+}
+
+// Job is the Spark API representation of a Spark job
+type Job struct {
+	JobID          int64  `json:"jobId"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	SubmissionTime string `json:"submissionTime"`
+	CompletionTime string `json:"completionTime"`
+	StageIDs       []int  `json:"stageIds"`
+	JobGroup       string `json:"jobGroup"`
+	Status         string `json:"status"`
+	NumTasks       int64  `json:"numTasks"`
+	NumFailedTasks int64  `json:"numFailedTasks"`
+}
+
+const (
+	JobStatusRunning   = "RUNNING"
+	JobStatusSucceeded = "SUCCEEDED"
+	JobStatusFailed    = "FAILED"
+	JobStatusUnknown   = "UNKNOWN"
+
+	SQLExecutionStatusRunning   = "RUNNING"
+	SQLExecutionStatusCompleted = "COMPLETED"
+	SQLExecutionStatusFailed    = "FAILED"
+)
+
+// SQLExecution is the Spark API representation of a Spark SQL execution
+type SQLExecution struct {
+	ID              int64   `json:"id"`
+	Status          string  `json:"status"`
+	Description     string  `json:"description"`
+	PlanDescription string  `json:"planDescription"`
+	SubmissionTime  string  `json:"submissionTime"`
+	Duration        int64   `json:"duration"`
+	RunningJobIDs   []int64 `json:"runningJobIds"`
+	SuccessJobIDs   []int64 `json:"successJobIds"`
+	FailedJobIDs    []int64 `json:"failedJobIds"`
 }
 
 // Executor is the Spark API representation of a Spark executor
@@ -58,6 +189,22 @@ type Executor struct {
 	MaxMemory         int64                      `json:"maxMemory"`
 	MemoryMetrics     ExecutorMemoryMetrics      `json:"memoryMetrics"`
 	PeakMemoryMetrics *ExecutorPeakMemoryMetrics `json:"peakMemoryMetrics"`
+
+	// Renamed from isBlacklisted and blacklistedInStages in Spark 3.1, see normalizeExecutors
+	IsExcluded          bool  `json:"isExcluded"`
+	ExcludedInStages    []int `json:"excludedInStages"`
+	BlacklistedInStages []int `json:"blacklistedInStages"`
+
+	// Available from Spark 3.0
+	Resources map[string]ResourceInformation `json:"resources"`
+	// Available from Spark 3.1
+	ResourceProfileID int `json:"resourceProfileId"`
+}
+
+// ResourceInformation describes the addresses of a resource, such as GPUs, allocated to an executor
+type ResourceInformation struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"`
 }
 
 // ExecutorMemoryMetrics holds the current values of an executor's memory metrics
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SparkVersion is the major and minor version of a Spark application, the Spark API schema
+// changes between minor versions
+type SparkVersion struct {
+	Major int
+	Minor int
+}
+
+// ParseSparkVersion parses versions such as 2.4.7, 3.1.1 or 3.0.0-amzn-0
+func ParseSparkVersion(version string) (SparkVersion, bool) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return SparkVersion{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return SparkVersion{}, false
+	}
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return SparkVersion{}, false
+	}
+	return SparkVersion{Major: major, Minor: minor}, true
+}
+
+// AtLeast returns true if the version is the given version or newer
+func (v SparkVersion) AtLeast(major int, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// GetSparkVersion returns the Spark version of the most recent application attempt,
+// the Spark API lists the most recent attempt first
+func GetSparkVersion(application *Application) string {
+	if application == nil {
+		return ""
+	}
+	for _, attempt := range application.Attempts {
+		if attempt.AppSparkVersion != "" {
+			return attempt.AppSparkVersion
+		}
+	}
+	return ""
+}
+
+// normalizeExecutors makes the executor fields renamed between Spark versions consistent.
+// Spark 3.1 renamed isBlacklisted to isExcluded and blacklistedInStages to excludedInStages,
+// and still returns the deprecated fields. When the version is unknown either field is trusted.
+func normalizeExecutors(executors []Executor, sparkVersion string) {
+	version, ok := ParseSparkVersion(sparkVersion)
+	for i := range executors {
+		e := &executors[i]
+		switch {
+		case ok && version.AtLeast(3, 1):
+			e.IsBlacklisted = e.IsExcluded
+			e.BlacklistedInStages = e.ExcludedInStages
+		case ok:
+			e.IsExcluded = e.IsBlacklisted
+			e.ExcludedInStages = e.BlacklistedInStages
+		default:
+			e.IsExcluded = e.IsExcluded || e.IsBlacklisted
+			e.IsBlacklisted = e.IsExcluded
+			if len(e.ExcludedInStages) == 0 {
+				e.ExcludedInStages = e.BlacklistedInStages
+			}
+			e.BlacklistedInStages = e.ExcludedInStages
+		}
+	}
+}
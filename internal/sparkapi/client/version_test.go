@@ -0,0 +1,112 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSparkVersion(t *testing.T) {
+
+	t.Run("whenValid", func(tt *testing.T) {
+		versions := map[string]SparkVersion{
+			"2.4.7":         {Major: 2, Minor: 4},
+			"3.0.1":         {Major: 3, Minor: 0},
+			"3.1":           {Major: 3, Minor: 1},
+			"3.0.0-amzn-0":  {Major: 3, Minor: 0},
+			"3.2-SNAPSHOT":  {Major: 3, Minor: 2},
+			"10.12.1-extra": {Major: 10, Minor: 12},
+		}
+		for s, expected := range versions {
+			version, ok := ParseSparkVersion(s)
+			assert.True(tt, ok, s)
+			assert.Equal(tt, expected, version, s)
+		}
+	})
+
+	t.Run("whenInvalid", func(tt *testing.T) {
+		for _, s := range []string{"", "3", "three.one", "3.x.0"} {
+			_, ok := ParseSparkVersion(s)
+			assert.False(tt, ok, s)
+		}
+	})
+}
+
+func TestSparkVersion_AtLeast(t *testing.T) {
+
+	version := SparkVersion{Major: 3, Minor: 0}
+	assert.True(t, version.AtLeast(2, 4))
+	assert.True(t, version.AtLeast(3, 0))
+	assert.False(t, version.AtLeast(3, 1))
+	assert.False(t, version.AtLeast(4, 0))
+}
+
+func TestGetSparkVersion(t *testing.T) {
+
+	assert.Equal(t, "", GetSparkVersion(nil))
+	assert.Equal(t, "", GetSparkVersion(&Application{}))
+	assert.Equal(t, "3.1.1", GetSparkVersion(&Application{
+		Attempts: []Attempt{
+			{},
+			{AppSparkVersion: "3.1.1"},
+			{AppSparkVersion: "3.0.1"},
+		},
+	}))
+}
+
+func TestNormalizeExecutors(t *testing.T) {
+
+	getExecutors := func() []Executor {
+		return []Executor{
+			{
+				ID:                  "1",
+				IsBlacklisted:       true,
+				BlacklistedInStages: []int{1},
+			},
+			{
+				ID:               "2",
+				IsExcluded:       true,
+				ExcludedInStages: []int{2},
+			},
+		}
+	}
+
+	t.Run("whenSpark24", func(tt *testing.T) {
+		executors := getExecutors()
+		normalizeExecutors(executors, "2.4.7")
+		assert.True(tt, executors[0].IsExcluded)
+		assert.Equal(tt, []int{1}, executors[0].ExcludedInStages)
+		assert.False(tt, executors[1].IsExcluded)
+		assert.False(tt, executors[1].IsBlacklisted)
+		assert.Nil(tt, executors[1].ExcludedInStages)
+	})
+
+	t.Run("whenSpark30", func(tt *testing.T) {
+		executors := getExecutors()
+		normalizeExecutors(executors, "3.0.1")
+		assert.True(tt, executors[0].IsExcluded)
+		assert.True(tt, executors[0].IsBlacklisted)
+		assert.False(tt, executors[1].IsExcluded)
+	})
+
+	t.Run("whenSpark31", func(tt *testing.T) {
+		executors := getExecutors()
+		normalizeExecutors(executors, "3.1.1")
+		assert.False(tt, executors[0].IsExcluded)
+		assert.False(tt, executors[0].IsBlacklisted)
+		assert.Nil(tt, executors[0].BlacklistedInStages)
+		assert.True(tt, executors[1].IsExcluded)
+		assert.True(tt, executors[1].IsBlacklisted)
+		assert.Equal(tt, []int{2}, executors[1].BlacklistedInStages)
+	})
+
+	t.Run("whenVersionUnknown", func(tt *testing.T) {
+		executors := getExecutors()
+		normalizeExecutors(executors, "")
+		for _, e := range executors {
+			assert.True(tt, e.IsExcluded, e.ID)
+			assert.True(tt, e.IsBlacklisted, e.ID)
+			assert.Equal(tt, e.ExcludedInStages, e.BlacklistedInStages, e.ID)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package sparkapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+// collectionStateTTL is how long the collection state of an application is kept after it was last used
+const collectionStateTTL = time.Hour
+
+// collectionState is what is remembered about an application between reconciles,
+// so Spark API endpoints that have not changed are not fetched again
+type collectionState struct {
+	// live is true if the state was collected from the driver
+	live bool
+	// attemptStartTimeEpoch identifies the application attempt the state belongs to
+	attemptStartTimeEpoch int64
+	// lastUpdatedEpoch is the last updated time of the attempt when the state was collected,
+	// only history servers and event logs update it while the application runs
+	lastUpdatedEpoch int64
+
+	// the environment is fetched once per attempt
+	sparkProperties map[string]string
+	environment     *v1alpha1.RuntimeEnvironment
+
+	// inactiveExecutors are the removed executors, they never change once removed
+	inactiveExecutors []sparkapiclient.Executor
+	// activeExecutorIDs are the executors that were active in the last response
+	activeExecutorIDs map[string]bool
+	// executorsFingerprint changes whenever tasks are scheduled or completed on any executor
+	executorsFingerprint string
+	// idle is true if no tasks were running when the executors were fetched
+	idle bool
+	// executors is the last list of all executors
+	executors []sparkapiclient.Executor
+
+	// collected is true once jobs, SQL executions, lineage and insights have been collected
+	collected     bool
+	jobs          []sparkapiclient.Job
+	sqlExecutions []sparkapiclient.SQLExecution
+	lineage       *v1alpha1.Lineage
+	insights      []v1alpha1.Insight
+
+	lastUsed time.Time
+}
+
+// collectionCache holds the collection state of applications, indexed by application ID
+type collectionCache struct {
+	mu           sync.Mutex
+	states       map[string]*collectionState
+	timeProvider func() time.Time
+}
+
+func newCollectionCache(timeProvider func() time.Time) *collectionCache {
+	return &collectionCache{
+		states:       make(map[string]*collectionState),
+		timeProvider: timeProvider,
+	}
+}
+
+// get returns the collection state of the application attempt, a new state if the attempt
+// or the Spark API source changed. Expired states of other applications are removed.
+func (c *collectionCache) get(applicationID string, attemptStartTimeEpoch int64, live bool) *collectionState {
+	if c == nil {
+		return &collectionState{live: live, attemptStartTimeEpoch: attemptStartTimeEpoch}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.timeProvider()
+	for id, state := range c.states {
+		if now.Sub(state.lastUsed) > collectionStateTTL {
+			delete(c.states, id)
+		}
+	}
+
+	state, ok := c.states[applicationID]
+	if !ok || state.attemptStartTimeEpoch != attemptStartTimeEpoch || state.live != live {
+		state = &collectionState{live: live, attemptStartTimeEpoch: attemptStartTimeEpoch}
+		c.states[applicationID] = state
+	}
+	state.lastUsed = now
+
+	return state
+}
+
+// getLatestAttempt returns the most recent application attempt, listed first by the Spark API
+func getLatestAttempt(application *sparkapiclient.Application) sparkapiclient.Attempt {
+	if len(application.Attempts) == 0 {
+		return sparkapiclient.Attempt{}
+	}
+	return application.Attempts[0]
+}
+
+// recordExecutors remembers the executors, inactive executors are kept so only the active
+// executors need to be fetched from then on
+func (s *collectionState) recordExecutors(executors []sparkapiclient.Executor) {
+	s.inactiveExecutors = make([]sparkapiclient.Executor, 0)
+	s.activeExecutorIDs = make(map[string]bool)
+	for _, executor := range executors {
+		if executor.IsActive {
+			s.activeExecutorIDs[executor.ID] = true
+		} else {
+			s.inactiveExecutors = append(s.inactiveExecutors, executor)
+		}
+	}
+}
+
+// mergeActiveExecutors returns the recorded inactive executors and the given active executors,
+// returns false if an executor was removed since the executors were recorded, its final state is unknown
+func (s *collectionState) mergeActiveExecutors(active []sparkapiclient.Executor) ([]sparkapiclient.Executor, bool) {
+	if s.activeExecutorIDs == nil {
+		return nil, false
+	}
+
+	ids := make(map[string]bool, len(active))
+	for _, executor := range active {
+		ids[executor.ID] = true
+	}
+	for id := range s.activeExecutorIDs {
+		if !ids[id] {
+			return nil, false
+		}
+	}
+
+	executors := make([]sparkapiclient.Executor, 0, len(s.inactiveExecutors)+len(active))
+	executors = append(executors, s.inactiveExecutors...)
+	executors = append(executors, active...)
+	for _, executor := range active {
+		s.activeExecutorIDs[executor.ID] = true
+	}
+
+	return executors, true
+}
+
+// getExecutorsFingerprint returns a hash of the executors' task counters,
+// and whether no tasks are running
+func getExecutorsFingerprint(executors []sparkapiclient.Executor) (string, bool) {
+	counters := make([]string, 0, len(executors))
+	idle := true
+	for _, e := range executors {
+		counters = append(counters, fmt.Sprintf("%s:%t:%d:%d:%d:%d", e.ID, e.IsActive, e.ActiveTasks, e.CompletedTasks, e.FailedTasks, e.TotalTasks))
+		if e.ActiveTasks > 0 {
+			idle = false
+		}
+	}
+	sort.Strings(counters)
+
+	hash := sha256.New()
+	for _, c := range counters {
+		hash.Write([]byte(c))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), idle
+}
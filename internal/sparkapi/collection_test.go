@@ -0,0 +1,106 @@
+package sparkapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+func TestCollectionCache(t *testing.T) {
+
+	now := time.Now()
+	cache := newCollectionCache(func() time.Time { return now })
+
+	t.Run("whenSameAttempt", func(tt *testing.T) {
+		state := cache.get("app-1", 100, true)
+		state.collected = true
+		assert.Same(tt, state, cache.get("app-1", 100, true))
+	})
+
+	t.Run("whenNewAttempt", func(tt *testing.T) {
+		state := cache.get("app-1", 200, true)
+		assert.False(tt, state.collected)
+	})
+
+	t.Run("whenSourceChanged", func(tt *testing.T) {
+		state := cache.get("app-1", 200, true)
+		state.collected = true
+		assert.False(tt, cache.get("app-1", 200, false).collected)
+	})
+
+	t.Run("whenExpired", func(tt *testing.T) {
+		cache.get("app-2", 100, true)
+		now = now.Add(collectionStateTTL + time.Minute)
+		cache.get("app-3", 100, true)
+		assert.Equal(tt, 1, len(cache.states))
+	})
+
+	t.Run("whenNoCache", func(tt *testing.T) {
+		var noCache *collectionCache
+		assert.NotNil(tt, noCache.get("app-1", 100, true))
+	})
+}
+
+func TestMergeActiveExecutors(t *testing.T) {
+
+	state := &collectionState{}
+
+	_, ok := state.mergeActiveExecutors(nil)
+	assert.False(t, ok)
+
+	state.recordExecutors([]sparkapiclient.Executor{
+		{ID: "driver", IsActive: true},
+		{ID: "1"},
+		{ID: "2", IsActive: true},
+	})
+
+	t.Run("whenExecutorAdded", func(tt *testing.T) {
+		executors, ok := state.mergeActiveExecutors([]sparkapiclient.Executor{
+			{ID: "driver", IsActive: true},
+			{ID: "2", IsActive: true},
+			{ID: "3", IsActive: true},
+		})
+		assert.True(tt, ok)
+		assert.Equal(tt, []string{"1", "driver", "2", "3"}, getExecutorIDs(executors))
+	})
+
+	t.Run("whenExecutorRemoved", func(tt *testing.T) {
+		_, ok := state.mergeActiveExecutors([]sparkapiclient.Executor{
+			{ID: "driver", IsActive: true},
+			{ID: "2", IsActive: true},
+		})
+		assert.False(tt, ok)
+	})
+}
+
+func TestGetExecutorsFingerprint(t *testing.T) {
+
+	executors := []sparkapiclient.Executor{
+		{ID: "driver", IsActive: true},
+		{ID: "1", IsActive: true, CompletedTasks: 3, TotalTasks: 3, MemoryUsed: 100},
+	}
+
+	fingerprint, idle := getExecutorsFingerprint(executors)
+	assert.True(t, idle)
+
+	// Memory usage changes without any progress
+	executors[1].MemoryUsed = 200
+	same, _ := getExecutorsFingerprint([]sparkapiclient.Executor{executors[1], executors[0]})
+	assert.Equal(t, fingerprint, same)
+
+	executors[1].ActiveTasks = 1
+	changed, idle := getExecutorsFingerprint(executors)
+	assert.NotEqual(t, fingerprint, changed)
+	assert.False(t, idle)
+}
+
+func getExecutorIDs(executors []sparkapiclient.Executor) []string {
+	ids := make([]string, 0, len(executors))
+	for _, e := range executors {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
@@ -0,0 +1,134 @@
+package sparkapi
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+const (
+	sparkJarsPackagesProperty = "spark.jars.packages"
+	classpathKey              = "classpath"
+	jarExtension              = ".jar"
+)
+
+// newRuntimeEnvironment returns the curated runtime environment of the application,
+// with sensitive values redacted
+func newRuntimeEnvironment(environment *sparkapiclient.Environment, application *sparkapiclient.Application,
+	sparkProperties map[string]string, r *redactor) *v1alpha1.RuntimeEnvironment {
+
+	env := &v1alpha1.RuntimeEnvironment{
+		JavaVersion:      environment.Runtime.JavaVersion,
+		ScalaVersion:     environment.Runtime.ScalaVersion,
+		SparkVersion:     sparkapiclient.GetSparkVersion(application),
+		Jars:             getJars(environment.ClasspathEntries, r),
+		Packages:         getPackages(sparkProperties, r),
+		ResourceProfiles: getResourceProfiles(environment.ResourceProfiles),
+	}
+
+	return env
+}
+
+// getJars returns the sorted file names of the jars on the classpath
+func getJars(classpathEntries [][]string, r *redactor) []string {
+	names := make(map[string]bool)
+	for _, entry := range classpathEntries {
+		if len(entry) != 2 {
+			continue
+		}
+		name := getJarName(entry[0])
+		if name == "" {
+			continue
+		}
+		names[r.redact(classpathKey, name)] = true
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	jars := make([]string, 0, len(names))
+	for name := range names {
+		jars = append(jars, name)
+	}
+	sort.Strings(jars)
+
+	return jars
+}
+
+// getJarName returns the file name of a classpath entry, which is a local path or a URL
+// of a jar served by the driver, or the empty string if the entry is not a jar
+func getJarName(entry string) string {
+	p := entry
+	if u, err := url.Parse(entry); err == nil && u.Scheme != "" {
+		p = u.Path
+	}
+	name := path.Base(p)
+	if !strings.HasSuffix(name, jarExtension) {
+		return ""
+	}
+	return name
+}
+
+// getPackages returns the Maven coordinates of the packages requested with spark.jars.packages
+func getPackages(sparkProperties map[string]string, r *redactor) []string {
+	value := sparkProperties[sparkJarsPackagesProperty]
+	if value == "" {
+		return nil
+	}
+
+	packages := make([]string, 0)
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			packages = append(packages, r.redact(sparkJarsPackagesProperty, p))
+		}
+	}
+
+	return packages
+}
+
+func getResourceProfiles(apiProfiles []sparkapiclient.ResourceProfile) []v1alpha1.ResourceProfile {
+	if len(apiProfiles) == 0 {
+		return nil
+	}
+
+	profiles := make([]v1alpha1.ResourceProfile, 0, len(apiProfiles))
+	for _, apiProfile := range apiProfiles {
+		profile := v1alpha1.ResourceProfile{
+			ID: int64(apiProfile.ID),
+		}
+		for name, request := range apiProfile.ExecutorResources {
+			profile.ExecutorResources = append(profile.ExecutorResources, v1alpha1.ResourceRequest{
+				Name:   name,
+				Amount: strconv.FormatInt(request.Amount, 10),
+			})
+		}
+		for name, request := range apiProfile.TaskResources {
+			profile.TaskResources = append(profile.TaskResources, v1alpha1.ResourceRequest{
+				Name:   name,
+				Amount: strconv.FormatFloat(request.Amount, 'f', -1, 64),
+			})
+		}
+		sortResourceRequests(profile.ExecutorResources)
+		sortResourceRequests(profile.TaskResources)
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ID < profiles[j].ID
+	})
+
+	return profiles
+}
+
+func sortResourceRequests(requests []v1alpha1.ResourceRequest) {
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Name < requests[j].Name
+	})
+}
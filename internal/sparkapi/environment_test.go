@@ -0,0 +1,100 @@
+package sparkapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+func TestNewRuntimeEnvironment(t *testing.T) {
+
+	logger := getTestLogger()
+
+	environment := &sparkapiclient.Environment{
+		Runtime: sparkapiclient.Runtime{
+			JavaVersion:  "1.8.0_252 (Oracle Corporation)",
+			JavaHome:     "/usr/local/openjdk-8",
+			ScalaVersion: "version 2.12.10",
+		},
+		ClasspathEntries: [][]string{
+			{"/opt/spark/jars/log4j-1.2.17.jar", sparkapiclient.ClasspathSourceSystem},
+			{"/opt/spark/conf/", sparkapiclient.ClasspathSourceSystem},
+			{"spark://10.0.0.1:7078/jars/app.jar", sparkapiclient.ClasspathSourceUser},
+			{"spark://10.0.0.1:7078/files/secret-credentials.jar", sparkapiclient.ClasspathSourceUser},
+			{"/opt/spark/jars/commons-io-2.4.jar", sparkapiclient.ClasspathSourceSystem},
+			{"/opt/spark/jars/log4j-1.2.17.jar"},
+		},
+		ResourceProfiles: []sparkapiclient.ResourceProfile{
+			{
+				ID: 1,
+				ExecutorResources: map[string]sparkapiclient.ExecutorResourceRequest{
+					"memory": {ResourceName: "memory", Amount: 4096},
+					"cores":  {ResourceName: "cores", Amount: 4},
+					"gpu":    {ResourceName: "gpu", Amount: 1, Vendor: "nvidia.com"},
+				},
+				TaskResources: map[string]sparkapiclient.TaskResourceRequest{
+					"gpu": {ResourceName: "gpu", Amount: 0.25},
+				},
+			},
+			{
+				ID: 0,
+			},
+		},
+	}
+
+	// The most recent attempt is listed first
+	application := &sparkapiclient.Application{
+		Attempts: []sparkapiclient.Attempt{
+			{AppSparkVersion: "3.1.1"},
+			{AppSparkVersion: "3.0.1"},
+		},
+	}
+
+	sparkProperties := map[string]string{
+		sparkJarsPackagesProperty: "org.apache.hadoop:hadoop-aws:3.2.0, io.delta:delta-core_2.12:0.8.0,,https://token@repo.example.com:pkg:1.0",
+	}
+
+	env := newRuntimeEnvironment(environment, application, sparkProperties, newRedactor(sparkProperties, testRedactionOptions, logger))
+	require.NotNil(t, env)
+
+	assert.Equal(t, "1.8.0_252 (Oracle Corporation)", env.JavaVersion)
+	assert.Equal(t, "version 2.12.10", env.ScalaVersion)
+	assert.Equal(t, "3.1.1", env.SparkVersion)
+
+	require.Equal(t, 4, len(env.Jars))
+	assert.Equal(t, []string{"app.jar", "commons-io-2.4.jar", "log4j-1.2.17.jar"}, env.Jars[1:])
+	assert.True(t, strings.HasPrefix(env.Jars[0], RedactedValue+":"))
+
+	require.Equal(t, 3, len(env.Packages))
+	assert.Equal(t, []string{"org.apache.hadoop:hadoop-aws:3.2.0", "io.delta:delta-core_2.12:0.8.0"}, env.Packages[:2])
+	assert.True(t, strings.HasPrefix(env.Packages[2], RedactedValue+":"))
+
+	assert.Equal(t, []v1alpha1.ResourceProfile{
+		{
+			ID: 0,
+		},
+		{
+			ID: 1,
+			ExecutorResources: []v1alpha1.ResourceRequest{
+				{Name: "cores", Amount: "4"},
+				{Name: "gpu", Amount: "1"},
+				{Name: "memory", Amount: "4096"},
+			},
+			TaskResources: []v1alpha1.ResourceRequest{
+				{Name: "gpu", Amount: "0.25"},
+			},
+		},
+	}, env.ResourceProfiles)
+}
+
+func TestNewRuntimeEnvironment_empty(t *testing.T) {
+
+	env := newRuntimeEnvironment(&sparkapiclient.Environment{}, &sparkapiclient.Application{}, map[string]string{},
+		newRedactor(map[string]string{}, RedactionOptions{}, getTestLogger()))
+	assert.Equal(t, &v1alpha1.RuntimeEnvironment{}, env)
+}
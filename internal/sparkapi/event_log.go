@@ -0,0 +1,55 @@
+package sparkapi
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spotinst/wave-operator/cloudstorage"
+	"github.com/spotinst/wave-operator/internal/config"
+	"github.com/spotinst/wave-operator/internal/sparkapi/eventlog"
+)
+
+// EventLogOptions configures reading Spark event logs directly, which serves information
+// on finished applications without a history server
+type EventLogOptions struct {
+	// Enabled determines if event logs are read when no history server is available for an application
+	Enabled bool
+	// StorageProvider provides the cloud storage event logs are synced to
+	StorageProvider cloudstorage.CloudStorageProvider
+	// ObjectStore reads event logs from cloud storage
+	ObjectStore cloudstorage.ObjectStore
+}
+
+// getEventLogStore returns a store for the given event log directory
+func getEventLogStore(eventLogDir string, opts EventLogOptions) (eventlog.Store, error) {
+	if eventLogDir == config.SyncedEventLogDir {
+		if opts.StorageProvider == nil || opts.ObjectStore == nil {
+			return nil, fmt.Errorf("cloud storage not configured: %w", ErrApiNotAvailable)
+		}
+		storageInfo, err := opts.StorageProvider.GetStorageInfo()
+		if err != nil {
+			return nil, fmt.Errorf("could not get storage info, %w", err)
+		}
+		if storageInfo == nil {
+			return nil, fmt.Errorf("storage info is nil")
+		}
+		return eventlog.NewObjectStore(opts.ObjectStore, storageInfo.Name, ""), nil
+	}
+
+	u, err := url.Parse(eventLogDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse event log directory %q, %w", eventLogDir, err)
+	}
+
+	switch u.Scheme {
+	case "", "file", "local":
+		return eventlog.NewLocalStore(u.Path), nil
+	case "s3", "s3a", "s3n":
+		if opts.ObjectStore == nil {
+			return nil, fmt.Errorf("cloud storage not configured: %w", ErrApiNotAvailable)
+		}
+		return eventlog.NewObjectStore(opts.ObjectStore, u.Host, u.Path), nil
+	default:
+		return nil, fmt.Errorf("event log directory scheme %q not supported: %w", u.Scheme, ErrApiNotAvailable)
+	}
+}
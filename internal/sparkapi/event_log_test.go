@@ -0,0 +1,142 @@
+package sparkapi
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/spotinst/wave-operator/cloudstorage"
+	"github.com/spotinst/wave-operator/internal/config"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+type fakeStorage struct {
+	storageInfo *cloudstorage.StorageInfo
+	buckets     []string
+}
+
+func (f *fakeStorage) ConfigureHistoryServerStorage() (*cloudstorage.StorageInfo, error) {
+	return f.storageInfo, nil
+}
+
+func (f *fakeStorage) GetStorageInfo() (*cloudstorage.StorageInfo, error) {
+	return f.storageInfo, nil
+}
+
+func (f *fakeStorage) ListObjects(bucket string, prefix string) ([]string, error) {
+	f.buckets = append(f.buckets, bucket)
+	return []string{}, nil
+}
+
+func (f *fakeStorage) GetObject(bucket string, key string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not found")
+}
+
+func TestGetEventLogStore(t *testing.T) {
+
+	storage := &fakeStorage{
+		storageInfo: &cloudstorage.StorageInfo{
+			Name: "spark-history-cluster",
+		},
+	}
+
+	opts := EventLogOptions{
+		Enabled:         true,
+		StorageProvider: storage,
+		ObjectStore:     storage,
+	}
+
+	t.Run("whenSynced", func(tt *testing.T) {
+		store, err := getEventLogStore(config.SyncedEventLogDir, opts)
+		require.NoError(tt, err)
+		_, err = store.List("spark-123")
+		require.NoError(tt, err)
+		assert.Equal(tt, "spark-history-cluster", storage.buckets[len(storage.buckets)-1])
+	})
+
+	t.Run("whenSyncedStorageNotConfigured", func(tt *testing.T) {
+		_, err := getEventLogStore(config.SyncedEventLogDir, EventLogOptions{Enabled: true})
+		assert.True(tt, IsApiNotAvailableError(err))
+	})
+
+	t.Run("whenS3", func(tt *testing.T) {
+		store, err := getEventLogStore("s3a://spark-logs/team-a", opts)
+		require.NoError(tt, err)
+		_, err = store.List("spark-123")
+		require.NoError(tt, err)
+		assert.Equal(tt, "spark-logs", storage.buckets[len(storage.buckets)-1])
+	})
+
+	t.Run("whenLocal", func(tt *testing.T) {
+		store, err := getEventLogStore("file:///mnt/spark-logs", opts)
+		require.NoError(tt, err)
+		assert.NotNil(tt, store)
+	})
+
+	t.Run("whenUnsupportedScheme", func(tt *testing.T) {
+		_, err := getEventLogStore("hdfs://namenode/spark-logs", opts)
+		assert.True(tt, IsApiNotAvailableError(err))
+	})
+}
+
+func TestGetSparkApiClient_eventLogs(t *testing.T) {
+
+	logger := getTestLogger()
+
+	opts := DefaultOptions()
+	opts.EventLogs = EventLogOptions{
+		Enabled: true,
+	}
+
+	app := newTestSparkApplication("3.0.0", map[string]string{
+		sparkEventLogEnabledProperty: "true",
+		sparkEventLogDirProperty:     "file:///mnt/spark-logs",
+	})
+
+	t.Run("whenNoHistoryServerSelected", func(tt *testing.T) {
+		pod := newRunningDriverPod(false)
+		pod.Status.Phase = corev1.PodSucceeded
+
+		c, err := getSparkApiClient(k8sfake.NewSimpleClientset(pod), pod, app, opts, nil, logger)
+		assert.NoError(tt, err)
+		assert.Implements(tt, (*sparkapiclient.Client)(nil), c)
+	})
+
+	t.Run("whenHistoryServerMissing", func(tt *testing.T) {
+		pod := newRunningDriverPod(false)
+		pod.Status.Phase = corev1.PodSucceeded
+
+		o := opts
+		o.HistoryServers = []config.HistoryServer{{Name: "all"}}
+
+		c, err := getSparkApiClient(k8sfake.NewSimpleClientset(pod), pod, app, o, nil, logger)
+		assert.NoError(tt, err)
+		assert.Implements(tt, (*sparkapiclient.Client)(nil), c)
+	})
+
+	t.Run("whenDisabled", func(tt *testing.T) {
+		pod := newRunningDriverPod(false)
+		pod.Status.Phase = corev1.PodSucceeded
+
+		o := opts
+		o.EventLogs.Enabled = false
+
+		c, err := getSparkApiClient(k8sfake.NewSimpleClientset(pod), pod, app, o, nil, logger)
+		assert.Nil(tt, c)
+		assert.True(tt, IsApiNotAvailableError(err))
+	})
+
+	t.Run("whenNoEventLog", func(tt *testing.T) {
+		pod := newRunningDriverPod(false)
+		pod.Status.Phase = corev1.PodSucceeded
+
+		c, err := getSparkApiClient(k8sfake.NewSimpleClientset(pod), pod, nil, opts, nil, logger)
+		assert.Nil(tt, c)
+		assert.True(tt, IsApiNotAvailableError(err))
+	})
+}
@@ -0,0 +1,77 @@
+package eventlog
+
+import (
+	"sync"
+	"time"
+)
+
+// completedLogTTL is how long the replayed event log of a completed application is kept after it was last used
+const completedLogTTL = time.Hour
+
+type cachedLog struct {
+	log      *ApplicationLog
+	lastUsed time.Time
+}
+
+// Cache holds the replayed event logs of completed applications, indexed by application ID.
+// The event log of a completed application no longer changes, so it is downloaded and replayed once
+// instead of on every reconcile. Event logs of applications that have not completed are not cached,
+// their final events may not be synced yet.
+type Cache struct {
+	mu           sync.Mutex
+	logs         map[string]*cachedLog
+	timeProvider func() time.Time
+}
+
+func NewCache(timeProvider func() time.Time) *Cache {
+	return &Cache{
+		logs:         make(map[string]*cachedLog),
+		timeProvider: timeProvider,
+	}
+}
+
+// get returns the cached event log of the application. Expired event logs of other applications are removed.
+func (c *Cache) get(applicationID string) (*ApplicationLog, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.timeProvider()
+	for id, cached := range c.logs {
+		if now.Sub(cached.lastUsed) > completedLogTTL {
+			delete(c.logs, id)
+		}
+	}
+
+	cached, ok := c.logs[applicationID]
+	if !ok {
+		return nil, false
+	}
+	cached.lastUsed = now
+
+	return cached.log, true
+}
+
+// add caches the event log of the application if the application has completed
+func (c *Cache) add(applicationID string, log *ApplicationLog) {
+	if c == nil || !isCompleted(log) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.logs[applicationID] = &cachedLog{
+		log:      log,
+		lastUsed: c.timeProvider(),
+	}
+}
+
+// isCompleted returns whether the latest application attempt, listed first, has completed
+func isCompleted(log *ApplicationLog) bool {
+	attempts := log.Application.Attempts
+	return len(attempts) > 0 && attempts[0].Completed
+}
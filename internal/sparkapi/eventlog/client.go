@@ -0,0 +1,165 @@
+package eventlog
+
+import (
+	"fmt"
+
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
+)
+
+type client struct {
+	store Store
+	cache *Cache
+	logs  map[string]*ApplicationLog
+}
+
+// NewClient returns a Spark API client that serves application information
+// by replaying the application event logs in the given store
+func NewClient(store Store) sparkapiclient.Client {
+	return NewCachedClient(store, nil)
+}
+
+// NewCachedClient returns a Spark API client replaying the application event logs in the given store,
+// the event logs of completed applications are replayed once and kept in the cache
+func NewCachedClient(store Store, cache *Cache) sparkapiclient.Client {
+	return &client{
+		store: store,
+		cache: cache,
+		logs:  make(map[string]*ApplicationLog),
+	}
+}
+
+func (c *client) GetApplication(applicationID string) (*sparkapiclient.Application, error) {
+	log, err := c.getApplicationLog(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	application := log.Application
+	return &application, nil
+}
+
+func (c *client) GetEnvironment(applicationID string) (*sparkapiclient.Environment, error) {
+	log, err := c.getApplicationLog(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	environment := log.Environment
+	return &environment, nil
+}
+
+func (c *client) GetStages(applicationID string) ([]sparkapiclient.Stage, error) {
+	log, err := c.getApplicationLog(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return log.Stages, nil
+}
+
+func (c *client) GetStageTaskSummary(applicationID string, stageID int, attemptID int, quantiles []float64) (*sparkapiclient.TaskSummary, error) {
+	log, err := c.getApplicationLog(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return log.TaskSummary(stageID, attemptID, quantiles)
+}
+
+func (c *client) GetAllExecutors(applicationID string) ([]sparkapiclient.Executor, error) {
+	log, err := c.getApplicationLog(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return log.Executors, nil
+}
+
+// GetExecutors returns the executors that were active when the event log was last written
+func (c *client) GetExecutors(applicationID string) ([]sparkapiclient.Executor, error) {
+	log, err := c.getApplicationLog(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	executors := make([]sparkapiclient.Executor, 0)
+	for _, executor := range log.Executors {
+		if executor.IsActive {
+			executors = append(executors, executor)
+		}
+	}
+	return executors, nil
+}
+
+func (c *client) GetJobs(applicationID string) ([]sparkapiclient.Job, error) {
+	log, err := c.getApplicationLog(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return log.Jobs, nil
+}
+
+func (c *client) GetSQLExecutions(applicationID string) ([]sparkapiclient.SQLExecution, error) {
+	log, err := c.getApplicationLog(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return log.SQLExecutions, nil
+}
+
+// getApplicationLog replays the event log of the given application once per client,
+// or once per cache if the application has completed
+func (c *client) getApplicationLog(applicationID string) (*ApplicationLog, error) {
+	if log, ok := c.logs[applicationID]; ok {
+		return log, nil
+	}
+
+	if log, ok := c.cache.get(applicationID); ok {
+		c.logs[applicationID] = log
+		return log, nil
+	}
+
+	log, err := ReadApplicationLog(c.store, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.add(applicationID, log)
+	c.logs[applicationID] = log
+	return log, nil
+}
+
+// ReadApplicationLog finds the event log of the given application in the store and replays it
+func ReadApplicationLog(store Store, applicationID string) (*ApplicationLog, error) {
+	files, err := findEventLogFiles(store, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find event log, %w", err)
+	}
+
+	rp := newReplayer()
+	for _, f := range files {
+		if err := replayFile(rp, store, f); err != nil {
+			return nil, err
+		}
+	}
+
+	log, err := rp.applicationLog()
+	if err != nil {
+		return nil, fmt.Errorf("could not replay event log, %w", err)
+	}
+
+	if log.Application.ID != applicationID {
+		return nil, transport.NewNotFoundError(fmt.Errorf("event log is of application %q, wanted %q", log.Application.ID, applicationID))
+	}
+
+	return log, nil
+}
+
+func replayFile(rp *replayer, store Store, path string) error {
+	r, closer, err := openEventLogFile(store, path)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	if err := rp.replay(r); err != nil {
+		return fmt.Errorf("could not replay event log file %q, %w", path, err)
+	}
+
+	return nil
+}
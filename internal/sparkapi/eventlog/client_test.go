@@ -0,0 +1,469 @@
+package eventlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
+)
+
+const testApplicationID = "spark-123"
+
+var testEventLogStart = []string{
+	`{"Event":"SparkListenerLogStart","Spark Version":"3.0.1"}`,
+	`{"Event":"SparkListenerBlockManagerAdded","Block Manager ID":{"Executor ID":"driver","Host":"10.0.0.1","Port":7079},"Maximum Memory":1000,"Timestamp":1606238338000}`,
+	`{"Event":"SparkListenerEnvironmentUpdate","JVM Information":{"Java Home":"/usr/local/openjdk-11","Java Version":"11.0.9 (Oracle Corporation)","Scala Version":"version 2.12.10"},"Spark Properties":{"spark.app.name":"spark-pi","spark.task.cpus":"2"},"Hadoop Properties":{"fs.s3a.impl":"org.apache.hadoop.fs.s3a.S3AFileSystem"},"System Properties":{"java.vendor":"Oracle Corporation"},"Classpath Entries":{"/opt/spark/jars/log4j-1.2.17.jar":"System Classpath","/opt/spark/conf/":"System Classpath"}}`,
+	`{"Event":"SparkListenerResourceProfileAdded","Resource Profile Id":0,"Executor Resource Requests":{"cores":{"Resource Name":"cores","Amount":4,"Discovery Script":"","Vendor":""}},"Task Resource Requests":{"cpus":{"Resource Name":"cpus","Amount":2.0}}}`,
+	`{"Event":"SparkListenerApplicationStart","App Name":"spark-pi","App ID":"spark-123","Timestamp":1606238337000,"User":"root"}`,
+	`{"Event":"SparkListenerExecutorAdded","Timestamp":1606238340000,"Executor ID":"1","Executor Info":{"Host":"10.0.0.2","Total Cores":4,"Log Urls":{},"Resources":{"gpu":{"name":"gpu","addresses":["0","1"]}},"Resource Profile Identifier":0}}`,
+	`{"Event":"SparkListenerBlockManagerAdded","Block Manager ID":{"Executor ID":"1","Host":"10.0.0.2","Port":7079},"Maximum Memory":2000,"Timestamp":1606238340100}`,
+	`{"Event":"SparkListenerExecutorAdded","Timestamp":1606238340500,"Executor ID":"2","Executor Info":{"Host":"10.0.0.3","Total Cores":4,"Log Urls":{}}}`,
+	`{"Event":"SparkListenerStageSubmitted","Stage Info":{"Stage ID":0,"Stage Attempt ID":0,"Stage Name":"reduce","Number of Tasks":2}}`,
+	`{"Event":"SparkListenerTaskStart","Stage ID":0,"Stage Attempt ID":0,"Task Info":{"Task ID":0,"Executor ID":"1","Launch Time":1606238341000}}`,
+	`{"Event":"SparkListenerTaskStart","Stage ID":0,"Stage Attempt ID":0,"Task Info":{"Task ID":1,"Executor ID":"2","Launch Time":1606238341000}}`,
+	`{"Event":"SparkListenerTaskEnd","Stage ID":0,"Stage Attempt ID":0,"Task Type":"ResultTask","Task End Reason":{"Reason":"Success"},"Task Info":{"Task ID":0,"Executor ID":"1","Launch Time":1606238341000,"Finish Time":1606238342000,"Failed":false,"Killed":false},"Task Metrics":{"Executor Run Time":900,"Executor CPU Time":500,"JVM GC Time":10,"Memory Bytes Spilled":64,"Disk Bytes Spilled":32,"Input Metrics":{"Bytes Read":100},"Output Metrics":{"Bytes Written":50},"Shuffle Read Metrics":{"Remote Bytes Read":5,"Local Bytes Read":7},"Shuffle Write Metrics":{"Shuffle Bytes Written":20}}}`,
+}
+
+var testEventLogEnd = []string{
+	`{"Event":"SparkListenerTaskEnd","Stage ID":0,"Stage Attempt ID":0,"Task Type":"ResultTask","Task End Reason":{"Reason":"ExceptionFailure"},"Task Info":{"Task ID":1,"Executor ID":"2","Launch Time":1606238341000,"Finish Time":1606238343000,"Failed":true,"Killed":false},"Task Metrics":{"Executor Run Time":1800,"Executor CPU Time":300,"JVM GC Time":5,"Input Metrics":{"Bytes Read":200},"Output Metrics":{"Bytes Written":0},"Shuffle Read Metrics":{"Remote Bytes Read":0,"Local Bytes Read":0},"Shuffle Write Metrics":{"Shuffle Bytes Written":0}}}`,
+	`{"Event":"SparkListenerStageCompleted","Stage Info":{"Stage ID":0,"Stage Attempt ID":0,"Stage Name":"reduce","Number of Tasks":2,"Failure Reason":"Task failed"}}`,
+	`{"Event":"SparkListenerStageSubmitted","Stage Info":{"Stage ID":0,"Stage Attempt ID":1,"Stage Name":"reduce","Number of Tasks":1}}`,
+	`{"Event":"SparkListenerStageCompleted","Stage Info":{"Stage ID":0,"Stage Attempt ID":1,"Stage Name":"reduce","Number of Tasks":1}}`,
+	`{"Event":"SparkListenerExecutorExcluded","time":1606238343500,"executorId":"2","taskFailures":1}`,
+	`{"Event":"SparkListenerExecutorRemoved","Timestamp":1606238344000,"Executor ID":"2","Removed Reason":"Executor killed"}`,
+	`{"Event":"SparkListenerApplicationEnd","Timestamp":1606238345000}`,
+}
+
+func writeTestFile(t *testing.T, dir string, name string, data []byte) {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+	require.NoError(t, ioutil.WriteFile(p, data, 0644))
+}
+
+func testEventLog(events ...[]string) []byte {
+	lines := make([]string, 0)
+	for _, e := range events {
+		lines = append(lines, e...)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func testLZ4EventLog(events ...[]string) []byte {
+	data := testEventLog(events...)
+	return newTestLZ4Block(lz4BlockMethodRaw, data, len(data))
+}
+
+func testZstdEventLog(t *testing.T, events ...[]string) []byte {
+	w, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer w.Close()
+	return w.EncodeAll(testEventLog(events...), nil)
+}
+
+func assertCompletedApplication(t *testing.T, c sparkapiclient.Client) {
+	application, err := c.GetApplication(testApplicationID)
+	require.NoError(t, err)
+	assert.Equal(t, testApplicationID, application.ID)
+	assert.Equal(t, "spark-pi", application.Name)
+	require.Len(t, application.Attempts, 1)
+	assert.Equal(t, sparkapiclient.Attempt{
+		StartTimeEpoch:   1606238337000,
+		EndTimeEpoch:     1606238345000,
+		LastUpdatedEpoch: 1606238345000,
+		Duration:         8000,
+		SparkUser:        "root",
+		Completed:        true,
+		AppSparkVersion:  "3.0.1",
+	}, application.Attempts[0])
+
+	environment, err := c.GetEnvironment(testApplicationID)
+	require.NoError(t, err)
+	assert.Equal(t, sparkapiclient.Runtime{
+		JavaVersion:  "11.0.9 (Oracle Corporation)",
+		JavaHome:     "/usr/local/openjdk-11",
+		ScalaVersion: "version 2.12.10",
+	}, environment.Runtime)
+	assert.Equal(t, [][]string{{"spark.app.name", "spark-pi"}, {"spark.task.cpus", "2"}}, environment.SparkProperties)
+	assert.Equal(t, [][]string{{"fs.s3a.impl", "org.apache.hadoop.fs.s3a.S3AFileSystem"}}, environment.HadoopProperties)
+	assert.Equal(t, [][]string{{"java.vendor", "Oracle Corporation"}}, environment.SystemProperties)
+	assert.Equal(t, [][]string{
+		{"/opt/spark/conf/", sparkapiclient.ClasspathSourceSystem},
+		{"/opt/spark/jars/log4j-1.2.17.jar", sparkapiclient.ClasspathSourceSystem},
+	}, environment.ClasspathEntries)
+	assert.Equal(t, []sparkapiclient.ResourceProfile{
+		{
+			ID: 0,
+			ExecutorResources: map[string]sparkapiclient.ExecutorResourceRequest{
+				"cores": {ResourceName: "cores", Amount: 4},
+			},
+			TaskResources: map[string]sparkapiclient.TaskResourceRequest{
+				"cpus": {ResourceName: "cpus", Amount: 2},
+			},
+		},
+	}, environment.ResourceProfiles)
+
+	stages, err := c.GetStages(testApplicationID)
+	require.NoError(t, err)
+	assert.Equal(t, []sparkapiclient.Stage{
+		{Status: "COMPLETE", StageID: 0, AttemptID: 1, Name: "reduce", NumTasks: 1},
+		{
+			Status:             "FAILED",
+			StageID:            0,
+			AttemptID:          0,
+			Name:               "reduce",
+			NumTasks:           2,
+			NumCompleteTasks:   1,
+			InputBytes:         300,
+			OutputBytes:        50,
+			ShuffleReadBytes:   12,
+			ExecutorRunTime:    2700,
+			ExecutorCpuTime:    800,
+			JvmGcTime:          15,
+			MemoryBytesSpilled: 64,
+			DiskBytesSpilled:   32,
+		},
+	}, stages)
+
+	// Only the successful task is summarized
+	summary, err := c.GetStageTaskSummary(testApplicationID, 0, 0, []float64{0.5, 1.0})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.5, 1.0}, summary.Quantiles)
+	assert.Equal(t, []float64{900, 900}, summary.ExecutorRunTime)
+	assert.Equal(t, []float64{10, 10}, summary.JvmGcTime)
+	assert.Equal(t, []float64{64, 64}, summary.MemoryBytesSpilled)
+	assert.Equal(t, []float64{32, 32}, summary.DiskBytesSpilled)
+	assert.Equal(t, []float64{100, 100}, summary.InputMetrics.BytesRead)
+	assert.Equal(t, []float64{12, 12}, summary.ShuffleReadMetrics.ReadBytes)
+
+	_, err = c.GetStageTaskSummary(testApplicationID, 0, 1, []float64{0.5, 1.0})
+	assert.ErrorAs(t, err, &transport.NotFoundError{})
+
+	executors, err := c.GetAllExecutors(testApplicationID)
+	require.NoError(t, err)
+	assert.Equal(t, []sparkapiclient.Executor{
+		{
+			ID:        "driver",
+			AddTime:   "2020-11-24T17:18:58.000GMT",
+			MaxMemory: 1000,
+		},
+		{
+			ID:                "1",
+			AddTime:           "2020-11-24T17:19:00.000GMT",
+			TotalCores:        4,
+			MaxTasks:          2,
+			CompletedTasks:    1,
+			TotalTasks:        1,
+			TotalDuration:     1000,
+			TotalGCTime:       10,
+			TotalInputBytes:   100,
+			TotalShuffleRead:  12,
+			TotalShuffleWrite: 20,
+			MaxMemory:         2000,
+			Resources: map[string]sparkapiclient.ResourceInformation{
+				"gpu": {Name: "gpu", Addresses: []string{"0", "1"}},
+			},
+		},
+		{
+			ID:              "2",
+			AddTime:         "2020-11-24T17:19:00.500GMT",
+			RemoveTime:      "2020-11-24T17:19:04.000GMT",
+			RemoveReason:    "Executor killed",
+			TotalCores:      4,
+			MaxTasks:        2,
+			FailedTasks:     1,
+			TotalTasks:      1,
+			TotalDuration:   2000,
+			TotalGCTime:     5,
+			TotalInputBytes: 200,
+			IsBlacklisted:   true,
+			IsExcluded:      true,
+		},
+	}, executors)
+
+	// No executor is active once the application has ended
+	activeExecutors, err := c.GetExecutors(testApplicationID)
+	require.NoError(t, err)
+	assert.Empty(t, activeExecutors)
+}
+
+func TestClient(t *testing.T) {
+
+	t.Run("whenSingleFile", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, testApplicationID, testEventLog(testEventLogStart, testEventLogEnd))
+		writeTestFile(tt, dir, "spark-1234", []byte("not this one"))
+
+		assertCompletedApplication(tt, NewClient(NewLocalStore(dir)))
+	})
+
+	t.Run("whenCompressedFile", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, testApplicationID+".lz4", testLZ4EventLog(testEventLogStart, testEventLogEnd))
+
+		assertCompletedApplication(tt, NewClient(NewLocalStore(dir)))
+	})
+
+	t.Run("whenZstdCompressedFile", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, testApplicationID+".zstd", testZstdEventLog(tt, testEventLogStart, testEventLogEnd))
+
+		assertCompletedApplication(tt, NewClient(NewLocalStore(dir)))
+	})
+
+	t.Run("whenRollingFiles", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, "eventlog_v2_spark-123/appstatus_spark-123", nil)
+		writeTestFile(tt, dir, "eventlog_v2_spark-123/events_1_spark-123.lz4", testLZ4EventLog(testEventLogStart))
+		writeTestFile(tt, dir, "eventlog_v2_spark-123/events_2_spark-123.snappy", newTestSnappyStream(string(testEventLog(testEventLogEnd))))
+
+		assertCompletedApplication(tt, NewClient(NewLocalStore(dir)))
+	})
+
+	t.Run("whenCompactedRollingFiles", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, "eventlog_v2_spark-123/events_1_spark-123", []byte("{}\n"))
+		writeTestFile(tt, dir, "eventlog_v2_spark-123/events_2_spark-123.compact", testEventLog(testEventLogStart))
+		writeTestFile(tt, dir, "eventlog_v2_spark-123/events_10_spark-123", testEventLog(testEventLogEnd))
+
+		assertCompletedApplication(tt, NewClient(NewLocalStore(dir)))
+	})
+
+	t.Run("whenInProgress", func(tt *testing.T) {
+		dir := tt.TempDir()
+		// The last event may be partially written
+		data := append(testEventLog(testEventLogStart), []byte(`{"Event":"SparkListenerTask`)...)
+		writeTestFile(tt, dir, testApplicationID+".inprogress", data)
+
+		c := NewClient(NewLocalStore(dir))
+		application, err := c.GetApplication(testApplicationID)
+		require.NoError(tt, err)
+		require.Len(tt, application.Attempts, 1)
+		assert.False(tt, application.Attempts[0].Completed)
+		assert.Equal(tt, int64(-1), application.Attempts[0].EndTimeEpoch)
+		assert.Equal(tt, int64(1606238342000), application.Attempts[0].LastUpdatedEpoch)
+
+		executors, err := c.GetAllExecutors(testApplicationID)
+		require.NoError(tt, err)
+		require.Len(tt, executors, 3)
+		assert.True(tt, executors[2].IsActive)
+		assert.Equal(tt, int64(1), executors[2].ActiveTasks)
+	})
+
+	t.Run("whenNotFound", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, "spark-1234", testEventLog(testEventLogStart))
+
+		_, err := NewClient(NewLocalStore(dir)).GetApplication(testApplicationID)
+		assert.ErrorAs(tt, err, &transport.NotFoundError{})
+	})
+
+	t.Run("whenInvalidEvent", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, testApplicationID, testEventLog([]string{"not json"}, testEventLogStart))
+
+		_, err := NewClient(NewLocalStore(dir)).GetApplication(testApplicationID)
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenUnsupportedCodec", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, testApplicationID+".lzf", nil)
+
+		_, err := NewClient(NewLocalStore(dir)).GetApplication(testApplicationID)
+		assert.ErrorAs(tt, err, &ErrUnsupportedCodec{})
+	})
+}
+
+type fakeObjectStore struct {
+	bucket  string
+	objects map[string][]byte
+}
+
+func (f *fakeObjectStore) ListObjects(bucket string, prefix string) ([]string, error) {
+	if bucket != f.bucket {
+		return nil, fmt.Errorf("bucket %q not found", bucket)
+	}
+	keys := make([]string, 0)
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (f *fakeObjectStore) GetObject(bucket string, key string) (io.ReadCloser, error) {
+	data, ok := f.objects[key]
+	if bucket != f.bucket || !ok {
+		return nil, fmt.Errorf("object %q not found", key)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestClient_objectStore(t *testing.T) {
+	objects := &fakeObjectStore{
+		bucket: "spark-logs",
+		objects: map[string][]byte{
+			"logs/":                 nil,
+			"logs/spark-123_1.lz4":  testLZ4EventLog(testEventLogStart),
+			"logs/spark-123_2.lz4":  testLZ4EventLog(testEventLogStart, testEventLogEnd),
+			"other/spark-123_3.lz4": testLZ4EventLog(testEventLogStart),
+		},
+	}
+
+	assertCompletedApplication(t, NewClient(NewObjectStore(objects, "spark-logs", "/logs/")))
+}
+
+func TestClient_jobs(t *testing.T) {
+	events := []string{
+		`{"Event":"SparkListenerApplicationStart","App Name":"notebook","App ID":"spark-123","Timestamp":1606238337000,"User":"root"}`,
+		`{"Event":"SparkListenerExecutorAdded","Timestamp":1606238340000,"Executor ID":"1","Executor Info":{"Host":"10.0.0.2","Total Cores":4,"Log Urls":{}}}`,
+		`{"Event":"org.apache.spark.sql.execution.ui.SparkListenerSQLExecutionStart","executionId":0,"description":"select count(*) from t","details":"","physicalPlanDescription":"== Physical Plan ==","sparkPlanInfo":{},"time":1606238341000}`,
+		`{"Event":"SparkListenerJobStart","Job ID":0,"Submission Time":1606238341000,"Stage Infos":[{"Stage ID":1,"Stage Name":"count at <console>:1","Number of Tasks":2},{"Stage ID":0,"Stage Name":"scan","Number of Tasks":4}],"Stage IDs":[0,1],"Properties":{"spark.sql.execution.id":"0","spark.job.description":"select count(*) from t","spark.jobGroup.id":"cell-1"}}`,
+		`{"Event":"SparkListenerTaskEnd","Stage ID":1,"Stage Attempt ID":0,"Task Type":"ResultTask","Task End Reason":{"Reason":"ExceptionFailure"},"Task Info":{"Task ID":0,"Executor ID":"1","Launch Time":1606238341000,"Finish Time":1606238342000,"Killed":false}}`,
+		`{"Event":"SparkListenerJobEnd","Job ID":0,"Completion Time":1606238343000,"Job Result":{"Result":"JobFailed","Exception":{"Message":"failed"}}}`,
+		`{"Event":"org.apache.spark.sql.execution.ui.SparkListenerSQLExecutionEnd","executionId":0,"time":1606238343500}`,
+		`{"Event":"org.apache.spark.sql.execution.ui.SparkListenerSQLExecutionStart","executionId":1,"description":"select 1","details":"","physicalPlanDescription":"== Physical Plan ==","sparkPlanInfo":{},"time":1606238344000}`,
+		`{"Event":"SparkListenerJobStart","Job ID":1,"Submission Time":1606238344000,"Stage Infos":[{"Stage ID":2,"Stage Name":"collect at <console>:1","Number of Tasks":1}],"Stage IDs":[2],"Properties":{"spark.sql.execution.id":"1"}}`,
+		`{"Event":"SparkListenerTaskStart","Stage ID":2,"Stage Attempt ID":0,"Task Info":{"Task ID":1,"Executor ID":"1","Launch Time":1606238345000}}`,
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, testApplicationID+".inprogress", testEventLog(events))
+
+	c := NewClient(NewLocalStore(dir))
+
+	jobs, err := c.GetJobs(testApplicationID)
+	require.NoError(t, err)
+	assert.Equal(t, []sparkapiclient.Job{
+		{
+			JobID:          1,
+			Name:           "collect at <console>:1",
+			SubmissionTime: "2020-11-24T17:19:04.000GMT",
+			StageIDs:       []int{2},
+			Status:         sparkapiclient.JobStatusRunning,
+			NumTasks:       1,
+		},
+		{
+			JobID:          0,
+			Name:           "count at <console>:1",
+			Description:    "select count(*) from t",
+			SubmissionTime: "2020-11-24T17:19:01.000GMT",
+			CompletionTime: "2020-11-24T17:19:03.000GMT",
+			StageIDs:       []int{0, 1},
+			JobGroup:       "cell-1",
+			Status:         sparkapiclient.JobStatusFailed,
+			NumTasks:       6,
+			NumFailedTasks: 1,
+		},
+	}, jobs)
+
+	executions, err := c.GetSQLExecutions(testApplicationID)
+	require.NoError(t, err)
+	assert.Equal(t, []sparkapiclient.SQLExecution{
+		{
+			ID:              0,
+			Status:          sparkapiclient.SQLExecutionStatusFailed,
+			Description:     "select count(*) from t",
+			PlanDescription: "== Physical Plan ==",
+			SubmissionTime:  "2020-11-24T17:19:01.000GMT",
+			Duration:        2500,
+			RunningJobIDs:   []int64{},
+			SuccessJobIDs:   []int64{},
+			FailedJobIDs:    []int64{0},
+		},
+		{
+			ID:              1,
+			Status:          sparkapiclient.SQLExecutionStatusRunning,
+			Description:     "select 1",
+			PlanDescription: "== Physical Plan ==",
+			SubmissionTime:  "2020-11-24T17:19:04.000GMT",
+			Duration:        1000,
+			RunningJobIDs:   []int64{1},
+			SuccessJobIDs:   []int64{},
+			FailedJobIDs:    []int64{},
+		},
+	}, executions)
+}
+
+func TestQuantileValues(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 100}
+	assert.Equal(t, []float64{1, 4, 100}, quantileValues(values, []float64{0.0, 0.5, 1.0}))
+	assert.Equal(t, []float64{5, 1, 4, 2, 3, 100}, values)
+}
+
+// countingStore counts the files opened in the store
+type countingStore struct {
+	Store
+	opened int
+}
+
+func (s *countingStore) Open(p string) (io.ReadCloser, error) {
+	s.opened++
+	return s.Store.Open(p)
+}
+
+func TestClient_cache(t *testing.T) {
+
+	now := time.Date(2020, 11, 24, 17, 20, 0, 0, time.UTC)
+	timeProvider := func() time.Time { return now }
+
+	t.Run("whenCompleted", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, testApplicationID, testEventLog(testEventLogStart, testEventLogEnd))
+		store := &countingStore{Store: NewLocalStore(dir)}
+		cache := NewCache(timeProvider)
+
+		assertCompletedApplication(tt, NewCachedClient(store, cache))
+		assertCompletedApplication(tt, NewCachedClient(store, cache))
+		assert.Equal(tt, 1, store.opened)
+	})
+
+	t.Run("whenInProgress", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, testApplicationID+".inprogress", testEventLog(testEventLogStart))
+		store := &countingStore{Store: NewLocalStore(dir)}
+		cache := NewCache(timeProvider)
+
+		for i := 0; i < 2; i++ {
+			application, err := NewCachedClient(store, cache).GetApplication(testApplicationID)
+			require.NoError(tt, err)
+			assert.False(tt, application.Attempts[0].Completed)
+		}
+		assert.Equal(tt, 2, store.opened)
+
+		// The final event log is read once the application has completed
+		require.NoError(tt, os.Remove(filepath.Join(dir, testApplicationID+".inprogress")))
+		writeTestFile(tt, dir, testApplicationID, testEventLog(testEventLogStart, testEventLogEnd))
+		assertCompletedApplication(tt, NewCachedClient(store, cache))
+		assertCompletedApplication(tt, NewCachedClient(store, cache))
+		assert.Equal(tt, 3, store.opened)
+	})
+
+	t.Run("whenExpired", func(tt *testing.T) {
+		dir := tt.TempDir()
+		writeTestFile(tt, dir, testApplicationID, testEventLog(testEventLogStart, testEventLogEnd))
+		store := &countingStore{Store: NewLocalStore(dir)}
+		current := now
+		cache := NewCache(func() time.Time { return current })
+
+		assertCompletedApplication(tt, NewCachedClient(store, cache))
+		current = current.Add(completedLogTTL + time.Second)
+		assertCompletedApplication(tt, NewCachedClient(store, cache))
+		assert.Equal(tt, 2, store.opened)
+	})
+}
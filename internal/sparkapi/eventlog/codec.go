@@ -0,0 +1,350 @@
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec is a Spark IO compression codec, identified by its short name
+type Codec string
+
+const (
+	NoCodec     Codec = ""
+	LZ4Codec    Codec = "lz4"
+	LZFCodec    Codec = "lzf"
+	SnappyCodec Codec = "snappy"
+	ZstdCodec   Codec = "zstd"
+)
+
+const (
+	inProgressSuffix = ".inprogress"
+	compactedSuffix  = ".compact"
+
+	// maxBlockSize guards against corrupt block headers, the block size of both
+	// lz4 and snappy compressed event logs is configured in kilobytes and is 32k by default
+	maxBlockSize = 64 << 20
+)
+
+// ErrUnsupportedCodec is returned for event logs compressed with a codec that can not be decoded
+type ErrUnsupportedCodec struct {
+	Codec Codec
+}
+
+func (e ErrUnsupportedCodec) Error() string {
+	return fmt.Sprintf("unsupported event log compression codec %q", e.Codec)
+}
+
+// codecFromFileName returns the compression codec of an event log file,
+// Spark appends the codec short name to the file name
+func codecFromFileName(name string) Codec {
+	name = path.Base(name)
+	name = strings.TrimSuffix(name, inProgressSuffix)
+	name = strings.TrimSuffix(name, compactedSuffix)
+
+	switch codec := Codec(strings.TrimPrefix(path.Ext(name), ".")); codec {
+	case LZ4Codec, LZFCodec, SnappyCodec, ZstdCodec:
+		return codec
+	default:
+		return NoCodec
+	}
+}
+
+// newDecompressingReader wraps r in a reader that decompresses the given codec
+func newDecompressingReader(r io.Reader, codec Codec) (io.Reader, error) {
+	switch codec {
+	case NoCodec:
+		return r, nil
+	case LZ4Codec:
+		return newLZ4BlockReader(r), nil
+	case SnappyCodec:
+		return newSnappyStreamReader(r), nil
+	case ZstdCodec:
+		return newZstdReader(r)
+	default:
+		return nil, ErrUnsupportedCodec{Codec: codec}
+	}
+}
+
+// zstdReader reads the frame format written by zstd-jni's ZstdOutputStream,
+// which Spark uses for the zstd codec
+type zstdReader struct {
+	d    *zstd.Decoder
+	done bool
+}
+
+func newZstdReader(r io.Reader) (*zstdReader, error) {
+	// A single goroutine is enough for event logs, and avoids leaking
+	// the decoder's background goroutines when a reader is abandoned
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxBlockSize))
+	if err != nil {
+		return nil, fmt.Errorf("could not create zstd decoder, %w", err)
+	}
+	return &zstdReader{d: d}, nil
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	if z.done {
+		return 0, io.EOF
+	}
+	n, err := z.d.Read(p)
+	if err == io.EOF {
+		z.done = true
+		z.d.Close()
+	} else if err != nil {
+		err = fmt.Errorf("could not decompress zstd stream, %w", err)
+	}
+	return n, err
+}
+
+// lz4BlockReader reads the block format written by lz4-java's LZ4BlockOutputStream,
+// which Spark uses for the lz4 codec
+type lz4BlockReader struct {
+	r          *bufio.Reader
+	compressed []byte
+	block      []byte
+	pending    []byte
+}
+
+const (
+	lz4BlockHeaderLength     = 21
+	lz4BlockMethodRaw        = 0x10
+	lz4BlockMethodCompressed = 0x20
+)
+
+var lz4BlockMagic = []byte("LZ4Block")
+
+func newLZ4BlockReader(r io.Reader) *lz4BlockReader {
+	return &lz4BlockReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+func (l *lz4BlockReader) Read(p []byte) (int, error) {
+	for len(l.pending) == 0 {
+		if err := l.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, l.pending)
+	l.pending = l.pending[n:]
+	return n, nil
+}
+
+func (l *lz4BlockReader) readBlock() error {
+	header := make([]byte, lz4BlockHeaderLength)
+	if _, err := io.ReadFull(l.r, header); err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return fmt.Errorf("could not read lz4 block header, %w", err)
+	}
+
+	if !bytes.Equal(header[:len(lz4BlockMagic)], lz4BlockMagic) {
+		return fmt.Errorf("invalid lz4 block magic")
+	}
+
+	method := header[8] & 0xF0
+	compressedLength := int(int32(binary.LittleEndian.Uint32(header[9:13])))
+	decompressedLength := int(int32(binary.LittleEndian.Uint32(header[13:17])))
+	// header[17:21] holds a checksum of the decompressed block, which is not verified
+
+	if compressedLength < 0 || compressedLength > maxBlockSize || decompressedLength < 0 || decompressedLength > maxBlockSize {
+		return fmt.Errorf("invalid lz4 block lengths, compressed %d, decompressed %d", compressedLength, decompressedLength)
+	}
+
+	// An empty block marks the end of a stream, another stream may follow
+	if decompressedLength == 0 {
+		return nil
+	}
+
+	if cap(l.compressed) < compressedLength {
+		l.compressed = make([]byte, compressedLength)
+	}
+	compressed := l.compressed[:compressedLength]
+	if _, err := io.ReadFull(l.r, compressed); err != nil {
+		return fmt.Errorf("could not read lz4 block, %w", io.ErrUnexpectedEOF)
+	}
+
+	switch method {
+	case lz4BlockMethodRaw:
+		if compressedLength != decompressedLength {
+			return fmt.Errorf("invalid raw lz4 block lengths, compressed %d, decompressed %d", compressedLength, decompressedLength)
+		}
+		l.pending = compressed
+	case lz4BlockMethodCompressed:
+		if cap(l.block) < decompressedLength {
+			l.block = make([]byte, decompressedLength)
+		}
+		block := l.block[:decompressedLength]
+		n, err := decodeLZ4Block(block, compressed)
+		if err != nil {
+			return fmt.Errorf("could not decompress lz4 block, %w", err)
+		}
+		if n != decompressedLength {
+			return fmt.Errorf("lz4 block decompressed to %d bytes, wanted %d", n, decompressedLength)
+		}
+		l.pending = block
+	default:
+		return fmt.Errorf("unknown lz4 block compression method %#x", method)
+	}
+
+	return nil
+}
+
+// decodeLZ4Block decompresses a raw lz4 block into dst and returns the number of bytes written
+func decodeLZ4Block(dst, src []byte) (int, error) {
+	si, di := 0, 0
+	for si < len(src) {
+		token := src[si]
+		si++
+
+		literalLength := int(token >> 4)
+		if literalLength == 15 {
+			n, err := readLZ4Length(src, &si)
+			if err != nil {
+				return di, err
+			}
+			literalLength += n
+		}
+		if si+literalLength > len(src) || di+literalLength > len(dst) {
+			return di, fmt.Errorf("literal length %d out of range", literalLength)
+		}
+		copy(dst[di:], src[si:si+literalLength])
+		si += literalLength
+		di += literalLength
+
+		// The last sequence only holds literals
+		if si == len(src) {
+			break
+		}
+
+		if si+2 > len(src) {
+			return di, fmt.Errorf("truncated match offset")
+		}
+		offset := int(src[si]) | int(src[si+1])<<8
+		si += 2
+		if offset == 0 || offset > di {
+			return di, fmt.Errorf("match offset %d out of range", offset)
+		}
+
+		matchLength := int(token & 0x0F)
+		if matchLength == 15 {
+			n, err := readLZ4Length(src, &si)
+			if err != nil {
+				return di, err
+			}
+			matchLength += n
+		}
+		matchLength += 4
+		if di+matchLength > len(dst) {
+			return di, fmt.Errorf("match length %d out of range", matchLength)
+		}
+
+		// Matches may overlap the bytes being written, so copy byte by byte
+		for i := 0; i < matchLength; i++ {
+			dst[di] = dst[di-offset]
+			di++
+		}
+	}
+	return di, nil
+}
+
+func readLZ4Length(src []byte, si *int) (int, error) {
+	length := 0
+	for {
+		if *si >= len(src) {
+			return 0, fmt.Errorf("truncated length")
+		}
+		b := src[*si]
+		*si++
+		length += int(b)
+		if b != 255 {
+			return length, nil
+		}
+	}
+}
+
+// snappyStreamReader reads the stream format written by snappy-java's SnappyOutputStream,
+// which Spark uses for the snappy codec
+type snappyStreamReader struct {
+	r          *bufio.Reader
+	compressed []byte
+	block      []byte
+	pending    []byte
+}
+
+const snappyStreamHeaderLength = 16
+
+var snappyStreamMagic = []byte{0x82, 'S', 'N', 'A', 'P', 'P', 'Y', 0}
+
+func newSnappyStreamReader(r io.Reader) *snappyStreamReader {
+	return &snappyStreamReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+func (s *snappyStreamReader) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if err := s.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+func (s *snappyStreamReader) readBlock() error {
+	// Every stream starts with a header, streams may be concatenated
+	next, err := s.r.Peek(1)
+	if err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return fmt.Errorf("could not read snappy stream, %w", err)
+	}
+	if next[0] == snappyStreamMagic[0] {
+		header := make([]byte, snappyStreamHeaderLength)
+		if _, err := io.ReadFull(s.r, header); err != nil {
+			return fmt.Errorf("could not read snappy stream header, %w", io.ErrUnexpectedEOF)
+		}
+		if !bytes.Equal(header[:len(snappyStreamMagic)], snappyStreamMagic) {
+			return fmt.Errorf("invalid snappy stream magic")
+		}
+		return nil
+	}
+
+	lengthBytes := make([]byte, 4)
+	if _, err := io.ReadFull(s.r, lengthBytes); err != nil {
+		return fmt.Errorf("could not read snappy block length, %w", io.ErrUnexpectedEOF)
+	}
+	compressedLength := int(int32(binary.BigEndian.Uint32(lengthBytes)))
+	if compressedLength < 0 || compressedLength > maxBlockSize {
+		return fmt.Errorf("invalid snappy block length %d", compressedLength)
+	}
+
+	if cap(s.compressed) < compressedLength {
+		s.compressed = make([]byte, compressedLength)
+	}
+	compressed := s.compressed[:compressedLength]
+	if _, err := io.ReadFull(s.r, compressed); err != nil {
+		return fmt.Errorf("could not read snappy block, %w", io.ErrUnexpectedEOF)
+	}
+
+	block, err := snappy.Decode(s.block[:cap(s.block)], compressed)
+	if err != nil {
+		return fmt.Errorf("could not decompress snappy block, %w", err)
+	}
+	s.block = block
+	s.pending = block
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package eventlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lz4 block holding "abc" followed by a match of 9 bytes at offset 3 and the literal "\n"
+var testLZ4CompressedBlock = []byte{0x35, 'a', 'b', 'c', 0x03, 0x00, 0x10, '\n'}
+
+const testLZ4DecompressedBlock = "abcabcabcabc\n"
+
+// zstd frame holding "first line\n" followed by a repeat of 33 bytes at offset 11
+var testZstdFrame = []byte{
+	0x28, 0xb5, 0x2f, 0xfd, 0x00, 0x58, 0x8d, 0x00, 0x00, 0x58, 0x66, 0x69, 0x72,
+	0x73, 0x74, 0x20, 0x6c, 0x69, 0x6e, 0x65, 0x0a, 0x01, 0x00, 0x1e, 0x8b, 0x17,
+}
+
+const testZstdDecompressedFrame = "first line\nfirst line\nfirst line\nfirst line\n"
+
+func newTestLZ4Block(method byte, data []byte, decompressedLength int) []byte {
+	block := append([]byte{}, lz4BlockMagic...)
+	block = append(block, method)
+	block = append(block, make([]byte, 12)...)
+	binary.LittleEndian.PutUint32(block[9:13], uint32(len(data)))
+	binary.LittleEndian.PutUint32(block[13:17], uint32(decompressedLength))
+	return append(block, data...)
+}
+
+func newTestSnappyStream(blocks ...string) []byte {
+	stream := append([]byte{}, snappyStreamMagic...)
+	stream = append(stream, 0, 0, 0, 1, 0, 0, 0, 1)
+	for _, b := range blocks {
+		compressed := snappy.Encode(nil, []byte(b))
+		length := make([]byte, 4)
+		binary.BigEndian.PutUint32(length, uint32(len(compressed)))
+		stream = append(stream, length...)
+		stream = append(stream, compressed...)
+	}
+	return stream
+}
+
+func TestCodecFromFileName(t *testing.T) {
+	testCases := map[string]Codec{
+		"spark-123":                     NoCodec,
+		"spark-123.inprogress":          NoCodec,
+		"spark-123.lz4":                 LZ4Codec,
+		"spark-123_1.snappy.inprogress": SnappyCodec,
+		"eventlog_v2_spark-123/events_1_spark-123.zstd":        ZstdCodec,
+		"eventlog_v2_spark-123/events_1_spark-123.lzf.compact": LZFCodec,
+		"spark-123.gz": NoCodec,
+	}
+	for name, expected := range testCases {
+		assert.Equal(t, expected, codecFromFileName(name), name)
+	}
+}
+
+func TestNewDecompressingReader(t *testing.T) {
+
+	t.Run("whenLZ4", func(tt *testing.T) {
+		data := newTestLZ4Block(lz4BlockMethodCompressed, testLZ4CompressedBlock, len(testLZ4DecompressedBlock))
+		data = append(data, newTestLZ4Block(lz4BlockMethodRaw, []byte("raw\n"), 4)...)
+		// End of stream, followed by a concatenated stream
+		data = append(data, newTestLZ4Block(lz4BlockMethodRaw, nil, 0)...)
+		data = append(data, newTestLZ4Block(lz4BlockMethodRaw, []byte("next\n"), 5)...)
+
+		r, err := newDecompressingReader(bytes.NewReader(data), LZ4Codec)
+		require.NoError(tt, err)
+		res, err := ioutil.ReadAll(r)
+		require.NoError(tt, err)
+		assert.Equal(tt, testLZ4DecompressedBlock+"raw\nnext\n", string(res))
+	})
+
+	t.Run("whenLZ4Truncated", func(tt *testing.T) {
+		data := newTestLZ4Block(lz4BlockMethodCompressed, testLZ4CompressedBlock, len(testLZ4DecompressedBlock))
+
+		r, err := newDecompressingReader(bytes.NewReader(data[:len(data)-2]), LZ4Codec)
+		require.NoError(tt, err)
+		_, err = ioutil.ReadAll(r)
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenLZ4InvalidOffset", func(tt *testing.T) {
+		compressed := []byte{0x35, 'a', 'b', 'c', 0x04, 0x00, 0x10, '\n'}
+		data := newTestLZ4Block(lz4BlockMethodCompressed, compressed, len(testLZ4DecompressedBlock))
+
+		r, err := newDecompressingReader(bytes.NewReader(data), LZ4Codec)
+		require.NoError(tt, err)
+		_, err = ioutil.ReadAll(r)
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenSnappy", func(tt *testing.T) {
+		data := newTestSnappyStream("first line\n", "second line\n")
+		data = append(data, newTestSnappyStream("third line\n")...)
+
+		r, err := newDecompressingReader(bytes.NewReader(data), SnappyCodec)
+		require.NoError(tt, err)
+		res, err := ioutil.ReadAll(r)
+		require.NoError(tt, err)
+		assert.Equal(tt, "first line\nsecond line\nthird line\n", string(res))
+	})
+
+	t.Run("whenSnappyInvalidMagic", func(tt *testing.T) {
+		data := newTestSnappyStream("first line\n")
+		data[1] = 'X'
+
+		r, err := newDecompressingReader(bytes.NewReader(data), SnappyCodec)
+		require.NoError(tt, err)
+		_, err = ioutil.ReadAll(r)
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenZstd", func(tt *testing.T) {
+		data := append(append([]byte{}, testZstdFrame...), testZstdFrame...)
+
+		r, err := newDecompressingReader(bytes.NewReader(data), ZstdCodec)
+		require.NoError(tt, err)
+		res, err := ioutil.ReadAll(r)
+		require.NoError(tt, err)
+		assert.Equal(tt, testZstdDecompressedFrame+testZstdDecompressedFrame, string(res))
+	})
+
+	t.Run("whenZstdTruncated", func(tt *testing.T) {
+		data := testZstdFrame[:len(testZstdFrame)-4]
+
+		r, err := newDecompressingReader(bytes.NewReader(data), ZstdCodec)
+		require.NoError(tt, err)
+		_, err = ioutil.ReadAll(r)
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenUncompressed", func(tt *testing.T) {
+		r, err := newDecompressingReader(bytes.NewReader([]byte("line\n")), NoCodec)
+		require.NoError(tt, err)
+		res, err := ioutil.ReadAll(r)
+		require.NoError(tt, err)
+		assert.Equal(tt, "line\n", string(res))
+	})
+
+	t.Run("whenUnsupported", func(tt *testing.T) {
+		_, err := newDecompressingReader(bytes.NewReader(nil), LZFCodec)
+		assert.ErrorAs(tt, err, &ErrUnsupportedCodec{})
+	})
+}
@@ -0,0 +1,145 @@
+package eventlog
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
+)
+
+const (
+	rollingEventLogDirPrefix  = "eventlog_v2_"
+	rollingEventLogFilePrefix = "events_"
+)
+
+// findEventLogFiles returns the event log files of the given application, in the order they should be replayed.
+// Single file event logs are named after the application ID, with optional attempt ID, codec and in progress suffixes.
+// Rolling event logs are written to a directory per application, holding numbered event files
+// of which the oldest may have been compacted.
+// If the application has several attempts, the files of the last attempt are returned.
+func findEventLogFiles(store Store, applicationID string) ([]string, error) {
+	rollingPaths, err := store.List(rollingEventLogDirPrefix + applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	rollingDirs := make(map[string][]string)
+	for _, p := range rollingPaths {
+		dir, file := path.Split(p)
+		dir = strings.TrimSuffix(dir, "/")
+		if !isApplicationLogName(strings.TrimPrefix(dir, rollingEventLogDirPrefix), applicationID) {
+			continue
+		}
+		if strings.HasPrefix(file, rollingEventLogFilePrefix) {
+			rollingDirs[dir] = append(rollingDirs[dir], p)
+		}
+	}
+
+	if len(rollingDirs) > 0 {
+		dirs := make([]string, 0, len(rollingDirs))
+		for dir := range rollingDirs {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+		return orderRollingEventLogFiles(rollingDirs[dirs[len(dirs)-1]])
+	}
+
+	singlePaths, err := store.List(applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	for _, p := range singlePaths {
+		if !strings.Contains(p, "/") && isApplicationLogName(p, applicationID) {
+			files = append(files, p)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, transport.NewNotFoundError(fmt.Errorf("event log of application %q not found", applicationID))
+	}
+
+	sort.Strings(files)
+	return files[len(files)-1:], nil
+}
+
+// isApplicationLogName determines if name is the application ID, optionally followed by an attempt ID or extensions
+func isApplicationLogName(name string, applicationID string) bool {
+	if !strings.HasPrefix(name, applicationID) {
+		return false
+	}
+	rest := strings.TrimPrefix(name, applicationID)
+	return rest == "" || strings.HasPrefix(rest, "_") || strings.HasPrefix(rest, ".")
+}
+
+type rollingEventLogFile struct {
+	path      string
+	index     int64
+	compacted bool
+}
+
+// orderRollingEventLogFiles orders rolling event log files by index, skipping the files
+// that precede the last compacted file, as their events are included in the compacted file
+func orderRollingEventLogFiles(paths []string) ([]string, error) {
+	files := make([]rollingEventLogFile, 0, len(paths))
+	for _, p := range paths {
+		name := strings.TrimPrefix(path.Base(p), rollingEventLogFilePrefix)
+		i := strings.Index(name, "_")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid rolling event log file name %q", p)
+		}
+		index, err := strconv.ParseInt(name[:i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rolling event log file index %q, %w", p, err)
+		}
+		files = append(files, rollingEventLogFile{
+			path:      p,
+			index:     index,
+			compacted: strings.HasSuffix(p, compactedSuffix),
+		})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].index < files[j].index
+	})
+
+	start := 0
+	for i, f := range files {
+		if f.compacted {
+			start = i
+		}
+	}
+
+	ordered := make([]string, 0, len(files)-start)
+	for _, f := range files[start:] {
+		// The file a compacted file was built from may not have been deleted yet
+		if f.index == files[start].index && f.compacted != files[start].compacted {
+			continue
+		}
+		ordered = append(ordered, f.path)
+	}
+
+	return ordered, nil
+}
+
+// openEventLogFile opens an event log file, decompressing it according to its name
+func openEventLogFile(store Store, p string) (io.Reader, io.Closer, error) {
+	codec := codecFromFileName(p)
+	f, err := store.Open(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open event log file %q, %w", p, err)
+	}
+
+	r, err := newDecompressingReader(f, codec)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return r, f, nil
+}
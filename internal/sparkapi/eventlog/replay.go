@@ -0,0 +1,832 @@
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
+)
+
+const (
+	logStartEvent          = "SparkListenerLogStart"
+	applicationStartEvent  = "SparkListenerApplicationStart"
+	applicationEndEvent    = "SparkListenerApplicationEnd"
+	environmentUpdateEvent = "SparkListenerEnvironmentUpdate"
+	resourceProfileAdded   = "SparkListenerResourceProfileAdded"
+	blockManagerAddedEvent = "SparkListenerBlockManagerAdded"
+	executorAddedEvent     = "SparkListenerExecutorAdded"
+	executorRemovedEvent   = "SparkListenerExecutorRemoved"
+	executorBlacklisted    = "SparkListenerExecutorBlacklisted"
+	executorUnblacklisted  = "SparkListenerExecutorUnblacklisted"
+	executorExcluded       = "SparkListenerExecutorExcluded"
+	executorUnexcluded     = "SparkListenerExecutorUnexcluded"
+	stageSubmittedEvent    = "SparkListenerStageSubmitted"
+	stageCompletedEvent    = "SparkListenerStageCompleted"
+	taskStartEvent         = "SparkListenerTaskStart"
+	taskEndEvent           = "SparkListenerTaskEnd"
+	jobStartEvent          = "SparkListenerJobStart"
+	jobEndEvent            = "SparkListenerJobEnd"
+	sqlExecutionStartEvent = "org.apache.spark.sql.execution.ui.SparkListenerSQLExecutionStart"
+	sqlExecutionEndEvent   = "org.apache.spark.sql.execution.ui.SparkListenerSQLExecutionEnd"
+	jobResultSucceeded     = "JobSucceeded"
+	jobDescriptionProperty = "spark.job.description"
+	jobGroupProperty       = "spark.jobGroup.id"
+	sqlExecutionIDProperty = "spark.sql.execution.id"
+	driverExecutorID       = "driver"
+	taskEndReasonSuccess   = "Success"
+	sparkTaskCpusProperty  = "spark.task.cpus"
+)
+
+// ApplicationLog is the state of a Spark application, rebuilt by replaying its event log.
+// The state is represented the same way the Spark API represents it.
+type ApplicationLog struct {
+	Application sparkapiclient.Application
+	Environment sparkapiclient.Environment
+	Stages      []sparkapiclient.Stage
+	Executors   []sparkapiclient.Executor
+	Jobs        []sparkapiclient.Job
+	// SQLExecutions is empty for applications that do not use Spark SQL
+	SQLExecutions []sparkapiclient.SQLExecution
+
+	stageTaskMetrics map[stageKey]*stageTaskMetrics
+}
+
+// TaskSummary returns the distribution of the task metrics of a stage attempt at the given quantiles.
+// Like the Spark API, the distribution is computed over the successful tasks of the stage attempt.
+func (l *ApplicationLog) TaskSummary(stageID int, attemptID int, quantiles []float64) (*sparkapiclient.TaskSummary, error) {
+	metrics, ok := l.stageTaskMetrics[stageKey{id: stageID, attempt: attemptID}]
+	if !ok || len(metrics.executorRunTime) == 0 {
+		return nil, transport.NewNotFoundError(fmt.Errorf("no successful tasks for stage %d attempt %d", stageID, attemptID))
+	}
+
+	summary := &sparkapiclient.TaskSummary{
+		Quantiles:          quantiles,
+		ExecutorRunTime:    quantileValues(metrics.executorRunTime, quantiles),
+		JvmGcTime:          quantileValues(metrics.jvmGcTime, quantiles),
+		MemoryBytesSpilled: quantileValues(metrics.memoryBytesSpilled, quantiles),
+		DiskBytesSpilled:   quantileValues(metrics.diskBytesSpilled, quantiles),
+	}
+	summary.InputMetrics.BytesRead = quantileValues(metrics.bytesRead, quantiles)
+	summary.ShuffleReadMetrics.ReadBytes = quantileValues(metrics.shuffleReadBytes, quantiles)
+
+	return summary, nil
+}
+
+// stageTaskMetrics holds the metrics of the successful tasks of a stage attempt
+type stageTaskMetrics struct {
+	executorRunTime    []float64
+	jvmGcTime          []float64
+	memoryBytesSpilled []float64
+	diskBytesSpilled   []float64
+	bytesRead          []float64
+	shuffleReadBytes   []float64
+}
+
+func (m *stageTaskMetrics) add(metrics *taskMetrics) {
+	m.executorRunTime = append(m.executorRunTime, float64(metrics.ExecutorRunTime))
+	m.jvmGcTime = append(m.jvmGcTime, float64(metrics.JVMGCTime))
+	m.memoryBytesSpilled = append(m.memoryBytesSpilled, float64(metrics.MemoryBytesSpilled))
+	m.diskBytesSpilled = append(m.diskBytesSpilled, float64(metrics.DiskBytesSpilled))
+	m.bytesRead = append(m.bytesRead, float64(metrics.InputMetrics.BytesRead))
+	m.shuffleReadBytes = append(m.shuffleReadBytes, float64(metrics.ShuffleReadMetrics.RemoteBytesRead+metrics.ShuffleReadMetrics.LocalBytesRead))
+}
+
+// quantileValues returns the values at the given quantiles, indexed the same way the Spark API indexes them
+func quantileValues(values []float64, quantiles []float64) []float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	result := make([]float64, 0, len(quantiles))
+	for _, q := range quantiles {
+		idx := int(q * float64(len(sorted)))
+		if idx > len(sorted)-1 {
+			idx = len(sorted) - 1
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		result = append(result, sorted[idx])
+	}
+	return result
+}
+
+type event struct {
+	Event string `json:"Event"`
+}
+
+type logStart struct {
+	SparkVersion string `json:"Spark Version"`
+}
+
+type applicationStart struct {
+	AppName   string `json:"App Name"`
+	AppID     string `json:"App ID"`
+	Timestamp int64  `json:"Timestamp"`
+	User      string `json:"User"`
+}
+
+type applicationEnd struct {
+	Timestamp int64 `json:"Timestamp"`
+}
+
+type environmentUpdate struct {
+	JVMInformation struct {
+		JavaVersion  string `json:"Java Version"`
+		JavaHome     string `json:"Java Home"`
+		ScalaVersion string `json:"Scala Version"`
+	} `json:"JVM Information"`
+	SparkProperties  map[string]string `json:"Spark Properties"`
+	HadoopProperties map[string]string `json:"Hadoop Properties"`
+	SystemProperties map[string]string `json:"System Properties"`
+	ClasspathEntries map[string]string `json:"Classpath Entries"`
+}
+
+type resourceProfileAddedEvent struct {
+	ResourceProfileID        int `json:"Resource Profile Id"`
+	ExecutorResourceRequests map[string]struct {
+		ResourceName    string `json:"Resource Name"`
+		Amount          int64  `json:"Amount"`
+		DiscoveryScript string `json:"Discovery Script"`
+		Vendor          string `json:"Vendor"`
+	} `json:"Executor Resource Requests"`
+	TaskResourceRequests map[string]struct {
+		ResourceName string  `json:"Resource Name"`
+		Amount       float64 `json:"Amount"`
+	} `json:"Task Resource Requests"`
+}
+
+type blockManagerAdded struct {
+	BlockManagerID struct {
+		ExecutorID string `json:"Executor ID"`
+	} `json:"Block Manager ID"`
+	MaximumMemory int64 `json:"Maximum Memory"`
+	Timestamp     int64 `json:"Timestamp"`
+}
+
+type executorAdded struct {
+	Timestamp    int64  `json:"Timestamp"`
+	ExecutorID   string `json:"Executor ID"`
+	ExecutorInfo struct {
+		TotalCores        int64                                         `json:"Total Cores"`
+		Resources         map[string]sparkapiclient.ResourceInformation `json:"Resources"`
+		ResourceProfileID int                                           `json:"Resource Profile Identifier"`
+	} `json:"Executor Info"`
+}
+
+type executorRemoved struct {
+	Timestamp     int64  `json:"Timestamp"`
+	ExecutorID    string `json:"Executor ID"`
+	RemovedReason string `json:"Removed Reason"`
+}
+
+type executorExclusion struct {
+	ExecutorID string `json:"executorId"`
+}
+
+type stageInfo struct {
+	StageID        int     `json:"Stage ID"`
+	StageAttemptID int     `json:"Stage Attempt ID"`
+	StageName      string  `json:"Stage Name"`
+	NumberOfTasks  int64   `json:"Number of Tasks"`
+	FailureReason  *string `json:"Failure Reason"`
+}
+
+type stageEvent struct {
+	StageInfo stageInfo `json:"Stage Info"`
+}
+
+type taskInfo struct {
+	ExecutorID string `json:"Executor ID"`
+	LaunchTime int64  `json:"Launch Time"`
+	FinishTime int64  `json:"Finish Time"`
+	Killed     bool   `json:"Killed"`
+}
+
+type taskStart struct {
+	TaskInfo taskInfo `json:"Task Info"`
+}
+
+type taskEnd struct {
+	StageID        int `json:"Stage ID"`
+	StageAttemptID int `json:"Stage Attempt ID"`
+	TaskEndReason  struct {
+		Reason string `json:"Reason"`
+	} `json:"Task End Reason"`
+	TaskInfo    taskInfo     `json:"Task Info"`
+	TaskMetrics *taskMetrics `json:"Task Metrics"`
+}
+
+type taskMetrics struct {
+	ExecutorRunTime    int64 `json:"Executor Run Time"`
+	ExecutorCPUTime    int64 `json:"Executor CPU Time"`
+	JVMGCTime          int64 `json:"JVM GC Time"`
+	MemoryBytesSpilled int64 `json:"Memory Bytes Spilled"`
+	DiskBytesSpilled   int64 `json:"Disk Bytes Spilled"`
+	InputMetrics       struct {
+		BytesRead int64 `json:"Bytes Read"`
+	} `json:"Input Metrics"`
+	OutputMetrics struct {
+		BytesWritten int64 `json:"Bytes Written"`
+	} `json:"Output Metrics"`
+	ShuffleReadMetrics struct {
+		RemoteBytesRead int64 `json:"Remote Bytes Read"`
+		LocalBytesRead  int64 `json:"Local Bytes Read"`
+	} `json:"Shuffle Read Metrics"`
+	ShuffleWriteMetrics struct {
+		ShuffleBytesWritten int64 `json:"Shuffle Bytes Written"`
+	} `json:"Shuffle Write Metrics"`
+}
+
+type jobStart struct {
+	JobID          int64 `json:"Job ID"`
+	SubmissionTime int64 `json:"Submission Time"`
+	StageInfos     []struct {
+		StageID       int    `json:"Stage ID"`
+		StageName     string `json:"Stage Name"`
+		NumberOfTasks int64  `json:"Number of Tasks"`
+	} `json:"Stage Infos"`
+	Properties map[string]string `json:"Properties"`
+}
+
+type jobEnd struct {
+	JobID          int64 `json:"Job ID"`
+	CompletionTime int64 `json:"Completion Time"`
+	JobResult      struct {
+		Result string `json:"Result"`
+	} `json:"Job Result"`
+}
+
+type sqlExecutionStart struct {
+	ExecutionID             int64  `json:"executionId"`
+	Description             string `json:"description"`
+	PhysicalPlanDescription string `json:"physicalPlanDescription"`
+	Time                    int64  `json:"time"`
+}
+
+type sqlExecutionEnd struct {
+	ExecutionID int64 `json:"executionId"`
+	Time        int64 `json:"time"`
+}
+
+type sqlExecution struct {
+	execution sparkapiclient.SQLExecution
+	startTime int64
+	endTime   int64
+	jobIDs    []int64
+}
+
+type stageKey struct {
+	id      int
+	attempt int
+}
+
+// replayer rebuilds application state from listener events
+type replayer struct {
+	application      sparkapiclient.Application
+	attempt          sparkapiclient.Attempt
+	started          bool
+	runtime          sparkapiclient.Runtime
+	sparkProperties  map[string]string
+	hadoopProperties map[string]string
+	systemProperties map[string]string
+	classpathEntries map[string]string
+	resourceProfiles map[int]sparkapiclient.ResourceProfile
+	executors        map[string]*sparkapiclient.Executor
+	stages           map[stageKey]*sparkapiclient.Stage
+	stageTasks       map[stageKey]*stageTaskMetrics
+	jobs             map[int64]*sparkapiclient.Job
+	stageJobs        map[int]int64
+	sqlExecutions    map[int64]*sqlExecution
+}
+
+func newReplayer() *replayer {
+	return &replayer{
+		sparkProperties:  make(map[string]string),
+		hadoopProperties: make(map[string]string),
+		systemProperties: make(map[string]string),
+		classpathEntries: make(map[string]string),
+		resourceProfiles: make(map[int]sparkapiclient.ResourceProfile),
+		executors:        make(map[string]*sparkapiclient.Executor),
+		stages:           make(map[stageKey]*sparkapiclient.Stage),
+		stageTasks:       make(map[stageKey]*stageTaskMetrics),
+		jobs:             make(map[int64]*sparkapiclient.Job),
+		stageJobs:        make(map[int]int64),
+		sqlExecutions:    make(map[int64]*sqlExecution),
+	}
+}
+
+// replay applies the events read from r, one JSON event per line
+func (rp *replayer) replay(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if applyErr := rp.apply(line); applyErr != nil {
+				// The last line of an in progress or truncated log may be incomplete
+				if err == io.EOF {
+					return nil
+				}
+				return applyErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read event log, %w", err)
+		}
+	}
+}
+
+func (rp *replayer) apply(line []byte) error {
+	e := &event{}
+	if err := json.Unmarshal(line, e); err != nil {
+		return fmt.Errorf("could not parse event, %w", err)
+	}
+
+	var err error
+	switch e.Event {
+	case logStartEvent:
+		ev := &logStart{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			rp.attempt.AppSparkVersion = ev.SparkVersion
+		}
+	case applicationStartEvent:
+		ev := &applicationStart{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			rp.started = true
+			rp.application.ID = ev.AppID
+			rp.application.Name = ev.AppName
+			rp.attempt.StartTimeEpoch = ev.Timestamp
+			rp.attempt.SparkUser = ev.User
+			rp.updated(ev.Timestamp)
+		}
+	case applicationEndEvent:
+		ev := &applicationEnd{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			rp.attempt.EndTimeEpoch = ev.Timestamp
+			rp.attempt.Completed = true
+			rp.updated(ev.Timestamp)
+		}
+	case environmentUpdateEvent:
+		ev := &environmentUpdate{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			rp.runtime = sparkapiclient.Runtime{
+				JavaVersion:  ev.JVMInformation.JavaVersion,
+				JavaHome:     ev.JVMInformation.JavaHome,
+				ScalaVersion: ev.JVMInformation.ScalaVersion,
+			}
+			copyProperties(rp.sparkProperties, ev.SparkProperties)
+			copyProperties(rp.hadoopProperties, ev.HadoopProperties)
+			copyProperties(rp.systemProperties, ev.SystemProperties)
+			copyProperties(rp.classpathEntries, ev.ClasspathEntries)
+		}
+	case resourceProfileAdded:
+		ev := &resourceProfileAddedEvent{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			rp.applyResourceProfileAdded(ev)
+		}
+	case blockManagerAddedEvent:
+		ev := &blockManagerAdded{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			executorID := ev.BlockManagerID.ExecutorID
+			// The driver is listed as an executor by the Spark API, but no executor added event is posted for it
+			if executorID == driverExecutorID {
+				rp.addExecutor(executorID, ev.Timestamp, 0)
+			}
+			if executor, ok := rp.executors[executorID]; ok {
+				executor.MaxMemory = ev.MaximumMemory
+			}
+			rp.updated(ev.Timestamp)
+		}
+	case executorAddedEvent:
+		ev := &executorAdded{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			executor := rp.addExecutor(ev.ExecutorID, ev.Timestamp, ev.ExecutorInfo.TotalCores)
+			if len(ev.ExecutorInfo.Resources) > 0 {
+				executor.Resources = ev.ExecutorInfo.Resources
+			}
+			executor.ResourceProfileID = ev.ExecutorInfo.ResourceProfileID
+			rp.updated(ev.Timestamp)
+		}
+	case executorRemovedEvent:
+		ev := &executorRemoved{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			if executor, ok := rp.executors[ev.ExecutorID]; ok {
+				executor.IsActive = false
+				executor.ActiveTasks = 0
+				executor.RemoveTime = formatTime(ev.Timestamp)
+				executor.RemoveReason = ev.RemovedReason
+			}
+			rp.updated(ev.Timestamp)
+		}
+	case executorBlacklisted, executorExcluded, executorUnblacklisted, executorUnexcluded:
+		ev := &executorExclusion{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			if executor, ok := rp.executors[ev.ExecutorID]; ok {
+				// Spark 3.1 renamed blacklisting to exclusion, the Spark API returns both fields
+				executor.IsExcluded = e.Event == executorBlacklisted || e.Event == executorExcluded
+				executor.IsBlacklisted = executor.IsExcluded
+			}
+		}
+	case stageSubmittedEvent:
+		ev := &stageEvent{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			stage := rp.getStage(ev.StageInfo.StageID, ev.StageInfo.StageAttemptID)
+			stage.Status = sparkapiclient.StageStatusActive
+			stage.Name = ev.StageInfo.StageName
+			stage.NumTasks = ev.StageInfo.NumberOfTasks
+		}
+	case stageCompletedEvent:
+		ev := &stageEvent{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			stage := rp.getStage(ev.StageInfo.StageID, ev.StageInfo.StageAttemptID)
+			stage.Name = ev.StageInfo.StageName
+			stage.NumTasks = ev.StageInfo.NumberOfTasks
+			if ev.StageInfo.FailureReason != nil {
+				stage.Status = sparkapiclient.StageStatusFailed
+			} else {
+				stage.Status = sparkapiclient.StageStatusComplete
+			}
+		}
+	case taskStartEvent:
+		ev := &taskStart{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			if executor, ok := rp.executors[ev.TaskInfo.ExecutorID]; ok {
+				executor.ActiveTasks++
+			}
+			rp.updated(ev.TaskInfo.LaunchTime)
+		}
+	case taskEndEvent:
+		ev := &taskEnd{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			rp.applyTaskEnd(ev)
+		}
+	case jobStartEvent:
+		ev := &jobStart{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			rp.applyJobStart(ev)
+		}
+	case jobEndEvent:
+		ev := &jobEnd{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			if job, ok := rp.jobs[ev.JobID]; ok {
+				job.CompletionTime = formatTime(ev.CompletionTime)
+				if ev.JobResult.Result == jobResultSucceeded {
+					job.Status = sparkapiclient.JobStatusSucceeded
+				} else {
+					job.Status = sparkapiclient.JobStatusFailed
+				}
+			}
+			rp.updated(ev.CompletionTime)
+		}
+	case sqlExecutionStartEvent:
+		ev := &sqlExecutionStart{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			rp.sqlExecutions[ev.ExecutionID] = &sqlExecution{
+				execution: sparkapiclient.SQLExecution{
+					ID:              ev.ExecutionID,
+					Description:     ev.Description,
+					PlanDescription: ev.PhysicalPlanDescription,
+					SubmissionTime:  formatTime(ev.Time),
+				},
+				startTime: ev.Time,
+			}
+			rp.updated(ev.Time)
+		}
+	case sqlExecutionEndEvent:
+		ev := &sqlExecutionEnd{}
+		if err = json.Unmarshal(line, ev); err == nil {
+			if execution, ok := rp.sqlExecutions[ev.ExecutionID]; ok {
+				execution.endTime = ev.Time
+			}
+			rp.updated(ev.Time)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not parse %s event, %w", e.Event, err)
+	}
+
+	return nil
+}
+
+func (rp *replayer) applyTaskEnd(ev *taskEnd) {
+	rp.updated(ev.TaskInfo.FinishTime)
+
+	if jobID, ok := rp.stageJobs[ev.StageID]; ok && ev.TaskEndReason.Reason != taskEndReasonSuccess && !ev.TaskInfo.Killed {
+		if job, ok := rp.jobs[jobID]; ok {
+			job.NumFailedTasks++
+		}
+	}
+
+	stage := rp.getStage(ev.StageID, ev.StageAttemptID)
+	if ev.TaskEndReason.Reason == taskEndReasonSuccess {
+		stage.NumCompleteTasks++
+	}
+	if ev.TaskMetrics != nil {
+		stage.InputBytes += ev.TaskMetrics.InputMetrics.BytesRead
+		stage.OutputBytes += ev.TaskMetrics.OutputMetrics.BytesWritten
+		stage.ShuffleReadBytes += ev.TaskMetrics.ShuffleReadMetrics.RemoteBytesRead + ev.TaskMetrics.ShuffleReadMetrics.LocalBytesRead
+		stage.ExecutorRunTime += ev.TaskMetrics.ExecutorRunTime
+		stage.ExecutorCpuTime += ev.TaskMetrics.ExecutorCPUTime
+		stage.JvmGcTime += ev.TaskMetrics.JVMGCTime
+		stage.MemoryBytesSpilled += ev.TaskMetrics.MemoryBytesSpilled
+		stage.DiskBytesSpilled += ev.TaskMetrics.DiskBytesSpilled
+
+		if ev.TaskEndReason.Reason == taskEndReasonSuccess {
+			key := stageKey{id: ev.StageID, attempt: ev.StageAttemptID}
+			metrics, ok := rp.stageTasks[key]
+			if !ok {
+				metrics = &stageTaskMetrics{}
+				rp.stageTasks[key] = metrics
+			}
+			metrics.add(ev.TaskMetrics)
+		}
+	}
+
+	executor, ok := rp.executors[ev.TaskInfo.ExecutorID]
+	if !ok {
+		return
+	}
+
+	if executor.ActiveTasks > 0 {
+		executor.ActiveTasks--
+	}
+	executor.TotalTasks++
+	switch {
+	case ev.TaskEndReason.Reason == taskEndReasonSuccess:
+		executor.CompletedTasks++
+	case !ev.TaskInfo.Killed:
+		executor.FailedTasks++
+	}
+
+	if ev.TaskInfo.FinishTime > ev.TaskInfo.LaunchTime {
+		executor.TotalDuration += ev.TaskInfo.FinishTime - ev.TaskInfo.LaunchTime
+	}
+
+	if ev.TaskMetrics != nil {
+		executor.TotalGCTime += ev.TaskMetrics.JVMGCTime
+		executor.TotalInputBytes += ev.TaskMetrics.InputMetrics.BytesRead
+		executor.TotalShuffleRead += ev.TaskMetrics.ShuffleReadMetrics.RemoteBytesRead + ev.TaskMetrics.ShuffleReadMetrics.LocalBytesRead
+		executor.TotalShuffleWrite += ev.TaskMetrics.ShuffleWriteMetrics.ShuffleBytesWritten
+	}
+}
+
+func (rp *replayer) applyResourceProfileAdded(ev *resourceProfileAddedEvent) {
+	profile := sparkapiclient.ResourceProfile{
+		ID:                ev.ResourceProfileID,
+		ExecutorResources: make(map[string]sparkapiclient.ExecutorResourceRequest, len(ev.ExecutorResourceRequests)),
+		TaskResources:     make(map[string]sparkapiclient.TaskResourceRequest, len(ev.TaskResourceRequests)),
+	}
+	for name, request := range ev.ExecutorResourceRequests {
+		profile.ExecutorResources[name] = sparkapiclient.ExecutorResourceRequest{
+			ResourceName:    request.ResourceName,
+			Amount:          request.Amount,
+			DiscoveryScript: request.DiscoveryScript,
+			Vendor:          request.Vendor,
+		}
+	}
+	for name, request := range ev.TaskResourceRequests {
+		profile.TaskResources[name] = sparkapiclient.TaskResourceRequest{
+			ResourceName: request.ResourceName,
+			Amount:       request.Amount,
+		}
+	}
+	rp.resourceProfiles[ev.ResourceProfileID] = profile
+}
+
+func (rp *replayer) applyJobStart(ev *jobStart) {
+	rp.updated(ev.SubmissionTime)
+
+	job := &sparkapiclient.Job{
+		JobID:          ev.JobID,
+		Description:    ev.Properties[jobDescriptionProperty],
+		JobGroup:       ev.Properties[jobGroupProperty],
+		SubmissionTime: formatTime(ev.SubmissionTime),
+		Status:         sparkapiclient.JobStatusRunning,
+		StageIDs:       make([]int, 0, len(ev.StageInfos)),
+	}
+
+	// The job is named after its last stage
+	lastStageID := -1
+	for _, stage := range ev.StageInfos {
+		job.StageIDs = append(job.StageIDs, stage.StageID)
+		job.NumTasks += stage.NumberOfTasks
+		rp.stageJobs[stage.StageID] = ev.JobID
+		if stage.StageID > lastStageID {
+			lastStageID = stage.StageID
+			job.Name = stage.StageName
+		}
+	}
+	sort.Ints(job.StageIDs)
+	rp.jobs[ev.JobID] = job
+
+	if id, err := strconv.ParseInt(ev.Properties[sqlExecutionIDProperty], 10, 64); err == nil {
+		if execution, ok := rp.sqlExecutions[id]; ok {
+			execution.jobIDs = append(execution.jobIDs, ev.JobID)
+		}
+	}
+}
+
+func (rp *replayer) addExecutor(executorID string, timestamp int64, totalCores int64) *sparkapiclient.Executor {
+	executor, ok := rp.executors[executorID]
+	if !ok {
+		executor = &sparkapiclient.Executor{
+			ID: executorID,
+		}
+		rp.executors[executorID] = executor
+	}
+	executor.IsActive = true
+	if executor.AddTime == "" {
+		executor.AddTime = formatTime(timestamp)
+	}
+	if totalCores > 0 {
+		executor.TotalCores = totalCores
+	}
+	return executor
+}
+
+func (rp *replayer) getStage(stageID int, attemptID int) *sparkapiclient.Stage {
+	key := stageKey{id: stageID, attempt: attemptID}
+	stage, ok := rp.stages[key]
+	if !ok {
+		stage = &sparkapiclient.Stage{
+			StageID:   stageID,
+			AttemptID: attemptID,
+		}
+		rp.stages[key] = stage
+	}
+	return stage
+}
+
+func (rp *replayer) updated(timestamp int64) {
+	if timestamp > rp.attempt.LastUpdatedEpoch {
+		rp.attempt.LastUpdatedEpoch = timestamp
+	}
+}
+
+// applicationLog returns the replayed application state
+func (rp *replayer) applicationLog() (*ApplicationLog, error) {
+	if !rp.started {
+		return nil, fmt.Errorf("application start event not found")
+	}
+
+	log := &ApplicationLog{
+		Application: rp.application,
+		Environment: sparkapiclient.Environment{
+			Runtime:          rp.runtime,
+			SparkProperties:  sortedPairs(rp.sparkProperties),
+			HadoopProperties: sortedPairs(rp.hadoopProperties),
+			SystemProperties: sortedPairs(rp.systemProperties),
+			ClasspathEntries: sortedPairs(rp.classpathEntries),
+			ResourceProfiles: make([]sparkapiclient.ResourceProfile, 0, len(rp.resourceProfiles)),
+		},
+		Stages:           make([]sparkapiclient.Stage, 0, len(rp.stages)),
+		Executors:        make([]sparkapiclient.Executor, 0, len(rp.executors)),
+		Jobs:             make([]sparkapiclient.Job, 0, len(rp.jobs)),
+		SQLExecutions:    make([]sparkapiclient.SQLExecution, 0, len(rp.sqlExecutions)),
+		stageTaskMetrics: rp.stageTasks,
+	}
+
+	attempt := rp.attempt
+	if attempt.Completed {
+		attempt.Duration = attempt.EndTimeEpoch - attempt.StartTimeEpoch
+	} else {
+		attempt.EndTimeEpoch = -1
+	}
+	log.Application.Attempts = []sparkapiclient.Attempt{attempt}
+
+	for _, profile := range rp.resourceProfiles {
+		log.Environment.ResourceProfiles = append(log.Environment.ResourceProfiles, profile)
+	}
+	sort.Slice(log.Environment.ResourceProfiles, func(i, j int) bool {
+		return log.Environment.ResourceProfiles[i].ID < log.Environment.ResourceProfiles[j].ID
+	})
+
+	// The Spark API lists stages with the most recent first
+	for _, stage := range rp.stages {
+		log.Stages = append(log.Stages, *stage)
+	}
+	sort.Slice(log.Stages, func(i, j int) bool {
+		if log.Stages[i].StageID != log.Stages[j].StageID {
+			return log.Stages[i].StageID > log.Stages[j].StageID
+		}
+		return log.Stages[i].AttemptID > log.Stages[j].AttemptID
+	})
+
+	taskCpus := int64(1)
+	if v, err := strconv.ParseInt(rp.sparkProperties[sparkTaskCpusProperty], 10, 64); err == nil && v > 0 {
+		taskCpus = v
+	}
+
+	for _, executor := range rp.executors {
+		e := *executor
+		e.MaxTasks = e.TotalCores / taskCpus
+		// An application that is no longer running has no active executors
+		if attempt.Completed {
+			e.IsActive = false
+			e.ActiveTasks = 0
+		}
+		log.Executors = append(log.Executors, e)
+	}
+	sort.Slice(log.Executors, func(i, j int) bool {
+		return executorLess(log.Executors[i].ID, log.Executors[j].ID)
+	})
+
+	// The Spark API lists jobs with the most recent first
+	for _, job := range rp.jobs {
+		log.Jobs = append(log.Jobs, *job)
+	}
+	sort.Slice(log.Jobs, func(i, j int) bool {
+		return log.Jobs[i].JobID > log.Jobs[j].JobID
+	})
+
+	for _, execution := range rp.sqlExecutions {
+		log.SQLExecutions = append(log.SQLExecutions, rp.sqlExecution(execution))
+	}
+	sort.Slice(log.SQLExecutions, func(i, j int) bool {
+		return log.SQLExecutions[i].ID < log.SQLExecutions[j].ID
+	})
+
+	return log, nil
+}
+
+// sqlExecution returns the Spark API representation of a SQL execution, its status is derived from its jobs
+func (rp *replayer) sqlExecution(execution *sqlExecution) sparkapiclient.SQLExecution {
+	e := execution.execution
+	e.RunningJobIDs = make([]int64, 0)
+	e.SuccessJobIDs = make([]int64, 0)
+	e.FailedJobIDs = make([]int64, 0)
+
+	for _, jobID := range execution.jobIDs {
+		job, ok := rp.jobs[jobID]
+		if !ok {
+			continue
+		}
+		switch job.Status {
+		case sparkapiclient.JobStatusSucceeded:
+			e.SuccessJobIDs = append(e.SuccessJobIDs, jobID)
+		case sparkapiclient.JobStatusFailed:
+			e.FailedJobIDs = append(e.FailedJobIDs, jobID)
+		default:
+			e.RunningJobIDs = append(e.RunningJobIDs, jobID)
+		}
+	}
+
+	switch {
+	case execution.endTime == 0:
+		e.Status = sparkapiclient.SQLExecutionStatusRunning
+		e.Duration = rp.attempt.LastUpdatedEpoch - execution.startTime
+	case len(e.FailedJobIDs) > 0:
+		e.Status = sparkapiclient.SQLExecutionStatusFailed
+		e.Duration = execution.endTime - execution.startTime
+	default:
+		e.Status = sparkapiclient.SQLExecutionStatusCompleted
+		e.Duration = execution.endTime - execution.startTime
+	}
+
+	return e
+}
+
+func copyProperties(dst map[string]string, src map[string]string) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// sortedPairs returns the properties as key value pairs sorted by key, the way the Spark API lists them
+func sortedPairs(properties map[string]string) [][]string {
+	pairs := make([][]string, 0, len(properties))
+	for k, v := range properties {
+		pairs = append(pairs, []string{k, v})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0]
+	})
+	return pairs
+}
+
+// executorLess orders the driver first, followed by the executors in numeric order
+func executorLess(a, b string) bool {
+	if a == driverExecutorID || b == driverExecutorID {
+		return a == driverExecutorID && b != driverExecutorID
+	}
+	ai, aErr := strconv.Atoi(a)
+	bi, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		return ai < bi
+	}
+	return a < b
+}
+
+func formatTime(timestamp int64) string {
+	return time.Unix(0, timestamp*int64(time.Millisecond)).UTC().Format(sparkapiclient.TimeFormat)
+}
@@ -0,0 +1,110 @@
+package eventlog
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spotinst/wave-operator/cloudstorage"
+)
+
+// Store is a read only view of an event log directory
+type Store interface {
+	// List returns the paths of the files whose path starts with the given prefix.
+	// Paths are relative to the event log directory and separated by slashes,
+	// files in subdirectories are included.
+	List(prefix string) ([]string, error)
+	// Open opens the file at the given path, relative to the event log directory
+	Open(path string) (io.ReadCloser, error)
+}
+
+type localStore struct {
+	dir string
+}
+
+// NewLocalStore returns a store reading the event log directory at the given local path
+func NewLocalStore(dir string) Store {
+	return &localStore{
+		dir: dir,
+	}
+}
+
+func (s *localStore) List(prefix string) ([]string, error) {
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read event log directory, %w", err)
+	}
+
+	paths := make([]string, 0)
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+
+		if !entry.IsDir() {
+			paths = append(paths, entry.Name())
+			continue
+		}
+
+		// Rolling event logs are written to a directory per application
+		subEntries, err := ioutil.ReadDir(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read event log directory, %w", err)
+		}
+		for _, subEntry := range subEntries {
+			if !subEntry.IsDir() {
+				paths = append(paths, path.Join(entry.Name(), subEntry.Name()))
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+func (s *localStore) Open(p string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, filepath.FromSlash(p)))
+}
+
+type objectStore struct {
+	objects cloudstorage.ObjectStore
+	bucket  string
+	prefix  string
+}
+
+// NewObjectStore returns a store reading the event log directory at the given prefix of a cloud storage bucket
+func NewObjectStore(objects cloudstorage.ObjectStore, bucket string, prefix string) Store {
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &objectStore{
+		objects: objects,
+		bucket:  bucket,
+		prefix:  prefix,
+	}
+}
+
+func (s *objectStore) List(prefix string) ([]string, error) {
+	keys, err := s.objects.ListObjects(s.bucket, s.prefix+prefix)
+	if err != nil {
+		return nil, fmt.Errorf("could not list objects, %w", err)
+	}
+
+	paths := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		paths = append(paths, strings.TrimPrefix(key, s.prefix))
+	}
+
+	return paths, nil
+}
+
+func (s *objectStore) Open(p string) (io.ReadCloser, error) {
+	return s.objects.GetObject(s.bucket, s.prefix+p)
+}
@@ -0,0 +1,190 @@
+package sparkapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/catalog"
+	"github.com/spotinst/wave-operator/internal/config"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+const (
+	sparkEventLogEnabledProperty = "spark.eventLog.enabled"
+	sparkEventLogDirProperty     = "spark.eventLog.dir"
+)
+
+// DefaultHistoryServers returns the history server installed by wave,
+// which serves the applications with event log sync enabled
+func DefaultHistoryServers() []config.HistoryServer {
+	return []config.HistoryServer{
+		{
+			Name:         "wave",
+			EventLogDirs: []string{config.SyncedEventLogDir},
+		},
+	}
+}
+
+// historyServerApplication holds the application attributes used to select a history server
+type historyServerApplication struct {
+	namespace    string
+	eventLogDir  string
+	sparkVersion string
+}
+
+// newHistoryServerApplication returns the history server selection attributes of the application,
+// returns false if the application does not write event logs
+func newHistoryServerApplication(driverPod *corev1.Pod, app *v1alpha1.SparkApplication) (historyServerApplication, bool) {
+	hsApp := historyServerApplication{
+		namespace: driverPod.Namespace,
+	}
+
+	if app != nil {
+		for _, attempt := range app.Status.Data.RunStatistics.Attempts {
+			if attempt.AppSparkVersion != "" {
+				hsApp.sparkVersion = attempt.AppSparkVersion
+				break
+			}
+		}
+	}
+
+	// The event log directory of applications with event log sync enabled is set by the admission controller
+	if config.IsEventLogSyncEnabled(driverPod.Annotations) {
+		hsApp.eventLogDir = config.SyncedEventLogDir
+		return hsApp, true
+	}
+
+	if app == nil {
+		return hsApp, false
+	}
+
+	enabled, err := strconv.ParseBool(app.Status.Data.SparkProperties[sparkEventLogEnabledProperty])
+	if err != nil || !enabled {
+		return hsApp, false
+	}
+
+	hsApp.eventLogDir = app.Status.Data.SparkProperties[sparkEventLogDirProperty]
+	if hsApp.eventLogDir == "" {
+		return hsApp, false
+	}
+
+	return hsApp, true
+}
+
+// selectHistoryServer returns the first history server that serves the given application
+func selectHistoryServer(historyServers []config.HistoryServer, app historyServerApplication) (config.HistoryServer, bool) {
+	for _, hs := range historyServers {
+		if historyServerMatches(hs, app) {
+			return hs, true
+		}
+	}
+	return config.HistoryServer{}, false
+}
+
+func historyServerMatches(hs config.HistoryServer, app historyServerApplication) bool {
+	if len(hs.EventLogDirs) > 0 && !matchesAnyPrefix(app.eventLogDir, hs.EventLogDirs, "/") {
+		return false
+	}
+
+	if len(hs.ApplicationNamespaces) > 0 && !containsString(hs.ApplicationNamespaces, app.namespace) {
+		return false
+	}
+
+	if len(hs.SparkVersions) > 0 && !matchesAnyPrefix(app.sparkVersion, hs.SparkVersions, ".") {
+		return false
+	}
+
+	return true
+}
+
+// matchesAnyPrefix determines if s equals any of the prefixes, or starts with any of the prefixes
+// followed by the separator, e.g. s3a://bucket/logs/team-a matches s3a://bucket/logs and 3.1.2 matches 3
+func matchesAnyPrefix(s string, prefixes []string, separator string) bool {
+	s = strings.TrimRight(strings.TrimSpace(s), separator)
+	if s == "" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		p := strings.TrimRight(strings.TrimSpace(prefix), separator)
+		if p != "" && (s == p || strings.HasPrefix(s, p+separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// getHistoryServerClient returns a Spark API client for the given history server
+func getHistoryServerClient(clientSet kubernetes.Interface, hs config.HistoryServer, transportOpts TransportOptions, logger logr.Logger) (sparkapiclient.Client, error) {
+	if hs.URL != "" {
+		return sparkapiclient.NewExternalHistoryServerClient(hs.URL)
+	}
+
+	namespace := hs.Namespace
+	if namespace == "" {
+		namespace = catalog.SystemNamespace
+	}
+
+	selector := hs.Selector
+	if selector == "" {
+		selector = fmt.Sprintf("%s=%s", appNameLabel, historyServerAppNameLabelValue)
+	}
+
+	historyServerService, err := getHistoryServerService(clientSet, namespace, selector, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not get history server service, %w", err)
+	}
+
+	transportConfig, err := getTransportConfig(historyServerService, transportOpts)
+	if err != nil {
+		return nil, fmt.Errorf("could not get history server transport configuration, %w", err)
+	}
+
+	return sparkapiclient.NewHistoryServerClient(historyServerService, clientSet, transportConfig), nil
+}
+
+// getHistoryServerService returns the history server service matching the selector,
+// the first service by name if several services match
+func getHistoryServerService(clientSet kubernetes.Interface, namespace string, selector string, logger logr.Logger) (*corev1.Service, error) {
+	ctx := context.TODO()
+	foundServices, err := clientSet.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list services, %w", err)
+	}
+
+	if len(foundServices.Items) == 0 {
+		return nil, fmt.Errorf("could not find history server service")
+	}
+
+	services := foundServices.Items
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+
+	if len(services) > 1 {
+		logger.Info(fmt.Sprintf("Found %d history server services matching selector %q, using %s, configure a more specific selector",
+			len(services), selector, services[0].Name))
+	}
+
+	service := services[0]
+	return &service, nil
+}
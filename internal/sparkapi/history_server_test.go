@@ -0,0 +1,266 @@
+package sparkapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+func TestNewHistoryServerApplication(t *testing.T) {
+
+	t.Run("whenEventLogSyncEnabled", func(tt *testing.T) {
+		pod := newRunningDriverPod(true)
+		app := newTestSparkApplication("3.0.1", map[string]string{
+			sparkEventLogEnabledProperty: "true",
+			sparkEventLogDirProperty:     "s3a://some-bucket",
+		})
+
+		hsApp, ok := newHistoryServerApplication(pod, app)
+		assert.True(tt, ok)
+		assert.Equal(tt, config.SyncedEventLogDir, hsApp.eventLogDir)
+		assert.Equal(tt, "3.0.1", hsApp.sparkVersion)
+		assert.Equal(tt, pod.Namespace, hsApp.namespace)
+	})
+
+	t.Run("whenEventLogSyncEnabled_noApplication", func(tt *testing.T) {
+		hsApp, ok := newHistoryServerApplication(newRunningDriverPod(true), nil)
+		assert.True(tt, ok)
+		assert.Equal(tt, config.SyncedEventLogDir, hsApp.eventLogDir)
+	})
+
+	t.Run("whenEventLogEnabled", func(tt *testing.T) {
+		app := newTestSparkApplication("2.4.7", map[string]string{
+			sparkEventLogEnabledProperty: "true",
+			sparkEventLogDirProperty:     "s3a://team-a/logs",
+		})
+
+		hsApp, ok := newHistoryServerApplication(newRunningDriverPod(false), app)
+		assert.True(tt, ok)
+		assert.Equal(tt, "s3a://team-a/logs", hsApp.eventLogDir)
+		assert.Equal(tt, "2.4.7", hsApp.sparkVersion)
+	})
+
+	t.Run("whenEventLogDisabled", func(tt *testing.T) {
+		app := newTestSparkApplication("2.4.7", map[string]string{
+			sparkEventLogEnabledProperty: "false",
+			sparkEventLogDirProperty:     "s3a://team-a/logs",
+		})
+
+		_, ok := newHistoryServerApplication(newRunningDriverPod(false), app)
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenEventLogDirMissing", func(tt *testing.T) {
+		app := newTestSparkApplication("2.4.7", map[string]string{
+			sparkEventLogEnabledProperty: "true",
+		})
+
+		_, ok := newHistoryServerApplication(newRunningDriverPod(false), app)
+		assert.False(tt, ok)
+	})
+}
+
+func TestSelectHistoryServer(t *testing.T) {
+
+	historyServers := []config.HistoryServer{
+		{
+			Name:          "team-a-spark2",
+			EventLogDirs:  []string{"s3a://team-a/logs/"},
+			SparkVersions: []string{"2"},
+		},
+		{
+			Name:         "team-a",
+			EventLogDirs: []string{"s3a://team-a/logs"},
+		},
+		{
+			Name:                  "team-b",
+			ApplicationNamespaces: []string{"team-b"},
+			EventLogDirs:          []string{"s3a://shared/logs", "hdfs://namenode/logs"},
+		},
+		DefaultHistoryServers()[0],
+	}
+
+	testCases := []struct {
+		name     string
+		app      historyServerApplication
+		expected string
+	}{
+		{
+			name:     "synced",
+			app:      historyServerApplication{namespace: "spark-jobs", eventLogDir: config.SyncedEventLogDir, sparkVersion: "3.0.0"},
+			expected: "wave",
+		},
+		{
+			name:     "sparkVersionMatches",
+			app:      historyServerApplication{namespace: "team-a", eventLogDir: "s3a://team-a/logs", sparkVersion: "2.4.7"},
+			expected: "team-a-spark2",
+		},
+		{
+			name:     "sparkVersionDoesNotMatch",
+			app:      historyServerApplication{namespace: "team-a", eventLogDir: "s3a://team-a/logs/", sparkVersion: "3.1.1"},
+			expected: "team-a",
+		},
+		{
+			name:     "sparkVersionPrefixIsNotMajorVersion",
+			app:      historyServerApplication{namespace: "team-a", eventLogDir: "s3a://team-a/logs", sparkVersion: "20.0.0"},
+			expected: "team-a",
+		},
+		{
+			name:     "eventLogSubdirectory",
+			app:      historyServerApplication{namespace: "team-a", eventLogDir: "s3a://team-a/logs/nightly"},
+			expected: "team-a",
+		},
+		{
+			name:     "namespaceMatches",
+			app:      historyServerApplication{namespace: "team-b", eventLogDir: "hdfs://namenode/logs"},
+			expected: "team-b",
+		},
+		{
+			name: "namespaceDoesNotMatch",
+			app:  historyServerApplication{namespace: "team-c", eventLogDir: "hdfs://namenode/logs"},
+		},
+		{
+			name: "eventLogDirIsNotADirectoryPrefix",
+			app:  historyServerApplication{namespace: "team-a", eventLogDir: "s3a://team-a/logs-old"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			hs, ok := selectHistoryServer(historyServers, tc.app)
+			if tc.expected == "" {
+				assert.False(tt, ok)
+			} else {
+				assert.True(tt, ok)
+				assert.Equal(tt, tc.expected, hs.Name)
+			}
+		})
+	}
+}
+
+func TestGetSparkApiClient_historyServers(t *testing.T) {
+
+	logger := getTestLogger()
+
+	opts := DefaultOptions()
+	opts.HistoryServers = []config.HistoryServer{
+		{
+			Name:                  "team-a",
+			Namespace:             "team-a",
+			Selector:              "team=a",
+			ApplicationNamespaces: []string{"team-a"},
+		},
+		{
+			Name:         "external",
+			URL:          "https://spark-history.example.com",
+			EventLogDirs: []string{"s3a://external"},
+		},
+	}
+
+	app := newTestSparkApplication("3.0.0", map[string]string{
+		sparkEventLogEnabledProperty: "true",
+		sparkEventLogDirProperty:     "s3a://external/logs",
+	})
+
+	t.Run("whenServiceSelected", func(tt *testing.T) {
+		pod := newRunningDriverPod(false)
+		pod.Namespace = "team-a"
+		pod.Status.Phase = corev1.PodSucceeded
+
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "history",
+				Namespace: "team-a",
+				Labels:    map[string]string{"team": "a"},
+			},
+		}
+
+		c, err := getSparkApiClient(k8sfake.NewSimpleClientset(pod, svc), pod, app, opts, nil, logger)
+		assert.NoError(tt, err)
+		assert.Implements(tt, (*sparkapiclient.Client)(nil), c)
+	})
+
+	t.Run("whenSelectedServiceMissing", func(tt *testing.T) {
+		pod := newRunningDriverPod(false)
+		pod.Namespace = "team-a"
+		pod.Status.Phase = corev1.PodSucceeded
+
+		c, err := getSparkApiClient(k8sfake.NewSimpleClientset(pod, newHistoryServerService()), pod, app, opts, nil, logger)
+		assert.Error(tt, err)
+		assert.Nil(tt, c)
+	})
+
+	t.Run("whenExternalSelected", func(tt *testing.T) {
+		pod := newRunningDriverPod(false)
+		pod.Status.Phase = corev1.PodSucceeded
+
+		c, err := getSparkApiClient(k8sfake.NewSimpleClientset(pod), pod, app, opts, nil, logger)
+		assert.NoError(tt, err)
+		assert.Implements(tt, (*sparkapiclient.Client)(nil), c)
+	})
+
+	t.Run("whenNoneSelected", func(tt *testing.T) {
+		pod := newRunningDriverPod(true)
+		pod.Status.Phase = corev1.PodSucceeded
+
+		c, err := getSparkApiClient(k8sfake.NewSimpleClientset(pod, newHistoryServerService()), pod, nil, opts, nil, logger)
+		assert.Error(tt, err)
+		assert.Nil(tt, c)
+		assert.True(tt, IsApiNotAvailableError(err))
+	})
+}
+
+func newTestSparkApplication(sparkVersion string, sparkProperties map[string]string) *v1alpha1.SparkApplication {
+	app := &v1alpha1.SparkApplication{}
+	app.Status.Data.SparkProperties = sparkProperties
+	app.Status.Data.RunStatistics.Attempts = []v1alpha1.Attempt{
+		{
+			AppSparkVersion: sparkVersion,
+		},
+	}
+	return app
+}
+
+func TestGetHistoryServerService(t *testing.T) {
+
+	logger := getTestLogger()
+
+	newService := func(name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "spark",
+				Labels:    map[string]string{"team": "a"},
+			},
+		}
+	}
+
+	t.Run("whenOneService", func(tt *testing.T) {
+		svc, err := getHistoryServerService(k8sfake.NewSimpleClientset(newService("history")), "spark", "team=a", logger)
+		require.NoError(tt, err)
+		assert.Equal(tt, "history", svc.Name)
+	})
+
+	t.Run("whenSeveralServices", func(tt *testing.T) {
+		clientSet := k8sfake.NewSimpleClientset(newService("history-web"), newService("history"), newService("history-headless"))
+		for i := 0; i < 3; i++ {
+			svc, err := getHistoryServerService(clientSet, "spark", "team=a", logger)
+			require.NoError(tt, err)
+			assert.Equal(tt, "history", svc.Name)
+		}
+	})
+
+	t.Run("whenNoService", func(tt *testing.T) {
+		svc, err := getHistoryServerService(k8sfake.NewSimpleClientset(newService("history")), "spark", "team=b", logger)
+		assert.Error(tt, err)
+		assert.Nil(tt, svc)
+	})
+}
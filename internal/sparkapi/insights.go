@@ -0,0 +1,193 @@
+package sparkapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+const (
+	medianQuantile = 0.5
+	maxQuantile    = 1.0
+
+	// skewRatioThreshold is the ratio of the largest task to the median task above which a stage is skewed
+	skewRatioThreshold = 5.0
+	// minSkewedTaskRunTime is the run time of the slowest task below which skew is not reported (milliseconds)
+	minSkewedTaskRunTime = 30 * 1000
+	// minSkewedTaskBytes is the input of the largest task below which skew is not reported
+	minSkewedTaskBytes = 128 * 1024 * 1024
+	// minSkewStageTasks is the number of successful tasks a stage needs for skew to be detected
+	minSkewStageTasks = 4
+	// maxTaskSummaryStages is the number of stages, slowest first, whose task summaries are inspected for skew
+	maxTaskSummaryStages = 20
+
+	// minSpillBytes is the amount of data spilled to disk by a stage above which spill is reported
+	minSpillBytes = 1024 * 1024 * 1024
+
+	// gcTimeRatioThreshold is the share of task run time spent in garbage collection above which GC is reported
+	gcTimeRatioThreshold = 0.1
+	// minGCStageRunTime is the total task run time of a stage below which GC is not reported (milliseconds)
+	minGCStageRunTime = 60 * 1000
+)
+
+var taskSummaryQuantiles = []float64{medianQuantile, maxQuantile}
+
+// getInsights detects data skew, excessive spill and heavy garbage collection in the completed stages
+func (m manager) getInsights(applicationID string, stages []sparkapiclient.Stage) []v1alpha1.Insight {
+	insights := make([]v1alpha1.Insight, 0)
+	candidates := make([]sparkapiclient.Stage, 0)
+
+	for _, stage := range stages {
+		if stage.Status != sparkapiclient.StageStatusComplete {
+			continue
+		}
+		if insight, ok := getSpillInsight(stage); ok {
+			insights = append(insights, insight)
+		}
+		if insight, ok := getGCInsight(stage); ok {
+			insights = append(insights, insight)
+		}
+		if stage.NumCompleteTasks >= minSkewStageTasks {
+			candidates = append(candidates, stage)
+		}
+	}
+
+	// Task summaries are requested one stage at a time, only inspect the slowest stages
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ExecutorRunTime > candidates[j].ExecutorRunTime
+	})
+	if len(candidates) > maxTaskSummaryStages {
+		candidates = candidates[:maxTaskSummaryStages]
+	}
+
+	for _, stage := range candidates {
+		summary, err := m.client.GetStageTaskSummary(applicationID, stage.StageID, stage.AttemptID, taskSummaryQuantiles)
+		if err != nil {
+			if !IsNotFoundError(err) {
+				m.logger.Error(err, "Unable to get stage task summary", "stageId", stage.StageID, "attemptId", stage.AttemptID)
+			}
+			continue
+		}
+		if insight, ok := getSkewInsight(stage, summary); ok {
+			insights = append(insights, insight)
+		}
+	}
+
+	sort.SliceStable(insights, func(i, j int) bool {
+		if insights[i].StageID != insights[j].StageID {
+			return insights[i].StageID < insights[j].StageID
+		}
+		return insights[i].StageAttemptID < insights[j].StageAttemptID
+	})
+
+	return insights
+}
+
+func getSkewInsight(stage sparkapiclient.Stage, summary *sparkapiclient.TaskSummary) (v1alpha1.Insight, bool) {
+	if medianRunTime, maxRunTime, ok := getMedianAndMax(summary, summary.ExecutorRunTime); ok &&
+		isSkewed(medianRunTime, maxRunTime, minSkewedTaskRunTime) {
+		return newInsight(v1alpha1.InsightTypeDataSkew, stage,
+			fmt.Sprintf("the slowest task ran for %s, %s the median task (%s)",
+				formatDuration(maxRunTime), formatRatio(medianRunTime, maxRunTime), formatDuration(medianRunTime))), true
+	}
+
+	for _, input := range []struct {
+		name   string
+		values []float64
+	}{
+		{name: "input", values: summary.InputMetrics.BytesRead},
+		{name: "shuffle", values: summary.ShuffleReadMetrics.ReadBytes},
+	} {
+		if medianBytes, maxBytes, ok := getMedianAndMax(summary, input.values); ok &&
+			isSkewed(medianBytes, maxBytes, minSkewedTaskBytes) {
+			return newInsight(v1alpha1.InsightTypeDataSkew, stage,
+				fmt.Sprintf("the largest task read %s of %s data, %s the median task (%s)",
+					formatBytes(maxBytes), input.name, formatRatio(medianBytes, maxBytes), formatBytes(medianBytes))), true
+		}
+	}
+
+	return v1alpha1.Insight{}, false
+}
+
+func getSpillInsight(stage sparkapiclient.Stage) (v1alpha1.Insight, bool) {
+	if stage.DiskBytesSpilled < minSpillBytes {
+		return v1alpha1.Insight{}, false
+	}
+	return newInsight(v1alpha1.InsightTypeSpill, stage,
+		fmt.Sprintf("tasks spilled %s to disk (%s in memory), consider more executor memory or more partitions",
+			formatBytes(float64(stage.DiskBytesSpilled)), formatBytes(float64(stage.MemoryBytesSpilled)))), true
+}
+
+func getGCInsight(stage sparkapiclient.Stage) (v1alpha1.Insight, bool) {
+	if stage.ExecutorRunTime < minGCStageRunTime {
+		return v1alpha1.Insight{}, false
+	}
+	ratio := float64(stage.JvmGcTime) / float64(stage.ExecutorRunTime)
+	if ratio < gcTimeRatioThreshold {
+		return v1alpha1.Insight{}, false
+	}
+	return newInsight(v1alpha1.InsightTypeHeavyGC, stage,
+		fmt.Sprintf("tasks spent %.0f%% of their run time in garbage collection (%s of %s)",
+			ratio*100, formatDuration(float64(stage.JvmGcTime)), formatDuration(float64(stage.ExecutorRunTime)))), true
+}
+
+func newInsight(insightType v1alpha1.InsightType, stage sparkapiclient.Stage, explanation string) v1alpha1.Insight {
+	return v1alpha1.Insight{
+		Type:           insightType,
+		StageID:        int64(stage.StageID),
+		StageAttemptID: int64(stage.AttemptID),
+		StageName:      stage.Name,
+		Message:        fmt.Sprintf("Stage %d: %s", stage.StageID, explanation),
+	}
+}
+
+func getMedianAndMax(summary *sparkapiclient.TaskSummary, metric []float64) (float64, float64, bool) {
+	median, ok := summary.Quantile(metric, medianQuantile)
+	if !ok {
+		return 0, 0, false
+	}
+	maxValue, ok := summary.Quantile(metric, maxQuantile)
+	if !ok {
+		return 0, 0, false
+	}
+	return median, maxValue, true
+}
+
+func isSkewed(median float64, maxValue float64, minMaxValue float64) bool {
+	if maxValue < minMaxValue {
+		return false
+	}
+	return median <= 0 || maxValue/median >= skewRatioThreshold
+}
+
+func formatRatio(median float64, maxValue float64) string {
+	if median <= 0 {
+		return "far more than"
+	}
+	return fmt.Sprintf("%.1fx", maxValue/median)
+}
+
+func formatDuration(milliseconds float64) string {
+	d := time.Duration(milliseconds) * time.Millisecond
+	if d >= time.Second {
+		d = d.Round(time.Second)
+	}
+	return d.String()
+}
+
+func formatBytes(bytes float64) string {
+	const unit = 1024
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for bytes >= unit && i < len(units)-1 {
+		bytes /= unit
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", bytes, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[i])
+}
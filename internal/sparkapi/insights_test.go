@@ -0,0 +1,133 @@
+package sparkapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+func TestGetSkewInsight(t *testing.T) {
+
+	stage := sparkapiclient.Stage{
+		Status:           sparkapiclient.StageStatusComplete,
+		StageID:          3,
+		AttemptID:        1,
+		Name:             "join at Job.scala:42",
+		NumCompleteTasks: 200,
+	}
+
+	t.Run("whenRunTimeSkewed", func(tt *testing.T) {
+		summary := &sparkapiclient.TaskSummary{
+			Quantiles:       []float64{0.5, 1.0},
+			ExecutorRunTime: []float64{90000, 720000},
+		}
+
+		insight, ok := getSkewInsight(stage, summary)
+		assert.True(tt, ok)
+		assert.Equal(tt, v1alpha1.Insight{
+			Type:           v1alpha1.InsightTypeDataSkew,
+			StageID:        3,
+			StageAttemptID: 1,
+			StageName:      "join at Job.scala:42",
+			Message:        "Stage 3: the slowest task ran for 12m0s, 8.0x the median task (1m30s)",
+		}, insight)
+	})
+
+	t.Run("whenInputSkewed", func(tt *testing.T) {
+		summary := &sparkapiclient.TaskSummary{
+			Quantiles:       []float64{0.5, 1.0},
+			ExecutorRunTime: []float64{1000, 2000},
+		}
+		summary.ShuffleReadMetrics.ReadBytes = []float64{0, 2 * 1024 * 1024 * 1024}
+
+		insight, ok := getSkewInsight(stage, summary)
+		assert.True(tt, ok)
+		assert.Equal(tt, "Stage 3: the largest task read 2.0 GiB of shuffle data, far more than the median task (0 B)", insight.Message)
+	})
+
+	t.Run("whenSlowestTaskIsShort", func(tt *testing.T) {
+		summary := &sparkapiclient.TaskSummary{
+			Quantiles:       []float64{0.5, 1.0},
+			ExecutorRunTime: []float64{100, 10000},
+		}
+
+		_, ok := getSkewInsight(stage, summary)
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenNotSkewed", func(tt *testing.T) {
+		summary := &sparkapiclient.TaskSummary{
+			Quantiles:       []float64{0.5, 1.0},
+			ExecutorRunTime: []float64{60000, 120000},
+		}
+		summary.InputMetrics.BytesRead = []float64{512 * 1024 * 1024, 1024 * 1024 * 1024}
+
+		_, ok := getSkewInsight(stage, summary)
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenQuantilesMissing", func(tt *testing.T) {
+		summary := &sparkapiclient.TaskSummary{
+			Quantiles:       []float64{0.25, 0.75},
+			ExecutorRunTime: []float64{1000, 720000},
+		}
+
+		_, ok := getSkewInsight(stage, summary)
+		assert.False(tt, ok)
+	})
+}
+
+func TestGetSpillInsight(t *testing.T) {
+
+	t.Run("whenSpilled", func(tt *testing.T) {
+		insight, ok := getSpillInsight(sparkapiclient.Stage{
+			StageID:            5,
+			DiskBytesSpilled:   3 * 1024 * 1024 * 1024,
+			MemoryBytesSpilled: 12 * 1024 * 1024 * 1024,
+		})
+		assert.True(tt, ok)
+		assert.Equal(tt, v1alpha1.InsightTypeSpill, insight.Type)
+		assert.Equal(tt, "Stage 5: tasks spilled 3.0 GiB to disk (12.0 GiB in memory), consider more executor memory or more partitions", insight.Message)
+	})
+
+	t.Run("whenLittleSpill", func(tt *testing.T) {
+		_, ok := getSpillInsight(sparkapiclient.Stage{
+			DiskBytesSpilled:   100 * 1024 * 1024,
+			MemoryBytesSpilled: 400 * 1024 * 1024,
+		})
+		assert.False(tt, ok)
+	})
+}
+
+func TestGetGCInsight(t *testing.T) {
+
+	t.Run("whenHeavyGC", func(tt *testing.T) {
+		insight, ok := getGCInsight(sparkapiclient.Stage{
+			StageID:         7,
+			ExecutorRunTime: 600000,
+			JvmGcTime:       150000,
+		})
+		assert.True(tt, ok)
+		assert.Equal(tt, v1alpha1.InsightTypeHeavyGC, insight.Type)
+		assert.Equal(tt, "Stage 7: tasks spent 25% of their run time in garbage collection (2m30s of 10m0s)", insight.Message)
+	})
+
+	t.Run("whenLightGC", func(tt *testing.T) {
+		_, ok := getGCInsight(sparkapiclient.Stage{
+			ExecutorRunTime: 600000,
+			JvmGcTime:       6000,
+		})
+		assert.False(tt, ok)
+	})
+
+	t.Run("whenShortStage", func(tt *testing.T) {
+		_, ok := getGCInsight(sparkapiclient.Stage{
+			ExecutorRunTime: 10000,
+			JvmGcTime:       5000,
+		})
+		assert.False(tt, ok)
+	})
+}
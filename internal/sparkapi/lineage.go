@@ -0,0 +1,146 @@
+package sparkapi
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+// maxLineageDatasets is the number of input and output datasets kept in the SparkApplication resource
+const maxLineageDatasets = 50
+
+var (
+	// fileIndexRegex matches the locations of file scans in a physical plan, e.g.
+	// Location: InMemoryFileIndex[s3a://bucket/path] or InMemoryFileIndex(2 paths)[s3a://bucket/a, s3a://bucket/b]
+	fileIndexRegex = regexp.MustCompile(`(?:InMemoryFileIndex|CatalogFileIndex|PrunedInMemoryFileIndex|MetadataLogFileIndex)(?:\(\d+ paths?\))?\s*\[([^\]]*)\]`)
+
+	// insertCommandRegex matches file writes in a physical plan, the output path either follows the
+	// command or, in formatted plans, is the first of the node's arguments
+	insertCommandRegex = regexp.MustCompile(`InsertIntoHadoopFsRelationCommand(?:\s+([^\s,(]+))?`)
+	argumentsRegex     = regexp.MustCompile(`^\s*Arguments:\s*([^\s,]+)`)
+)
+
+// newLineage returns the file datasets read and written by the SQL executions, derived from their physical plans.
+// Tables are not resolved, only datasets accessed by path are found.
+func newLineage(executions []sparkapiclient.SQLExecution) *v1alpha1.Lineage {
+	inputs := make(map[v1alpha1.Dataset]bool)
+	outputs := make(map[v1alpha1.Dataset]bool)
+
+	for _, execution := range executions {
+		in, out := getPlanPaths(execution.PlanDescription)
+		for _, path := range in {
+			if dataset, ok := newDataset(path); ok {
+				inputs[dataset] = true
+			}
+		}
+		for _, path := range out {
+			if dataset, ok := newDataset(path); ok {
+				outputs[dataset] = true
+			}
+		}
+	}
+
+	if len(inputs) == 0 && len(outputs) == 0 {
+		return nil
+	}
+
+	return &v1alpha1.Lineage{
+		Inputs:  sortDatasets(inputs),
+		Outputs: sortDatasets(outputs),
+	}
+}
+
+// getPlanPaths returns the paths read and written in the physical plan description
+func getPlanPaths(plan string) ([]string, []string) {
+	var inputs, outputs []string
+
+	for _, match := range fileIndexRegex.FindAllStringSubmatch(plan, -1) {
+		for _, path := range strings.Split(match[1], ",") {
+			path = strings.TrimSpace(path)
+			if path != "" && path != "..." {
+				inputs = append(inputs, path)
+			}
+		}
+	}
+
+	awaitingArguments := false
+	for _, line := range strings.Split(plan, "\n") {
+		if match := insertCommandRegex.FindStringSubmatch(line); match != nil {
+			if isPath(match[1]) {
+				outputs = append(outputs, match[1])
+				awaitingArguments = false
+			} else {
+				awaitingArguments = true
+			}
+			continue
+		}
+		if awaitingArguments {
+			if match := argumentsRegex.FindStringSubmatch(line); match != nil {
+				if isPath(match[1]) {
+					outputs = append(outputs, match[1])
+				}
+				awaitingArguments = false
+			}
+		}
+	}
+
+	return inputs, outputs
+}
+
+func isPath(value string) bool {
+	return strings.HasPrefix(value, "/") || strings.Contains(value, ":/")
+}
+
+// newDataset returns the OpenLineage dataset of the path,
+// see https://openlineage.io/docs/spec/naming
+func newDataset(path string) (v1alpha1.Dataset, bool) {
+	if strings.HasPrefix(path, "/") {
+		return v1alpha1.Dataset{Namespace: "file", Name: path}, true
+	}
+
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme == "" {
+		return v1alpha1.Dataset{}, false
+	}
+
+	// Azure storage paths carry the container as the user, e.g. abfss://container@account.dfs.core.windows.net/path
+	authority := u.Host
+	if u.User != nil {
+		authority = u.User.String() + "@" + u.Host
+	}
+
+	switch scheme := strings.ToLower(u.Scheme); scheme {
+	case "file":
+		return v1alpha1.Dataset{Namespace: "file", Name: u.Path}, true
+	case "s3", "s3a", "s3n":
+		return v1alpha1.Dataset{Namespace: "s3://" + authority, Name: strings.TrimPrefix(u.Path, "/")}, true
+	case "gs", "abfs", "abfss", "wasb", "wasbs":
+		return v1alpha1.Dataset{Namespace: scheme + "://" + authority, Name: strings.TrimPrefix(u.Path, "/")}, true
+	default:
+		if u.Host == "" {
+			return v1alpha1.Dataset{}, false
+		}
+		return v1alpha1.Dataset{Namespace: scheme + "://" + authority, Name: u.Path}, true
+	}
+}
+
+func sortDatasets(datasets map[v1alpha1.Dataset]bool) []v1alpha1.Dataset {
+	sorted := make([]v1alpha1.Dataset, 0, len(datasets))
+	for dataset := range datasets {
+		sorted = append(sorted, dataset)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	if len(sorted) > maxLineageDatasets {
+		sorted = sorted[:maxLineageDatasets]
+	}
+	return sorted
+}
@@ -0,0 +1,117 @@
+package sparkapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+)
+
+const testSimplePlan = `== Physical Plan ==
+Execute InsertIntoHadoopFsRelationCommand s3a://output-bucket/reports/daily, false, Parquet, Map(path -> s3a://output-bucket/reports/daily), Overwrite
++- *(1) Project [id#0L, name#1]
+   +- *(1) FileScan parquet [id#0L,name#1] Batched: true, Format: Parquet, Location: InMemoryFileIndex[s3a://input-bucket/events/2021], PartitionFilters: [], ReadSchema: struct<id:bigint,name:string>`
+
+const testFormattedPlan = `== Physical Plan ==
+Execute InsertIntoHadoopFsRelationCommand (4)
++- BroadcastHashJoin (3)
+   :- Scan csv  (1)
+   +- Scan csv  (2)
+
+
+(1) Scan csv 
+Output [1]: [id#10]
+Location: InMemoryFileIndex(2 paths)[file:/data/a.csv, /data/b.csv]
+
+(2) Scan csv 
+Output [1]: [id#20]
+Location: InMemoryFileIndex[hdfs://namenode:8020/warehouse/ids]
+
+(4) Execute InsertIntoHadoopFsRelationCommand
+Input [1]: [id#10]
+Arguments: gs://results/joined, false, CSV, [path=gs://results/joined], Append`
+
+func TestNewLineage(t *testing.T) {
+
+	t.Run("whenNoExecutions", func(tt *testing.T) {
+		assert.Nil(tt, newLineage(nil))
+	})
+
+	t.Run("whenNoFileDatasets", func(tt *testing.T) {
+		executions := []sparkapiclient.SQLExecution{
+			{PlanDescription: "== Physical Plan ==\n*(1) Range (0, 100, step=1, splits=2)"},
+		}
+		assert.Nil(tt, newLineage(executions))
+	})
+
+	t.Run("whenSimplePlan", func(tt *testing.T) {
+		lineage := newLineage([]sparkapiclient.SQLExecution{{PlanDescription: testSimplePlan}})
+		require.NotNil(tt, lineage)
+		assert.Equal(tt, []v1alpha1.Dataset{{Namespace: "s3://input-bucket", Name: "events/2021"}}, lineage.Inputs)
+		assert.Equal(tt, []v1alpha1.Dataset{{Namespace: "s3://output-bucket", Name: "reports/daily"}}, lineage.Outputs)
+	})
+
+	t.Run("whenFormattedPlan", func(tt *testing.T) {
+		lineage := newLineage([]sparkapiclient.SQLExecution{{PlanDescription: testFormattedPlan}})
+		require.NotNil(tt, lineage)
+		assert.Equal(tt, []v1alpha1.Dataset{
+			{Namespace: "file", Name: "/data/a.csv"},
+			{Namespace: "file", Name: "/data/b.csv"},
+			{Namespace: "hdfs://namenode:8020", Name: "/warehouse/ids"},
+		}, lineage.Inputs)
+		assert.Equal(tt, []v1alpha1.Dataset{{Namespace: "gs://results", Name: "joined"}}, lineage.Outputs)
+	})
+
+	t.Run("whenDuplicateDatasets", func(tt *testing.T) {
+		lineage := newLineage([]sparkapiclient.SQLExecution{
+			{PlanDescription: testSimplePlan},
+			{PlanDescription: testSimplePlan},
+		})
+		require.NotNil(tt, lineage)
+		assert.Len(tt, lineage.Inputs, 1)
+		assert.Len(tt, lineage.Outputs, 1)
+	})
+
+	t.Run("whenTooManyDatasets", func(tt *testing.T) {
+		executions := make([]sparkapiclient.SQLExecution, 0)
+		for i := 0; i < maxLineageDatasets+10; i++ {
+			executions = append(executions, sparkapiclient.SQLExecution{
+				PlanDescription: fmt.Sprintf("Location: InMemoryFileIndex[s3://bucket/table-%03d]", i),
+			})
+		}
+		lineage := newLineage(executions)
+		require.NotNil(tt, lineage)
+		assert.Len(tt, lineage.Inputs, maxLineageDatasets)
+		assert.Equal(tt, "table-000", lineage.Inputs[0].Name)
+		assert.Empty(tt, lineage.Outputs)
+	})
+}
+
+func TestNewDataset(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected v1alpha1.Dataset
+		ok       bool
+	}{
+		{"s3a://bucket/a/b", v1alpha1.Dataset{Namespace: "s3://bucket", Name: "a/b"}, true},
+		{"s3://bucket/a", v1alpha1.Dataset{Namespace: "s3://bucket", Name: "a"}, true},
+		{"abfss://container@account.dfs.core.windows.net/a", v1alpha1.Dataset{Namespace: "abfss://container@account.dfs.core.windows.net", Name: "a"}, true},
+		{"hdfs://namenode:8020/a", v1alpha1.Dataset{Namespace: "hdfs://namenode:8020", Name: "/a"}, true},
+		{"file:/tmp/a", v1alpha1.Dataset{Namespace: "file", Name: "/tmp/a"}, true},
+		{"/tmp/a", v1alpha1.Dataset{Namespace: "file", Name: "/tmp/a"}, true},
+		{"relative/path", v1alpha1.Dataset{}, false},
+		{"hdfs:/no-host", v1alpha1.Dataset{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(tt *testing.T) {
+			dataset, ok := newDataset(test.path)
+			assert.Equal(tt, test.ok, ok)
+			assert.Equal(tt, test.expected, dataset)
+		})
+	}
+}
@@ -1,19 +1,19 @@
 package sparkapi
 
 import (
-	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
-	"github.com/spotinst/wave-operator/catalog"
+	"github.com/spotinst/wave-operator/api/v1alpha1"
 	"github.com/spotinst/wave-operator/internal/config"
 	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
 	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
+	"github.com/spotinst/wave-operator/internal/sparkapi/eventlog"
 )
 
 const (
@@ -33,8 +33,10 @@ type Manager interface {
 }
 
 type manager struct {
-	client sparkapiclient.Client
-	logger logr.Logger
+	client      sparkapiclient.Client
+	redaction   RedactionOptions
+	collections *collectionCache
+	logger      logr.Logger
 }
 
 type ApplicationInfo struct {
@@ -46,45 +48,108 @@ type ApplicationInfo struct {
 	TotalNewExecutorCpuTime int64
 	Attempts                []sparkapiclient.Attempt
 	Executors               []sparkapiclient.Executor
+	Jobs                    []sparkapiclient.Job
+	SQLExecutions           []sparkapiclient.SQLExecution
+	Insights                []v1alpha1.Insight
+	Environment             *v1alpha1.RuntimeEnvironment
+	Lineage                 *v1alpha1.Lineage
 	WorkloadType            WorkloadType
 	Metrics                 sparkapiclient.Metrics
 }
 
-var GetManager = func(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (Manager, error) {
-	client, err := getSparkApiClient(clientSet, driverPod, logger)
-	if err != nil {
-		return nil, fmt.Errorf("could not get spark api client, %w", err)
+// Options holds the operator wide configuration of the Spark API managers
+type Options struct {
+	Transport TransportOptions
+	// HistoryServers are the history servers used once the driver has finished, in order of precedence
+	HistoryServers []config.HistoryServer
+	// EventLogs configures reading event logs directly once the driver has finished,
+	// used when no history server is available
+	EventLogs EventLogOptions
+	// Redaction configures the redaction of secrets from Spark properties
+	Redaction RedactionOptions
+}
+
+// DefaultOptions returns the default Spark API manager options
+func DefaultOptions() Options {
+	return Options{
+		Transport:      DefaultTransportOptions(),
+		HistoryServers: DefaultHistoryServers(),
+	}
+}
+
+var GetManager = NewManagerGetter(DefaultOptions())
+
+// NewManagerGetter returns a factory function that creates managers configured with the given options
+func NewManagerGetter(opts Options) func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (Manager, error) {
+	// Managers are created for every reconcile, the collection state and replayed event logs are shared between them
+	collections := newCollectionCache(time.Now)
+	eventLogs := eventlog.NewCache(time.Now)
+	return func(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, logger logr.Logger) (Manager, error) {
+		client, err := getSparkApiClient(clientSet, driverPod, app, opts, eventLogs, logger)
+		if err != nil {
+			return nil, fmt.Errorf("could not get spark api client, %w", err)
+		}
+		return manager{
+			client:      client,
+			redaction:   opts.Redaction,
+			collections: collections,
+			logger:      logger,
+		}, nil
 	}
-	return manager{
-		client: client,
-		logger: logger,
-	}, nil
 }
 
-func getSparkApiClient(clientSet kubernetes.Interface, driverPod *corev1.Pod, logger logr.Logger) (sparkapiclient.Client, error) {
+func getSparkApiClient(clientSet kubernetes.Interface, driverPod *corev1.Pod, app *v1alpha1.SparkApplication, opts Options, eventLogs *eventlog.Cache, logger logr.Logger) (sparkapiclient.Client, error) {
+
+	transportOpts := getNamespaceTransportOptions(clientSet, driverPod.Namespace, opts.Transport, logger)
 
 	// Try the driver API first, to get information on running applications
 	// Once the application is finished the info is written to history server
 
 	// Get client for driver pod
 	if isSparkDriverRunning(driverPod) {
-		return sparkapiclient.NewDriverPodClient(driverPod, clientSet), nil
+		var driverService *corev1.Service
+		if transportOpts.Mode == transport.ServiceMode {
+			svc, err := getDriverService(clientSet, driverPod)
+			if err != nil {
+				return nil, fmt.Errorf("could not get driver service, %w", err)
+			}
+			driverService = svc
+		}
+		transportConfig, err := getTransportConfig(driverService, transportOpts)
+		if err != nil {
+			return nil, fmt.Errorf("could not get driver transport configuration, %w", err)
+		}
+		return sparkapiclient.NewDriverPodClient(driverPod, clientSet, transportConfig), nil
 	}
 
-	// Check if the event log sync feature is on
-	if !config.IsEventLogSyncEnabled(driverPod.Annotations) {
+	// The history server only knows about applications that write event logs
+	hsApp, ok := newHistoryServerApplication(driverPod, app)
+	if !ok {
+		return nil, ErrApiNotAvailable
+	}
+
+	historyServer, ok := selectHistoryServer(opts.HistoryServers, hsApp)
+	if ok {
+		logger.Info("Driver pod/container not running, will use history server Spark API client", "historyServer", historyServer.Name)
+		client, err := getHistoryServerClient(clientSet, historyServer, transportOpts, logger)
+		if err == nil || !opts.EventLogs.Enabled {
+			return client, err
+		}
+		logger.Info(fmt.Sprintf("History server %q not available, falling back to event log: %s", historyServer.Name, err.Error()))
+	} else if !opts.EventLogs.Enabled {
+		logger.Info("No history server configured for application", "eventLogDir", hsApp.eventLogDir,
+			"namespace", hsApp.namespace, "sparkVersion", hsApp.sparkVersion)
 		return nil, ErrApiNotAvailable
 	}
 
-	logger.Info("Driver pod/container not running, will use history server Spark API client")
+	logger.Info("Driver pod/container not running, will use event log Spark API client", "eventLogDir", hsApp.eventLogDir)
 
-	// Get client for history server
-	historyServerService, err := getHistoryServerService(clientSet, logger)
+	store, err := getEventLogStore(hsApp.eventLogDir, opts.EventLogs)
 	if err != nil {
-		return nil, fmt.Errorf("could not get history server service, %w", err)
+		return nil, fmt.Errorf("could not get event log store, %w", err)
 	}
 
-	return sparkapiclient.NewHistoryServerClient(historyServerService, clientSet), nil
+	return eventlog.NewCachedClient(store, eventLogs), nil
 }
 
 func (m manager) GetApplicationInfo(applicationID string) (*ApplicationInfo, error) {
@@ -104,26 +169,65 @@ func (m manager) GetApplicationInfo(applicationID string) (*ApplicationInfo, err
 	applicationInfo.ApplicationName = application.Name
 	applicationInfo.Attempts = application.Attempts
 
-	environment, err := m.client.GetEnvironment(applicationID)
-	if err != nil {
-		return nil, fmt.Errorf("could not get environment, %w", err)
+	dc, live := m.client.(sparkapiclient.DriverClient)
+	attempt := getLatestAttempt(application)
+	state := m.collections.get(applicationID, attempt.StartTimeEpoch, live)
+
+	// History servers and event logs update the attempt whenever the event log changes,
+	// the driver does not update it while the application runs
+	unchanged := !live && state.collected && state.lastUpdatedEpoch == attempt.LastUpdatedEpoch
+
+	if state.environment == nil {
+		environment, err := m.client.GetEnvironment(applicationID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get environment, %w", err)
+		}
+
+		sparkProperties, err := parseSparkProperties(environment, m.logger)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse spark properties, %w", err)
+		}
+
+		// Spark properties are stored in the SparkApplication resource and exported, secrets must not leave the operator
+		r := newRedactor(sparkProperties, m.redaction, m.logger)
+		state.sparkProperties = redactSparkProperties(sparkProperties, r)
+		state.environment = newRuntimeEnvironment(environment, application, sparkProperties, r)
 	}
 
-	sparkProperties, err := parseSparkProperties(environment, m.logger)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse spark properties, %w", err)
+	applicationInfo.SparkProperties = state.sparkProperties
+	applicationInfo.Environment = state.environment
+
+	if unchanged {
+		applicationInfo.Executors = state.executors
+	} else {
+		executors, err := m.getExecutors(applicationID, state)
+		if err != nil {
+			return nil, fmt.Errorf("could not get executors, %w", err)
+		}
+		applicationInfo.Executors = executors
 	}
 
-	applicationInfo.SparkProperties = sparkProperties
+	// Jobs, stages and SQL executions only change while tasks run
+	fingerprint, idle := getExecutorsFingerprint(applicationInfo.Executors)
+	if live && state.collected && state.idle && idle && state.executorsFingerprint == fingerprint {
+		unchanged = true
+	}
 
-	executors, err := m.client.GetAllExecutors(applicationID)
-	if err != nil {
-		return nil, fmt.Errorf("could not get executors, %w", err)
+	if !unchanged {
+		if err := m.collectProgress(applicationID, state); err != nil {
+			return nil, err
+		}
+		state.lastUpdatedEpoch = attempt.LastUpdatedEpoch
+		state.executorsFingerprint = fingerprint
+		state.idle = idle
 	}
 
-	applicationInfo.Executors = executors
+	applicationInfo.Jobs = state.jobs
+	applicationInfo.SQLExecutions = state.sqlExecutions
+	applicationInfo.Insights = state.insights
+	applicationInfo.Lineage = state.lineage
 
-	if dc, ok := m.client.(sparkapiclient.DriverClient); ok {
+	if live {
 		applicationInfo.WorkloadType = m.getWorkloadType(dc, applicationID)
 		metrics, err := dc.GetMetrics()
 		if err != nil {
@@ -140,6 +244,64 @@ func (m manager) GetApplicationInfo(applicationID string) (*ApplicationInfo, err
 	return applicationInfo, nil
 }
 
+// getExecutors returns all executors of the application. Once the removed executors are recorded
+// only the active executors are fetched, unless an executor was removed since.
+func (m manager) getExecutors(applicationID string, state *collectionState) ([]sparkapiclient.Executor, error) {
+	if state.activeExecutorIDs != nil {
+		active, err := m.client.GetExecutors(applicationID)
+		if err != nil {
+			return nil, err
+		}
+		if executors, ok := state.mergeActiveExecutors(active); ok {
+			state.executors = executors
+			return executors, nil
+		}
+	}
+
+	executors, err := m.client.GetAllExecutors(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	state.recordExecutors(executors)
+	state.executors = executors
+
+	return executors, nil
+}
+
+// collectProgress collects the jobs, SQL executions, lineage and insights of the application
+func (m manager) collectProgress(applicationID string, state *collectionState) error {
+	jobs, err := m.client.GetJobs(applicationID)
+	if err != nil {
+		return fmt.Errorf("could not get jobs, %w", err)
+	}
+
+	// The SQL endpoint is not available before Spark 3.0
+	sqlExecutions, err := m.client.GetSQLExecutions(applicationID)
+	if err != nil {
+		if !IsNotFoundError(err) {
+			return fmt.Errorf("could not get sql executions, %w", err)
+		}
+		m.logger.Info("Spark SQL executions not available")
+	}
+
+	// Insights are best effort, the application info is still useful without them
+	var insights []v1alpha1.Insight
+	stages, err := m.client.GetStages(applicationID)
+	if err != nil {
+		m.logger.Error(err, "Unable to get stages, insights not available")
+	} else {
+		insights = m.getInsights(applicationID, stages)
+	}
+
+	state.jobs = jobs
+	state.sqlExecutions = sqlExecutions
+	state.lineage = newLineage(sqlExecutions)
+	state.insights = insights
+	state.collected = true
+
+	return nil
+}
+
 func (m manager) getWorkloadType(c sparkapiclient.DriverClient, applicationID string) WorkloadType {
 	// Streaming statistics endpoint is only available on running driver
 	_, err := c.GetStreamingStatistics(applicationID)
@@ -175,27 +337,6 @@ func parseSparkProperties(environment *sparkapiclient.Environment, logger logr.L
 	return sparkProperties, nil
 }
 
-func getHistoryServerService(clientSet kubernetes.Interface, logger logr.Logger) (*corev1.Service, error) {
-	ctx := context.TODO()
-	foundServices, err := clientSet.CoreV1().Services(catalog.SystemNamespace).List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", appNameLabel, historyServerAppNameLabelValue),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("could not list services, %w", err)
-	}
-
-	if len(foundServices.Items) != 1 {
-		logger.Info(fmt.Sprintf("Found unexpected number of history server services, found %d but wanted 1", len(foundServices.Items)))
-	}
-
-	if len(foundServices.Items) > 0 {
-		service := foundServices.Items[0]
-		return &service, nil
-	}
-
-	return nil, fmt.Errorf("could not find history server service")
-}
-
 func isSparkDriverRunning(driverPod *corev1.Pod) bool {
 
 	if driverPod.Status.Phase != corev1.PodRunning {
@@ -3,6 +3,7 @@ package sparkapi
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
@@ -12,10 +13,12 @@ import (
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/spotinst/wave-operator/api/v1alpha1"
 	"github.com/spotinst/wave-operator/catalog"
 	"github.com/spotinst/wave-operator/internal/config"
 	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
 	"github.com/spotinst/wave-operator/internal/sparkapi/client/mock_client"
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
 )
 
 func TestGetSparkApiClient(t *testing.T) {
@@ -29,7 +32,7 @@ func TestGetSparkApiClient(t *testing.T) {
 
 		clientSet := k8sfake.NewSimpleClientset(svc, pod)
 
-		c, err := getSparkApiClient(clientSet, pod, logger)
+		c, err := getSparkApiClient(clientSet, pod, nil, DefaultOptions(), nil, logger)
 		assert.NoError(tt, err)
 		assert.NotNil(tt, c)
 		assert.Implements(tt, (*sparkapiclient.DriverClient)(nil), c)
@@ -42,7 +45,7 @@ func TestGetSparkApiClient(t *testing.T) {
 
 		clientSet := k8sfake.NewSimpleClientset(svc, pod)
 
-		c, err := getSparkApiClient(clientSet, pod, logger)
+		c, err := getSparkApiClient(clientSet, pod, nil, DefaultOptions(), nil, logger)
 		assert.NoError(tt, err)
 		assert.NotNil(tt, c)
 		assert.Implements(tt, (*sparkapiclient.DriverClient)(nil), c)
@@ -56,7 +59,7 @@ func TestGetSparkApiClient(t *testing.T) {
 
 		clientSet := k8sfake.NewSimpleClientset(svc, pod)
 
-		c, err := getSparkApiClient(clientSet, pod, logger)
+		c, err := getSparkApiClient(clientSet, pod, nil, DefaultOptions(), nil, logger)
 		assert.NoError(tt, err)
 		assert.NotNil(tt, c)
 		assert.Implements(tt, (*sparkapiclient.Client)(nil), c)
@@ -71,7 +74,7 @@ func TestGetSparkApiClient(t *testing.T) {
 
 		clientSet := k8sfake.NewSimpleClientset(svc, pod)
 
-		c, err := getSparkApiClient(clientSet, pod, logger)
+		c, err := getSparkApiClient(clientSet, pod, nil, DefaultOptions(), nil, logger)
 		assert.Error(tt, err)
 		assert.Nil(tt, c)
 		assert.True(tt, IsApiNotAvailableError(err))
@@ -85,7 +88,7 @@ func TestGetSparkApiClient(t *testing.T) {
 
 		clientSet := k8sfake.NewSimpleClientset(pod)
 
-		c, err := getSparkApiClient(clientSet, pod, logger)
+		c, err := getSparkApiClient(clientSet, pod, nil, DefaultOptions(), nil, logger)
 		assert.Error(tt, err)
 		assert.Nil(tt, c)
 
@@ -123,6 +126,11 @@ func TestGetApplicationInfo(t *testing.T) {
 		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(1)
 		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
 		m.EXPECT().GetAllExecutors(applicationID).Return(getExecutorsResponse(), nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(getJobsResponse(), nil).Times(1)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(getSQLExecutionsResponse(), nil).Times(1)
+		m.EXPECT().GetStages(applicationID).Return(getStagesResponse(), nil).Times(1)
+		m.EXPECT().GetStageTaskSummary(applicationID, 3, 0, []float64{0.5, 1.0}).Return(getTaskSummaryResponse(), nil).Times(1)
+		m.EXPECT().GetStageTaskSummary(applicationID, 2, 0, []float64{0.5, 1.0}).Return(nil, transport.NewNotFoundError(fmt.Errorf("404"))).Times(1)
 
 		manager := &manager{
 			client: m,
@@ -147,15 +155,69 @@ func TestGetApplicationInfo(t *testing.T) {
 		assert.Equal(tt, getExecutorsResponse()[1], res.Executors[1])
 		assert.Equal(tt, getExecutorsResponse()[2], res.Executors[2])
 
+		assert.Equal(tt, getJobsResponse(), res.Jobs)
+		assert.Equal(tt, getSQLExecutionsResponse(), res.SQLExecutions)
+
+		assert.Equal(tt, &v1alpha1.RuntimeEnvironment{SparkVersion: "3.0.0"}, res.Environment)
+
+		assert.Equal(tt, 2, len(res.Insights))
+		assert.Equal(tt, v1alpha1.InsightTypeSpill, res.Insights[0].Type)
+		assert.Equal(tt, int64(2), res.Insights[0].StageID)
+		assert.Equal(tt, v1alpha1.InsightTypeDataSkew, res.Insights[1].Type)
+		assert.Equal(tt, int64(3), res.Insights[1].StageID)
+
 		assert.Equal(tt, WorkloadType(""), res.WorkloadType)
 	})
 
+	t.Run("whenSQLExecutionsNotAvailable", func(tt *testing.T) {
+
+		m := mock_client.NewMockClient(ctrl)
+		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(1)
+		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
+		m.EXPECT().GetAllExecutors(applicationID).Return(getExecutorsResponse(), nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(getJobsResponse(), nil).Times(1)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(nil, transport.NewNotFoundError(fmt.Errorf("404"))).Times(1)
+		m.EXPECT().GetStages(applicationID).Return(nil, fmt.Errorf("test error")).Times(1)
+
+		manager := &manager{
+			client: m,
+			logger: getTestLogger(),
+		}
+
+		res, err := manager.GetApplicationInfo(applicationID)
+		assert.NoError(tt, err)
+		assert.Equal(tt, getJobsResponse(), res.Jobs)
+		assert.Empty(tt, res.SQLExecutions)
+		assert.Empty(tt, res.Insights)
+	})
+
+	t.Run("whenJobsError", func(tt *testing.T) {
+
+		m := mock_client.NewMockClient(ctrl)
+		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(1)
+		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
+		m.EXPECT().GetAllExecutors(applicationID).Return(getExecutorsResponse(), nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(nil, fmt.Errorf("test error")).Times(1)
+
+		manager := &manager{
+			client: m,
+			logger: getTestLogger(),
+		}
+
+		_, err := manager.GetApplicationInfo(applicationID)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "test error")
+	})
+
 	t.Run("whenDriverClient_notSparkStreaming", func(tt *testing.T) {
 
 		m := mock_client.NewMockDriverClient(ctrl)
 		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(1)
 		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
 		m.EXPECT().GetAllExecutors(applicationID).Return(getExecutorsResponse(), nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(getJobsResponse(), nil).Times(1)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(getSQLExecutionsResponse(), nil).Times(1)
+		m.EXPECT().GetStages(applicationID).Return([]sparkapiclient.Stage{}, nil).Times(1)
 		m.EXPECT().GetMetrics().Return(getMetricsResponse(), nil).Times(1)
 		m.EXPECT().GetStreamingStatistics(applicationID).Return(nil, fmt.Errorf("404 not found")).Times(1)
 
@@ -176,6 +238,9 @@ func TestGetApplicationInfo(t *testing.T) {
 		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(1)
 		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
 		m.EXPECT().GetAllExecutors(applicationID).Return(getExecutorsResponse(), nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(getJobsResponse(), nil).Times(1)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(getSQLExecutionsResponse(), nil).Times(1)
+		m.EXPECT().GetStages(applicationID).Return([]sparkapiclient.Stage{}, nil).Times(1)
 		m.EXPECT().GetMetrics().Return(getMetricsResponse(), nil).Times(1)
 		m.EXPECT().GetStreamingStatistics(applicationID).Return(getStreamingStatisticsResponse(), nil).Times(1)
 
@@ -196,6 +261,9 @@ func TestGetApplicationInfo(t *testing.T) {
 		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(1)
 		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
 		m.EXPECT().GetAllExecutors(applicationID).Return(getExecutorsResponse(), nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(getJobsResponse(), nil).Times(1)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(getSQLExecutionsResponse(), nil).Times(1)
+		m.EXPECT().GetStages(applicationID).Return([]sparkapiclient.Stage{}, nil).Times(1)
 
 		manager := &manager{
 			client: m,
@@ -208,6 +276,117 @@ func TestGetApplicationInfo(t *testing.T) {
 		assert.Equal(tt, WorkloadType(""), res.WorkloadType)
 	})
 
+	t.Run("whenDriverClient_incremental", func(tt *testing.T) {
+
+		executors := []sparkapiclient.Executor{
+			{ID: "driver", IsActive: true},
+			{ID: "1", IsActive: false, CompletedTasks: 10, TotalTasks: 10},
+			{ID: "2", IsActive: true, CompletedTasks: 5, TotalTasks: 5},
+		}
+
+		m := mock_client.NewMockDriverClient(ctrl)
+		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(3)
+		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
+		m.EXPECT().GetMetrics().Return(getMetricsResponse(), nil).Times(3)
+		m.EXPECT().GetStreamingStatistics(applicationID).Return(nil, fmt.Errorf("404 not found")).Times(3)
+
+		manager := &manager{
+			client:      m,
+			collections: newCollectionCache(time.Now),
+			logger:      getTestLogger(),
+		}
+
+		// First collection, all endpoints are fetched
+		m.EXPECT().GetAllExecutors(applicationID).Return(executors, nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(getJobsResponse(), nil).Times(1)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(getSQLExecutionsResponse(), nil).Times(1)
+		m.EXPECT().GetStages(applicationID).Return([]sparkapiclient.Stage{}, nil).Times(1)
+
+		res, err := manager.GetApplicationInfo(applicationID)
+		assert.NoError(tt, err)
+		assert.Equal(tt, 3, len(res.Executors))
+		assert.Equal(tt, getJobsResponse(), res.Jobs)
+
+		// Nothing changed, only the active executors are fetched
+		m.EXPECT().GetExecutors(applicationID).Return([]sparkapiclient.Executor{executors[0], executors[2]}, nil).Times(1)
+
+		res, err = manager.GetApplicationInfo(applicationID)
+		assert.NoError(tt, err)
+		assert.ElementsMatch(tt, executors, res.Executors)
+		assert.Equal(tt, getJobsResponse(), res.Jobs)
+		assert.Equal(tt, getSQLExecutionsResponse(), res.SQLExecutions)
+		assert.Equal(tt, "val1", res.SparkProperties["prop1"])
+
+		// Executor 2 was removed, all executors are fetched again
+		removed := executors[2]
+		removed.IsActive = false
+		removed.CompletedTasks = 6
+		removed.TotalTasks = 6
+		m.EXPECT().GetExecutors(applicationID).Return([]sparkapiclient.Executor{executors[0]}, nil).Times(1)
+		m.EXPECT().GetAllExecutors(applicationID).Return([]sparkapiclient.Executor{executors[0], executors[1], removed}, nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(nil, nil).Times(1)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(nil, nil).Times(1)
+		m.EXPECT().GetStages(applicationID).Return([]sparkapiclient.Stage{}, nil).Times(1)
+
+		res, err = manager.GetApplicationInfo(applicationID)
+		assert.NoError(tt, err)
+		assert.Equal(tt, []sparkapiclient.Executor{executors[0], executors[1], removed}, res.Executors)
+		assert.Empty(tt, res.Jobs)
+	})
+
+	t.Run("whenHistoryServerClient_lastUpdatedUnchanged", func(tt *testing.T) {
+
+		m := mock_client.NewMockClient(ctrl)
+		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(2)
+		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
+		m.EXPECT().GetAllExecutors(applicationID).Return(getExecutorsResponse(), nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(getJobsResponse(), nil).Times(1)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(getSQLExecutionsResponse(), nil).Times(1)
+		m.EXPECT().GetStages(applicationID).Return([]sparkapiclient.Stage{}, nil).Times(1)
+
+		manager := &manager{
+			client:      m,
+			collections: newCollectionCache(time.Now),
+			logger:      getTestLogger(),
+		}
+
+		first, err := manager.GetApplicationInfo(applicationID)
+		assert.NoError(tt, err)
+
+		second, err := manager.GetApplicationInfo(applicationID)
+		assert.NoError(tt, err)
+		assert.Equal(tt, first, second)
+	})
+
+	t.Run("whenHistoryServerClient_lastUpdatedChanged", func(tt *testing.T) {
+
+		updated := getApplicationResponse()
+		updated.Attempts[0].LastUpdatedEpoch++
+
+		m := mock_client.NewMockClient(ctrl)
+		m.EXPECT().GetApplication(applicationID).Return(getApplicationResponse(), nil).Times(1)
+		m.EXPECT().GetApplication(applicationID).Return(updated, nil).Times(1)
+		m.EXPECT().GetEnvironment(applicationID).Return(getEnvironmentResponse(), nil).Times(1)
+		m.EXPECT().GetAllExecutors(applicationID).Return(getExecutorsResponse(), nil).Times(1)
+		// All executors are already recorded as inactive
+		m.EXPECT().GetExecutors(applicationID).Return([]sparkapiclient.Executor{}, nil).Times(1)
+		m.EXPECT().GetJobs(applicationID).Return(getJobsResponse(), nil).Times(2)
+		m.EXPECT().GetSQLExecutions(applicationID).Return(getSQLExecutionsResponse(), nil).Times(2)
+		m.EXPECT().GetStages(applicationID).Return([]sparkapiclient.Stage{}, nil).Times(2)
+
+		manager := &manager{
+			client:      m,
+			collections: newCollectionCache(time.Now),
+			logger:      getTestLogger(),
+		}
+
+		_, err := manager.GetApplicationInfo(applicationID)
+		assert.NoError(tt, err)
+
+		res, err := manager.GetApplicationInfo(applicationID)
+		assert.NoError(tt, err)
+		assert.Equal(tt, getExecutorsResponse(), res.Executors)
+	})
 }
 
 func getTestLogger() logr.Logger {
@@ -274,6 +453,75 @@ func getExecutorsResponse() []sparkapiclient.Executor {
 	}
 }
 
+func getJobsResponse() []sparkapiclient.Job {
+	return []sparkapiclient.Job{
+		{
+			JobID:          1,
+			Name:           "count at NativeMethodAccessorImpl.java:0",
+			SubmissionTime: "2020-11-24T17:19:10.000GMT",
+			Status:         sparkapiclient.JobStatusRunning,
+		},
+		{
+			JobID:          0,
+			Name:           "collect at SparkPi.scala:38",
+			SubmissionTime: "2020-11-24T17:19:00.000GMT",
+			CompletionTime: "2020-11-24T17:19:05.000GMT",
+			Status:         sparkapiclient.JobStatusSucceeded,
+		},
+	}
+}
+
+func getSQLExecutionsResponse() []sparkapiclient.SQLExecution {
+	return []sparkapiclient.SQLExecution{
+		{
+			ID:              0,
+			Status:          sparkapiclient.SQLExecutionStatusCompleted,
+			Description:     "select count(*) from table",
+			PlanDescription: "== Physical Plan ==",
+			SubmissionTime:  "2020-11-24T17:19:00.000GMT",
+			Duration:        5000,
+			SuccessJobIDs:   []int64{0},
+		},
+	}
+}
+
+func getStagesResponse() []sparkapiclient.Stage {
+	return []sparkapiclient.Stage{
+		{
+			Status:           sparkapiclient.StageStatusComplete,
+			StageID:          3,
+			Name:             "count at Job.scala:10",
+			NumCompleteTasks: 200,
+			ExecutorRunTime:  1000000,
+		},
+		{
+			Status:           sparkapiclient.StageStatusComplete,
+			StageID:          2,
+			Name:             "save at Job.scala:20",
+			NumCompleteTasks: 200,
+			ExecutorRunTime:  500000,
+			DiskBytesSpilled: 2 * 1024 * 1024 * 1024,
+		},
+		{
+			Status:           sparkapiclient.StageStatusActive,
+			StageID:          4,
+			NumCompleteTasks: 200,
+		},
+		{
+			Status:           sparkapiclient.StageStatusComplete,
+			StageID:          1,
+			NumCompleteTasks: 1,
+		},
+	}
+}
+
+func getTaskSummaryResponse() *sparkapiclient.TaskSummary {
+	return &sparkapiclient.TaskSummary{
+		Quantiles:       []float64{0.5, 1.0},
+		ExecutorRunTime: []float64{3000, 120000},
+	}
+}
+
 func getMetricsResponse() sparkapiclient.Metrics {
 	return sparkapiclient.Metrics{
 		Gauges: map[string]sparkapiclient.GaugeValue{
@@ -311,6 +559,9 @@ func newHistoryServerService() *corev1.Service {
 func newRunningDriverPod(eventLogSyncEnabled bool) *corev1.Pod {
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
+			Name:              "spark-pi-driver",
+			Namespace:         "spark-jobs",
+			UID:               "c1a6d4b4-0b1e-4a3c-9fd0-55e1a1a8c0c5",
 			DeletionTimestamp: nil,
 		},
 		Status: corev1.PodStatus{
@@ -329,3 +580,167 @@ func newRunningDriverPod(eventLogSyncEnabled bool) *corev1.Pod {
 	}
 	return pod
 }
+
+func TestGetSparkApiClient_transport(t *testing.T) {
+
+	logger := getTestLogger()
+
+	t.Run("whenProxyMode", func(tt *testing.T) {
+
+		pod := newRunningDriverPod(false)
+		clientSet := k8sfake.NewSimpleClientset(pod)
+
+		opts := DefaultOptions()
+		opts.Transport.Mode = transport.ProxyMode
+
+		c, err := getSparkApiClient(clientSet, pod, nil, opts, nil, logger)
+		assert.NoError(tt, err)
+		assert.Implements(tt, (*sparkapiclient.DriverClient)(nil), c)
+	})
+
+	t.Run("whenServiceMode_driverServiceMissing", func(tt *testing.T) {
+
+		pod := newRunningDriverPod(false)
+		clientSet := k8sfake.NewSimpleClientset(pod)
+
+		opts := DefaultOptions()
+		opts.Transport.Mode = transport.ServiceMode
+
+		c, err := getSparkApiClient(clientSet, pod, nil, opts, nil, logger)
+		assert.Error(tt, err)
+		assert.Nil(tt, c)
+	})
+
+	t.Run("whenServiceMode_driverServiceFound", func(tt *testing.T) {
+
+		pod := newRunningDriverPod(false)
+		svc := newDriverService(pod)
+		clientSet := k8sfake.NewSimpleClientset(pod, svc)
+
+		opts := DefaultOptions()
+		opts.Transport.Mode = transport.ServiceMode
+
+		c, err := getSparkApiClient(clientSet, pod, nil, opts, nil, logger)
+		assert.NoError(tt, err)
+		assert.Implements(tt, (*sparkapiclient.DriverClient)(nil), c)
+	})
+
+	t.Run("whenNamespaceAnnotationOverridesMode", func(tt *testing.T) {
+
+		pod := newRunningDriverPod(false)
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: pod.Namespace,
+				Annotations: map[string]string{
+					config.WaveConfigAnnotationSparkApiTransport: "service",
+				},
+			},
+		}
+		clientSet := k8sfake.NewSimpleClientset(pod, ns)
+
+		// Operator default is direct, but the namespace requires a driver service which doesn't exist
+		c, err := getSparkApiClient(clientSet, pod, nil, DefaultOptions(), nil, logger)
+		assert.Error(tt, err)
+		assert.Nil(tt, c)
+	})
+}
+
+func TestGetNamespaceTransportOptions(t *testing.T) {
+
+	logger := getTestLogger()
+
+	t.Run("whenNamespaceNotFound", func(tt *testing.T) {
+		clientSet := k8sfake.NewSimpleClientset()
+		opts := getNamespaceTransportOptions(clientSet, "spark-jobs", DefaultTransportOptions(), logger)
+		assert.Equal(tt, DefaultTransportOptions(), opts)
+	})
+
+	t.Run("whenNamespaceAnnotated", func(tt *testing.T) {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "spark-jobs",
+				Annotations: map[string]string{
+					config.WaveConfigAnnotationSparkApiTransport:   "Proxy",
+					config.WaveConfigAnnotationSparkApiServiceHost: "{{ .Name }}.example.com",
+				},
+			},
+		}
+		clientSet := k8sfake.NewSimpleClientset(ns)
+		opts := getNamespaceTransportOptions(clientSet, "spark-jobs", DefaultTransportOptions(), logger)
+		assert.Equal(tt, transport.ProxyMode, opts.Mode)
+		assert.Equal(tt, "{{ .Name }}.example.com", opts.ServiceHostTemplate)
+	})
+
+	t.Run("whenNamespaceAnnotationInvalid", func(tt *testing.T) {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "spark-jobs",
+				Annotations: map[string]string{
+					config.WaveConfigAnnotationSparkApiTransport: "carrier-pigeon",
+				},
+			},
+		}
+		clientSet := k8sfake.NewSimpleClientset(ns)
+		opts := getNamespaceTransportOptions(clientSet, "spark-jobs", DefaultTransportOptions(), logger)
+		assert.Equal(tt, transport.DirectMode, opts.Mode)
+	})
+}
+
+func TestGetTransportConfig(t *testing.T) {
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-app-driver-svc",
+			Namespace: "spark-jobs",
+		},
+	}
+
+	t.Run("whenDirectMode", func(tt *testing.T) {
+		conf, err := getTransportConfig(svc, DefaultTransportOptions())
+		assert.NoError(tt, err)
+		assert.Equal(tt, transport.DirectMode, conf.Mode)
+		assert.Empty(tt, conf.ServiceHost)
+	})
+
+	t.Run("whenServiceMode_defaultTemplate", func(tt *testing.T) {
+		opts := DefaultTransportOptions()
+		opts.Mode = transport.ServiceMode
+		conf, err := getTransportConfig(svc, opts)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "my-app-driver-svc.spark-jobs.svc", conf.ServiceHost)
+	})
+
+	t.Run("whenServiceMode_customTemplate", func(tt *testing.T) {
+		opts := TransportOptions{
+			Mode:                transport.ServiceMode,
+			ServiceHostTemplate: "{{ .Name }}.{{ .Namespace }}.svc.example.local",
+		}
+		conf, err := getTransportConfig(svc, opts)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "my-app-driver-svc.spark-jobs.svc.example.local", conf.ServiceHost)
+	})
+
+	t.Run("whenServiceMode_invalidTemplate", func(tt *testing.T) {
+		opts := TransportOptions{
+			Mode:                transport.ServiceMode,
+			ServiceHostTemplate: "{{ .Name ",
+		}
+		_, err := getTransportConfig(svc, opts)
+		assert.Error(tt, err)
+	})
+}
+
+func newDriverService(driverPod *corev1.Pod) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "spark-pi-driver-svc",
+			Namespace: driverPod.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					Name: driverPod.Name,
+					UID:  driverPod.UID,
+				},
+			},
+		},
+	}
+}
@@ -87,7 +87,8 @@ func newExecutorCollector(applicationLabels prometheus.Labels) *executorCollecto
 		info: prometheus.NewDesc(
 			"spark_executor_info",
 			"General executor info",
-			[]string{"executor_id", "active", "add_time", "removed_time", "blacklisted"},
+			// blacklisted is kept for existing dashboards, it has the same value as excluded on all Spark versions
+			[]string{"executor_id", "active", "add_time", "removed_time", "blacklisted", "excluded", "resource_profile_id"},
 			applicationLabels),
 		memoryMax: prometheus.NewDesc(
 			"spark_executor_memory_bytes_max",
@@ -183,7 +184,9 @@ func (e *executorCollector) Collect(executors []client.Executor, metrics chan<-
 			strconv.FormatBool(executor.IsActive),
 			executor.AddTime,
 			executor.RemoveTime,
-			strconv.FormatBool(executor.IsBlacklisted))
+			strconv.FormatBool(executor.IsExcluded),
+			strconv.FormatBool(executor.IsExcluded),
+			strconv.Itoa(executor.ResourceProfileID))
 
 		if !executor.IsActive || executor.RemoveTime != "" {
 			continue
@@ -212,6 +215,8 @@ type applicationCollector struct {
 	timeProvider    func() time.Time
 	info            *prometheus.Desc
 	durationSeconds *prometheus.Desc
+	jobsRunning     *prometheus.Desc
+	jobsFailed      *prometheus.Desc
 	executors       *executorCollector
 }
 
@@ -229,6 +234,14 @@ func newApplicationCollector(info *ApplicationInfo, timeProvider func() time.Tim
 			"Spark application running duration in seconds",
 			nil,
 			applicationLabels),
+		jobsRunning: prometheus.NewDesc("spark_jobs_running_count",
+			"Current count of running jobs for the application",
+			nil,
+			applicationLabels),
+		jobsFailed: prometheus.NewDesc("spark_jobs_failed_count",
+			"Current count of failed jobs retained by the Spark UI for the application",
+			nil,
+			applicationLabels),
 		executors: newExecutorCollector(applicationLabels),
 	}
 }
@@ -243,6 +256,8 @@ func label(info *ApplicationInfo) prometheus.Labels {
 func (a *applicationCollector) Describe(descs chan<- *prometheus.Desc) {
 	descs <- a.info
 	descs <- a.durationSeconds
+	descs <- a.jobsRunning
+	descs <- a.jobsFailed
 
 	for name := range a.app.Metrics.Counters {
 		descs <- a.describe(name)
@@ -268,6 +283,18 @@ func (a *applicationCollector) Collect(metrics chan<- prometheus.Metric) {
 	metrics <- prometheus.MustNewConstMetric(a.info, prometheus.GaugeValue, 1, a.app.Attempts[0].AppSparkVersion)
 	metrics <- prometheus.MustNewConstMetric(a.durationSeconds, prometheus.GaugeValue, float64(a.calculateDuration()))
 
+	var runningJobs, failedJobs int
+	for _, job := range a.app.Jobs {
+		switch job.Status {
+		case client.JobStatusRunning:
+			runningJobs++
+		case client.JobStatusFailed:
+			failedJobs++
+		}
+	}
+	metrics <- prometheus.MustNewConstMetric(a.jobsRunning, prometheus.GaugeValue, float64(runningJobs))
+	metrics <- prometheus.MustNewConstMetric(a.jobsFailed, prometheus.GaugeValue, float64(failedJobs))
+
 	for name, value := range a.app.Metrics.Counters {
 		metrics <- prometheus.MustNewConstMetric(a.describe(name), prometheus.CounterValue, float64(value.Count))
 	}
@@ -53,6 +53,12 @@ func TestApplicationRegistry(t *testing.T) {
 			# HELP spark_executor_count Current executor count for the application
 			# TYPE spark_executor_count gauge
 			spark_executor_count{application_id="some-id",application_name="some-name"} 0
+			# HELP spark_jobs_failed_count Current count of failed jobs retained by the Spark UI for the application
+			# TYPE spark_jobs_failed_count gauge
+			spark_jobs_failed_count{application_id="some-id",application_name="some-name"} 0
+			# HELP spark_jobs_running_count Current count of running jobs for the application
+			# TYPE spark_jobs_running_count gauge
+			spark_jobs_running_count{application_id="some-id",application_name="some-name"} 0
 `
 
 		assert.NoError(tt, testutil.CollectAndCompare(collector, strings.NewReader(expectedOutput)))
@@ -98,7 +104,7 @@ func TestApplicationRegistry(t *testing.T) {
 			spark_executor_gc_time_total_milliseconds{application_id="update",application_name="update",executor_id="0"} 0
 			# HELP spark_executor_info General executor info
 			# TYPE spark_executor_info gauge
-			spark_executor_info{active="true",add_time="",application_id="update",application_name="update",blacklisted="false",executor_id="0",removed_time=""} 1
+			spark_executor_info{active="true",add_time="",application_id="update",application_name="update",blacklisted="false",excluded="false",executor_id="0",removed_time="",resource_profile_id="0"} 1
 			# HELP spark_executor_input_bytes_total Total amount of bytes processed by executor
 			# TYPE spark_executor_input_bytes_total counter
 			spark_executor_input_bytes_total{application_id="update",application_name="update",executor_id="0"} 0
@@ -129,6 +135,12 @@ func TestApplicationRegistry(t *testing.T) {
 			# HELP spark_executor_tasks_total Total number of tasks the executor has been assigned
 			# TYPE spark_executor_tasks_total counter
 			spark_executor_tasks_total{application_id="update",application_name="update",executor_id="0"} 0
+			# HELP spark_jobs_failed_count Current count of failed jobs retained by the Spark UI for the application
+			# TYPE spark_jobs_failed_count gauge
+			spark_jobs_failed_count{application_id="update",application_name="update"} 0
+			# HELP spark_jobs_running_count Current count of running jobs for the application
+			# TYPE spark_jobs_running_count gauge
+			spark_jobs_running_count{application_id="update",application_name="update"} 0
 `
 		assert.NotNil(tt, collector)
 		assert.NoError(tt, testutil.CollectAndCompare(collector, strings.NewReader(expectedOutput)))
@@ -179,9 +191,9 @@ func TestApplicationRegistry(t *testing.T) {
 			spark_executor_gc_time_total_milliseconds{application_id="update",application_name="update",executor_id="added-executor"} 0
 			# HELP spark_executor_info General executor info
 			# TYPE spark_executor_info gauge
-			spark_executor_info{active="false",add_time="",application_id="update",application_name="update",blacklisted="false",executor_id="ignored-executor",removed_time=""} 1
-			spark_executor_info{active="true",add_time="",application_id="update",application_name="update",blacklisted="false",executor_id="0",removed_time=""} 1
-			spark_executor_info{active="true",add_time="",application_id="update",application_name="update",blacklisted="false",executor_id="added-executor",removed_time=""} 1
+			spark_executor_info{active="false",add_time="",application_id="update",application_name="update",blacklisted="false",excluded="false",executor_id="ignored-executor",removed_time="",resource_profile_id="0"} 1
+			spark_executor_info{active="true",add_time="",application_id="update",application_name="update",blacklisted="false",excluded="false",executor_id="0",removed_time="",resource_profile_id="0"} 1
+			spark_executor_info{active="true",add_time="",application_id="update",application_name="update",blacklisted="false",excluded="false",executor_id="added-executor",removed_time="",resource_profile_id="0"} 1
 			# HELP spark_executor_input_bytes_total Total amount of bytes processed by executor
 			# TYPE spark_executor_input_bytes_total counter
 			spark_executor_input_bytes_total{application_id="update",application_name="update",executor_id="0"} 0
@@ -222,9 +234,45 @@ func TestApplicationRegistry(t *testing.T) {
 			# TYPE spark_executor_tasks_total counter
 			spark_executor_tasks_total{application_id="update",application_name="update",executor_id="0"} 0
 			spark_executor_tasks_total{application_id="update",application_name="update",executor_id="added-executor"} 0
+			# HELP spark_jobs_failed_count Current count of failed jobs retained by the Spark UI for the application
+			# TYPE spark_jobs_failed_count gauge
+			spark_jobs_failed_count{application_id="update",application_name="update"} 0
+			# HELP spark_jobs_running_count Current count of running jobs for the application
+			# TYPE spark_jobs_running_count gauge
+			spark_jobs_running_count{application_id="update",application_name="update"} 0
 `
 		assert.NoError(tt, testutil.CollectAndCompare(collector, strings.NewReader(expectedOutput)))
 	})
+	t.Run("RecordsJobCounts", func(tt *testing.T) {
+		info := &sparkapi.ApplicationInfo{
+			ID:              "jobs-id",
+			ApplicationName: "jobs-name",
+			Attempts: []sparkapiclient.Attempt{
+				{
+					Duration: time.Unix(0, 0).Unix(),
+				},
+			},
+			Jobs: []sparkapiclient.Job{
+				{JobID: 3, Status: sparkapiclient.JobStatusRunning},
+				{JobID: 2, Status: sparkapiclient.JobStatusFailed},
+				{JobID: 1, Status: sparkapiclient.JobStatusFailed},
+				{JobID: 0, Status: sparkapiclient.JobStatusSucceeded},
+			},
+		}
+		collector, err := registry.Register(info)
+		require.NoError(tt, err)
+		assert.NotNil(tt, collector)
+
+		expectedOutput := `
+			# HELP spark_jobs_failed_count Current count of failed jobs retained by the Spark UI for the application
+			# TYPE spark_jobs_failed_count gauge
+			spark_jobs_failed_count{application_id="jobs-id",application_name="jobs-name"} 2
+			# HELP spark_jobs_running_count Current count of running jobs for the application
+			# TYPE spark_jobs_running_count gauge
+			spark_jobs_running_count{application_id="jobs-id",application_name="jobs-name"} 1
+`
+		assert.NoError(tt, testutil.CollectAndCompare(collector, strings.NewReader(expectedOutput), "spark_jobs_failed_count", "spark_jobs_running_count"))
+	})
 	t.Run("RecordsApplicationSparkMetrics", func(tt *testing.T) {
 		info := &sparkapi.ApplicationInfo{
 			ID:              "some.id",
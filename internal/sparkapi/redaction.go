@@ -0,0 +1,123 @@
+package sparkapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	sparkRedactionRegexProperty = "spark.redaction.regex"
+
+	// defaultSparkRedactionRegex is the default value of spark.redaction.regex
+	defaultSparkRedactionRegex = "(?i)secret|password|token|access[.]key"
+
+	// RedactedValue is the marker Spark uses in place of redacted values
+	RedactedValue = "*********(redacted)"
+
+	// redactedHashLength is the number of hex characters of the value HMAC kept in a redacted value
+	redactedHashLength = 16
+)
+
+var defaultSparkRedactionPattern = regexp.MustCompile(defaultSparkRedactionRegex)
+
+// RedactionOptions configures the redaction of Spark properties
+type RedactionOptions struct {
+	// Patterns are matched against property keys and values in addition to the application's spark.redaction.regex
+	Patterns []*regexp.Regexp
+	// HashKey is the key of the HMAC of the value kept in redacted values,
+	// redacted values are replaced by the redaction marker only if there is no key
+	HashKey []byte
+}
+
+// NewRedactionOptions compiles the given redaction patterns
+func NewRedactionOptions(patterns []string, hashKey []byte) (RedactionOptions, error) {
+	opts := RedactionOptions{
+		Patterns: make([]*regexp.Regexp, 0, len(patterns)),
+		HashKey:  hashKey,
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return RedactionOptions{}, fmt.Errorf("invalid redaction pattern %q, %w", pattern, err)
+		}
+		opts.Patterns = append(opts.Patterns, re)
+	}
+	return opts, nil
+}
+
+// redactor redacts sensitive values of a Spark application
+type redactor struct {
+	patterns []*regexp.Regexp
+	hashKey  []byte
+}
+
+// newRedactor returns a redactor for the application with the given Spark properties.
+// A value is sensitive if it, or its key, matches the application's spark.redaction.regex,
+// or any of the operator wide redaction patterns.
+func newRedactor(sparkProperties map[string]string, opts RedactionOptions, logger logr.Logger) *redactor {
+	patterns := make([]*regexp.Regexp, 0, len(opts.Patterns)+1)
+	patterns = append(patterns, getSparkRedactionPattern(sparkProperties, logger))
+	patterns = append(patterns, opts.Patterns...)
+	return &redactor{
+		patterns: patterns,
+		hashKey:  opts.HashKey,
+	}
+}
+
+// redact returns the value, or a redaction marker followed by an HMAC of the value if it is sensitive,
+// so changed values can still be detected
+func (r *redactor) redact(key string, value string) string {
+	for _, re := range r.patterns {
+		if re.MatchString(key) || re.MatchString(value) {
+			return redactValue(value, r.hashKey)
+		}
+	}
+	return value
+}
+
+// redactSparkProperties replaces the values of sensitive Spark properties with a redaction marker
+func redactSparkProperties(sparkProperties map[string]string, r *redactor) map[string]string {
+	redacted := make(map[string]string, len(sparkProperties))
+	for key, value := range sparkProperties {
+		// The redaction regex itself is not a secret, and usually matches its own value
+		if key != sparkRedactionRegexProperty {
+			value = r.redact(key, value)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// getSparkRedactionPattern returns the application's redaction regex, Spark's default if it is not set.
+// Spark uses Java regular expressions, which are not all valid in Go.
+func getSparkRedactionPattern(sparkProperties map[string]string, logger logr.Logger) *regexp.Regexp {
+	regex, ok := sparkProperties[sparkRedactionRegexProperty]
+	if !ok || regex == "" {
+		return defaultSparkRedactionPattern
+	}
+
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		logger.Info(fmt.Sprintf("Could not compile %s, using default: %s", sparkRedactionRegexProperty, err.Error()))
+		return defaultSparkRedactionPattern
+	}
+
+	return re
+}
+
+// redactValue returns the redaction marker followed by a truncated HMAC-SHA256 of the value, keyed by the operator's
+// hash key so low entropy values can not be guessed from it. Without a key only the marker is returned.
+// Values already redacted by Spark are left as they are.
+func redactValue(value string, hashKey []byte) string {
+	if value == RedactedValue || len(hashKey) == 0 {
+		return RedactedValue
+	}
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write([]byte(value))
+	return fmt.Sprintf("%s:%s", RedactedValue, hex.EncodeToString(mac.Sum(nil))[:redactedHashLength])
+}
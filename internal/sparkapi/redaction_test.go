@@ -0,0 +1,115 @@
+package sparkapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRedactionOptions = RedactionOptions{HashKey: []byte("test-key")}
+
+func TestRedactSparkProperties(t *testing.T) {
+
+	logger := getTestLogger()
+
+	t.Run("whenDefaultRegex", func(tt *testing.T) {
+		props := map[string]string{
+			"spark.hadoop.fs.s3a.secret.key": "abc123",
+			"spark.hadoop.fs.s3a.access.key": "AKIA123",
+			"spark.app.name":                 "my-app",
+			"spark.driver.extraJavaOptions":  "-Dauth.token=xyz",
+			"spark.ui.filters.password":      RedactedValue,
+		}
+
+		res := redactSparkProperties(props, newRedactor(props, testRedactionOptions, logger))
+		assert.Equal(tt, "my-app", res["spark.app.name"])
+		assert.True(tt, strings.HasPrefix(res["spark.hadoop.fs.s3a.secret.key"], RedactedValue+":"))
+		assert.True(tt, strings.HasPrefix(res["spark.hadoop.fs.s3a.access.key"], RedactedValue+":"))
+		assert.True(tt, strings.HasPrefix(res["spark.driver.extraJavaOptions"], RedactedValue+":"))
+		assert.Equal(tt, RedactedValue, res["spark.ui.filters.password"])
+		assert.NotContains(tt, res["spark.hadoop.fs.s3a.secret.key"], "abc123")
+
+		// The input is not modified
+		assert.Equal(tt, "abc123", props["spark.hadoop.fs.s3a.secret.key"])
+	})
+
+	t.Run("whenApplicationRegex", func(tt *testing.T) {
+		props := map[string]string{
+			sparkRedactionRegexProperty:      "(?i)secret|jdbc",
+			"spark.hadoop.fs.s3a.secret.key": "abc123",
+			"spark.sql.catalog.url":          "jdbc:postgresql://db/app?password=hunter2",
+			"spark.auth.token":               "xyz",
+		}
+
+		res := redactSparkProperties(props, newRedactor(props, testRedactionOptions, logger))
+		assert.Equal(tt, "(?i)secret|jdbc", res[sparkRedactionRegexProperty])
+		assert.True(tt, strings.HasPrefix(res["spark.hadoop.fs.s3a.secret.key"], RedactedValue+":"))
+		assert.True(tt, strings.HasPrefix(res["spark.sql.catalog.url"], RedactedValue+":"))
+		assert.Equal(tt, "xyz", res["spark.auth.token"])
+	})
+
+	t.Run("whenApplicationRegexInvalid", func(tt *testing.T) {
+		props := map[string]string{
+			sparkRedactionRegexProperty: "(?i)(?!public)token",
+			"spark.auth.token":          "xyz",
+		}
+
+		res := redactSparkProperties(props, newRedactor(props, testRedactionOptions, logger))
+		assert.True(tt, strings.HasPrefix(res["spark.auth.token"], RedactedValue+":"))
+	})
+
+	t.Run("whenOperatorPatterns", func(tt *testing.T) {
+		opts, err := NewRedactionOptions([]string{"(?i)credential", "internal[.]example[.]com"}, testRedactionOptions.HashKey)
+		require.NoError(tt, err)
+
+		props := map[string]string{
+			"spark.hadoop.google.cloud.auth.credential": "{}",
+			"spark.kubernetes.container.image":          "registry.internal.example.com/spark:3.0.1",
+			"spark.executor.instances":                  "2",
+		}
+
+		res := redactSparkProperties(props, newRedactor(props, opts, logger))
+		assert.True(tt, strings.HasPrefix(res["spark.hadoop.google.cloud.auth.credential"], RedactedValue+":"))
+		assert.True(tt, strings.HasPrefix(res["spark.kubernetes.container.image"], RedactedValue+":"))
+		assert.Equal(tt, "2", res["spark.executor.instances"])
+	})
+
+	t.Run("whenOperatorPatternInvalid", func(tt *testing.T) {
+		_, err := NewRedactionOptions([]string{"secret("}, nil)
+		assert.Error(tt, err)
+	})
+
+	t.Run("whenValueChanges", func(tt *testing.T) {
+		redact := func(props map[string]string) map[string]string {
+			return redactSparkProperties(props, newRedactor(props, testRedactionOptions, logger))
+		}
+		first := redact(map[string]string{"spark.password": "a"})
+		second := redact(map[string]string{"spark.password": "a"})
+		changed := redact(map[string]string{"spark.password": "b"})
+
+		assert.Equal(tt, first["spark.password"], second["spark.password"])
+		assert.NotEqual(tt, first["spark.password"], changed["spark.password"])
+		assert.Len(tt, first["spark.password"], len(RedactedValue)+1+redactedHashLength)
+	})
+
+	t.Run("whenHashKeyChanges", func(tt *testing.T) {
+		props := map[string]string{"spark.password": "a"}
+		first := redactSparkProperties(props, newRedactor(props, testRedactionOptions, logger))
+		other := redactSparkProperties(props, newRedactor(props, RedactionOptions{HashKey: []byte("other-key")}, logger))
+
+		assert.NotEqual(tt, first["spark.password"], other["spark.password"])
+	})
+
+	t.Run("whenNoHashKey", func(tt *testing.T) {
+		props := map[string]string{
+			"spark.password": "a",
+			"spark.app.name": "my-app",
+		}
+
+		res := redactSparkProperties(props, newRedactor(props, RedactionOptions{}, logger))
+		assert.Equal(tt, RedactedValue, res["spark.password"])
+		assert.Equal(tt, "my-app", res["spark.app.name"])
+	})
+}
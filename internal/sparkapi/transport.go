@@ -0,0 +1,131 @@
+package sparkapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/spotinst/wave-operator/internal/config"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
+)
+
+// DefaultServiceHostTemplate is the service DNS name template used in service transport mode
+const DefaultServiceHostTemplate = "{{ .Name }}.{{ .Namespace }}.svc"
+
+// TransportOptions configures how the operator reaches the Spark API.
+// The options can be overridden per namespace with namespace annotations.
+type TransportOptions struct {
+	// Mode is the transport mode
+	Mode transport.Mode
+	// ServiceHostTemplate is a text/template for the service DNS name used in service mode,
+	// rendered with the service's Name and Namespace
+	ServiceHostTemplate string
+}
+
+// DefaultTransportOptions returns transport options that reach the Spark API directly
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		Mode:                transport.DirectMode,
+		ServiceHostTemplate: DefaultServiceHostTemplate,
+	}
+}
+
+type serviceHostTemplateData struct {
+	Name      string
+	Namespace string
+}
+
+// getNamespaceTransportOptions applies the transport annotations of the given namespace on top of the operator options
+func getNamespaceTransportOptions(clientSet kubernetes.Interface, namespace string, opts TransportOptions, logger logr.Logger) TransportOptions {
+	ns, err := clientSet.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err != nil {
+		logger.Info(fmt.Sprintf("Could not get namespace %q, using default Spark API transport: %s", namespace, err.Error()))
+		return opts
+	}
+
+	if conf := ns.Annotations[config.WaveConfigAnnotationSparkApiTransport]; conf != "" {
+		mode, err := transport.ParseMode(conf)
+		if err != nil {
+			logger.Info(fmt.Sprintf("Ignoring invalid Spark API transport namespace annotation: %s", err.Error()))
+		} else {
+			opts.Mode = mode
+		}
+	}
+
+	if conf := ns.Annotations[config.WaveConfigAnnotationSparkApiServiceHost]; conf != "" {
+		opts.ServiceHostTemplate = conf
+	}
+
+	return opts
+}
+
+// getTransportConfig returns the client transport configuration for reaching the Spark API served by the given service
+func getTransportConfig(service *corev1.Service, opts TransportOptions) (sparkapiclient.TransportConfig, error) {
+	conf := sparkapiclient.TransportConfig{
+		Mode: opts.Mode,
+	}
+
+	if opts.Mode != transport.ServiceMode {
+		return conf, nil
+	}
+
+	if service == nil {
+		return conf, fmt.Errorf("service is nil")
+	}
+
+	host, err := renderServiceHost(opts.ServiceHostTemplate, service)
+	if err != nil {
+		return conf, fmt.Errorf("could not render service host, %w", err)
+	}
+	conf.ServiceHost = host
+
+	return conf, nil
+}
+
+func renderServiceHost(hostTemplate string, service *corev1.Service) (string, error) {
+	if hostTemplate == "" {
+		hostTemplate = DefaultServiceHostTemplate
+	}
+
+	tmpl, err := template.New("host").Parse(hostTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, serviceHostTemplateData{
+		Name:      service.Name,
+		Namespace: service.Namespace,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// getDriverService returns the service Spark creates for the given driver pod
+func getDriverService(clientSet kubernetes.Interface, driverPod *corev1.Pod) (*corev1.Service, error) {
+	services, err := clientSet.CoreV1().Services(driverPod.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list services, %w", err)
+	}
+
+	for _, service := range services.Items {
+		for _, ownerRef := range service.OwnerReferences {
+			if ownerRef.UID == driverPod.UID {
+				svc := service
+				return &svc, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("could not find driver service")
+}
@@ -0,0 +1,145 @@
+//go:generate mockgen -destination=mock_tracing/tracing_mock.go . Exporter
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/httpsend"
+)
+
+const tracesPath = "v1/traces"
+
+// Exporter exports the timeline of a Spark application as a trace
+type Exporter interface {
+	Export(ctx context.Context, app *v1alpha1.SparkApplication) error
+}
+
+type otlpExporter struct {
+	sender  *httpsend.Sender
+	url     string
+	headers map[string]string
+}
+
+// NewOTLPExporter creates an exporter sending traces to the OTLP/HTTP receiver at the given base URL,
+// e.g. http://otel-collector:4318, using the OTLP JSON encoding
+func NewOTLPExporter(endpoint string, headers map[string]string) Exporter {
+	return &otlpExporter{
+		sender:  httpsend.NewSender(),
+		url:     strings.TrimSuffix(endpoint, "/") + "/" + tracesPath,
+		headers: headers,
+	}
+}
+
+func (e *otlpExporter) Export(ctx context.Context, app *v1alpha1.SparkApplication) error {
+	err := e.sender.PostJSON(ctx, e.url, e.headers, NewApplicationTrace(app))
+	if err != nil {
+		return fmt.Errorf("could not send trace, %w", err)
+	}
+	return nil
+}
+
+// The OTLP JSON encoding of trace export requests,
+// see https://github.com/open-telemetry/opentelemetry-proto/blob/main/docs/specification.md#json-protobuf-encoding
+// Trace and span IDs are hex encoded, 64 bit integers are encoded as decimal strings.
+
+type ExportTraceServiceRequest struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+type ScopeSpans struct {
+	Scope InstrumentationScope `json:"scope"`
+	Spans []Span               `json:"spans"`
+}
+
+type InstrumentationScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type SpanKind int
+
+const SpanKindInternal SpanKind = 1
+
+type StatusCode int
+
+const (
+	StatusCodeUnset StatusCode = 0
+	StatusCodeOk    StatusCode = 1
+	StatusCodeError StatusCode = 2
+)
+
+type Span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              SpanKind   `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	Events            []Event    `json:"events,omitempty"`
+	Status            Status     `json:"status"`
+}
+
+type Event struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []KeyValue `json:"attributes,omitempty"`
+}
+
+type Status struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+type AnyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	ArrayValue  *ArrayValue `json:"arrayValue,omitempty"`
+}
+
+type ArrayValue struct {
+	Values []AnyValue `json:"values"`
+}
+
+func stringAttribute(key string, value string) KeyValue {
+	return KeyValue{Key: key, Value: AnyValue{StringValue: &value}}
+}
+
+func intAttribute(key string, value int64) KeyValue {
+	v := strconv.FormatInt(value, 10)
+	return KeyValue{Key: key, Value: AnyValue{IntValue: &v}}
+}
+
+func stringArrayAttribute(key string, values []string) KeyValue {
+	array := &ArrayValue{Values: make([]AnyValue, 0, len(values))}
+	for i := range values {
+		array.Values = append(array.Values, AnyValue{StringValue: &values[i]})
+	}
+	return KeyValue{Key: key, Value: AnyValue{ArrayValue: array}}
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+
+	t.Run("whenSuccessful", func(tt *testing.T) {
+		var received ExportTraceServiceRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, http.MethodPost, r.Method)
+			assert.Equal(tt, "/v1/traces", r.URL.Path)
+			assert.Equal(tt, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(tt, "secret", r.Header.Get("x-api-key"))
+			require.NoError(tt, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		exporter := NewOTLPExporter(server.URL+"/", map[string]string{"x-api-key": "secret"})
+		err := exporter.Export(context.TODO(), newTestApplication())
+		require.NoError(tt, err)
+		require.Len(tt, received.ResourceSpans, 1)
+		assert.Len(tt, received.ResourceSpans[0].ScopeSpans[0].Spans, 6)
+	})
+
+	t.Run("whenRejected", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid trace"))
+		}))
+		defer server.Close()
+
+		exporter := NewOTLPExporter(server.URL, nil)
+		err := exporter.Export(context.TODO(), newTestApplication())
+		require.Error(tt, err)
+		assert.Contains(tt, err.Error(), "code: 400")
+		assert.Contains(tt, err.Error(), "invalid trace")
+	})
+}
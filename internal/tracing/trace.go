@@ -0,0 +1,317 @@
+package tracing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+	sparkapiclient "github.com/spotinst/wave-operator/internal/sparkapi/client"
+	"github.com/spotinst/wave-operator/internal/version"
+)
+
+const (
+	scopeName   = "github.com/spotinst/wave-operator"
+	serviceName = "spark"
+
+	sparkExecutorIDLabel = "spark-exec-id"
+
+	jobStatusFailed = "FAILED"
+)
+
+// traceParentRegex matches a W3C trace context traceparent header value,
+// see https://www.w3.org/TR/trace-context/#traceparent-header
+var traceParentRegex = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// trace builds the spans of a single trace, span IDs are derived from the trace ID and a key
+// identifying the span within the trace, so exporting an application again results in the same IDs
+type trace struct {
+	traceID string
+	spans   []Span
+}
+
+// addSpan adds a span to the trace, the returned span is valid until the next span is added
+func (t *trace) addSpan(key string, parentSpanID string, name string, start time.Time, end time.Time, attributes ...KeyValue) *Span {
+	if end.Before(start) {
+		end = start
+	}
+	t.spans = append(t.spans, Span{
+		TraceID:           t.traceID,
+		SpanID:            hashHex(t.traceID+"/"+key, 8),
+		ParentSpanID:      parentSpanID,
+		Name:              name,
+		Kind:              SpanKindInternal,
+		StartTimeUnixNano: formatTime(start),
+		EndTimeUnixNano:   formatTime(end),
+		Attributes:        attributes,
+	})
+	return &t.spans[len(t.spans)-1]
+}
+
+// NewApplicationTrace returns the timeline of a finished Spark application as an OTLP trace export request.
+// The application is the root span, with child spans for the driver pending and running phases,
+// executor pod lifetimes and the jobs stored in the application's run statistics.
+// Stage level data is not stored in the SparkApplication resource, insights about stages are added as events.
+// If the application carries a W3C trace context the root span is part of that trace.
+func NewApplicationTrace(app *v1alpha1.SparkApplication) *ExportTraceServiceRequest {
+	data := app.Status.Data
+
+	t := &trace{traceID: hashHex("wave/"+string(app.UID), 16)}
+	rootParentSpanID := ""
+	if match := traceParentRegex.FindStringSubmatch(app.Annotations[config.WaveConfigAnnotationTraceParent]); match != nil {
+		t.traceID = match[1]
+		rootParentSpanID = match[2]
+	}
+
+	appStart, appEnd := getApplicationTimes(app)
+
+	name := app.Spec.ApplicationName
+	if name == "" {
+		name = app.Spec.ApplicationID
+	}
+
+	root := t.addSpan("application", rootParentSpanID, name, appStart, appEnd, getApplicationAttributes(app)...)
+	root.Status = getPodStatus(data.Driver)
+	for _, insight := range data.Insights {
+		root.Events = append(root.Events, Event{
+			TimeUnixNano: formatTime(appEnd),
+			Name:         string(insight.Type),
+			Attributes: []KeyValue{
+				intAttribute("spark.stage.id", insight.StageID),
+				intAttribute("spark.stage.attempt_id", insight.StageAttemptID),
+				stringAttribute("spark.stage.name", insight.StageName),
+				stringAttribute("message", insight.Message),
+			},
+		})
+	}
+	rootSpanID := root.SpanID
+
+	driverStart := getPodStart(data.Driver, appStart)
+	driverRunning, running := getPodPhaseTime(data.Driver, corev1.PodRunning)
+	driverEnd := getPodEnd(data.Driver, appEnd)
+	if !running {
+		driverRunning = driverEnd
+	}
+	t.addSpan("driver/pending", rootSpanID, "driver pending", driverStart, driverRunning, getPodAttributes(data.Driver)...)
+
+	jobsParentSpanID := rootSpanID
+	if running {
+		driverSpan := t.addSpan("driver/running", rootSpanID, "driver running", driverRunning, driverEnd, getPodAttributes(data.Driver)...)
+		driverSpan.Status = getPodStatus(data.Driver)
+		jobsParentSpanID = driverSpan.SpanID
+	}
+
+	for _, executor := range data.Executors {
+		executorID := executor.Labels[sparkExecutorIDLabel]
+		if executorID == "" {
+			executorID = executor.Name
+		}
+		span := t.addSpan("executor/"+executor.UID, rootSpanID, fmt.Sprintf("executor %s", executorID),
+			getPodStart(executor, appStart), getPodEnd(executor, appEnd),
+			append(getPodAttributes(executor), stringAttribute("spark.executor.id", executorID))...)
+		span.Status = getPodStatus(executor)
+	}
+
+	for _, job := range getJobs(data.RunStatistics) {
+		start, err := sparkapiclient.ParseTime(job.SubmissionTime)
+		if err != nil {
+			continue
+		}
+		attributes := []KeyValue{
+			intAttribute("spark.job.id", job.JobID),
+			stringAttribute("spark.job.status", job.Status),
+		}
+		if job.Description != "" {
+			attributes = append(attributes, stringAttribute("spark.job.description", job.Description))
+		}
+		if job.JobGroup != "" {
+			attributes = append(attributes, stringAttribute("spark.job.group", job.JobGroup))
+		}
+		span := t.addSpan(fmt.Sprintf("job/%d", job.JobID), jobsParentSpanID, fmt.Sprintf("job %d", job.JobID),
+			start, start.Add(time.Duration(job.Duration)*time.Millisecond), attributes...)
+		if job.Status == jobStatusFailed {
+			span.Status = Status{Code: StatusCodeError, Message: job.Name}
+		}
+	}
+
+	return &ExportTraceServiceRequest{
+		ResourceSpans: []ResourceSpans{
+			{
+				Resource: Resource{
+					Attributes: []KeyValue{
+						stringAttribute("service.name", serviceName),
+						stringAttribute("k8s.namespace.name", app.Namespace),
+					},
+				},
+				ScopeSpans: []ScopeSpans{
+					{
+						Scope: InstrumentationScope{Name: scopeName, Version: version.BuildVersion},
+						Spans: t.spans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func getApplicationAttributes(app *v1alpha1.SparkApplication) []KeyValue {
+	data := app.Status.Data
+
+	attributes := []KeyValue{
+		stringAttribute("k8s.namespace.name", app.Namespace),
+		stringAttribute("spark.app.id", app.Spec.ApplicationID),
+		stringAttribute("spark.app.name", app.Spec.ApplicationName),
+		stringAttribute("wave.heritage", string(app.Spec.Heritage)),
+	}
+	if workloadType := app.Annotations[config.WaveConfigAnnotationWorkloadType]; workloadType != "" {
+		attributes = append(attributes, stringAttribute("wave.workload_type", workloadType))
+	}
+	if data.Environment != nil && data.Environment.SparkVersion != "" {
+		attributes = append(attributes, stringAttribute("spark.version", data.Environment.SparkVersion))
+	}
+
+	instanceTypes := make(map[string]bool)
+	lifecycles := make(map[string]bool)
+	for _, pod := range append([]v1alpha1.Pod{data.Driver}, data.Executors...) {
+		if pod.InstanceType != "" {
+			instanceTypes[pod.InstanceType] = true
+		}
+		if pod.InstanceLifecycle != "" {
+			lifecycles[pod.InstanceLifecycle] = true
+		}
+	}
+	attributes = append(attributes,
+		intAttribute("spark.executor.count", int64(len(data.Executors))),
+		stringArrayAttribute("wave.instance_types", sortedKeys(instanceTypes)),
+		stringArrayAttribute("wave.instance_lifecycles", sortedKeys(lifecycles)))
+
+	return attributes
+}
+
+func getPodAttributes(pod v1alpha1.Pod) []KeyValue {
+	attributes := []KeyValue{
+		stringAttribute("k8s.pod.name", pod.Name),
+		stringAttribute("k8s.pod.uid", pod.UID),
+		stringAttribute("k8s.pod.phase", string(pod.Phase)),
+	}
+	if pod.NodeName != "" {
+		attributes = append(attributes, stringAttribute("k8s.node.name", pod.NodeName))
+	}
+	if pod.InstanceType != "" {
+		attributes = append(attributes, stringAttribute("wave.instance_type", pod.InstanceType))
+	}
+	if pod.InstanceLifecycle != "" {
+		attributes = append(attributes, stringAttribute("wave.instance_lifecycle", pod.InstanceLifecycle))
+	}
+	return attributes
+}
+
+func getPodStatus(pod v1alpha1.Pod) Status {
+	switch pod.Phase {
+	case corev1.PodSucceeded:
+		return Status{Code: StatusCodeOk}
+	case corev1.PodFailed:
+		return Status{Code: StatusCodeError, Message: fmt.Sprintf("pod %s failed", pod.Name)}
+	default:
+		return Status{Code: StatusCodeUnset}
+	}
+}
+
+// getApplicationTimes returns the start and end of the application, from the driver pod's
+// state history, falling back to the epochs of the latest Spark application attempt
+func getApplicationTimes(app *v1alpha1.SparkApplication) (time.Time, time.Time) {
+	var attempt v1alpha1.Attempt
+	if attempts := app.Status.Data.RunStatistics.Attempts; len(attempts) > 0 {
+		attempt = attempts[0]
+	}
+
+	start := app.CreationTimestamp.Time
+	if attempt.StartTimeEpoch > 0 {
+		start = time.Unix(0, attempt.StartTimeEpoch*int64(time.Millisecond))
+	}
+	start = getPodStart(app.Status.Data.Driver, start)
+
+	end := start
+	if attempt.EndTimeEpoch > 0 {
+		end = time.Unix(0, attempt.EndTimeEpoch*int64(time.Millisecond))
+	}
+	end = getPodEnd(app.Status.Data.Driver, end)
+
+	return start, end
+}
+
+// getPodStart returns the creation time of the pod, or the fallback if unknown
+func getPodStart(pod v1alpha1.Pod, fallback time.Time) time.Time {
+	if !pod.CreationTimestamp.IsZero() {
+		return pod.CreationTimestamp.Time
+	}
+	if len(pod.StateHistory) > 0 {
+		return pod.StateHistory[0].Timestamp.Time
+	}
+	return fallback
+}
+
+// getPodPhaseTime returns the time the pod was first seen in the phase
+func getPodPhaseTime(pod v1alpha1.Pod, phase corev1.PodPhase) (time.Time, bool) {
+	for _, entry := range pod.StateHistory {
+		if entry.Phase == phase {
+			return entry.Timestamp.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// getPodEnd returns the time the pod terminated or was deleted, or the fallback if unknown
+func getPodEnd(pod v1alpha1.Pod, fallback time.Time) time.Time {
+	for _, entry := range pod.StateHistory {
+		if entry.Phase == corev1.PodSucceeded || entry.Phase == corev1.PodFailed {
+			return entry.Timestamp.Time
+		}
+	}
+	if pod.DeletionTimestamp != nil {
+		return pod.DeletionTimestamp.Time
+	}
+	return fallback
+}
+
+// getJobs returns the jobs stored in the run statistics, the longest job and the most recent failed jobs
+func getJobs(statistics v1alpha1.Statistics) []v1alpha1.Job {
+	if statistics.Jobs == nil {
+		return nil
+	}
+
+	jobs := make([]v1alpha1.Job, 0, len(statistics.Jobs.FailedJobs)+1)
+	seen := make(map[int64]bool)
+	if statistics.Jobs.Longest != nil {
+		jobs = append(jobs, *statistics.Jobs.Longest)
+		seen[statistics.Jobs.Longest.JobID] = true
+	}
+	for _, job := range statistics.Jobs.FailedJobs {
+		if !seen[job.JobID] {
+			jobs = append(jobs, job)
+			seen[job.JobID] = true
+		}
+	}
+	return jobs
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hashHex returns the first n bytes of the SHA-256 hash of the value, hex encoded
+func hashHex(value string, n int) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:n])
+}
@@ -0,0 +1,183 @@
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/internal/config"
+)
+
+var testStart = time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestPod(name string, uid string, phase corev1.PodPhase, offsets ...time.Duration) v1alpha1.Pod {
+	phases := []corev1.PodPhase{corev1.PodPending, corev1.PodRunning, phase}
+	pod := v1alpha1.Pod{
+		Name:              name,
+		UID:               uid,
+		Phase:             phase,
+		CreationTimestamp: metav1.NewTime(testStart.Add(offsets[0])),
+		Labels:            map[string]string{},
+		NodeName:          "node-" + name,
+		InstanceType:      "m5.xlarge",
+		InstanceLifecycle: "spot",
+	}
+	for i, offset := range offsets {
+		pod.StateHistory = append(pod.StateHistory, v1alpha1.PodStateHistoryEntry{
+			Timestamp: metav1.NewTime(testStart.Add(offset)),
+			Phase:     phases[i],
+		})
+	}
+	return pod
+}
+
+func newTestApplication() *v1alpha1.SparkApplication {
+	executor := newTestPod("exec-1", "exec-uid-1", corev1.PodFailed, time.Minute, 2*time.Minute, 5*time.Minute)
+	executor.Labels[sparkExecutorIDLabel] = "1"
+	executor.InstanceLifecycle = "od"
+	executor.InstanceType = "r5.large"
+
+	app := &v1alpha1.SparkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "spark-123",
+			Namespace:   "spark-jobs",
+			UID:         "app-uid",
+			Annotations: map[string]string{},
+		},
+		Spec: v1alpha1.SparkApplicationSpec{
+			ApplicationID:   "spark-123",
+			ApplicationName: "my-app",
+			Heritage:        v1alpha1.SparkHeritageSubmit,
+		},
+	}
+	app.Status.Data.Driver = newTestPod("driver", "driver-uid", corev1.PodSucceeded, 0, 30*time.Second, 10*time.Minute)
+	app.Status.Data.Executors = []v1alpha1.Pod{executor}
+	app.Status.Data.RunStatistics.Jobs = &v1alpha1.JobStatistics{
+		Longest: &v1alpha1.Job{JobID: 3, Name: "count", Status: "SUCCEEDED", SubmissionTime: "2021-03-01T10:02:00.000GMT", Duration: 60000},
+		FailedJobs: []v1alpha1.Job{
+			{JobID: 4, Name: "collect", Status: "FAILED", SubmissionTime: "2021-03-01T10:04:00.000GMT", Duration: 1000},
+			{JobID: 3, Name: "count", Status: "SUCCEEDED", SubmissionTime: "2021-03-01T10:02:00.000GMT", Duration: 60000},
+		},
+	}
+	app.Status.Data.Insights = []v1alpha1.Insight{
+		{Type: v1alpha1.InsightTypeSpill, StageID: 2, Message: "spilled"},
+	}
+	return app
+}
+
+func getSpans(req *ExportTraceServiceRequest) map[string]Span {
+	spans := make(map[string]Span)
+	for _, span := range req.ResourceSpans[0].ScopeSpans[0].Spans {
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func getAttribute(attributes []KeyValue, key string) *AnyValue {
+	for _, attribute := range attributes {
+		if attribute.Key == key {
+			return &attribute.Value
+		}
+	}
+	return nil
+}
+
+func TestNewApplicationTrace(t *testing.T) {
+
+	t.Run("whenFinished", func(tt *testing.T) {
+		req := NewApplicationTrace(newTestApplication())
+		require.Len(tt, req.ResourceSpans, 1)
+
+		spans := getSpans(req)
+		require.Len(tt, spans, 6)
+
+		root := spans["my-app"]
+		assert.Empty(tt, root.ParentSpanID)
+		assert.Len(tt, root.TraceID, 32)
+		assert.Len(tt, root.SpanID, 16)
+		assert.Equal(tt, formatTime(testStart), root.StartTimeUnixNano)
+		assert.Equal(tt, formatTime(testStart.Add(10*time.Minute)), root.EndTimeUnixNano)
+		assert.Equal(tt, StatusCodeOk, root.Status.Code)
+		assert.Equal(tt, "spark-jobs", *getAttribute(root.Attributes, "k8s.namespace.name").StringValue)
+		assert.Equal(tt, "spark-submit", *getAttribute(root.Attributes, "wave.heritage").StringValue)
+		instanceTypes := getAttribute(root.Attributes, "wave.instance_types").ArrayValue.Values
+		require.Len(tt, instanceTypes, 2)
+		assert.Equal(tt, "m5.xlarge", *instanceTypes[0].StringValue)
+		assert.Equal(tt, "r5.large", *instanceTypes[1].StringValue)
+		require.Len(tt, root.Events, 1)
+		assert.Equal(tt, "Spill", root.Events[0].Name)
+
+		pending := spans["driver pending"]
+		assert.Equal(tt, root.SpanID, pending.ParentSpanID)
+		assert.Equal(tt, formatTime(testStart.Add(30*time.Second)), pending.EndTimeUnixNano)
+
+		running := spans["driver running"]
+		assert.Equal(tt, root.SpanID, running.ParentSpanID)
+		assert.Equal(tt, formatTime(testStart.Add(30*time.Second)), running.StartTimeUnixNano)
+		assert.Equal(tt, formatTime(testStart.Add(10*time.Minute)), running.EndTimeUnixNano)
+
+		executor := spans["executor 1"]
+		assert.Equal(tt, root.SpanID, executor.ParentSpanID)
+		assert.Equal(tt, formatTime(testStart.Add(5*time.Minute)), executor.EndTimeUnixNano)
+		assert.Equal(tt, StatusCodeError, executor.Status.Code)
+		assert.Equal(tt, "od", *getAttribute(executor.Attributes, "wave.instance_lifecycle").StringValue)
+
+		longest := spans["job 3"]
+		assert.Equal(tt, running.SpanID, longest.ParentSpanID)
+		assert.Equal(tt, formatTime(testStart.Add(3*time.Minute)), longest.EndTimeUnixNano)
+		assert.Equal(tt, StatusCodeUnset, longest.Status.Code)
+
+		failed := spans["job 4"]
+		assert.Equal(tt, StatusCodeError, failed.Status.Code)
+
+		for _, span := range spans {
+			assert.Equal(tt, root.TraceID, span.TraceID)
+		}
+	})
+
+	t.Run("whenExportedAgain", func(tt *testing.T) {
+		first := getSpans(NewApplicationTrace(newTestApplication()))
+		second := getSpans(NewApplicationTrace(newTestApplication()))
+		assert.Equal(tt, first, second)
+	})
+
+	t.Run("whenTraceParent", func(tt *testing.T) {
+		app := newTestApplication()
+		app.Annotations[config.WaveConfigAnnotationTraceParent] = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+		spans := getSpans(NewApplicationTrace(app))
+		root := spans["my-app"]
+		assert.Equal(tt, "4bf92f3577b34da6a3ce929d0e0e4736", root.TraceID)
+		assert.Equal(tt, "00f067aa0ba902b7", root.ParentSpanID)
+		assert.Equal(tt, "4bf92f3577b34da6a3ce929d0e0e4736", spans["executor 1"].TraceID)
+	})
+
+	t.Run("whenTraceParentInvalid", func(tt *testing.T) {
+		app := newTestApplication()
+		app.Annotations[config.WaveConfigAnnotationTraceParent] = "not-a-trace-parent"
+
+		root := getSpans(NewApplicationTrace(app))["my-app"]
+		assert.Empty(tt, root.ParentSpanID)
+		assert.Equal(tt, hashHex("wave/app-uid", 16), root.TraceID)
+	})
+
+	t.Run("whenDriverNeverRan", func(tt *testing.T) {
+		app := newTestApplication()
+		app.Status.Data.Driver = newTestPod("driver", "driver-uid", corev1.PodFailed, 0)
+		app.Status.Data.Driver.StateHistory = append(app.Status.Data.Driver.StateHistory, v1alpha1.PodStateHistoryEntry{
+			Timestamp: metav1.NewTime(testStart.Add(time.Minute)),
+			Phase:     corev1.PodFailed,
+		})
+
+		spans := getSpans(NewApplicationTrace(app))
+		assert.NotContains(tt, spans, "driver running")
+		assert.Equal(tt, formatTime(testStart.Add(time.Minute)), spans["driver pending"].EndTimeUnixNano)
+		assert.Equal(tt, spans["my-app"].SpanID, spans["job 3"].ParentSpanID)
+		assert.Equal(tt, StatusCodeError, spans["my-app"].Status.Code)
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -28,19 +29,28 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/spotinst/wave-operator/admission"
 	"github.com/spotinst/wave-operator/api/v1alpha1"
+	"github.com/spotinst/wave-operator/cloudstorage"
 	"github.com/spotinst/wave-operator/controllers"
 	"github.com/spotinst/wave-operator/install"
 	"github.com/spotinst/wave-operator/internal/aws"
+	waveconfig "github.com/spotinst/wave-operator/internal/config"
 	"github.com/spotinst/wave-operator/internal/config/instances"
+	"github.com/spotinst/wave-operator/internal/lineage"
 	"github.com/spotinst/wave-operator/internal/logger"
+	"github.com/spotinst/wave-operator/internal/notifications"
 	"github.com/spotinst/wave-operator/internal/ocean"
 	"github.com/spotinst/wave-operator/internal/sparkapi"
+	"github.com/spotinst/wave-operator/internal/sparkapi/client/transport"
 	"github.com/spotinst/wave-operator/internal/spot/client"
 	spotconfig "github.com/spotinst/wave-operator/internal/spot/client/config"
+	"github.com/spotinst/wave-operator/internal/tracing"
 	"github.com/spotinst/wave-operator/internal/version"
+	sparkoperator "github.com/spotinst/wave-operator/sparkoperator.k8s.io/v1beta2"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -53,28 +63,54 @@ func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = apiextensions.AddToScheme(scheme)
 	_ = v1alpha1.AddToScheme(scheme)
+	_ = sparkoperator.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var metricsAddr string
+	var probeAddr string
 	var enableLeaderElection bool
+	var configFile string
+	var sparkApiTransport string
+	var sparkApiServiceHost string
 	flag.StringVar(&metricsAddr, "metrics-addr", "0.0.0.0:8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&probeAddr, "health-probe-addr", "0.0.0.0:8081", "The address the health probe endpoints bind to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&configFile, "config", "", "Path to the operator configuration file.")
+	flag.StringVar(&sparkApiTransport, "spark-api-transport", string(transport.DirectMode),
+		"How the Spark API is reached, one of direct, proxy (Kubernetes API server proxy) or service (service DNS name). "+
+			"Can be overridden per namespace with the "+waveconfig.WaveConfigAnnotationSparkApiTransport+" annotation.")
+	flag.StringVar(&sparkApiServiceHost, "spark-api-service-host", sparkapi.DefaultServiceHostTemplate,
+		"Template for the service DNS name used in service transport mode. "+
+			"Can be overridden per namespace with the "+waveconfig.WaveConfigAnnotationSparkApiServiceHost+" annotation.")
 	flag.Parse()
 
 	log := logger.New()
 	ctrl.SetLogger(log)
 
+	operatorConfig, err := waveconfig.LoadOperatorConfig(configFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load operator configuration")
+		os.Exit(1)
+	}
+
+	sparkApiTransportMode, err := transport.ParseMode(sparkApiTransport)
+	if err != nil {
+		setupLog.Error(err, "invalid spark api transport")
+		os.Exit(1)
+	}
+
 	config := ctrl.GetConfigOrDie()
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		Port:               9443,
-		LeaderElection:     enableLeaderElection,
-		LeaderElectionID:   "9c5d2999.wave.spot.io",
+		Scheme:                 scheme,
+		MetricsBindAddress:     metricsAddr,
+		HealthProbeBindAddress: probeAddr,
+		Port:                   9443,
+		LeaderElection:         enableLeaderElection,
+		LeaderElectionID:       "9c5d2999.wave.spot.io",
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -116,10 +152,33 @@ func main() {
 		os.Exit(1)
 	}
 
+	sparkApiOptions := sparkapi.DefaultOptions()
+	sparkApiOptions.Transport.Mode = sparkApiTransportMode
+	sparkApiOptions.Transport.ServiceHostTemplate = sparkApiServiceHost
+	if len(operatorConfig.HistoryServers) > 0 {
+		sparkApiOptions.HistoryServers = operatorConfig.HistoryServers
+	}
+	sparkApiOptions.EventLogs.Enabled = operatorConfig.EventLogs.Enabled
+	sparkApiOptions.EventLogs.StorageProvider = storageProvider
+	if objectStore, ok := storageProvider.(cloudstorage.ObjectStore); ok {
+		sparkApiOptions.EventLogs.ObjectStore = objectStore
+	}
+	redactionHashKey, err := operatorConfig.Redaction.LoadHashKey()
+	if err != nil {
+		setupLog.Error(err, "invalid redaction configuration")
+		os.Exit(1)
+	}
+	sparkApiOptions.Redaction, err = sparkapi.NewRedactionOptions(operatorConfig.Redaction.Patterns, redactionHashKey)
+	if err != nil {
+		setupLog.Error(err, "invalid redaction configuration")
+		os.Exit(1)
+	}
+
 	sparkPodController := controllers.NewSparkPodReconciler(
 		mgr.GetClient(),
+		mgr.GetAPIReader(),
 		clientSet,
-		sparkapi.GetManager,
+		sparkapi.NewManagerGetter(sparkApiOptions),
 		ctrl.Log.WithName("controllers").WithName("SparkPod"),
 		mgr.GetScheme())
 
@@ -128,6 +187,79 @@ func main() {
 		os.Exit(1)
 	}
 
+	if operatorConfig.Tracing.Endpoint != "" {
+		traceController := controllers.NewSparkApplicationTraceReconciler(
+			mgr.GetClient(),
+			tracing.NewOTLPExporter(operatorConfig.Tracing.Endpoint, operatorConfig.Tracing.Headers),
+			time.Now,
+			ctrl.Log.WithName("controllers").WithName("SparkApplicationTracing"))
+		if err = traceController.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SparkApplicationTracing")
+			os.Exit(1)
+		}
+	}
+
+	if operatorConfig.OpenLineage.URL != "" {
+		lineageController := controllers.NewSparkApplicationLineageReconciler(
+			mgr.GetClient(),
+			lineage.NewHTTPEmitter(operatorConfig.OpenLineage.URL, operatorConfig.OpenLineage.Headers),
+			time.Now,
+			ctrl.Log.WithName("controllers").WithName("SparkApplicationLineage"))
+		if err = lineageController.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SparkApplicationLineage")
+			os.Exit(1)
+		}
+	}
+
+	if len(operatorConfig.Notifications.Rules) > 0 {
+		notificationRouter, err := notifications.NewRouter(operatorConfig.Notifications)
+		if err != nil {
+			setupLog.Error(err, "invalid notification rules")
+			os.Exit(1)
+		}
+		notificationController := controllers.NewSparkApplicationNotificationReconciler(
+			mgr.GetClient(),
+			notificationRouter,
+			notifications.NewWebhookSender(),
+			time.Now,
+			ctrl.Log.WithName("controllers").WithName("SparkApplicationNotification"))
+		if err = notificationController.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SparkApplicationNotification")
+			os.Exit(1)
+		}
+	}
+
+	durationController := controllers.NewSparkApplicationDurationReconciler(
+		mgr.GetClient(),
+		mgr.GetEventRecorderFor("wave-operator"),
+		time.Now,
+		ctrl.Log.WithName("controllers").WithName("SparkApplicationDuration"))
+	if err = durationController.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SparkApplicationDuration")
+		os.Exit(1)
+	}
+
+	if operatorConfig.NodeInterruption.Enabled {
+		nodeInterruptionController := controllers.NewNodeInterruptionReconciler(
+			mgr.GetClient(),
+			operatorConfig.NodeInterruption,
+			mgr.GetEventRecorderFor("wave-operator"),
+			time.Now,
+			ctrl.Log.WithName("controllers").WithName("NodeInterruption"))
+		if err = nodeInterruptionController.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "NodeInterruption")
+			os.Exit(1)
+		}
+	}
+
+	// Cluster wide Spark metrics, aggregated over the SparkApplication resources in the manager's cache
+	sparkApplicationCollector := controllers.NewSparkApplicationCollector(mgr.GetClient(), time.Now,
+		ctrl.Log.WithName("controllers").WithName("SparkApplicationCollector"))
+	if err := metrics.Registry.Register(sparkApplicationCollector); err != nil {
+		setupLog.Error(err, "unable to register spark application metrics collector")
+		os.Exit(1)
+	}
+
 	spotClient, err := client.NewClient(clientSet, log.WithName("spotClient"))
 	if err != nil {
 		setupLog.Error(err, "could not create spot client")
@@ -140,12 +272,29 @@ func main() {
 		os.Exit(1)
 	}
 
-	ac := admission.NewAdmissionController(clientSet, storageProvider, instanceTypeManager, log.WithName("admission"))
+	ac := admission.NewAdmissionController(
+		clientSet,
+		storageProvider,
+		instanceTypeManager,
+		operatorConfig.StorageSync,
+		operatorConfig.SparkConf,
+		operatorConfig.AdmissionWebhook,
+		admission.NewPolicyProvider(mgr.GetClient(), log.WithName("policyProvider")),
+		admission.NewExecutorPlacement(mgr.GetClient(), log.WithName("executorPlacement")),
+		log.WithName("admission"))
 	err = mgr.Add(ac)
 	if err != nil {
 		setupLog.Error(err, "unable to add admission controller")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("webhook-certificate", ac.CheckCertificate); err != nil {
+		setupLog.Error(err, "unable to add readiness check")
+		os.Exit(1)
+	}
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to add health check")
+		os.Exit(1)
+	}
 
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("starting manager", "buildVersion", version.BuildVersion, "buildDate", version.BuildDate)
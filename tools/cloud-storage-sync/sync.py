@@ -14,7 +14,7 @@ frequency = "forever"
 if len(sys.argv) > 3:
     frequency = sys.argv[3]
 
-SYNC_INTERVAL_SECONDS = 5
+SYNC_INTERVAL_SECONDS = float(os.getenv("SYNC_INTERVAL_SECONDS", "5"))
 V1_PREFIX = "spark-"
 V2_PREFIX = "eventlog_v2_"
 